@@ -7,10 +7,16 @@ import (
 	"flag"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/captv89/nmea-simulator/pkg/mqtt"
 	"github.com/captv89/nmea-simulator/pkg/network"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/radar"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/talker"
 	"github.com/captv89/nmea-simulator/pkg/nmea2000"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
 	"github.com/rs/zerolog"
 )
 
@@ -23,9 +29,126 @@ func main() {
 	nmea0183TCPPort := flag.Int("nmea0183-tcp-port", 10110, "TCP server port for NMEA 0183")
 	baudRate := flag.Int("baud", 4800, "Baud rate for NMEA 0183 TCP output (4800, 9600, 19200, 38400)")
 
+	// Signal K flags
+	enableSignalK := flag.Bool("signalk", false, "Serve Signal K deltas and the full model alongside the NMEA servers of either protocol")
+	signalKPort := flag.Int("signalk-port", 3000, "HTTP and WebSocket server port for Signal K")
+
 	// NMEA 2000 flags
 	nmea2000WSPort := flag.Int("nmea2000-ws-port", 8081, "WebSocket server port for NMEA 2000")
 	nmea2000TCPPort := flag.Int("nmea2000-tcp-port", 10200, "TCP port for NMEA 2000")
+	nmea2000Source := flag.Bool("nmea2000-source", false, "Append the source address to $PNMEA2K lines as $PNMEA2K,PGN,Length,Data,Source")
+
+	// AIS flags
+	enableAIS := flag.Bool("ais", false, "Generate AIS VDM/VDO sentences and AIS PGNs for simulated targets")
+	aisTargets := flag.Int("ais-targets", 5, "Number of simulated AIS targets around own ship")
+	aisRadius := flag.Float64("ais-radius", 5.0, "Radius in nautical miles within which AIS targets are placed")
+	encounters := flag.String("encounters", "", "Comma-separated collision-course targets as type:cpa:tcpa[:speed], e.g. crossing:0.5:6m")
+
+	// Radar flags
+	enableRadar := flag.Bool("radar", false, "Generate ARPA radar TTM/TLL/TTD/OSD sentences for simulated targets")
+	radarRange := flag.Float64("radar-range", 12.0, "Radar detection range in nautical miles")
+
+	// GNSS flags
+	enableGNSS := flag.Bool("gnss", false, "Generate GSA/GSV/GST/GNS/ZDA sentences and PGNs 129539/129540 from the simulated satellite constellation")
+	gnssSystems := flag.String("gnss-systems", "gps,glonass,galileo,beidou", "Comma-separated satellite systems to track")
+	gnssSigma := flag.Float64("gnss-sigma", 1.5, "GNSS horizontal white noise per axis in meters (1 sigma), vertical is scaled by 5/3")
+	gnssDrift := flag.Float64("gnss-drift", 2.0, "GNSS Gauss-Markov drift per axis in meters (1 sigma) with a 5 minute correlation time")
+	gnssMultipath := flag.Float64("gnss-multipath", 2.0, "Mean number of GNSS multipath jumps per hour")
+
+	// Heading flags
+	enableHeading := flag.Bool("heading", false, "Generate HDG/HDM/THS/ROT compass sentences")
+	variation := flag.String("variation", "wmm", "Magnetic variation in degrees, east positive, or wmm to compute it from the World Magnetic Model")
+	deviation := flag.String("deviation", "", "Deviation card of the default compass as heading:deviation pairs, e.g. 0:1.5,90:-2,180:0.5,270:2")
+
+	// Wind flags
+	enableWind := flag.Bool("wind", false, "Generate true wind MWV, MWD, VWR and VWT sentences")
+	windDirection := flag.Float64("wind-direction", 225, "Mean true wind direction in degrees, the direction the wind blows from")
+	windSpeed := flag.Float64("wind-speed", 12, "Mean true wind speed in knots")
+	windTrend := flag.Float64("wind-trend", 0, "Change of the wind direction in degrees per hour, positive veering")
+	windGusts := flag.Float64("wind-gusts", 30, "Mean number of gusts per hour")
+
+	// Current flags
+	current := flag.String("current", "", "Current acting on own ship: constant:SET:DRIFT, tidal:AXIS:MAJOR[:MINOR] or grid:PATH, e.g. tidal:045:2.5:0.3")
+
+	// Depth flags
+	bathymetryPath := flag.String("bathymetry", "", "Bathymetry grid, an ESRI ASCII grid (.asc) or XYZ file of longitude, latitude and depth")
+	bathymetryElevation := flag.Bool("bathymetry-elevation", false, "Bathymetry values are elevations, negative below chart datum (e.g. GEBCO)")
+	flatDepth := flag.Float64("depth", 25, "Charted depth in meters when no bathymetry grid is given")
+	tideRange := flag.Float64("tide-range", 0, "Tidal range in meters, with high water at start-up")
+	tideMean := flag.Float64("tide-mean", 0, "Mean height of tide above chart datum in meters")
+	transducerDepth := flag.Float64("transducer-depth", 0.5, "Depth of the echo sounder transducer below the waterline in meters")
+	depthOffset := flag.Float64("depth-offset", -1.3, "DPT offset in meters, positive to the waterline or negative to the keel")
+
+	// Weather flags
+	enableWeather := flag.Bool("weather", false, "Generate MDA and XDR air temperature, pressure and humidity sentences and PGNs 130310, 130311, 130313, 130314 and 130316")
+	airTemperature := flag.Float64("air-temperature", 18, "Daily mean air temperature in °C")
+	waterTemperature := flag.Float64("water-temperature", 16, "Sea surface temperature in °C")
+	pressure := flag.Float64("pressure", 1013.25, "Mean sea level pressure in hPa")
+	humidity := flag.Float64("humidity", 75, "Relative humidity in percent at the daily mean air temperature")
+	fronts := flag.Float64("fronts", 0.5, "Mean number of passing fronts per day")
+	frontIn := flag.Duration("front-in", 0, "Start a passing front after this delay, e.g. 10m (default: none)")
+
+	// Attitude flags
+	enableAttitude := flag.Bool("attitude", false, "Generate XDR pitch, roll and heave, HRM and PRDID sentences and PGNs 127252 and 127257")
+	waveHeight := flag.Float64("wave-height", 1.5, "Significant wave height in meters")
+	waveDirection := flag.Float64("wave-direction", 270, "Direction the waves come from in degrees true")
+	wavePeriod := flag.Float64("wave-period", 0, "Peak wave period in seconds, derived from the wave height when 0")
+	rollPeriod := flag.Float64("roll-period", 6, "Natural roll period of own ship in seconds")
+
+	// Engine flags
+	enableEngine := flag.Bool("engine", false, "Generate RPM and engine XDR sentences and PGNs 127488, 127489 and 127493")
+	engineCount := flag.Int("engines", 1, "Number of engines, 1 (single) or 2 (twin)")
+	engineMaxRPM := flag.Float64("engine-max-rpm", 3000, "Rated engine speed in RPM, reached at the maximum speed through water")
+	engineMaxSpeed := flag.Float64("engine-max-speed", 7.5, "Speed through water in knots at the rated engine speed; the engine speed is derived from own ship's speed through water")
+	engineFault := flag.String("engine-fault", "", "Fault injected into the port or single engine: overheat or low-oil-pressure")
+
+	// Tank flags
+	enableTanks := flag.Bool("tanks", false, "Generate XDR tank level sentences and PGN 127505")
+
+	// Electrical flags
+	enableElectrical := flag.Bool("electrical", false, "Generate the battery, charger and charging source PGNs")
+	houseCapacity := flag.Float64("house-capacity", 400, "House battery bank capacity in amp hours")
+	batteryType := flag.String("battery-type", "agm", "House battery bank type: flooded, gel, agm or lithium")
+	solarPower := flag.Float64("solar-power", 400, "Solar panel output at noon in watts")
+	shorePower := flag.Bool("shore-power", false, "Connect shore power to the battery charger")
+
+	// Steering and autopilot flags
+	enableRudder := flag.Bool("rudder", false, "Turn own ship by its rudder, generating RSA and PGN 127245 Rudder")
+	enableAutopilot := flag.Bool("autopilot", false, "Steer own ship by APB/RMB/HSC and PGN 127237 commands from clients, generating RSA and rudder PGNs")
+	autopilotMode := flag.String("autopilot-mode", "standby", "Autopilot mode at start-up: standby, auto (hold the current heading) or track")
+	rudderLimit := flag.Float64("rudder-limit", 35, "Hard over rudder angle in degrees")
+	rudderRate := flag.Float64("rudder-rate", 5, "Rudder speed in degrees per second")
+	turnRate := flag.Float64("turn-rate", 1, "Steady rate of turn in degrees per minute per degree of rudder and knot of speed")
+	turnLength := flag.Float64("turn-length", 20, "Distance in meters own ship runs while its rate of turn builds up to 63% of the steady rate")
+
+	// Input flags
+	echo := flag.Bool("echo", false, "Send valid NMEA 0183 sentences received from a client back to it")
+	forward := flag.Bool("forward", false, "Forward valid NMEA 0183 sentences received from a client to the other clients")
+
+	// Client flags
+	connect := flag.String("connect", "", "Remote listeners to stream NMEA 0183 to, comma separated host:port (TCP) or ws:// URLs")
+	connect2000 := flag.String("connect-2000", "", "Remote listeners to stream NMEA 2000 to, comma separated host:port (TCP) or ws:// URLs")
+	reconnect := flag.Duration("reconnect", time.Second, "Delay before reconnecting to a remote listener, doubling after every failed attempt")
+	reconnectMax := flag.Duration("reconnect-max", 30*time.Second, "Longest delay between attempts to connect to a remote listener")
+
+	// MQTT flags
+	mqttDefaults := network.DefaultMQTTOptions()
+	mqttBroker := flag.String("mqtt", "", "MQTT broker to publish to as host:port, publishing is disabled when empty")
+	mqttVersion := flag.String("mqtt-version", "3.1.1", "MQTT protocol version: 3.1.1 or 5")
+	mqttQoS := flag.Int("mqtt-qos", 0, "QoS of published messages: 0, 1 or 2")
+	mqttRetain := flag.Bool("mqtt-retain", false, "Publish retained messages")
+	mqttClientID := flag.String("mqtt-client-id", mqttDefaults.ClientID, "MQTT client ID prefix, suffixed with -0183 and -2000 per protocol")
+	mqttUsername := flag.String("mqtt-username", "", "MQTT user name")
+	mqttPassword := flag.String("mqtt-password", "", "MQTT password")
+	mqttSentenceTopic := flag.String("mqtt-sentence-topic", mqttDefaults.SentenceTopic, "Topic of raw NMEA 0183 sentences with {talker} and {formatter}, disabled when empty")
+	mqttPGNTopic := flag.String("mqtt-pgn-topic", mqttDefaults.PGNTopic, "Topic of raw NMEA 2000 messages with {pgn} and {source}, disabled when empty")
+	mqttValueTopic := flag.String("mqtt-value-topic", mqttDefaults.ValueTopic, "Topic of decoded JSON values with {path} and {source}, disabled when empty")
+
+	// Talker flags
+	talkers := flag.String("talkers", "", "Talker ID overrides as key=talker[+talker], keyed by sentence (GGA) or device (gnss, heading, depth, wind, log, temperature, weather, propulsion, radar, ais, rudder), e.g. gnss=GN,heading=HC+HE")
+
+	// Scenario flags
+	scenarioPath := flag.String("scenario", "", "JSON scenario file defining sensor instances, the sea state and tanks")
 
 	// Common flags
 	host := flag.String("host", "0.0.0.0", "Host to bind servers to")
@@ -49,22 +172,309 @@ func main() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt)
 
+	// Create the shared fleet of own ship and AIS targets
+	own := simulation.DefaultOwnShip()
+	fleet := simulation.NewFleet(own, simulation.RandomTargets(own, *aisTargets, *aisRadius)...)
+
+	// Apply the current before placing encounters, which need own ship's motion over ground
+	currentModel, err := simulation.ParseCurrent(*current, time.Now())
+	if err != nil {
+		logger.Error().Err(err).Msg("invalid current")
+		os.Exit(1)
+	}
+	fleet.SetCurrent(currentModel)
+
+	if *encounters != "" {
+		for _, spec := range strings.Split(*encounters, ",") {
+			encounter, err := simulation.ParseEncounter(strings.TrimSpace(spec))
+			if err != nil {
+				logger.Error().Err(err).Msg("invalid encounter")
+				os.Exit(1)
+			}
+			target, err := simulation.PlaceTarget(fleet.OwnShip(), encounter)
+			if err != nil {
+				logger.Error().Err(err).Msg("invalid encounter")
+				os.Exit(1)
+			}
+			fleet.AddTarget(target)
+		}
+	}
+
+	// Let the autopilot steer own ship with its rudder, holding its heading or
+	// course when engaged at start-up
+	var pilot *simulation.Autopilot
+	if *enableAutopilot || *enableRudder {
+		mode, err := simulation.ParseAutopilotMode(*autopilotMode)
+		if err != nil {
+			logger.Error().Err(err).Msg("invalid autopilot mode")
+			os.Exit(1)
+		}
+		pilotCfg := simulation.DefaultAutopilotConfig()
+		pilotCfg.RudderLimit = *rudderLimit
+		pilotCfg.RudderRate = *rudderRate
+		pilotCfg.TurnRate = *turnRate
+		pilotCfg.TurnLength = *turnLength
+		pilot = simulation.NewAutopilot(pilotCfg)
+		switch mode {
+		case simulation.AutopilotHeading:
+			pilot.SetHeading(fleet.OwnShip().Heading)
+		case simulation.AutopilotTrack:
+			pilot.SetTrack(fleet.OwnShip().Heading, 0)
+		}
+		fleet.SetHelm(pilot)
+	}
+	go fleet.Run(ctx, *interval)
+
+	// Create the GNSS receiver shared by both protocols
+	gnssCfg := simulation.DefaultGNSSConfig()
+	gnssCfg.Systems = nil
+	for _, name := range strings.Split(*gnssSystems, ",") {
+		system, err := simulation.ParseGNSSSystem(strings.TrimSpace(name))
+		if err != nil {
+			logger.Error().Err(err).Msg("invalid GNSS system")
+			os.Exit(1)
+		}
+		gnssCfg.Systems = append(gnssCfg.Systems, system)
+	}
+	gnssCfg.Errors.HorizontalSigma = *gnssSigma
+	gnssCfg.Errors.VerticalSigma = *gnssSigma * 5 / 3
+	gnssCfg.Errors.DriftSigma = *gnssDrift
+	gnssCfg.Errors.MultipathRate = *gnssMultipath
+	gnss := simulation.NewGNSSReceiver(gnssCfg)
+
+	// Create the wind model shared by both protocols
+	windCfg := simulation.DefaultWindConfig()
+	windCfg.Direction = *windDirection
+	windCfg.Speed = *windSpeed
+	windCfg.Trend = *windTrend
+	windCfg.GustRate = *windGusts
+	wind := simulation.NewWindModel(windCfg)
+
+	// Create the weather model shared by both protocols
+	weatherCfg := simulation.DefaultWeatherConfig()
+	weatherCfg.AirTemperature = *airTemperature
+	weatherCfg.WaterTemperature = *waterTemperature
+	weatherCfg.Pressure = *pressure
+	weatherCfg.Humidity = *humidity
+	weatherCfg.FrontRate = *fronts
+	weather := simulation.NewWeatherModel(weatherCfg)
+	if *frontIn > 0 {
+		weather.StartFront(time.Now().Add(*frontIn))
+	}
+
+	// Create the attitude model shared by both protocols, with the scenario's
+	// sea state taking precedence over the command line
+	seaState := simulation.SeaStateConfig{
+		WaveHeight:    *waveHeight,
+		WaveDirection: *waveDirection,
+		WavePeriod:    *wavePeriod,
+		RollPeriod:    *rollPeriod,
+	}
+
+	// Create the engines shared by both protocols, running at the speed that
+	// drives own ship through the water
+	if *engineCount < 1 || *engineCount > 2 {
+		logger.Error().Int("engines", *engineCount).Msg("invalid engine count, expected 1 or 2")
+		os.Exit(1)
+	}
+	engineCfg := simulation.DefaultEngineConfig()
+	engineCfg.Count = *engineCount
+	engineCfg.MaxRPM = *engineMaxRPM
+	engineCfg.MaxSpeed = *engineMaxSpeed
+	engines := simulation.NewEngineModel(engineCfg)
+	fault, err := simulation.ParseEngineFault(*engineFault)
+	if err != nil {
+		logger.Error().Err(err).Msg("invalid engine fault")
+		os.Exit(1)
+	}
+	if err := engines.InjectFault(0, fault); err != nil {
+		logger.Error().Err(err).Msg("invalid engine fault")
+		os.Exit(1)
+	}
+
+	// Create the batteries and charging sources
+	electricalCfg := simulation.DefaultElectricalConfig()
+	electricalCfg.House.Capacity = *houseCapacity
+	electricalCfg.SolarPeak = *solarPower
+	electricalCfg.ShorePower = *shorePower
+	if electricalCfg.House.Type, err = simulation.ParseBatteryType(*batteryType); err != nil {
+		logger.Error().Err(err).Msg("invalid battery type")
+		os.Exit(1)
+	}
+	electrical := simulation.NewElectricalModel(electricalCfg)
+
+	// Create the echo sounder shared by both protocols
+	var bathymetry simulation.Bathymetry = simulation.FlatBathymetry(*flatDepth)
+	if *bathymetryPath != "" {
+		grid, err := simulation.LoadBathymetry(*bathymetryPath, *bathymetryElevation)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to load bathymetry")
+			os.Exit(1)
+		}
+		bathymetry = grid
+	}
+	depthCfg := simulation.DefaultDepthConfig()
+	depthCfg.TransducerDepth = *transducerDepth
+	depthCfg.Offset = *depthOffset
+	tide := simulation.TideHeight{MeanLevel: *tideMean, Range: *tideRange, Epoch: time.Now()}
+	depth := simulation.NewDepthSounder(depthCfg, bathymetry, tide)
+
+	// Create the sensor instances defined by the scenario
+	var sensors simulation.Sensors
+	tankCfg := simulation.DefaultTanks()
+	if *scenarioPath != "" {
+		scenario, err := simulation.LoadScenario(*scenarioPath)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to load scenario")
+			os.Exit(1)
+		}
+		sensors, err = simulation.NewSensors(scenario.Sensors)
+		if err != nil {
+			logger.Error().Err(err).Msg("invalid sensor configuration")
+			os.Exit(1)
+		}
+		go sensors.Run(ctx, fleet, *interval)
+		if scenario.SeaState != nil {
+			seaState = *scenario.SeaState
+		}
+		if scenario.Tanks != nil {
+			tankCfg = scenario.Tanks
+		}
+	}
+	attitude := simulation.NewAttitudeModel(seaState)
+
+	// Create the tanks shared by both protocols, drawing fuel for the engines
+	tanks, err := simulation.NewTankModel(tankCfg)
+	if err != nil {
+		logger.Error().Err(err).Msg("invalid tank configuration")
+		os.Exit(1)
+	}
+
+	var variationFunc simulation.VariationFunc
+	if *variation != "wmm" {
+		v, err := strconv.ParseFloat(*variation, 64)
+		if err != nil {
+			logger.Error().Err(err).Msg("invalid magnetic variation")
+			os.Exit(1)
+		}
+		variationFunc = simulation.FixedVariation(v)
+	}
+
+	deviationCard, err := simulation.ParseDeviationCard(*deviation)
+	if err != nil {
+		logger.Error().Err(err).Msg("invalid deviation card")
+		os.Exit(1)
+	}
+
+	talkerCfg, err := talker.Parse(*talkers)
+	if err != nil {
+		logger.Error().Err(err).Msg("invalid talker configuration")
+		os.Exit(1)
+	}
+
+	mqttProtocol, err := mqtt.ParseVersion(*mqttVersion)
+	if err != nil {
+		logger.Error().Err(err).Msg("invalid MQTT version")
+		os.Exit(1)
+	}
+	if *mqttQoS < 0 || *mqttQoS > 2 {
+		logger.Error().Int("qos", *mqttQoS).Msg("invalid MQTT QoS, expected 0, 1 or 2")
+		os.Exit(1)
+	}
+	if err := (mqtt.Options{Username: *mqttUsername, Password: *mqttPassword, Version: mqttProtocol}).Validate(); err != nil {
+		logger.Error().Err(err).Msg("invalid MQTT credentials")
+		os.Exit(1)
+	}
+	mqttOpts := network.MQTTOptions{
+		Username:      *mqttUsername,
+		Password:      *mqttPassword,
+		Version:       mqttProtocol,
+		QoS:           byte(*mqttQoS),
+		Retain:        *mqttRetain,
+		SentenceTopic: *mqttSentenceTopic,
+		PGNTopic:      *mqttPGNTopic,
+		ValueTopic:    *mqttValueTopic,
+	}
+
+	// Create the output controls shared by both protocols and the control API
+	controls := network.NewControls(*interval)
+
+	// Sample the noisy models once per update for the outputs of both protocols
+	snapshots := network.NewSnapshots(controls, simulation.Sampler{
+		Fleet:    fleet,
+		GNSS:     gnss,
+		Wind:     wind,
+		Depth:    depth,
+		Weather:  weather,
+		Attitude: attitude,
+		Engines:  engines,
+		Tanks:    tanks,
+	})
+
+	// One control API serves the shared models on the WebSocket ports of both
+	// protocols, with the waypoints received by the NMEA 0183 servers
+	waypoints := simulation.NewWaypoints()
+	api := network.Config{
+		Logger:    logger,
+		Fleet:     fleet,
+		Sensors:   sensors,
+		Weather:   weather,
+		Engines:   engines,
+		Controls:  controls,
+		Waypoints: waypoints,
+	}.ControlAPI()
+
 	var nmea0183Servers []network.Server
 	var nmea2000Sim *nmea2000.Simulator
 
 	// Start NMEA 0183 servers if needed
 	if *protocol == "both" || *protocol == "nmea0183" {
 		// Create NMEA 0183 server configuration
+		radarCfg := radar.DefaultTrackerConfig()
+		radarCfg.Range = *radarRange
+		radarCfg.AIS = *enableAIS
+
 		cfg := network.Config{
-			Host:           *host,
-			UpdateInterval: *interval,
-			Logger:         logger,
-			BaudRate:       *baudRate,
-			Protocol:       "nmea0183",
+			Host:              *host,
+			UpdateInterval:    *interval,
+			Logger:            logger,
+			BaudRate:          *baudRate,
+			Protocol:          "nmea0183",
+			Fleet:             fleet,
+			GNSS:              gnss,
+			Radar:             radar.NewGenerator(radarCfg),
+			Talkers:           talkerCfg,
+			Sensors:           sensors,
+			Variation:         variationFunc,
+			Deviation:         deviationCard,
+			Wind:              wind,
+			Depth:             depth,
+			Weather:           weather,
+			Attitude:          attitude,
+			Engines:           engines,
+			Tanks:             tanks,
+			Autopilot:         pilot,
+			AutopilotCommands: *enableAutopilot,
+			Controls:          controls,
+			Snapshots:         snapshots,
+			Waypoints:         waypoints,
+			API:               api,
+			Echo:              *echo,
+			Forward:           *forward,
 			SentenceOptions: network.SentenceOptions{
 				EnablePosition:    true,
 				EnableNavigation:  true,
+				EnableHeading:     *enableHeading,
 				EnableEnvironment: true,
+				EnableWind:        *enableWind,
+				EnableWeather:     *enableWeather,
+				EnableAttitude:    *enableAttitude,
+				EnableEngine:      *enableEngine,
+				EnableTanks:       *enableTanks,
+				EnableRudder:      *enableRudder || *enableAutopilot,
+				EnableAIS:         *enableAIS,
+				EnableRadar:       *enableRadar,
+				EnableGNSS:        *enableGNSS,
 			},
 		}
 
@@ -80,6 +490,26 @@ func main() {
 		tcpServer := network.NewTCPServer(tcpCfg)
 		nmea0183Servers = append(nmea0183Servers, tcpServer)
 
+		// Create clients connecting to remote listeners
+		if *connect != "" {
+			for _, remote := range strings.Split(*connect, ",") {
+				clientCfg := cfg
+				clientCfg.Remote = strings.TrimSpace(remote)
+				clientCfg.Reconnect = network.Backoff{Initial: *reconnect, Max: *reconnectMax}
+				nmea0183Servers = append(nmea0183Servers, network.NewClient(clientCfg))
+			}
+		}
+
+		// Create the publisher of sentences and values to the MQTT broker
+		if *mqttBroker != "" {
+			mqttCfg := cfg
+			mqttCfg.Remote = *mqttBroker
+			mqttCfg.Reconnect = network.Backoff{Initial: *reconnect, Max: *reconnectMax}
+			mqttCfg.MQTT = mqttOpts
+			mqttCfg.MQTT.ClientID = *mqttClientID + "-0183"
+			nmea0183Servers = append(nmea0183Servers, network.NewMQTTPublisher(mqttCfg))
+		}
+
 		// Start NMEA 0183 servers
 		for _, server := range nmea0183Servers {
 			srv := server // Create new variable for goroutine
@@ -105,25 +535,81 @@ func main() {
 			UpdateInterval: *interval,
 			Logger:         logger,
 			Protocol:       "nmea2000",
+			PGNSource:      *nmea2000Source,
 		}
 		tcpServer := network.NewTCP2000Server(tcpCfg)
 
-		// Create NMEA 2000 WebSocket server
+		// Create NMEA 2000 WebSocket server, serving the control API of the shared models
 		wsCfg := network.Config{
 			Host:           *host,
 			Port:           *nmea2000WSPort,
 			UpdateInterval: *interval,
 			Logger:         logger,
 			Protocol:       "nmea2000",
+			PGNSource:      *nmea2000Source,
+			API:            api,
 		}
 		wsServer := network.NewWebSocket2000Server(wsCfg)
 
+		// Create NMEA 2000 clients connecting to remote listeners
+		var clients []network.NMEA2000Server
+		if *connect2000 != "" {
+			for _, remote := range strings.Split(*connect2000, ",") {
+				clients = append(clients, network.NewClient2000(network.Config{
+					UpdateInterval: *interval,
+					Logger:         logger,
+					Protocol:       "nmea2000",
+					PGNSource:      *nmea2000Source,
+					Remote:         strings.TrimSpace(remote),
+					Reconnect:      network.Backoff{Initial: *reconnect, Max: *reconnectMax},
+				}))
+			}
+		}
+
+		// Create the publisher of NMEA 2000 messages to the MQTT broker
+		if *mqttBroker != "" {
+			mqttOpts.ClientID = *mqttClientID + "-2000"
+			clients = append(clients, network.NewMQTTPublisher2000(network.Config{
+				UpdateInterval: *interval,
+				Logger:         logger,
+				Protocol:       "nmea2000",
+				PGNSource:      *nmea2000Source,
+				Remote:         *mqttBroker,
+				Reconnect:      network.Backoff{Initial: *reconnect, Max: *reconnectMax},
+				MQTT:           mqttOpts,
+			}))
+		}
+
 		// Create and start NMEA 2000 simulator
-		nmea2000Sim = nmea2000.New(nmea2000.Config{
-			Transport:    tcpServer,
-			WebSocket:    wsServer,
-			UpdatePeriod: *interval,
-		})
+		n2kCfg := nmea2000.Config{
+			Transport:         tcpServer,
+			WebSocket:         wsServer,
+			Clients:           clients,
+			UpdatePeriod:      *interval,
+			Controls:          controls,
+			Snapshots:         snapshots,
+			Fleet:             fleet,
+			GNSS:              gnss,
+			EnableGNSS:        *enableGNSS,
+			EnableAIS:         *enableAIS,
+			Sensors:           sensors,
+			Variation:         variationFunc,
+			Wind:              wind,
+			Depth:             depth,
+			Weather:           weather,
+			Attitude:          attitude,
+			Engines:           engines,
+			Electrical:        electrical,
+			Tanks:             tanks,
+			Autopilot:         pilot,
+			AutopilotCommands: *enableAutopilot,
+			EnableWeather:     *enableWeather,
+			EnableAttitude:    *enableAttitude,
+			EnableEngine:      *enableEngine,
+			EnableTanks:       *enableTanks,
+			EnableElectrical:  *enableElectrical,
+		}
+		nmea2000Sim = nmea2000.New(n2kCfg)
 
 		// Start WebSocket server
 		go func() {
@@ -149,6 +635,35 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Start the Signal K server, serving own ship's state for either protocol
+	var signalKServer *network.SignalKServer
+	if *enableSignalK {
+		signalKServer = network.NewSignalKServer(network.Config{
+			Host:           *host,
+			Port:           *signalKPort,
+			UpdateInterval: *interval,
+			Logger:         logger,
+			Fleet:          fleet,
+			GNSS:           gnss,
+			Variation:      variationFunc,
+			Deviation:      deviationCard,
+			Wind:           wind,
+			Depth:          depth,
+			Weather:        weather,
+			Attitude:       attitude,
+			Engines:        engines,
+			Tanks:          tanks,
+			Autopilot:      pilot,
+			Controls:       controls,
+			Snapshots:      snapshots,
+		})
+		go func() {
+			if err := signalKServer.Start(ctx); err != nil {
+				logger.Error().Err(err).Msg("Signal K server failed")
+			}
+		}()
+	}
+
 	// Wait for interrupt signal
 	<-sigChan
 	logger.Info().Msg("shutting down simulators...")
@@ -162,4 +677,9 @@ func main() {
 	if nmea2000Sim != nil {
 		nmea2000Sim.Stop()
 	}
+
+	// Stop Signal K server
+	if signalKServer != nil {
+		signalKServer.Stop()
+	}
 }
@@ -0,0 +1,348 @@
+// Package mqtttest provides an in-process MQTT broker for tests
+package mqtttest
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+)
+
+// Control packet types
+const (
+	packetConnect    = 1
+	packetConnack    = 2
+	packetPublish    = 3
+	packetPuback     = 4
+	packetPubrec     = 5
+	packetPubrel     = 6
+	packetPubcomp    = 7
+	packetPingreq    = 12
+	packetPingresp   = 13
+	packetDisconnect = 14
+)
+
+// Protocol levels of MQTT 3.1.1 and 5
+const (
+	levelV311 = 4
+	levelV5   = 5
+)
+
+// errMalformed is returned for a packet that cannot be decoded
+var errMalformed = errors.New("malformed packet")
+
+// Message is a message published to a broker
+type Message struct {
+	Topic   string
+	Payload []byte
+	QoS     byte
+	Retain  bool
+}
+
+// Broker is a minimal in-process broker. It accepts MQTT 3.1.1 and 5 clients
+// and records the messages they publish, acknowledging them at their QoS, but
+// does not deliver them to subscribers.
+type Broker struct {
+	listener net.Listener
+	messages chan Message
+
+	mu       sync.Mutex
+	retained map[string]Message
+	conns    map[net.Conn]bool
+}
+
+// NewBroker starts a broker listening on addr, e.g. 127.0.0.1:0. Published
+// messages are buffered up to capacity and dropped beyond it.
+func NewBroker(addr string, capacity int) (*Broker, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Broker{
+		listener: listener,
+		messages: make(chan Message, capacity),
+		retained: make(map[string]Message),
+		conns:    make(map[net.Conn]bool),
+	}
+	go b.acceptLoop()
+	return b, nil
+}
+
+// Addr returns the address the broker listens on
+func (b *Broker) Addr() string {
+	return b.listener.Addr().String()
+}
+
+// Messages returns the messages published to the broker in order
+func (b *Broker) Messages() <-chan Message {
+	return b.messages
+}
+
+// Retained returns the retained message of a topic
+func (b *Broker) Retained(topic string) (Message, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m, ok := b.retained[topic]
+	return m, ok
+}
+
+// Clients returns the number of connected clients
+func (b *Broker) Clients() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.conns)
+}
+
+// Disconnect closes the connections of all clients
+func (b *Broker) Disconnect() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for conn := range b.conns {
+		conn.Close()
+		delete(b.conns, conn)
+	}
+}
+
+// Close stops the broker and closes the connections of all clients
+func (b *Broker) Close() error {
+	err := b.listener.Close()
+	b.Disconnect()
+	return err
+}
+
+func (b *Broker) acceptLoop() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		b.mu.Lock()
+		b.conns[conn] = true
+		b.mu.Unlock()
+		go b.serve(conn)
+	}
+}
+
+// serve handles the packets of a client until it disconnects
+func (b *Broker) serve(conn net.Conn) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, conn)
+		b.mu.Unlock()
+		conn.Close()
+	}()
+
+	r := bufio.NewReader(conn)
+	p, err := readPacket(r)
+	if err != nil || p.kind != packetConnect {
+		return
+	}
+	d := decoder{body: p.body}
+	d.string() // Protocol name
+	level := d.byte()
+	if d.err != nil || (level != levelV311 && level != levelV5) {
+		return
+	}
+
+	connack := []byte{0, 0}
+	if level == levelV5 {
+		connack = append(connack, 0) // No properties
+	}
+	if _, err := conn.Write(packet{kind: packetConnack, body: connack}.encode()); err != nil {
+		return
+	}
+
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			return
+		}
+
+		var reply packet
+		switch p.kind {
+		case packetPublish:
+			m, id, err := decodePublish(p, level)
+			if err != nil {
+				return
+			}
+			b.record(m)
+			switch m.QoS {
+			case 1:
+				reply = packet{kind: packetPuback, body: binary.BigEndian.AppendUint16(nil, id)}
+			case 2:
+				reply = packet{kind: packetPubrec, body: binary.BigEndian.AppendUint16(nil, id)}
+			}
+		case packetPubrel:
+			reply = packet{kind: packetPubcomp, body: p.body[:min(2, len(p.body))]}
+		case packetPingreq:
+			reply = packet{kind: packetPingresp}
+		case packetDisconnect:
+			return
+		}
+		if reply.kind != 0 {
+			if _, err := conn.Write(reply.encode()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// record stores a published message, keeping it as the topic's retained
+// message when Retain is set
+func (b *Broker) record(m Message) {
+	if m.Retain {
+		b.mu.Lock()
+		if len(m.Payload) == 0 {
+			delete(b.retained, m.Topic)
+		} else {
+			b.retained[m.Topic] = m
+		}
+		b.mu.Unlock()
+	}
+
+	select {
+	case b.messages <- m:
+	default: // Buffer full
+	}
+}
+
+// decodePublish returns the message and packet identifier of a PUBLISH packet
+func decodePublish(p packet, level byte) (Message, uint16, error) {
+	m := Message{QoS: p.flags >> 1 & 0x03, Retain: p.flags&0x01 != 0}
+	if m.QoS > 2 {
+		return Message{}, 0, errMalformed
+	}
+
+	d := decoder{body: p.body}
+	m.Topic = d.string()
+	var id uint16
+	if m.QoS > 0 {
+		id = d.uint16()
+	}
+	if level == levelV5 {
+		d.properties()
+	}
+	if d.err != nil {
+		return Message{}, 0, d.err
+	}
+	m.Payload = d.body
+	return m, id, nil
+}
+
+// packet is a control packet with its fixed header flags and the bytes
+// following the fixed header
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+// encode returns the packet with its fixed header
+func (p packet) encode() []byte {
+	buf := []byte{p.kind<<4 | p.flags}
+	for n := len(p.body); ; {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if n == 0 {
+			break
+		}
+	}
+	return append(buf, p.body...)
+}
+
+// readPacket reads a control packet
+func readPacket(r *bufio.Reader) (packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+	length, err := readVarint(r)
+	if err != nil {
+		return packet{}, err
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return packet{}, err
+	}
+	return packet{kind: header >> 4, flags: header & 0x0f, body: body}, nil
+}
+
+// readVarint reads a variable byte integer of at most four bytes
+func readVarint(r io.ByteReader) (int, error) {
+	var n, shift int
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return n, nil
+		}
+		shift += 7
+	}
+	return 0, errMalformed
+}
+
+// decoder reads the fields of a packet body
+type decoder struct {
+	body []byte
+	err  error
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil || len(d.body) < 1 {
+		d.err = errMalformed
+		return 0
+	}
+	b := d.body[0]
+	d.body = d.body[1:]
+	return b
+}
+
+func (d *decoder) uint16() uint16 {
+	if d.err != nil || len(d.body) < 2 {
+		d.err = errMalformed
+		return 0
+	}
+	n := binary.BigEndian.Uint16(d.body)
+	d.body = d.body[2:]
+	return n
+}
+
+func (d *decoder) string() string {
+	n := int(d.uint16())
+	if d.err != nil || len(d.body) < n {
+		d.err = errMalformed
+		return ""
+	}
+	s := string(d.body[:n])
+	d.body = d.body[n:]
+	return s
+}
+
+// properties skips the properties of an MQTT 5 packet
+func (d *decoder) properties() {
+	if d.err != nil {
+		return
+	}
+	n, err := readVarint(d)
+	if err != nil || len(d.body) < n {
+		d.err = errMalformed
+		return
+	}
+	d.body = d.body[n:]
+}
+
+// ReadByte reads the property length of an MQTT 5 packet
+func (d *decoder) ReadByte() (byte, error) {
+	b := d.byte()
+	return b, d.err
+}
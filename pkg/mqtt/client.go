@@ -0,0 +1,360 @@
+// Package mqtt implements a minimal MQTT 3.1.1 and 5 client publishing
+// messages to a broker
+package mqtt
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// Version is the MQTT protocol level
+type Version byte
+
+// Supported protocol versions
+const (
+	V311 Version = 4
+	V5   Version = 5
+)
+
+// ParseVersion parses 3.1.1 or 5
+func ParseVersion(s string) (Version, error) {
+	switch s {
+	case "3.1.1", "3", "4":
+		return V311, nil
+	case "5":
+		return V5, nil
+	}
+	return 0, fmt.Errorf("unsupported MQTT version %q", s)
+}
+
+// Options configures the connection to a broker
+type Options struct {
+	ClientID  string
+	Username  string // No user name when empty
+	Password  string // No password when empty; MQTT 3.1.1 requires a user name with a password
+	Version   Version
+	KeepAlive time.Duration // Interval of pings, none when zero
+	Timeout   time.Duration // Time to wait for acknowledgements, defaults to 10 seconds
+}
+
+// ErrClosed is returned when publishing on a closed connection
+var ErrClosed = errors.New("mqtt: connection closed")
+
+// Client is a connection to a broker publishing messages. It is safe for
+// concurrent use.
+type Client struct {
+	conn    net.Conn
+	opts    Options
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  uint16
+	pending map[uint16]chan packet // Acknowledgements awaited by packet identifier
+	err     error                  // Why the connection closed
+	done    chan struct{}
+}
+
+// Validate checks that the options can be sent to a broker
+func (o Options) Validate() error {
+	if o.Password != "" && o.Username == "" && o.Version != V5 {
+		return errors.New("mqtt: MQTT 3.1.1 does not allow a password without a user name")
+	}
+	return nil
+}
+
+// Dial connects to the broker at addr (host:port) and waits for its
+// acknowledgement
+func Dial(ctx context.Context, addr string, opts Options) (*Client, error) {
+	if opts.Version == 0 {
+		opts.Version = V311
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		conn:    conn,
+		opts:    opts,
+		pending: make(map[uint16]chan packet),
+		done:    make(chan struct{}),
+	}
+	r := bufio.NewReader(conn)
+	if err := c.connect(r); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	go c.readLoop(r)
+	if opts.KeepAlive > 0 {
+		go c.pingLoop()
+	}
+	return c, nil
+}
+
+// connect sends CONNECT and checks the broker's CONNACK
+func (c *Client) connect(r *bufio.Reader) error {
+	var flags byte = 0x02 // Clean session
+	if c.opts.Username != "" {
+		flags |= 0x80
+	}
+	if c.opts.Password != "" {
+		flags |= 0x40
+	}
+
+	body := appendString(nil, "MQTT")
+	body = append(body, byte(c.opts.Version), flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(c.opts.KeepAlive/time.Second))
+	if c.opts.Version == V5 {
+		body = append(body, 0) // No properties
+	}
+	body = appendString(body, c.opts.ClientID)
+	if c.opts.Username != "" {
+		body = appendString(body, c.opts.Username)
+	}
+	if c.opts.Password != "" {
+		body = appendString(body, c.opts.Password)
+	}
+
+	c.conn.SetDeadline(time.Now().Add(c.opts.Timeout))
+	defer c.conn.SetDeadline(time.Time{})
+
+	if _, err := c.conn.Write(packet{kind: packetConnect, body: body}.encode()); err != nil {
+		return err
+	}
+	p, err := readPacket(r)
+	if err != nil {
+		return fmt.Errorf("mqtt: no CONNACK: %w", err)
+	}
+	if p.kind != packetConnack {
+		return fmt.Errorf("mqtt: expected CONNACK, got packet type %d", p.kind)
+	}
+
+	d := decoder{body: p.body}
+	d.byte() // Session present
+	code := d.byte()
+	if d.err != nil {
+		return fmt.Errorf("mqtt: CONNACK: %w", d.err)
+	}
+	if c.opts.Version == V5 {
+		if err := reasonError(code); err != nil {
+			return fmt.Errorf("mqtt: connection refused: %w", err)
+		}
+	} else if code != 0 {
+		return fmt.Errorf("mqtt: connection refused: return code %d", code)
+	}
+	return nil
+}
+
+// Publish sends a message with QoS 0, 1 or 2, waiting for the broker's
+// acknowledgements of QoS 1 and 2. Topics longer than 65535 bytes and
+// messages too large for a packet are rejected.
+func (c *Client) Publish(topic string, payload []byte, qos byte, retain bool) error {
+	if qos > 2 {
+		return fmt.Errorf("mqtt: invalid QoS %d", qos)
+	}
+	if len(topic) > maxStringLength {
+		return fmt.Errorf("mqtt: topic of %d bytes exceeds %d bytes", len(topic), maxStringLength)
+	}
+	length := 2 + len(topic) + len(payload)
+	if qos > 0 {
+		length += 2 // Packet identifier
+	}
+	if c.opts.Version == V5 {
+		length++ // Properties length
+	}
+	if length > maxRemainingLength {
+		return fmt.Errorf("mqtt: message of %d bytes exceeds the packet size of %d bytes", length, maxRemainingLength)
+	}
+
+	flags := qos << 1
+	if retain {
+		flags |= 0x01
+	}
+	body := appendString(nil, topic)
+
+	var id uint16
+	var acks chan packet
+	if qos > 0 {
+		id, acks = c.await()
+		defer c.release(id)
+		body = binary.BigEndian.AppendUint16(body, id)
+	}
+	if c.opts.Version == V5 {
+		body = append(body, 0) // No properties
+	}
+	body = append(body, payload...)
+
+	if err := c.write(packet{kind: packetPublish, flags: flags, body: body}); err != nil {
+		return err
+	}
+	switch qos {
+	case 1:
+		return c.ack(acks, packetPuback)
+	case 2:
+		if err := c.ack(acks, packetPubrec); err != nil {
+			return err
+		}
+		rel := packet{kind: packetPubrel, flags: 0x02, body: binary.BigEndian.AppendUint16(nil, id)}
+		if err := c.write(rel); err != nil {
+			return err
+		}
+		return c.ack(acks, packetPubcomp)
+	}
+	return nil
+}
+
+// Done is closed once the connection is closed
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns why the connection closed
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close sends DISCONNECT and closes the connection
+func (c *Client) Close() error {
+	c.write(packet{kind: packetDisconnect})
+	c.close(ErrClosed)
+	return nil
+}
+
+// close closes the connection for a reason, failing the pending publishes
+func (c *Client) close(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+	c.err = err
+	close(c.done)
+	c.conn.Close()
+}
+
+func (c *Client) write(p packet) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	select {
+	case <-c.done:
+		return ErrClosed
+	default:
+	}
+	c.conn.SetWriteDeadline(time.Now().Add(c.opts.Timeout))
+	if _, err := c.conn.Write(p.encode()); err != nil {
+		c.close(err)
+		return err
+	}
+	return nil
+}
+
+// await allocates a packet identifier and the channel of its acknowledgements
+func (c *Client) await() (uint16, chan packet) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for {
+		c.nextID++
+		if _, busy := c.pending[c.nextID]; c.nextID != 0 && !busy {
+			break
+		}
+	}
+	acks := make(chan packet, 2)
+	c.pending[c.nextID] = acks
+	return c.nextID, acks
+}
+
+// release frees a packet identifier
+func (c *Client) release(id uint16) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pending, id)
+}
+
+// ack waits for an acknowledgement of the given type
+func (c *Client) ack(acks chan packet, kind byte) error {
+	select {
+	case p := <-acks:
+		if p.kind != kind {
+			return fmt.Errorf("mqtt: expected packet type %d, got %d", kind, p.kind)
+		}
+		if c.opts.Version == V5 && len(p.body) > 2 {
+			if err := reasonError(p.body[2]); err != nil {
+				return fmt.Errorf("mqtt: publish rejected: %w", err)
+			}
+		}
+		return nil
+	case <-c.done:
+		return ErrClosed
+	case <-time.After(c.opts.Timeout):
+		return errors.New("mqtt: acknowledgement timed out")
+	}
+}
+
+// readLoop passes acknowledgements to the publishes awaiting them until the
+// connection is closed
+func (c *Client) readLoop(r *bufio.Reader) {
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			c.close(err)
+			return
+		}
+
+		switch p.kind {
+		case packetPuback, packetPubrec, packetPubcomp:
+			d := decoder{body: p.body}
+			id := d.uint16()
+			c.mu.Lock()
+			acks := c.pending[id]
+			c.mu.Unlock()
+			if acks != nil && d.err == nil {
+				select {
+				case acks <- p:
+				default: // Duplicate acknowledgement
+				}
+			}
+		case packetDisconnect:
+			c.close(errors.New("mqtt: disconnected by the broker"))
+			return
+		}
+	}
+}
+
+// pingLoop keeps the connection alive
+func (c *Client) pingLoop() {
+	ticker := time.NewTicker(c.opts.KeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if c.write(packet{kind: packetPingreq}) != nil {
+				return
+			}
+		}
+	}
+}
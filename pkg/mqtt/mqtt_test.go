@@ -0,0 +1,140 @@
+package mqtt
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/internal/mqtttest"
+)
+
+func TestVarint(t *testing.T) {
+	for _, n := range []int{0, 127, 128, 16383, 16384, maxRemainingLength} {
+		buf := appendVarint(nil, n)
+		got, err := readVarint(bufio.NewReader(bytes.NewReader(buf)))
+		if err != nil || got != n {
+			t.Errorf("Varint %d encoded as %X decoded as %d, %v", n, buf, got, err)
+		}
+	}
+	if _, err := readVarint(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff, 0x01})); err == nil {
+		t.Error("Expected a five byte varint to be rejected")
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	if v, err := ParseVersion("3.1.1"); err != nil || v != V311 {
+		t.Errorf("ParseVersion(3.1.1) = %v, %v", v, err)
+	}
+	if v, err := ParseVersion("5"); err != nil || v != V5 {
+		t.Errorf("ParseVersion(5) = %v, %v", v, err)
+	}
+	if _, err := ParseVersion("3.1"); err == nil {
+		t.Error("Expected MQTT 3.1 to be rejected")
+	}
+}
+
+func TestClientPublish(t *testing.T) {
+	broker, err := mqtttest.NewBroker("127.0.0.1:0", 10)
+	if err != nil {
+		t.Fatalf("Failed to start broker: %v", err)
+	}
+	defer broker.Close()
+
+	for _, version := range []Version{V311, V5} {
+		client, err := Dial(context.Background(), broker.Addr(), Options{
+			ClientID:  "test",
+			Username:  "user",
+			Password:  "secret",
+			Version:   version,
+			KeepAlive: 10 * time.Millisecond,
+			Timeout:   time.Second,
+		})
+		if err != nil {
+			t.Fatalf("Version %d: failed to connect: %v", version, err)
+		}
+
+		for qos := byte(0); qos <= 2; qos++ {
+			if err := client.Publish("nmea/0183/GGA", []byte("$GPGGA"), qos, qos == 1); err != nil {
+				t.Errorf("Version %d: failed to publish at QoS %d: %v", version, qos, err)
+				continue
+			}
+			select {
+			case m := <-broker.Messages():
+				if m.Topic != "nmea/0183/GGA" || string(m.Payload) != "$GPGGA" || m.QoS != qos || m.Retain != (qos == 1) {
+					t.Errorf("Version %d: unexpected message %+v", version, m)
+				}
+			case <-time.After(time.Second):
+				t.Errorf("Version %d: message at QoS %d not received", version, qos)
+			}
+		}
+		if err := client.Publish("x", nil, 3, false); err == nil {
+			t.Errorf("Version %d: expected QoS 3 to be rejected", version)
+		}
+		if err := client.Publish(strings.Repeat("x", maxStringLength+1), nil, 0, false); err == nil {
+			t.Errorf("Version %d: expected a topic over 65535 bytes to be rejected", version)
+		}
+		if err := client.Publish("x", make([]byte, maxRemainingLength), 0, false); err == nil {
+			t.Errorf("Version %d: expected a message over the packet size to be rejected", version)
+		}
+
+		// Pings keep the connection open
+		time.Sleep(50 * time.Millisecond)
+		select {
+		case <-client.Done():
+			t.Fatalf("Version %d: connection closed: %v", version, client.Err())
+		default:
+		}
+		client.Close()
+		if err := client.Publish("x", nil, 0, false); err != ErrClosed {
+			t.Errorf("Version %d: expected ErrClosed after Close, got %v", version, err)
+		}
+	}
+
+	if m, ok := broker.Retained("nmea/0183/GGA"); !ok || string(m.Payload) != "$GPGGA" {
+		t.Errorf("Expected the retained message, got %+v", m)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	if err := (Options{Password: "secret"}).Validate(); err == nil {
+		t.Error("Expected a password without a user name to be rejected for MQTT 3.1.1")
+	}
+	if _, err := Dial(context.Background(), "127.0.0.1:0", Options{Password: "secret", Version: V311}); err == nil {
+		t.Error("Expected Dial to reject a password without a user name")
+	}
+	if err := (Options{Password: "secret", Version: V5}).Validate(); err != nil {
+		t.Errorf("Expected MQTT 5 to allow a password without a user name, got %v", err)
+	}
+	if err := (Options{Username: "user", Password: "secret"}).Validate(); err != nil {
+		t.Errorf("Expected a user name and password to be valid, got %v", err)
+	}
+}
+
+func TestClientBrokerDisconnect(t *testing.T) {
+	broker, err := mqtttest.NewBroker("127.0.0.1:0", 10)
+	if err != nil {
+		t.Fatalf("Failed to start broker: %v", err)
+	}
+	defer broker.Close()
+
+	client, err := Dial(context.Background(), broker.Addr(), Options{ClientID: "test", Version: V5})
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	broker.Disconnect()
+
+	select {
+	case <-client.Done():
+		if client.Err() == nil {
+			t.Error("Expected the reason the connection closed")
+		}
+	case <-time.After(time.Second):
+		t.Error("Expected the connection to close")
+	}
+	if err := client.Publish("x", nil, 1, false); err != ErrClosed {
+		t.Errorf("Expected ErrClosed, got %v", err)
+	}
+}
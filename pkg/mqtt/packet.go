@@ -0,0 +1,139 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Control packet types
+const (
+	packetConnect    = 1
+	packetConnack    = 2
+	packetPublish    = 3
+	packetPuback     = 4
+	packetPubrec     = 5
+	packetPubrel     = 6
+	packetPubcomp    = 7
+	packetPingreq    = 12
+	packetPingresp   = 13
+	packetDisconnect = 14
+)
+
+// maxRemainingLength is the largest remaining length of a packet
+const maxRemainingLength = 268435455
+
+// maxStringLength is the largest length of a string or binary data field
+const maxStringLength = 65535
+
+// packet is a control packet with its fixed header flags and the bytes
+// following the fixed header
+type packet struct {
+	kind  byte
+	flags byte
+	body  []byte
+}
+
+// encode returns the packet with its fixed header
+func (p packet) encode() []byte {
+	buf := []byte{p.kind<<4 | p.flags}
+	buf = appendVarint(buf, len(p.body))
+	return append(buf, p.body...)
+}
+
+// readPacket reads a control packet
+func readPacket(r *bufio.Reader) (packet, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+	length, err := readVarint(r)
+	if err != nil {
+		return packet{}, err
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return packet{}, err
+	}
+	return packet{kind: header >> 4, flags: header & 0x0f, body: body}, nil
+}
+
+// appendVarint appends a variable byte integer
+func appendVarint(buf []byte, n int) []byte {
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		buf = append(buf, b)
+		if n == 0 {
+			return buf
+		}
+	}
+}
+
+// readVarint reads a variable byte integer of at most four bytes
+func readVarint(r io.ByteReader) (int, error) {
+	var n, shift int
+	for i := 0; i < 4; i++ {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		n |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return n, nil
+		}
+		shift += 7
+	}
+	return 0, errors.New("malformed variable byte integer")
+}
+
+// appendString appends a UTF-8 string or binary data with its length
+func appendString(buf []byte, s string) []byte {
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(s)))
+	return append(buf, s...)
+}
+
+// decoder reads the fields of a packet body
+type decoder struct {
+	body []byte
+	err  error
+}
+
+func (d *decoder) byte() byte {
+	if d.err != nil || len(d.body) < 1 {
+		d.fail()
+		return 0
+	}
+	b := d.body[0]
+	d.body = d.body[1:]
+	return b
+}
+
+func (d *decoder) uint16() uint16 {
+	if d.err != nil || len(d.body) < 2 {
+		d.fail()
+		return 0
+	}
+	n := binary.BigEndian.Uint16(d.body)
+	d.body = d.body[2:]
+	return n
+}
+
+func (d *decoder) fail() {
+	if d.err == nil {
+		d.err = errors.New("malformed packet")
+	}
+}
+
+// reasonError returns the error of an MQTT 5 reason code, nil on success
+func reasonError(code byte) error {
+	if code < 0x80 {
+		return nil
+	}
+	return fmt.Errorf("reason code 0x%02X", code)
+}
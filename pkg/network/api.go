@@ -0,0 +1,519 @@
+package network
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+	"github.com/rs/zerolog"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// ControlAPI serves the HTTP control interface for the shared simulation state
+type ControlAPI struct {
+	fleet     *simulation.Fleet
+	waypoints *simulation.Waypoints // Waypoints and routes received from clients, nil when not loaded
+	controls  *Controls             // Update interval and disabled outputs, nil when not controllable
+	engines   *simulation.EngineModel
+	weather   *simulation.WeatherModel
+	sensors   simulation.Sensors
+	logger    zerolog.Logger
+}
+
+// NewControlAPI creates a control API for the given fleet
+func NewControlAPI(fleet *simulation.Fleet, logger zerolog.Logger) *ControlAPI {
+	return &ControlAPI{
+		fleet:  fleet,
+		logger: logger,
+	}
+}
+
+// Handler returns the HTTP handler serving the API routes under /api/
+func (a *ControlAPI) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/targets", a.handleListTargets)
+	mux.HandleFunc("GET /api/targets/{mmsi}", a.handleGetTarget)
+	mux.HandleFunc("DELETE /api/targets/{mmsi}", a.handleDeleteTarget)
+	mux.HandleFunc("POST /api/encounters", a.handleCreateEncounter)
+	mux.HandleFunc("GET /api/waypoints", a.handleListWaypoints)
+	mux.HandleFunc("GET /api/vessel", a.handleGetVessel)
+	mux.HandleFunc("PATCH /api/vessel", a.handleUpdateVessel)
+	mux.HandleFunc("GET /api/simulation", a.handleGetSimulation)
+	mux.HandleFunc("PATCH /api/simulation", a.handleUpdateSimulation)
+	mux.HandleFunc("GET /api/outputs", a.handleGetOutputs)
+	mux.HandleFunc("PATCH /api/outputs", a.handleUpdateOutputs)
+	mux.HandleFunc("POST /api/events", a.handleCreateEvent)
+	mux.HandleFunc("GET /api/openapi.json", a.handleOpenAPI)
+	return mux
+}
+
+// ControlAPI returns a control API of the configuration's simulation state
+// and output controls. Servers sharing their models should share one API, so
+// that every port gives the same answers.
+func (c Config) ControlAPI() *ControlAPI {
+	api := NewControlAPI(c.Fleet, c.Logger)
+	api.waypoints = c.Waypoints
+	api.controls = c.Controls
+	api.engines = c.Engines
+	api.weather = c.Weather
+	api.sensors = c.Sensors
+	return api
+}
+
+// controlAPI returns the control API the server serves under /api/
+func (b *BaseServer) controlAPI() *ControlAPI {
+	return b.Config.API
+}
+
+// targetStatus is the JSON representation of a target and its ground truth CPA/TCPA
+type targetStatus struct {
+	MMSI      uint32  `json:"mmsi"`
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	COG       float64 `json:"cog"`     // Degrees true
+	SOG       float64 `json:"sog"`     // Knots
+	Heading   float64 `json:"heading"` // Degrees true
+	Range     float64 `json:"range"`   // Nautical miles from own ship
+	Bearing   float64 `json:"bearing"` // Degrees true from own ship
+	CPA       float64 `json:"cpa"`     // Nautical miles
+	TCPA      float64 `json:"tcpa"`    // Seconds, negative once past CPA
+}
+
+// encounterRequest is the JSON body accepted by POST /api/encounters
+type encounterRequest struct {
+	Type       simulation.EncounterType `json:"type"`
+	CPA        float64                  `json:"cpa"`  // Nautical miles
+	TCPA       float64                  `json:"tcpa"` // Seconds
+	Speed      float64                  `json:"speed,omitempty"`
+	PassToPort bool                     `json:"pass_to_port,omitempty"`
+}
+
+// waypointList is the JSON representation of the waypoints and routes received from clients
+type waypointList struct {
+	Waypoints []simulation.Waypoint `json:"waypoints"`
+	Routes    []simulation.Route    `json:"routes"`
+}
+
+// vesselStatus is the JSON representation of own ship
+type vesselStatus struct {
+	MMSI      uint32  `json:"mmsi"`
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Heading   float64 `json:"heading"` // Degrees true
+	COG       float64 `json:"cog"`     // Degrees true
+	SOG       float64 `json:"sog"`     // Knots
+	STW       float64 `json:"stw"`     // Knots
+	ROT       float64 `json:"rot"`     // Degrees per minute
+	Leeway    float64 `json:"leeway"`  // Degrees, positive to starboard
+}
+
+// vesselUpdate is the JSON body accepted by PATCH /api/vessel, changing the
+// fields that are set
+type vesselUpdate struct {
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	Heading   *float64 `json:"heading"` // Degrees true
+	Speed     *float64 `json:"speed"`   // Knots through water
+	Leeway    *float64 `json:"leeway"`  // Degrees, positive to starboard
+}
+
+// simulationStatus is the JSON representation of the simulation, also
+// accepted by PATCH /api/simulation with the fields to change
+type simulationStatus struct {
+	Paused   *bool    `json:"paused,omitempty"`
+	Interval *float64 `json:"interval,omitempty"` // Seconds between updates
+}
+
+// outputStatus is the JSON representation of the sentences and PGNs generated
+// so far and the disabled ones, mapped to whether they are enabled, also
+// accepted by PATCH /api/outputs to enable (true) or disable (false) them
+type outputStatus struct {
+	Sentences map[string]bool `json:"sentences"` // By formatter, e.g. GGA, or proprietary address, e.g. PRDID
+	PGNs      map[string]bool `json:"pgns"`
+}
+
+// eventRequest is the JSON body accepted by POST /api/events
+type eventRequest struct {
+	Type     string                 `json:"type"`               // engine_fault, weather_front or sensor_failure
+	Engine   int                    `json:"engine,omitempty"`   // Engine instance of an engine fault
+	Fault    simulation.EngineFault `json:"fault,omitempty"`    // Engine fault, empty clearing it
+	Sensor   string                 `json:"sensor,omitempty"`   // Name of the failing sensor
+	Delay    float64                `json:"delay,omitempty"`    // Seconds until a front begins to pass
+	Duration float64                `json:"duration,omitempty"` // Seconds a sensor stays failed
+}
+
+// sentenceKey matches a sentence formatter or the address of a proprietary sentence
+var sentenceKey = regexp.MustCompile(`^([A-Z]{3}|P[A-Z0-9]{1,8})$`)
+
+// maxPGN is the largest PGN
+const maxPGN = 0x1ffff
+
+func (a *ControlAPI) status(own, target simulation.Vessel) targetStatus {
+	bearing, distance := simulation.BearingDistance(own.Latitude, own.Longitude, target.Latitude, target.Longitude)
+	cpa, tcpa := simulation.CPA(own, target)
+	return targetStatus{
+		MMSI:      target.MMSI,
+		Name:      target.Name,
+		Latitude:  target.Latitude,
+		Longitude: target.Longitude,
+		COG:       target.COG,
+		SOG:       target.SOG,
+		Heading:   target.Heading,
+		Range:     distance,
+		Bearing:   bearing,
+		CPA:       cpa,
+		TCPA:      tcpa.Seconds(),
+	}
+}
+
+func (a *ControlAPI) handleListTargets(w http.ResponseWriter, _ *http.Request) {
+	own := a.fleet.OwnShip()
+	targets := a.fleet.Targets()
+
+	statuses := make([]targetStatus, 0, len(targets))
+	for _, target := range targets {
+		statuses = append(statuses, a.status(own, target))
+	}
+	a.writeJSON(w, http.StatusOK, statuses)
+}
+
+func (a *ControlAPI) handleGetTarget(w http.ResponseWriter, r *http.Request) {
+	mmsi, err := strconv.ParseUint(r.PathValue("mmsi"), 10, 32)
+	if err != nil {
+		http.Error(w, "invalid MMSI", http.StatusBadRequest)
+		return
+	}
+
+	target, ok := a.fleet.Target(uint32(mmsi))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	a.writeJSON(w, http.StatusOK, a.status(a.fleet.OwnShip(), target))
+}
+
+func (a *ControlAPI) handleDeleteTarget(w http.ResponseWriter, r *http.Request) {
+	mmsi, err := strconv.ParseUint(r.PathValue("mmsi"), 10, 32)
+	if err != nil {
+		http.Error(w, "invalid MMSI", http.StatusBadRequest)
+		return
+	}
+
+	if !a.fleet.RemoveTarget(uint32(mmsi)) {
+		http.NotFound(w, r)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *ControlAPI) handleCreateEncounter(w http.ResponseWriter, r *http.Request) {
+	var req encounterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	switch req.Type {
+	case simulation.HeadOn, simulation.CrossingStarboard, simulation.CrossingPort,
+		simulation.Overtaking, simulation.Overtaken:
+	default:
+		http.Error(w, "invalid encounter type", http.StatusBadRequest)
+		return
+	}
+	if req.CPA < 0 || req.TCPA <= 0 || req.Speed < 0 {
+		http.Error(w, "cpa and speed must not be negative and tcpa must be positive", http.StatusBadRequest)
+		return
+	}
+
+	own := a.fleet.OwnShip()
+	target, err := simulation.PlaceTarget(own, simulation.Encounter{
+		Type:       req.Type,
+		CPA:        req.CPA,
+		TCPA:       time.Duration(req.TCPA * float64(time.Second)),
+		Speed:      req.Speed,
+		PassToPort: req.PassToPort,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	target.MMSI = a.fleet.AddTarget(target)
+
+	a.logger.Info().
+		Uint32("mmsi", target.MMSI).
+		Str("type", string(req.Type)).
+		Float64("cpa", req.CPA).
+		Float64("tcpa", req.TCPA).
+		Msg("encounter target created")
+
+	a.writeJSON(w, http.StatusCreated, a.status(own, target))
+}
+
+func (a *ControlAPI) handleListWaypoints(w http.ResponseWriter, _ *http.Request) {
+	list := waypointList{Waypoints: []simulation.Waypoint{}, Routes: []simulation.Route{}}
+	if a.waypoints != nil {
+		list.Waypoints = a.waypoints.List()
+		list.Routes = a.waypoints.Routes()
+	}
+	a.writeJSON(w, http.StatusOK, list)
+}
+
+func (a *ControlAPI) vesselStatus(own simulation.Vessel) vesselStatus {
+	return vesselStatus{
+		MMSI:      own.MMSI,
+		Name:      own.Name,
+		Latitude:  own.Latitude,
+		Longitude: own.Longitude,
+		Heading:   own.Heading,
+		COG:       own.COG,
+		SOG:       own.SOG,
+		STW:       own.STW,
+		ROT:       own.ROT,
+		Leeway:    own.Leeway,
+	}
+}
+
+func (a *ControlAPI) handleGetVessel(w http.ResponseWriter, _ *http.Request) {
+	a.writeJSON(w, http.StatusOK, a.vesselStatus(a.fleet.OwnShip()))
+}
+
+// handleUpdateVessel moves own ship or changes its heading and speed. Without
+// a current, own ship makes good its heading at its speed through water.
+func (a *ControlAPI) handleUpdateVessel(w http.ResponseWriter, r *http.Request) {
+	var req vesselUpdate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case req.Latitude != nil && (*req.Latitude < -90 || *req.Latitude > 90):
+		http.Error(w, "latitude must be between -90 and 90", http.StatusBadRequest)
+		return
+	case req.Longitude != nil && (*req.Longitude < -180 || *req.Longitude > 180):
+		http.Error(w, "longitude must be between -180 and 180", http.StatusBadRequest)
+		return
+	case req.Heading != nil && (*req.Heading < 0 || *req.Heading >= 360):
+		http.Error(w, "heading must be at least 0 and less than 360", http.StatusBadRequest)
+		return
+	case req.Speed != nil && (*req.Speed < 0 || *req.Speed > 100):
+		http.Error(w, "speed must be between 0 and 100 knots", http.StatusBadRequest)
+		return
+	case req.Leeway != nil && (*req.Leeway < -45 || *req.Leeway > 45):
+		http.Error(w, "leeway must be between -45 and 45 degrees", http.StatusBadRequest)
+		return
+	}
+
+	own := a.fleet.UpdateOwnShip(func(v *simulation.Vessel) {
+		if req.Latitude != nil {
+			v.Latitude = *req.Latitude
+		}
+		if req.Longitude != nil {
+			v.Longitude = *req.Longitude
+		}
+		if req.Heading != nil {
+			v.Heading, v.COG = *req.Heading, *req.Heading
+		}
+		if req.Speed != nil {
+			v.STW, v.SOG = *req.Speed, *req.Speed
+		}
+		if req.Leeway != nil {
+			v.Leeway = *req.Leeway
+		}
+	})
+
+	a.logger.Info().
+		Float64("latitude", own.Latitude).
+		Float64("longitude", own.Longitude).
+		Float64("heading", own.Heading).
+		Float64("stw", own.STW).
+		Msg("own ship updated")
+
+	a.writeJSON(w, http.StatusOK, a.vesselStatus(own))
+}
+
+func (a *ControlAPI) simulationStatus() simulationStatus {
+	paused := a.fleet.Paused()
+	status := simulationStatus{Paused: &paused}
+	if a.controls != nil {
+		interval := a.controls.Interval().Seconds()
+		status.Interval = &interval
+	}
+	return status
+}
+
+func (a *ControlAPI) handleGetSimulation(w http.ResponseWriter, _ *http.Request) {
+	a.writeJSON(w, http.StatusOK, a.simulationStatus())
+}
+
+// handleUpdateSimulation pauses or resumes the fleet and changes the update
+// interval
+func (a *ControlAPI) handleUpdateSimulation(w http.ResponseWriter, r *http.Request) {
+	var req simulationStatus
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Interval != nil {
+		if a.controls == nil {
+			http.Error(w, "update interval not controllable", http.StatusNotFound)
+			return
+		}
+		interval := time.Duration(*req.Interval * float64(time.Second))
+		if err := a.controls.SetInterval(interval); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		a.logger.Info().Dur("interval", interval).Msg("update interval changed")
+	}
+	if req.Paused != nil {
+		a.fleet.SetPaused(*req.Paused)
+		if *req.Paused {
+			a.logger.Info().Msg("simulation paused")
+		} else {
+			a.logger.Info().Msg("simulation resumed")
+		}
+	}
+
+	a.writeJSON(w, http.StatusOK, a.simulationStatus())
+}
+
+func (a *ControlAPI) outputStatus() outputStatus {
+	status := outputStatus{Sentences: a.controls.Sentences(), PGNs: map[string]bool{}}
+	for pgn, enabled := range a.controls.PGNs() {
+		status.PGNs[strconv.FormatUint(uint64(pgn), 10)] = enabled
+	}
+	return status
+}
+
+func (a *ControlAPI) handleGetOutputs(w http.ResponseWriter, r *http.Request) {
+	if a.controls == nil {
+		http.Error(w, "outputs not controllable", http.StatusNotFound)
+		return
+	}
+	a.writeJSON(w, http.StatusOK, a.outputStatus())
+}
+
+// handleUpdateOutputs enables and disables sentences and PGNs, changing none
+// when any of them is invalid
+func (a *ControlAPI) handleUpdateOutputs(w http.ResponseWriter, r *http.Request) {
+	if a.controls == nil {
+		http.Error(w, "outputs not controllable", http.StatusNotFound)
+		return
+	}
+
+	var req outputStatus
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	pgns := make(map[uint32]bool, len(req.PGNs))
+	for key, enabled := range req.PGNs {
+		pgn, err := strconv.ParseUint(key, 10, 32)
+		if err != nil || pgn > maxPGN {
+			http.Error(w, fmt.Sprintf("invalid PGN %q", key), http.StatusBadRequest)
+			return
+		}
+		pgns[uint32(pgn)] = enabled
+	}
+	for formatter := range req.Sentences {
+		if !sentenceKey.MatchString(strings.ToUpper(formatter)) {
+			http.Error(w, fmt.Sprintf("invalid sentence formatter %q", formatter), http.StatusBadRequest)
+			return
+		}
+	}
+
+	for formatter, enabled := range req.Sentences {
+		a.controls.SetSentenceEnabled(formatter, enabled)
+		a.logger.Info().Str("formatter", strings.ToUpper(formatter)).Bool("enabled", enabled).Msg("sentence output changed")
+	}
+	for pgn, enabled := range pgns {
+		a.controls.SetPGNEnabled(pgn, enabled)
+		a.logger.Info().Uint32("pgn", pgn).Bool("enabled", enabled).Msg("PGN output changed")
+	}
+
+	a.writeJSON(w, http.StatusOK, a.outputStatus())
+}
+
+// handleCreateEvent triggers a scenario event: an engine fault, the passage
+// of a weather front or the failure of a sensor
+func (a *ControlAPI) handleCreateEvent(w http.ResponseWriter, r *http.Request) {
+	var req eventRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	now := time.Now()
+	event := a.logger.Info().Str("type", req.Type)
+	switch req.Type {
+	case "engine_fault":
+		if a.engines == nil {
+			http.Error(w, "no engines simulated", http.StatusNotFound)
+			return
+		}
+		if _, err := simulation.ParseEngineFault(string(req.Fault)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := a.engines.InjectFault(req.Engine, req.Fault); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		event = event.Int("engine", req.Engine).Str("fault", string(req.Fault))
+	case "weather_front":
+		if a.weather == nil {
+			http.Error(w, "no weather simulated", http.StatusNotFound)
+			return
+		}
+		if req.Delay < 0 {
+			http.Error(w, "delay must not be negative", http.StatusBadRequest)
+			return
+		}
+		a.weather.StartFront(a.fleet.ModelTime(now).Add(time.Duration(req.Delay * float64(time.Second))))
+		event = event.Float64("delay", req.Delay)
+	case "sensor_failure":
+		sensor, ok := a.sensors.Named(req.Sensor)
+		if !ok {
+			http.Error(w, fmt.Sprintf("no sensor named %q", req.Sensor), http.StatusNotFound)
+			return
+		}
+		if req.Duration <= 0 {
+			http.Error(w, "duration must be positive", http.StatusBadRequest)
+			return
+		}
+		sensor.Fail(now, time.Duration(req.Duration*float64(time.Second)))
+		event = event.Str("sensor", req.Sensor).Float64("duration", req.Duration)
+	default:
+		http.Error(w, "invalid event type", http.StatusBadRequest)
+		return
+	}
+
+	event.Msg("scenario event triggered")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (a *ControlAPI) handleOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
+
+func (a *ControlAPI) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		a.logger.Error().Err(err).Msg("failed to encode API response")
+	}
+}
@@ -0,0 +1,316 @@
+package network
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+	"github.com/rs/zerolog"
+)
+
+func TestControlAPIEncounters(t *testing.T) {
+	fleet := simulation.NewFleet(simulation.DefaultOwnShip())
+	api := NewControlAPI(fleet, zerolog.Logger{})
+
+	s := httptest.NewServer(api.Handler())
+	defer s.Close()
+
+	body := `{"type": "crossing", "cpa": 0.25, "tcpa": 360}`
+	resp, err := http.Post(s.URL+"/api/encounters", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Failed to create encounter: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %v", resp.Status)
+	}
+
+	var created targetStatus
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if math.Abs(created.CPA-0.25) > 0.01 || math.Abs(created.TCPA-360) > 5 {
+		t.Errorf("Unexpected ground truth CPA/TCPA: %.3f NM / %.1f s", created.CPA, created.TCPA)
+	}
+
+	resp, err = http.Get(s.URL + "/api/targets")
+	if err != nil {
+		t.Fatalf("Failed to list targets: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var targets []targetStatus
+	if err := json.NewDecoder(resp.Body).Decode(&targets); err != nil {
+		t.Fatalf("Failed to decode targets: %v", err)
+	}
+	if len(targets) != 1 || targets[0].MMSI != created.MMSI {
+		t.Errorf("Expected the created target to be listed, got %+v", targets)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, s.URL+"/api/targets/"+strconv.FormatUint(uint64(created.MMSI), 10), nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to delete target: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %v", resp.Status)
+	}
+	if len(fleet.Targets()) != 0 {
+		t.Error("Expected target to be removed from the fleet")
+	}
+}
+
+func TestControlAPIInvalidEncounter(t *testing.T) {
+	api := NewControlAPI(simulation.NewFleet(simulation.DefaultOwnShip()), zerolog.Logger{})
+
+	for _, body := range []string{`{"type": "sideways", "cpa": 1, "tcpa": 60}`, `{"type": "head-on", "cpa": 1, "tcpa": 0}`, `{"type": "overtaking", "cpa": 0.1, "tcpa": 60, "speed": 1}`, `not json`} {
+		req := httptest.NewRequest(http.MethodPost, "/api/encounters", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		api.Handler().ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestControlAPIWaypoints(t *testing.T) {
+	api := NewControlAPI(simulation.NewFleet(simulation.DefaultOwnShip()), zerolog.Logger{})
+	s := httptest.NewServer(api.Handler())
+	defer s.Close()
+
+	get := func() waypointList {
+		t.Helper()
+		resp, err := http.Get(s.URL + "/api/waypoints")
+		if err != nil {
+			t.Fatalf("Failed to list waypoints: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var list waypointList
+		if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+			t.Fatalf("Failed to decode waypoints: %v", err)
+		}
+		return list
+	}
+
+	if list := get(); list.Waypoints == nil || list.Routes == nil || len(list.Waypoints)+len(list.Routes) != 0 {
+		t.Errorf("Expected empty lists without a waypoint store, got %+v", list)
+	}
+
+	api.waypoints = simulation.NewWaypoints()
+	api.waypoints.SetWaypoint(simulation.Waypoint{Name: "003", Latitude: 49.286, Longitude: -123.177})
+	api.waypoints.SetRoute(simulation.Route{Name: "0", Waypoints: []string{"003"}})
+
+	list := get()
+	if len(list.Waypoints) != 1 || list.Waypoints[0].Name != "003" || len(list.Routes) != 1 || list.Routes[0].Name != "0" {
+		t.Errorf("Expected the stored waypoint and route, got %+v", list)
+	}
+}
+
+// serveAPI sends a request to the API and returns the recorded response
+func serveAPI(api *ControlAPI, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	api.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestControlAPIShared(t *testing.T) {
+	cfg := Config{
+		Logger:    zerolog.Nop(),
+		Fleet:     simulation.NewFleet(simulation.DefaultOwnShip()),
+		Waypoints: simulation.NewWaypoints(),
+	}
+	cfg.API = cfg.ControlAPI()
+	server := NewWebSocketServer(cfg)
+	server2000 := NewWebSocket2000Server(Config{Logger: zerolog.Nop(), API: cfg.API})
+
+	// Waypoints received on NMEA 0183 are listed on the NMEA 2000 port
+	if !server.handleSentence(util.AppendChecksum("$GPWPL,4917.16,N,12310.64,W,003")) {
+		t.Fatal("Expected the WPL sentence to be accepted")
+	}
+	rec := serveAPI(server2000.controlAPI(), http.MethodGet, "/api/waypoints", "")
+	if !strings.Contains(rec.Body.String(), `"003"`) {
+		t.Errorf("Expected the waypoint on both ports, got %s", rec.Body)
+	}
+}
+
+func TestControlAPIVessel(t *testing.T) {
+	api := NewControlAPI(simulation.NewFleet(simulation.DefaultOwnShip()), zerolog.Logger{})
+
+	rec := serveAPI(api, http.MethodPatch, "/api/vessel", `{"latitude": 54.5, "heading": 270, "speed": 12}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var own vesselStatus
+	if err := json.NewDecoder(rec.Body).Decode(&own); err != nil {
+		t.Fatalf("Failed to decode vessel: %v", err)
+	}
+	want := simulation.DefaultOwnShip()
+	if own.Latitude != 54.5 || own.Longitude != want.Longitude || own.Heading != 270 || own.COG != 270 || own.STW != 12 || own.SOG != 12 {
+		t.Errorf("Unexpected own ship %+v", own)
+	}
+	if got := api.fleet.OwnShip(); got.Heading != 270 {
+		t.Errorf("Expected the fleet to be updated, got heading %f", got.Heading)
+	}
+
+	rec = serveAPI(api, http.MethodGet, "/api/vessel", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"heading":270`) {
+		t.Errorf("Expected own ship, got %d: %s", rec.Code, rec.Body)
+	}
+
+	for _, body := range []string{`{"latitude": 91}`, `{"heading": 360}`, `{"speed": -1}`, `{"leeway": 46}`, `not json`} {
+		if rec := serveAPI(api, http.MethodPatch, "/api/vessel", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestControlAPISimulation(t *testing.T) {
+	server := NewBaseServer(Config{UpdateInterval: time.Second, Logger: zerolog.Nop()})
+	api := server.controlAPI()
+
+	rec := serveAPI(api, http.MethodPatch, "/api/simulation", `{"paused": true, "interval": 0.5}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if !server.Config.Fleet.Paused() || server.Config.Controls.Interval() != 500*time.Millisecond {
+		t.Errorf("Expected a paused fleet at 500ms, got %v at %v", server.Config.Fleet.Paused(), server.Config.Controls.Interval())
+	}
+	if got := strings.TrimSpace(rec.Body.String()); got != `{"paused":true,"interval":0.5}` {
+		t.Errorf("Unexpected simulation state %s", got)
+	}
+
+	serveAPI(api, http.MethodPatch, "/api/simulation", `{"paused": false}`)
+	if rec := serveAPI(api, http.MethodGet, "/api/simulation", ""); strings.TrimSpace(rec.Body.String()) != `{"paused":false,"interval":0.5}` {
+		t.Errorf("Expected the simulation to be resumed, got %s", rec.Body)
+	}
+
+	if rec := serveAPI(api, http.MethodPatch, "/api/simulation", `{"interval": 0.01}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a too short interval, got %d", rec.Code)
+	}
+
+	// Without output controls only pausing is available
+	bare := NewControlAPI(simulation.NewFleet(simulation.DefaultOwnShip()), zerolog.Logger{})
+	if rec := serveAPI(bare, http.MethodPatch, "/api/simulation", `{"interval": 2}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without controls, got %d", rec.Code)
+	}
+}
+
+func TestControlAPIOutputs(t *testing.T) {
+	server := NewBaseServer(Config{UpdateInterval: time.Second, Logger: zerolog.Nop()})
+	api := server.controlAPI()
+	controls := server.Config.Controls
+
+	rec := serveAPI(api, http.MethodPatch, "/api/outputs", `{"sentences": {"gga": false, "PRDID": false}, "pgns": {"129029": false}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if controls.SentenceEnabled("GGA") || controls.SentenceEnabled("PRDID") || controls.PGNEnabled(129029) {
+		t.Error("Expected GGA, PRDID and PGN 129029 to be disabled")
+	}
+
+	// The outputs generated are listed with the disabled ones
+	serveAPI(api, http.MethodPatch, "/api/outputs", `{"sentences": {"PRDID": true}}`)
+	controls.FilterSentences([]string{util.AppendChecksum("$GPRMC,")})
+	controls.PGNEnabled(127250)
+	rec = serveAPI(api, http.MethodGet, "/api/outputs", "")
+	if got := strings.TrimSpace(rec.Body.String()); got != `{"sentences":{"GGA":false,"RMC":true},"pgns":{"127250":true,"129029":false}}` {
+		t.Errorf("Unexpected outputs %s", got)
+	}
+
+	// Nothing changes when a key is invalid
+	for _, body := range []string{`{"sentences": {"RMC": false, "G-A": false}}`, `{"sentences": {"RMC": false}, "pgns": {"200000": false}}`} {
+		if rec := serveAPI(api, http.MethodPatch, "/api/outputs", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", body, rec.Code)
+		}
+	}
+	if !controls.SentenceEnabled("RMC") {
+		t.Error("Expected RMC to stay enabled")
+	}
+
+	bare := NewControlAPI(simulation.NewFleet(simulation.DefaultOwnShip()), zerolog.Logger{})
+	if rec := serveAPI(bare, http.MethodGet, "/api/outputs", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without controls, got %d", rec.Code)
+	}
+}
+
+func TestControlAPIEvents(t *testing.T) {
+	sensors, err := simulation.NewSensors([]simulation.SensorConfig{{Name: "gps", Kind: simulation.SensorGNSS}})
+	if err != nil {
+		t.Fatalf("NewSensors failed: %v", err)
+	}
+	server := NewBaseServer(Config{UpdateInterval: time.Second, Logger: zerolog.Nop(), Sensors: sensors})
+	api := server.controlAPI()
+
+	rec := serveAPI(api, http.MethodPost, "/api/events", `{"type": "engine_fault", "engine": 0, "fault": "overheat"}`)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204, got %d: %s", rec.Code, rec.Body)
+	}
+	// The coolant heats up over minutes
+	now := time.Now()
+	server.Config.Engines.Sample(server.Config.Fleet.OwnShip(), now)
+	if engines := server.Config.Engines.Sample(server.Config.Fleet.OwnShip(), now.Add(time.Hour)); engines[0].Alarms&simulation.AlarmOverTemperature == 0 {
+		t.Errorf("Expected the engine to overheat, got alarms %b", engines[0].Alarms)
+	}
+
+	if rec := serveAPI(api, http.MethodPost, "/api/events", `{"type": "sensor_failure", "sensor": "gps", "duration": 60}`); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d: %s", rec.Code, rec.Body)
+	}
+	if !sensors[0].Failed(time.Now()) {
+		t.Error("Expected the GPS to fail")
+	}
+
+	if rec := serveAPI(api, http.MethodPost, "/api/events", `{"type": "weather_front", "delay": 0}`); rec.Code != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d: %s", rec.Code, rec.Body)
+	}
+
+	for body, status := range map[string]int{
+		`{"type": "earthquake"}`:                                     http.StatusBadRequest,
+		`{"type": "engine_fault", "engine": 5}`:                      http.StatusBadRequest,
+		`{"type": "engine_fault", "fault": "flooded"}`:               http.StatusBadRequest,
+		`{"type": "weather_front", "delay": -1}`:                     http.StatusBadRequest,
+		`{"type": "sensor_failure", "sensor": "gps"}`:                http.StatusBadRequest,
+		`{"type": "sensor_failure", "sensor": "log", "duration": 1}`: http.StatusNotFound,
+	} {
+		if rec := serveAPI(api, http.MethodPost, "/api/events", body); rec.Code != status {
+			t.Errorf("Expected status %d for %s, got %d", status, body, rec.Code)
+		}
+	}
+}
+
+func TestControlAPIOpenAPI(t *testing.T) {
+	api := NewControlAPI(simulation.NewFleet(simulation.DefaultOwnShip()), zerolog.Logger{})
+	rec := serveAPI(api, http.MethodGet, "/api/openapi.json", "")
+
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&spec); err != nil {
+		t.Fatalf("Invalid OpenAPI description: %v", err)
+	}
+
+	// Every route is described
+	routes := []string{
+		"GET /api/targets", "GET /api/targets/{mmsi}", "DELETE /api/targets/{mmsi}",
+		"POST /api/encounters", "GET /api/waypoints",
+		"GET /api/vessel", "PATCH /api/vessel", "GET /api/simulation", "PATCH /api/simulation",
+		"GET /api/outputs", "PATCH /api/outputs", "POST /api/events", "GET /api/openapi.json",
+	}
+	for _, route := range routes {
+		method, path, _ := strings.Cut(route, " ")
+		if _, ok := spec.Paths[path][strings.ToLower(method)]; !ok {
+			t.Errorf("Route %s is not described", route)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/gorilla/websocket"
+)
+
+// Backoff holds the delays between attempts to connect to a remote listener.
+// The delay starts at Initial after a lost connection and doubles after every
+// failed attempt up to Max.
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// DefaultBackoff returns a backoff from 1 second up to 30 seconds
+func DefaultBackoff() Backoff {
+	return Backoff{Initial: time.Second, Max: 30 * time.Second}
+}
+
+// next returns the delay after a failed attempt following delay d
+func (b Backoff) next(d time.Duration) time.Duration {
+	return min(2*d, b.Max)
+}
+
+// writeTimeout is how long a write may block before the remote listener is
+// considered lost, so that a listener that stops reading cannot stall the output
+const writeTimeout = 2 * time.Second
+
+// link is an outbound connection to a remote listener
+type link interface {
+	send(line string) error
+	receive(handle func(line string)) // Returns once the connection is closed
+	close() error
+}
+
+// tcpLink sends lines terminated by CR LF over TCP
+type tcpLink struct {
+	conn net.Conn
+}
+
+func (l *tcpLink) send(line string) error {
+	l.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := l.conn.Write([]byte(strings.TrimRight(line, "\r\n") + "\r\n"))
+	return err
+}
+
+func (l *tcpLink) receive(handle func(line string)) {
+	readLines(l.conn, handle)
+}
+
+func (l *tcpLink) close() error {
+	return l.conn.Close()
+}
+
+// wsLink sends every line in a text message, as the WebSocket servers do
+type wsLink struct {
+	conn *websocket.Conn
+}
+
+func (l *wsLink) send(line string) error {
+	l.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return l.conn.WriteMessage(websocket.TextMessage, []byte(line))
+}
+
+func (l *wsLink) receive(handle func(line string)) {
+	for {
+		_, data, err := l.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		readLines(bytes.NewReader(data), handle)
+	}
+}
+
+func (l *wsLink) close() error {
+	return l.conn.Close()
+}
+
+// dial connects to a remote listener, over WebSocket for ws:// and wss://
+// URLs and over TCP for host:port addresses
+func dial(ctx context.Context, remote string) (link, error) {
+	if strings.HasPrefix(remote, "ws://") || strings.HasPrefix(remote, "wss://") {
+		conn, _, err := websocket.DefaultDialer.DialContext(ctx, remote, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &wsLink{conn: conn}, nil
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", remote)
+	if err != nil {
+		return nil, err
+	}
+	return &tcpLink{conn: conn}, nil
+}
+
+// dialer keeps a connection to Config.Remote, reconnecting with backoff
+// whenever it is lost
+type dialer struct {
+	*BaseServer
+	mu      sync.Mutex
+	link    link       // nil while disconnected
+	writeMu sync.Mutex // Serializes writes, which are not made under mu so that Stop never waits for them
+}
+
+func newDialer(cfg Config) *dialer {
+	if cfg.Reconnect == (Backoff{}) {
+		cfg.Reconnect = DefaultBackoff()
+	}
+	return &dialer{BaseServer: NewBaseServer(cfg)}
+}
+
+// connectLoop connects to the remote listener and passes the lines it sends
+// to handle until ctx is done or the dialer is stopped, and then stops the dialer
+func (d *dialer) connectLoop(ctx context.Context, handle func(line string)) {
+	defer d.Stop()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-d.Done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	delay := d.Config.Reconnect.Initial
+	for {
+		l, err := dial(ctx, d.Config.Remote)
+		if err == nil {
+			d.Config.Logger.Info().Str("remote", d.Config.Remote).Msg("connected to remote listener")
+			delay = d.Config.Reconnect.Initial
+			d.serve(ctx, l, handle)
+			d.Config.Logger.Error().Str("remote", d.Config.Remote).Dur("retry", delay).Msg("connection to remote listener lost")
+		} else if ctx.Err() == nil {
+			d.Config.Logger.Error().Err(err).Str("remote", d.Config.Remote).Dur("retry", delay).Msg("failed to connect to remote listener")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if err != nil {
+			delay = d.Config.Reconnect.next(delay)
+		}
+	}
+}
+
+// serve sends on a connection and passes the lines received to handle until
+// the connection is lost or ctx is done
+func (d *dialer) serve(ctx context.Context, l link, handle func(line string)) {
+	d.mu.Lock()
+	d.link = l
+	d.mu.Unlock()
+
+	// Close the connection on shutdown to end receive
+	lost := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			l.close()
+		case <-lost:
+		}
+	}()
+
+	l.receive(handle)
+	close(lost)
+
+	d.mu.Lock()
+	if d.link == l {
+		d.link = nil
+	}
+	d.mu.Unlock()
+	l.close()
+}
+
+// send writes lines to the remote listener, dropping them while disconnected.
+// A failed or timed out write closes the connection, which is then reconnected.
+func (d *dialer) send(lines []string) {
+	d.mu.Lock()
+	l := d.link
+	d.mu.Unlock()
+	if l == nil {
+		return
+	}
+
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+	for _, line := range lines {
+		if err := l.send(line); err != nil {
+			d.Config.Logger.Error().Err(err).Str("remote", d.Config.Remote).Msg("failed to send message")
+			d.mu.Lock()
+			if d.link == l {
+				d.link = nil
+			}
+			d.mu.Unlock()
+			l.close()
+			return
+		}
+	}
+}
+
+// Stop closes the connection and stops reconnecting
+func (d *dialer) Stop() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	select {
+	case <-d.Done:
+		return nil
+	default:
+		close(d.Done)
+	}
+
+	if d.link != nil {
+		err := d.link.close()
+		d.link = nil
+		return err
+	}
+	return nil
+}
+
+// Client streams NMEA 0183 sentences to a remote listener, connecting out
+// over TCP or WebSocket instead of accepting connections
+type Client struct {
+	*dialer
+}
+
+// NewClient creates a client connecting to Config.Remote
+func NewClient(cfg Config) *Client {
+	return &Client{dialer: newDialer(cfg)}
+}
+
+// Start connects to the remote listener in the background and streams
+// sentences until ctx is done or the client is stopped, reconnecting whenever
+// the connection is lost
+func (c *Client) Start(ctx context.Context) error {
+	c.Config.Logger.Info().Str("remote", c.Config.Remote).Msg("starting NMEA 0183 client")
+
+	go c.connectLoop(ctx, c.receive)
+	go c.broadcastLoop(ctx)
+	return nil
+}
+
+// receive handles a sentence sent by the remote listener, echoing it back
+// when Echo is set
+func (c *Client) receive(line string) {
+	if c.handleSentence(line) && c.Config.Echo {
+		c.send([]string{line})
+	}
+}
+
+func (c *Client) broadcastLoop(ctx context.Context) {
+	snapshots, unsubscribe := c.Config.Snapshots.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.Done:
+			return
+		case snap := <-snapshots:
+			// Network links have no serial line to limit the output to
+			c.send(c.generateSentences(snap))
+		}
+	}
+}
+
+// Client2000 streams NMEA 2000 messages to a remote listener, connecting out
+// over TCP or WebSocket instead of accepting connections
+type Client2000 struct {
+	*dialer
+	assembler *pgn.Assembler
+}
+
+// NewClient2000 creates a NMEA 2000 client connecting to Config.Remote
+func NewClient2000(cfg Config) *Client2000 {
+	return &Client2000{dialer: newDialer(cfg), assembler: pgn.NewAssembler()}
+}
+
+// Start connects to the remote listener in the background until ctx is done
+// or the client is stopped, reconnecting whenever the connection is lost
+func (c *Client2000) Start(ctx context.Context) error {
+	c.Config.Logger.Info().Str("remote", c.Config.Remote).Msg("starting NMEA 2000 client")
+
+	// Only one connection receives at a time, so the assembler is not shared
+	go c.connectLoop(ctx, func(line string) { c.handlePGNLine(c.assembler, line) })
+	return nil
+}
+
+// SendPGN sends a NMEA 2000 message to the remote listener
+func (c *Client2000) SendPGN(msg pgn.Message) error {
+	c.send(formatPGNFrames(msg, c.Config.PGNSource))
+	return nil
+}
@@ -0,0 +1,210 @@
+package network
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+)
+
+func TestBackoff(t *testing.T) {
+	b := Backoff{Initial: time.Second, Max: 5 * time.Second}
+
+	var delays []time.Duration
+	for d := b.Initial; len(delays) < 5; d = b.next(d) {
+		delays = append(delays, d)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i := range want {
+		if delays[i] != want[i] {
+			t.Fatalf("Expected delays %v, got %v", want, delays)
+		}
+	}
+}
+
+func TestClient_Reconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	pilot := simulation.NewAutopilot(simulation.DefaultAutopilotConfig())
+	client := NewClient(Config{
+		UpdateInterval:    50 * time.Millisecond,
+		Logger:            zerolog.Nop(),
+		BaudRate:          38400,
+		Variation:         simulation.FixedVariation(0),
+		Autopilot:         pilot,
+		AutopilotCommands: true,
+		Remote:            listener.Addr().String(),
+		Reconnect:         Backoff{Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond},
+		SentenceOptions:   SentenceOptions{EnablePosition: true},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.Start(ctx)
+
+	for i := 0; i < 2; i++ {
+		listener.(*net.TCPListener).SetDeadline(time.Now().Add(2 * time.Second))
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Fatalf("Expected connection %d: %v", i+1, err)
+		}
+
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err != nil || !strings.HasSuffix(line, "\r\n") {
+			t.Fatalf("Expected a sentence on connection %d, got %q, %v", i+1, line, err)
+		}
+		if _, err := util.ParseSentence(strings.TrimSpace(line)); err != nil {
+			t.Errorf("Invalid sentence %q: %v", line, err)
+		}
+
+		if i == 0 {
+			// The client reconnects once the listener drops the connection
+			conn.Close()
+			continue
+		}
+
+		conn.Write([]byte(util.AppendChecksum("$IIHSC,120.0,T,,M") + "\r\n"))
+		deadline := time.Now().Add(2 * time.Second)
+		for pilot.State().Mode != simulation.AutopilotHeading && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if s := pilot.State(); s.Mode != simulation.AutopilotHeading || s.HeadingToSteer != 120 {
+			t.Errorf("Expected the remote listener to steer 120°, got %+v", s)
+		}
+		conn.Close()
+	}
+
+	cancel()
+	select {
+	case <-client.Done:
+	case <-time.After(time.Second):
+		t.Error("Expected the client to stop")
+	}
+}
+
+func TestClient2000_WebSocket(t *testing.T) {
+	received := make(chan string, 100)
+	commands := make(chan string, 1)
+	upgrader := websocket.Upgrader{}
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		go func() {
+			for frame := range commands {
+				conn.WriteMessage(websocket.TextMessage, []byte(frame))
+			}
+		}()
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			received <- string(data)
+		}
+	}))
+	defer remote.Close()
+
+	client := NewClient2000(Config{
+		Logger: zerolog.Nop(),
+		Remote: "ws" + strings.TrimPrefix(remote.URL, "http") + "/n2k",
+	})
+	handled := make(chan pgn.Message, 1)
+	client.HandlePGN(func(msg pgn.Message) error {
+		handled <- msg
+		return nil
+	})
+
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+	defer client.Stop()
+
+	// Messages are dropped until the client is connected
+	msg := pgn.Message{PGN: 127245, Data: []byte{0, 0, 0xff, 0x7f, 0, 0, 0xff, 0xff}, Source: 1}
+	deadline := time.After(2 * time.Second)
+	for frame := ""; frame == ""; {
+		client.SendPGN(msg)
+		select {
+		case frame = <-received:
+			if frame != formatPGNMessage(msg, false) {
+				t.Errorf("Expected %q, got %q", formatPGNMessage(msg, false), frame)
+			}
+		case <-time.After(20 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("Expected the client to connect and send the message")
+		}
+	}
+
+	commands <- formatPGNMessage(msg, true)
+	close(commands)
+	select {
+	case got := <-handled:
+		if got.PGN != 127245 || got.Source != 1 {
+			t.Errorf("Expected PGN 127245 from source 1, got %+v", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Error("Expected the message sent by the remote listener to be handled")
+	}
+}
+
+func TestClient2000_StalledRemote(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	client := NewClient2000(Config{Logger: zerolog.Nop(), Remote: listener.Addr().String()})
+	if err := client.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start client: %v", err)
+	}
+
+	// The remote listener accepts the connection but never reads
+	listener.(*net.TCPListener).SetDeadline(time.Now().Add(2 * time.Second))
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Expected a connection: %v", err)
+	}
+	defer conn.Close()
+
+	line := strings.Repeat("x", 1<<20)
+	sending := make(chan struct{})
+	go func() {
+		defer close(sending)
+		for i := 0; i < 100; i++ {
+			client.send([]string{line})
+		}
+	}()
+
+	// Stop does not wait for the blocked write, which ends once the connection is closed
+	time.Sleep(200 * time.Millisecond)
+	start := time.Now()
+	client.Stop()
+	if d := time.Since(start); d > writeTimeout/2 {
+		t.Errorf("Expected Stop to return at once, took %v", d)
+	}
+	select {
+	case <-sending:
+	case <-time.After(2 * writeTimeout):
+		t.Error("Expected sending to end once the client is stopped")
+	}
+}
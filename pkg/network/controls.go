@@ -0,0 +1,215 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MinInterval is the shortest update interval
+const MinInterval = 100 * time.Millisecond
+
+// Controls holds the output settings changed at run time through the control
+// API: the update interval and the disabled sentences and PGNs. The servers
+// of both protocols share one Controls, which also records the sentences and
+// PGNs they generate.
+type Controls struct {
+	mu        sync.RWMutex
+	interval  time.Duration
+	changed   chan struct{}   // Closed when the interval changes
+	sentences map[string]bool // Disabled sentence formatters
+	pgns      map[uint32]bool // Disabled PGNs
+	seen      map[string]bool // Sentence formatters generated so far
+	seenPGNs  map[uint32]bool // PGNs generated so far
+}
+
+// NewControls creates controls with every output enabled
+func NewControls(interval time.Duration) *Controls {
+	return &Controls{
+		interval:  interval,
+		changed:   make(chan struct{}),
+		sentences: make(map[string]bool),
+		pgns:      make(map[uint32]bool),
+		seen:      make(map[string]bool),
+		seenPGNs:  make(map[uint32]bool),
+	}
+}
+
+// Interval returns the update interval
+func (c *Controls) Interval() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.interval
+}
+
+// SetInterval changes the update interval of every ticker
+func (c *Controls) SetInterval(d time.Duration) error {
+	if d < MinInterval {
+		return fmt.Errorf("update interval %v is shorter than %v", d, MinInterval)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if d != c.interval {
+		c.interval = d
+		close(c.changed)
+		c.changed = make(chan struct{})
+	}
+	return nil
+}
+
+// watch returns the update interval and a channel closed when it changes
+func (c *Controls) watch() (time.Duration, <-chan struct{}) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.interval, c.changed
+}
+
+// SentenceEnabled reports whether sentences with a formatter are sent
+func (c *Controls) SentenceEnabled(formatter string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.sentences[strings.ToUpper(formatter)]
+}
+
+// SetSentenceEnabled enables or disables the sentences with a formatter, e.g.
+// GGA, or with the address of a proprietary sentence, e.g. PRDID
+func (c *Controls) SetSentenceEnabled(formatter string, enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if enabled {
+		delete(c.sentences, strings.ToUpper(formatter))
+	} else {
+		c.sentences[strings.ToUpper(formatter)] = true
+	}
+}
+
+// Sentences returns the sentence formatters generated so far and the disabled
+// ones, each mapped to whether it is enabled
+func (c *Controls) Sentences() map[string]bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	formatters := make(map[string]bool, len(c.seen)+len(c.sentences))
+	for formatter := range c.seen {
+		formatters[formatter] = true
+	}
+	for formatter := range c.sentences {
+		formatters[formatter] = false
+	}
+	return formatters
+}
+
+// FilterSentences returns the sentences whose formatter is enabled, recording
+// the formatters of all of them as generated
+func (c *Controls) FilterSentences(sentences []string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, s := range sentences {
+		if formatter := sentenceFormatter(s); formatter != "" {
+			c.seen[formatter] = true
+		}
+	}
+	if len(c.sentences) == 0 {
+		return sentences
+	}
+	out := sentences[:0:0]
+	for _, s := range sentences {
+		if !c.sentences[sentenceFormatter(s)] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// PGNEnabled reports whether messages with a PGN are sent, recording the PGN
+// as generated
+func (c *Controls) PGNEnabled(pgn uint32) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seenPGNs[pgn] = true
+	return !c.pgns[pgn]
+}
+
+// SetPGNEnabled enables or disables the messages with a PGN
+func (c *Controls) SetPGNEnabled(pgn uint32, enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if enabled {
+		delete(c.pgns, pgn)
+	} else {
+		c.pgns[pgn] = true
+	}
+}
+
+// PGNs returns the PGNs generated so far and the disabled ones, each mapped
+// to whether it is enabled
+func (c *Controls) PGNs() map[uint32]bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	pgns := make(map[uint32]bool, len(c.seenPGNs)+len(c.pgns))
+	for pgn := range c.seenPGNs {
+		pgns[pgn] = true
+	}
+	for pgn := range c.pgns {
+		pgns[pgn] = false
+	}
+	return pgns
+}
+
+// sentenceFormatter returns the formatter of a sentence, or the address of a
+// proprietary sentence
+func sentenceFormatter(sentence string) string {
+	if len(sentence) < 2 {
+		return ""
+	}
+	address, _, _ := strings.Cut(sentence[1:], ",")
+	if len(address) == 5 && address[0] != 'P' {
+		return address[2:]
+	}
+	return address
+}
+
+// Ticker delivers a tick every update interval, following changes of the
+// interval as they are made
+type Ticker struct {
+	C    <-chan time.Time
+	stop chan struct{}
+}
+
+// NewTicker returns a ticker following the update interval
+func (c *Controls) NewTicker() *Ticker {
+	ticks := make(chan time.Time, 1)
+	t := &Ticker{C: ticks, stop: make(chan struct{})}
+	go t.run(c, ticks)
+	return t
+}
+
+// Stop turns off the ticker
+func (t *Ticker) Stop() {
+	close(t.stop)
+}
+
+func (t *Ticker) run(c *Controls, ticks chan<- time.Time) {
+	interval, changed := c.watch()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-changed:
+			interval, changed = c.watch()
+			ticker.Reset(interval)
+		case now := <-ticker.C:
+			select {
+			case ticks <- now:
+			default: // Drop ticks for slow receivers, as time.Ticker does
+			}
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package network
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+)
+
+func TestControlsFilterSentences(t *testing.T) {
+	controls := NewControls(time.Second)
+	sentences := []string{
+		util.AppendChecksum("$GPGGA,"),
+		util.AppendChecksum("$GNGGA,"),
+		util.AppendChecksum("$GPRMC,"),
+		util.AppendChecksum("!AIVDM,1,1,,A,1,0"),
+		util.AppendChecksum("$PRDID,1,2,3"),
+	}
+	if got := controls.FilterSentences(sentences); len(got) != len(sentences) {
+		t.Errorf("Expected every sentence enabled, got %v", got)
+	}
+
+	controls.SetSentenceEnabled("gga", false)
+	controls.SetSentenceEnabled("VDM", false)
+	controls.SetSentenceEnabled("PRDID", false)
+	got := controls.FilterSentences(sentences)
+	if len(got) != 1 || got[0] != sentences[2] {
+		t.Errorf("Expected only RMC, got %v", got)
+	}
+	want := map[string]bool{"GGA": false, "RMC": true, "VDM": false, "PRDID": false}
+	if got := controls.Sentences(); !maps.Equal(got, want) {
+		t.Errorf("Expected sentences %v, got %v", want, got)
+	}
+
+	controls.SetSentenceEnabled("GGA", true)
+	if !controls.SentenceEnabled("GGA") || len(controls.FilterSentences(sentences)) != 3 {
+		t.Error("Expected GGA enabled again")
+	}
+}
+
+func TestControlsPGNs(t *testing.T) {
+	controls := NewControls(time.Second)
+	controls.SetPGNEnabled(129029, false)
+	controls.SetPGNEnabled(127250, false)
+	if controls.PGNEnabled(129029) || !controls.PGNEnabled(129025) {
+		t.Error("Expected only PGN 129029 of the two disabled")
+	}
+	want := map[uint32]bool{127250: false, 129025: true, 129029: false}
+	if got := controls.PGNs(); !maps.Equal(got, want) {
+		t.Errorf("Expected PGNs %v, got %v", want, got)
+	}
+}
+
+func TestControlsTicker(t *testing.T) {
+	controls := NewControls(time.Hour)
+	ticker := controls.NewTicker()
+	defer ticker.Stop()
+
+	if err := controls.SetInterval(time.Millisecond); err == nil {
+		t.Error("Expected an interval below MinInterval to be rejected")
+	}
+
+	// The ticker follows the new interval without waiting out the old one
+	if err := controls.SetInterval(MinInterval); err != nil {
+		t.Fatalf("SetInterval failed: %v", err)
+	}
+	select {
+	case <-ticker.C:
+	case <-time.After(time.Second):
+		t.Fatal("Expected a tick at the new interval")
+	}
+	if controls.Interval() != MinInterval {
+		t.Errorf("Expected interval %v, got %v", MinInterval, controls.Interval())
+	}
+}
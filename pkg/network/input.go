@@ -0,0 +1,115 @@
+package network
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/autopilot"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+)
+
+// HandlePGN installs the handler for NMEA 2000 messages received from
+// clients. Handler errors are logged.
+func (b *BaseServer) HandlePGN(handler func(pgn.Message) error) {
+	b.Mu.Lock()
+	defer b.Mu.Unlock()
+	b.pgnHandler = handler
+}
+
+// HandleSentence installs an additional handler for the valid NMEA 0183
+// sentences received from clients. Handler errors are logged.
+func (b *BaseServer) HandleSentence(handler func(util.Sentence) error) {
+	b.Mu.Lock()
+	defer b.Mu.Unlock()
+	b.sentenceHandler = handler
+}
+
+// readLines calls handle with every non-empty line received from a client
+// until the connection is closed
+func readLines(r io.Reader, handle func(line string)) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			handle(line)
+		}
+	}
+}
+
+// handleSentence validates an NMEA 0183 sentence received from a client and
+// dispatches it to the autopilot when AutopilotCommands is set, to the
+// waypoint store and to the installed handler. It reports whether the
+// sentence is valid, so that it may be echoed or forwarded.
+func (b *BaseServer) handleSentence(line string) bool {
+	s, err := util.ParseSentence(line)
+	if err != nil {
+		b.Config.Logger.Error().Err(err).Str("sentence", line).Msg("invalid sentence received")
+		return false
+	}
+	b.Config.Logger.Debug().Str("sentence", line).Msg("sentence received")
+
+	if b.Config.Autopilot != nil && b.Config.AutopilotCommands {
+		own := b.Config.Fleet.OwnShip()
+		ok, err := autopilot.Apply(b.Config.Autopilot, s, b.Config.Variation(own.Latitude, own.Longitude, time.Now()))
+		if err != nil {
+			b.Config.Logger.Error().Err(err).Str("sentence", line).Msg("autopilot command rejected")
+		} else if ok {
+			b.Config.Logger.Info().Str("sentence", line).Msg("autopilot command")
+		}
+	}
+
+	if b.waypoints != nil {
+		if _, err := b.waypoints.Load(s); err != nil {
+			b.Config.Logger.Error().Err(err).Str("sentence", line).Msg("waypoint rejected")
+		}
+	}
+
+	b.Mu.RLock()
+	handler := b.sentenceHandler
+	b.Mu.RUnlock()
+	if handler != nil {
+		if err := handler(s); err != nil {
+			b.Config.Logger.Error().Err(err).Str("sentence", line).Msg("sentence rejected")
+		}
+	}
+	return true
+}
+
+// relayed returns the clients a valid sentence received from a client is
+// sent to, the sender itself when Echo is set and the others when Forward is
+// set
+func relayed[C comparable](cfg Config, sender C, clients map[C]bool) []C {
+	var to []C
+	for c := range clients {
+		if (c == sender && cfg.Echo) || (c != sender && cfg.Forward) {
+			to = append(to, c)
+		}
+	}
+	return to
+}
+
+// handlePGNLine passes a NMEA 2000 message received from a client to the
+// installed handler, reassembling fast-packet messages from their frames
+func (b *BaseServer) handlePGNLine(assembler *pgn.Assembler, line string) {
+	frame, err := parsePGNMessage(line)
+	if err != nil {
+		b.Config.Logger.Error().Err(err).Str("frame", line).Msg("invalid frame received")
+		return
+	}
+	msg, ok := assembler.Add(frame)
+	if !ok {
+		return
+	}
+
+	b.Mu.RLock()
+	handler := b.pgnHandler
+	b.Mu.RUnlock()
+	if handler == nil {
+		return
+	}
+	if err := handler(msg); err != nil {
+		b.Config.Logger.Error().Err(err).Uint32("pgn", msg.PGN).Msg("message rejected")
+	}
+}
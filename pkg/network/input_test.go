@@ -0,0 +1,135 @@
+package network
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+	"github.com/rs/zerolog"
+)
+
+func TestBaseServer_HandleSentence(t *testing.T) {
+	pilot := simulation.NewAutopilot(simulation.DefaultAutopilotConfig())
+	server := NewBaseServer(Config{
+		Autopilot: pilot,
+		Variation: simulation.FixedVariation(-3),
+		Logger:    zerolog.Nop(),
+	})
+
+	server.handleSentence(util.AppendChecksum("$IIHSC,150.0,T,,M"))
+	if s := pilot.State(); s.Mode != simulation.AutopilotStandby {
+		t.Errorf("Expected commands to be ignored unless enabled, got %+v", s)
+	}
+
+	server.Config.AutopilotCommands = true
+
+	var valid []bool
+	readLines(strings.NewReader(util.AppendChecksum("$IIHSC,,T,120.0,M")+"\r\n\r\n$IIHSC,200.0,T,,M*00\r\n"), func(line string) {
+		valid = append(valid, server.handleSentence(line))
+	})
+
+	// The second sentence has a bad checksum and is ignored
+	if len(valid) != 2 || !valid[0] || valid[1] {
+		t.Errorf("Expected only the first sentence to be valid, got %v", valid)
+	}
+	if s := pilot.State(); s.Mode != simulation.AutopilotHeading || s.HeadingToSteer != 117 {
+		t.Errorf("Expected to steer 117° true, got %+v", s)
+	}
+
+	server.Config.SentenceOptions.EnableRudder = true
+	if sentences := server.rudderSentences(); len(sentences) != 1 || !strings.HasPrefix(sentences[0], "$IIRSA,0.0,A,") {
+		t.Errorf("Expected an RSA sentence, got %v", sentences)
+	}
+}
+
+func TestBaseServer_HandlePGNLine(t *testing.T) {
+	server := NewBaseServer(Config{Logger: zerolog.Nop()})
+
+	var received []pgn.Message
+	server.HandlePGN(func(msg pgn.Message) error {
+		received = append(received, msg)
+		return errors.New("rejected")
+	})
+
+	data := pgn.EncodeHeadingTrackControl(pgn.HeadingTrackControl{SteeringMode: pgn.SteeringHeadingControl})
+	assembler := pgn.NewAssembler()
+	for _, line := range formatPGNFrames(pgn.Message{PGN: 127237, Data: data, Source: 4}, true) {
+		server.handlePGNLine(assembler, line)
+	}
+	server.handlePGNLine(assembler, "$PNMEA2K,127245,8,0000000000000000,4*00")
+
+	if len(received) != 1 || received[0].PGN != 127237 || string(received[0].Data) != string(data) {
+		t.Errorf("Expected the reassembled PGN 127237 only, got %+v", received)
+	}
+}
+
+func TestBaseServer_HandleSentenceHandlers(t *testing.T) {
+	waypoints := simulation.NewWaypoints()
+	server := NewBaseServer(Config{Waypoints: waypoints, Logger: zerolog.Nop()})
+
+	var received []string
+	server.HandleSentence(func(s util.Sentence) error {
+		received = append(received, s.Formatter)
+		return nil
+	})
+
+	for _, sentence := range []string{
+		"$GPWPL,4917.16,N,12310.64,W,003",
+		"$GPRTE,1,1,c,0,003,004",
+		"$GPWPL,,N,12310.64,W,004",
+	} {
+		if !server.handleSentence(util.AppendChecksum(sentence)) {
+			t.Errorf("Expected %s to be valid", sentence)
+		}
+	}
+
+	// The invalid WPL is still passed on, only the waypoint store rejects it
+	if strings.Join(received, ",") != "WPL,RTE,WPL" {
+		t.Errorf("Expected every valid sentence to be handled, got %v", received)
+	}
+	if list := waypoints.List(); len(list) != 1 || list[0].Name != "003" {
+		t.Errorf("Expected waypoint 003 to be loaded, got %+v", list)
+	}
+	if r, ok := waypoints.Route("0"); !ok || len(r.Waypoints) != 2 {
+		t.Errorf("Expected route 0 to be loaded, got %+v", r)
+	}
+}
+
+func TestTCPServer_Relay(t *testing.T) {
+	sender, other := newMockConn(), newMockConn()
+	server := NewTCPServer(Config{Logger: zerolog.Nop()})
+	server.clients[sender] = true
+	server.clients[other] = true
+
+	tests := []struct {
+		echo, forward bool
+		toSender      bool
+		toOther       bool
+	}{
+		{false, false, false, false},
+		{true, false, true, false},
+		{false, true, false, true},
+		{true, true, true, true},
+	}
+	for _, tt := range tests {
+		server.Config.Echo, server.Config.Forward = tt.echo, tt.forward
+		server.relay(sender, "$IIHSC,150.0,T,,M*00")
+
+		if got := len(sender.writeData) == 1; got != tt.toSender {
+			t.Errorf("Echo %v, forward %v: expected sent back %v, got %v", tt.echo, tt.forward, tt.toSender, got)
+		}
+		if got := len(other.writeData) == 1; got != tt.toOther {
+			t.Errorf("Echo %v, forward %v: expected forwarded %v, got %v", tt.echo, tt.forward, tt.toOther, got)
+		}
+		for _, conn := range []*mockConn{sender, other} {
+			for len(conn.writeData) > 0 {
+				if data := string(<-conn.writeData); data != "$IIHSC,150.0,T,,M*00\r\n" {
+					t.Errorf("Unexpected relayed data %q", data)
+				}
+			}
+		}
+	}
+}
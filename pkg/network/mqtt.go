@@ -0,0 +1,311 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/mqtt"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/signalk"
+)
+
+// MQTTOptions configures the connection of an MQTT publisher and the topics
+// it publishes to. A topic is a template whose placeholders are replaced for
+// every message; an empty topic disables its stream.
+type MQTTOptions struct {
+	ClientID      string
+	Username      string
+	Password      string
+	Version       mqtt.Version // Defaults to MQTT 3.1.1
+	QoS           byte
+	Retain        bool
+	SentenceTopic string // Raw NMEA 0183 sentences, with {talker} and {formatter}
+	PGNTopic      string // Raw NMEA 2000 messages in the TCP line format, with {pgn} and {source}
+	ValueTopic    string // Decoded values as JSON, with {path} (the Signal K path separated by slashes) and {source}
+}
+
+// DefaultMQTTOptions returns QoS 0 publishing below the nmeasim topic
+func DefaultMQTTOptions() MQTTOptions {
+	return MQTTOptions{
+		ClientID:      "nmeasim",
+		SentenceTopic: "nmeasim/0183/{talker}/{formatter}",
+		PGNTopic:      "nmeasim/2000/{pgn}",
+		ValueTopic:    "nmeasim/values/{path}",
+	}
+}
+
+// mqttKeepAlive is the interval of pings to the broker
+const mqttKeepAlive = 30 * time.Second
+
+// mqttQueueLength is the number of messages waiting to be published, beyond
+// which messages are dropped
+const mqttQueueLength = 1024
+
+// mqttValue is the payload of a decoded value, as the leaf of the full
+// Signal K model
+type mqttValue struct {
+	Value     interface{} `json:"value"`
+	Source    string      `json:"$source"`
+	Timestamp string      `json:"timestamp"`
+}
+
+// mqttMessage is a message waiting to be published
+type mqttMessage struct {
+	topic   string
+	payload []byte
+}
+
+// publisher keeps a connection to the MQTT broker at Config.Remote,
+// reconnecting with backoff whenever it is lost. Messages are queued and
+// published in the background, so that waiting for the broker's
+// acknowledgements never holds up the output.
+type publisher struct {
+	*BaseServer
+	queue  chan mqttMessage
+	mu     sync.Mutex
+	client *mqtt.Client // nil while disconnected
+}
+
+func newPublisher(cfg Config) *publisher {
+	if cfg.Reconnect == (Backoff{}) {
+		cfg.Reconnect = DefaultBackoff()
+	}
+	return &publisher{BaseServer: NewBaseServer(cfg), queue: make(chan mqttMessage, mqttQueueLength)}
+}
+
+// connectLoop connects to the broker and publishes the queued messages until
+// ctx is done or the publisher is stopped
+func (p *publisher) connectLoop(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-p.Done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	go p.sendLoop(ctx)
+
+	broker := strings.TrimPrefix(p.Config.Remote, "mqtt://")
+	opts := mqtt.Options{
+		ClientID:  p.Config.MQTT.ClientID,
+		Username:  p.Config.MQTT.Username,
+		Password:  p.Config.MQTT.Password,
+		Version:   p.Config.MQTT.Version,
+		KeepAlive: mqttKeepAlive,
+	}
+
+	delay := p.Config.Reconnect.Initial
+	for {
+		client, err := mqtt.Dial(ctx, broker, opts)
+		if err == nil {
+			p.Config.Logger.Info().Str("broker", broker).Msg("connected to MQTT broker")
+			delay = p.Config.Reconnect.Initial
+			p.serve(ctx, client)
+			if ctx.Err() == nil {
+				p.Config.Logger.Error().Err(client.Err()).Str("broker", broker).Dur("retry", delay).Msg("connection to MQTT broker lost")
+			}
+		} else if ctx.Err() == nil {
+			p.Config.Logger.Error().Err(err).Str("broker", broker).Dur("retry", delay).Msg("failed to connect to MQTT broker")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		if err != nil {
+			delay = p.Config.Reconnect.next(delay)
+		}
+	}
+}
+
+// serve publishes on a connection until it is lost or ctx is done
+func (p *publisher) serve(ctx context.Context, client *mqtt.Client) {
+	p.mu.Lock()
+	p.client = client
+	p.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		client.Close()
+	case <-client.Done():
+	}
+
+	p.mu.Lock()
+	if p.client == client {
+		p.client = nil
+	}
+	p.mu.Unlock()
+}
+
+// publish queues a message for the broker, dropping it when the queue is full
+func (p *publisher) publish(topic string, payload []byte) {
+	select {
+	case p.queue <- mqttMessage{topic: topic, payload: payload}:
+	default:
+		p.Config.Logger.Debug().Str("topic", topic).Msg("MQTT queue full, message dropped")
+	}
+}
+
+// sendLoop publishes the queued messages until ctx is done, dropping them
+// while disconnected. A failed publish closes the connection, which is then
+// reconnected.
+func (p *publisher) sendLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case m := <-p.queue:
+			p.mu.Lock()
+			client := p.client
+			p.mu.Unlock()
+
+			if client == nil {
+				continue
+			}
+			if err := client.Publish(m.topic, m.payload, p.Config.MQTT.QoS, p.Config.MQTT.Retain); err != nil {
+				p.Config.Logger.Error().Err(err).Str("topic", m.topic).Msg("failed to publish message")
+				client.Close()
+			}
+		}
+	}
+}
+
+// Stop closes the connection and stops reconnecting
+func (p *publisher) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	select {
+	case <-p.Done:
+		return nil
+	default:
+		close(p.Done)
+	}
+
+	if p.client != nil {
+		err := p.client.Close()
+		p.client = nil
+		return err
+	}
+	return nil
+}
+
+// MQTTPublisher publishes the NMEA 0183 sentences and the decoded values of
+// own ship to an MQTT broker every update interval
+type MQTTPublisher struct {
+	*publisher
+	self string
+}
+
+// NewMQTTPublisher creates a publisher connecting to the broker at
+// Config.Remote
+func NewMQTTPublisher(cfg Config) *MQTTPublisher {
+	p := newPublisher(cfg)
+	return &MQTTPublisher{publisher: p, self: signalk.Self(p.Config.Fleet.OwnShip().MMSI)}
+}
+
+// Start connects to the broker and publishes in the background until ctx is
+// done or the publisher is stopped, reconnecting whenever the connection is
+// lost
+func (p *MQTTPublisher) Start(ctx context.Context) error {
+	p.Config.Logger.Info().Str("broker", p.Config.Remote).Msg("starting MQTT publisher")
+
+	go p.connectLoop(ctx)
+	go p.publishLoop(ctx)
+	return nil
+}
+
+func (p *MQTTPublisher) publishLoop(ctx context.Context) {
+	snapshots, unsubscribe := p.Config.Snapshots.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.Done:
+			return
+		case snap := <-snapshots:
+			if p.Config.MQTT.SentenceTopic != "" {
+				for _, sentence := range p.generateSentences(snap) {
+					p.publishSentence(sentence)
+				}
+			}
+			if p.Config.MQTT.ValueTopic != "" {
+				p.publishValues(signalk.NewDelta(p.self, p.signalKState(snap)))
+			}
+		}
+	}
+}
+
+// publishSentence publishes a sentence to the topic of its talker and
+// formatter
+func (p *MQTTPublisher) publishSentence(line string) {
+	s, err := util.ParseSentence(line)
+	if err != nil {
+		p.Config.Logger.Error().Err(err).Str("sentence", line).Msg("invalid sentence generated")
+		return
+	}
+	topic := strings.NewReplacer("{talker}", s.Talker, "{formatter}", s.Formatter).Replace(p.Config.MQTT.SentenceTopic)
+	p.publish(topic, []byte(strings.TrimRight(line, "\r\n")))
+}
+
+// publishValues publishes every value of a delta to the topic of its path
+func (p *MQTTPublisher) publishValues(delta signalk.Delta) {
+	for _, u := range delta.Updates {
+		for _, v := range u.Values {
+			payload, err := json.Marshal(mqttValue{Value: v.Value, Source: u.Source.Ref(), Timestamp: u.Timestamp})
+			if err != nil {
+				p.Config.Logger.Error().Err(err).Str("path", v.Path).Msg("failed to encode value")
+				continue
+			}
+			topic := strings.NewReplacer(
+				"{path}", strings.ReplaceAll(v.Path, ".", "/"),
+				"{source}", u.Source.Src,
+			).Replace(p.Config.MQTT.ValueTopic)
+			p.publish(topic, payload)
+		}
+	}
+}
+
+// MQTTPublisher2000 publishes NMEA 2000 messages to an MQTT broker, one
+// message per PGN in the TCP line format
+type MQTTPublisher2000 struct {
+	*publisher
+}
+
+// NewMQTTPublisher2000 creates a NMEA 2000 publisher connecting to the broker
+// at Config.Remote
+func NewMQTTPublisher2000(cfg Config) *MQTTPublisher2000 {
+	return &MQTTPublisher2000{publisher: newPublisher(cfg)}
+}
+
+// Start connects to the broker in the background, reconnecting whenever the
+// connection is lost
+func (p *MQTTPublisher2000) Start(ctx context.Context) error {
+	p.Config.Logger.Info().Str("broker", p.Config.Remote).Msg("starting NMEA 2000 MQTT publisher")
+
+	go p.connectLoop(ctx)
+	return nil
+}
+
+// SendPGN publishes a NMEA 2000 message to the topic of its PGN
+func (p *MQTTPublisher2000) SendPGN(msg pgn.Message) error {
+	if p.Config.MQTT.PGNTopic == "" {
+		return nil
+	}
+	topic := strings.NewReplacer(
+		"{pgn}", strconv.FormatUint(uint64(msg.PGN), 10),
+		"{source}", strconv.Itoa(int(msg.Source)),
+	).Replace(p.Config.MQTT.PGNTopic)
+	p.publish(topic, []byte(strings.TrimRight(formatPGNMessage(msg, p.Config.PGNSource), "\r\n")))
+	return nil
+}
@@ -0,0 +1,161 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/internal/mqtttest"
+	"github.com/captv89/nmea-simulator/pkg/mqtt"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+	"github.com/rs/zerolog"
+)
+
+func TestMQTTPublisher(t *testing.T) {
+	broker, err := mqtttest.NewBroker("127.0.0.1:0", 1000)
+	if err != nil {
+		t.Fatalf("Failed to start broker: %v", err)
+	}
+	defer broker.Close()
+
+	opts := DefaultMQTTOptions()
+	opts.Version = mqtt.V5
+	opts.QoS = 1
+	opts.Retain = true
+	publisher := NewMQTTPublisher(Config{
+		UpdateInterval:  50 * time.Millisecond,
+		Logger:          zerolog.Nop(),
+		Variation:       simulation.FixedVariation(0),
+		SentenceOptions: SentenceOptions{EnablePosition: true},
+		Remote:          "mqtt://" + broker.Addr(),
+		MQTT:            opts,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := publisher.Start(ctx); err != nil {
+		t.Fatalf("Failed to start publisher: %v", err)
+	}
+
+	var sentence, value bool
+	deadline := time.After(2 * time.Second)
+	for !sentence || !value {
+		select {
+		case m := <-broker.Messages():
+			if m.QoS != 1 || !m.Retain {
+				t.Errorf("Expected retained messages at QoS 1, got %+v", m)
+			}
+			switch {
+			case m.Topic == "nmeasim/0183/GP/GGA":
+				if _, err := util.ParseSentence(string(m.Payload)); err != nil {
+					t.Errorf("Expected a valid GGA sentence, got %q: %v", m.Payload, err)
+				}
+				sentence = true
+			case m.Topic == "nmeasim/values/navigation/position":
+				var leaf struct {
+					Value struct {
+						Latitude  float64 `json:"latitude"`
+						Longitude float64 `json:"longitude"`
+					} `json:"value"`
+					Source    string `json:"$source"`
+					Timestamp string `json:"timestamp"`
+				}
+				if err := json.Unmarshal(m.Payload, &leaf); err != nil {
+					t.Fatalf("Invalid value %s: %v", m.Payload, err)
+				}
+				if leaf.Source != "nmeasim.gnss" || leaf.Timestamp == "" || leaf.Value.Latitude == 0 {
+					t.Errorf("Unexpected position %s", m.Payload)
+				}
+				value = true
+			case !strings.HasPrefix(m.Topic, "nmeasim/0183/") && !strings.HasPrefix(m.Topic, "nmeasim/values/"):
+				t.Errorf("Unexpected topic %q", m.Topic)
+			}
+		case <-deadline:
+			t.Fatalf("Expected sentences and values, got sentence %v, value %v", sentence, value)
+		}
+	}
+
+	// The values of an update follow the position
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		if _, ok := broker.Retained("nmeasim/values/navigation/speedOverGround"); ok {
+			break
+		}
+		if time.Since(start) > time.Second {
+			t.Fatal("Expected the speed over ground to be retained")
+		}
+	}
+
+	// Cancelling ctx disconnects from the broker
+	cancel()
+	for start := time.Now(); broker.Clients() > 0; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatal("Expected the publisher to disconnect once ctx is done")
+		}
+	}
+	if err := publisher.Stop(); err != nil {
+		t.Errorf("Expected a clean stop, got %v", err)
+	}
+}
+
+func TestMQTTPublisher2000_Reconnect(t *testing.T) {
+	broker, err := mqtttest.NewBroker("127.0.0.1:0", 100)
+	if err != nil {
+		t.Fatalf("Failed to start broker: %v", err)
+	}
+	defer broker.Close()
+
+	opts := DefaultMQTTOptions()
+	opts.PGNTopic = "n2k/{source}/{pgn}"
+	publisher := NewMQTTPublisher2000(Config{
+		Logger:    zerolog.Nop(),
+		Remote:    broker.Addr(),
+		Reconnect: Backoff{Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond},
+		MQTT:      opts,
+	})
+	if err := publisher.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start publisher: %v", err)
+	}
+	defer publisher.Stop()
+
+	// A fast-packet message is published whole in one line
+	msg := pgn.Message{PGN: 129029, Data: make([]byte, 43), Source: 3}
+	want := strings.TrimRight(formatPGNMessage(msg, false), "\r\n")
+	expect := func() {
+		t.Helper()
+		deadline := time.After(2 * time.Second)
+		for {
+			// Messages are dropped until the publisher is connected
+			publisher.SendPGN(msg)
+			select {
+			case m := <-broker.Messages():
+				if m.Topic != "n2k/3/129029" || string(m.Payload) != want {
+					t.Errorf("Unexpected message %q on %q", m.Payload, m.Topic)
+				}
+				return
+			case <-time.After(20 * time.Millisecond):
+			case <-deadline:
+				t.Fatal("Expected the publisher to connect and publish the message")
+			}
+		}
+	}
+
+	expect()
+	broker.Disconnect()
+	expect()
+}
+
+func TestMQTTPublisher2000_Queue(t *testing.T) {
+	publisher := NewMQTTPublisher2000(Config{Logger: zerolog.Nop(), Remote: "127.0.0.1:1", MQTT: DefaultMQTTOptions()})
+
+	// Sending only queues the message, dropping it once the queue is full
+	msg := pgn.Message{PGN: 127245, Data: make([]byte, 8)}
+	for i := 0; i < 2*mqttQueueLength; i++ {
+		publisher.SendPGN(msg)
+	}
+	if n := len(publisher.queue); n != mqttQueueLength {
+		t.Errorf("Expected %d queued messages, got %d", mqttQueueLength, n)
+	}
+}
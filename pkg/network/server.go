@@ -3,10 +3,22 @@ package network
 
 import (
 	"context"
+	"math"
 	"sync"
 	"time"
 
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/ais"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/autopilot"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/engine"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/environment"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/navigation"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/position"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/radar"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/talker"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/waypoint"
 	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
 	"github.com/rs/zerolog"
 )
 
@@ -20,24 +32,61 @@ type Server interface {
 type NMEA2000Server interface {
 	Server
 	SendPGN(msg pgn.Message) error
+	HandlePGN(handler func(pgn.Message) error)
 }
 
 // Config holds server configuration
 type Config struct {
-	Host            string
-	Port            int
-	UpdateInterval  time.Duration
-	Logger          zerolog.Logger
-	SentenceOptions SentenceOptions
-	BaudRate        int
-	Protocol        string // "nmea0183" or "nmea2000"
+	Host              string
+	Port              int
+	UpdateInterval    time.Duration
+	Logger            zerolog.Logger
+	SentenceOptions   SentenceOptions
+	BaudRate          int
+	Protocol          string                   // "nmea0183" or "nmea2000"
+	PGNSource         bool                     // Append the source address to $PNMEA2K lines
+	Fleet             *simulation.Fleet        // Own ship and targets, defaults to a stationary own ship
+	GNSS              *simulation.GNSSReceiver // GNSS receiver model, defaults to DefaultGNSSConfig
+	RadarConfig       radar.TrackerConfig
+	Radar             *radar.Generator          // ARPA radar shared by the outputs, defaults to tracking with RadarConfig
+	Talkers           talker.Config             // Talker ID overrides, nil keeps the default talkers
+	Sensors           simulation.Sensors        // Sensor instances replacing the default GNSS receiver and heading output
+	Variation         simulation.VariationFunc  // Magnetic variation source, defaults to the World Magnetic Model
+	Deviation         simulation.DeviationCard  // Deviation card of the default compass
+	Wind              *simulation.WindModel     // True wind model, defaults to DefaultWindConfig
+	Depth             *simulation.DepthSounder  // Echo sounder, defaults to a flat 25 m bottom
+	Weather           *simulation.WeatherModel  // Weather model, defaults to DefaultWeatherConfig
+	Attitude          *simulation.AttitudeModel // Wave-driven motion model, defaults to DefaultSeaStateConfig
+	Engines           *simulation.EngineModel   // Own ship's engines, defaults to DefaultEngineConfig
+	Tanks             *simulation.TankModel     // Tank levels, defaults to DefaultTanks
+	Autopilot         *simulation.Autopilot     // Own ship's autopilot and steering reporting the rudder angle, RSA is disabled when nil
+	AutopilotCommands bool                      // Steer the autopilot by APB, RMB and HSC received from clients
+	Waypoints         *simulation.Waypoints     // Store of the waypoints and routes received in WPL and RTE, loading is disabled when nil
+	Echo              bool                      // Send valid sentences received from a client back to it
+	Forward           bool                      // Forward valid sentences received from a client to the other clients
+	Remote            string                    // Remote listener a client connects to, host:port over TCP or a ws:// or wss:// URL
+	Reconnect         Backoff                   // Delays between a client's connection attempts, defaults to DefaultBackoff
+	MQTT              MQTTOptions               // Connection and topics of an MQTT publisher, which connects to the broker at Remote
+	Controls          *Controls                 // Update interval and disabled sentences changed at run time, defaults to UpdateInterval with every sentence enabled
+	Snapshots         *Snapshots                // Samples of the models shared by the outputs, defaults to sampling the models above
+	API               *ControlAPI               // Control API served under /api/ by the WebSocket servers, defaults to one of the models above
 }
 
 // SentenceOptions configures which NMEA sentences to generate
 type SentenceOptions struct {
 	EnablePosition    bool // GGA, GLL
-	EnableNavigation  bool // RMC, HDT, VTG, XTE
-	EnableEnvironment bool // DBT, MTW, MWV, VHW, DPT
+	EnableGNSS        bool // GSA, GSV, GST, GNS, ZDA
+	EnableNavigation  bool // RMC, VTG, XTE, HDT
+	EnableHeading     bool // HDG, HDM, THS, ROT
+	EnableEnvironment bool // DBT, MTW, MWV, VHW, VBW, DPT
+	EnableWind        bool // MWV (true), MWD, VWR, VWT
+	EnableWeather     bool // MDA, XDR
+	EnableAttitude    bool // XDR (pitch, roll, heave), HRM, PRDID
+	EnableEngine      bool // RPM, XDR (engine)
+	EnableTanks       bool // XDR (tank levels)
+	EnableRudder      bool // RSA
+	EnableAIS         bool // VDM, VDO
+	EnableRadar       bool // TTM, TLL, TTD, OSD
 }
 
 // BaseServer provides common functionality for TCP and WebSocket servers
@@ -45,13 +94,348 @@ type BaseServer struct {
 	Config Config
 	Mu     sync.RWMutex
 	Done   chan struct{}
+
+	ais *ais.Generator
+
+	pgnHandler      func(pgn.Message) error
+	sentenceHandler func(util.Sentence) error
+	waypoints       *waypoint.Loader // Loads WPL and RTE into Config.Waypoints, nil when disabled
 }
 
 // NewBaseServer creates a new base server with the given configuration
 func NewBaseServer(cfg Config) *BaseServer {
-	return &BaseServer{
+	if cfg.Fleet == nil {
+		cfg.Fleet = simulation.NewFleet(simulation.DefaultOwnShip())
+	}
+	if cfg.GNSS == nil {
+		cfg.GNSS = simulation.NewGNSSReceiver(simulation.DefaultGNSSConfig())
+	}
+	if cfg.Variation == nil {
+		cfg.Variation = simulation.WMMVariation
+	}
+	if cfg.Wind == nil {
+		cfg.Wind = simulation.NewWindModel(simulation.DefaultWindConfig())
+	}
+	if cfg.Depth == nil {
+		cfg.Depth = simulation.NewDepthSounder(simulation.DefaultDepthConfig(), simulation.FlatBathymetry(25), simulation.TideHeight{})
+	}
+	if cfg.Weather == nil {
+		cfg.Weather = simulation.NewWeatherModel(simulation.DefaultWeatherConfig())
+	}
+	if cfg.Attitude == nil {
+		cfg.Attitude = simulation.NewAttitudeModel(simulation.DefaultSeaStateConfig())
+	}
+	if cfg.Engines == nil {
+		cfg.Engines = simulation.NewEngineModel(simulation.DefaultEngineConfig())
+	}
+	if cfg.Tanks == nil {
+		// The default tanks are always valid
+		cfg.Tanks, _ = simulation.NewTankModel(simulation.DefaultTanks())
+	}
+
+	if cfg.Radar == nil {
+		cfg.Radar = radar.NewGenerator(cfg.RadarConfig)
+	}
+
+	if cfg.Controls == nil {
+		cfg.Controls = NewControls(cfg.UpdateInterval)
+	}
+	if cfg.Snapshots == nil {
+		cfg.Snapshots = NewSnapshots(cfg.Controls, simulation.Sampler{
+			Fleet:    cfg.Fleet,
+			GNSS:     cfg.GNSS,
+			Wind:     cfg.Wind,
+			Depth:    cfg.Depth,
+			Weather:  cfg.Weather,
+			Attitude: cfg.Attitude,
+			Engines:  cfg.Engines,
+			Tanks:    cfg.Tanks,
+		})
+	}
+
+	if cfg.API == nil {
+		cfg.API = cfg.ControlAPI()
+	}
+
+	b := &BaseServer{
 		Config: cfg,
 		Done:   make(chan struct{}),
 		Mu:     sync.RWMutex{},
+		ais:    ais.NewGenerator(),
+	}
+	if cfg.Waypoints != nil {
+		b.waypoints = waypoint.NewLoader(cfg.Waypoints)
+	}
+	return b
+}
+
+// generateSentences returns the enabled sentences of a snapshot, without the
+// sentences disabled by Config.Controls
+func (b *BaseServer) generateSentences(snap simulation.Snapshot) []string {
+	var sentences []string
+	now := snap.Time
+
+	if b.Config.SentenceOptions.EnablePosition || b.Config.SentenceOptions.EnableNavigation || b.Config.SentenceOptions.EnableGNSS {
+		sentences = append(sentences, b.positionSentences(snap)...)
+	}
+
+	if b.Config.SentenceOptions.EnableNavigation {
+		sentences = append(sentences, navigation.GenerateXTE())
+	}
+
+	sentences = append(sentences, b.headingSentences(snap)...)
+	sentences = append(sentences, b.attitudeSentences(snap)...)
+
+	if b.Config.SentenceOptions.EnableEnvironment {
+		own := snap.Own
+		waterAlong, waterAcross := own.WaterSpeedComponents(snap.Current)
+		groundAlong, groundAcross := own.GroundSpeedComponents()
+		sentences = append(sentences, b.depthSentences(snap)...)
+		sentences = append(sentences,
+			environment.GenerateMTW(snap.Weather.WaterTemperature),
+			environment.GenerateVHW(own.Heading, b.variation(snap), own.STW),
+			environment.GenerateVBW(waterAlong, waterAcross, groundAlong, groundAcross),
+		)
+	}
+
+	sentences = append(sentences, b.windSentences(snap)...)
+	sentences = append(sentences, b.weatherSentences(snap)...)
+	sentences = append(sentences, b.engineSentences(snap)...)
+	sentences = append(sentences, b.tankSentences(snap)...)
+	sentences = append(sentences, b.rudderSentences()...)
+
+	if b.Config.SentenceOptions.EnableAIS {
+		sentences = append(sentences, b.ais.Generate(b.Config.Fleet, now)...)
+	}
+
+	if b.Config.SentenceOptions.EnableRadar {
+		sentences = append(sentences, b.Config.Radar.Generate(b.Config.Fleet, now)...)
+	}
+
+	return b.Config.Talkers.Apply(b.Config.Controls.FilterSentences(sentences))
+}
+
+// variation returns the magnetic variation at own ship's position in a
+// snapshot, so that every sentence of the snapshot reports the same value
+func (b *BaseServer) variation(snap simulation.Snapshot) float64 {
+	return b.Config.Variation(snap.Own.Latitude, snap.Own.Longitude, snap.Time)
+}
+
+// positionSentences returns the position, RMC/VTG and GNSS sentences of every
+// GNSS sensor, or of the default receiver when no GNSS sensors are installed
+func (b *BaseServer) positionSentences(snap simulation.Snapshot) []string {
+	variation := b.variation(snap)
+	sensors := b.Config.Sensors.Kind(simulation.SensorGNSS)
+	if len(sensors) == 0 {
+		return b.fixSentences(snap.Fix, "", variation)
+	}
+
+	var sentences []string
+	for _, sensor := range sensors {
+		reading := sensor.Reading()
+		if reading.Status == simulation.ReadingNone {
+			continue
+		}
+		sentences = append(sentences, b.fixSentences(reading.Fix, sensor.Config().Talker, variation)...)
+	}
+	return sentences
+}
+
+// fixSentences returns the enabled sentences for a fix. GSA and GSV keep their
+// per-constellation talkers; the other sentences use talkerID when it is set.
+func (b *BaseServer) fixSentences(fix simulation.GNSSFix, talkerID string, variation float64) []string {
+	var sentences []string
+
+	if b.Config.SentenceOptions.EnablePosition {
+		sentences = append(sentences,
+			talker.Replace(position.GenerateGGA(fix), talkerID),
+			talker.Replace(position.GenerateGLL(fix), talkerID),
+		)
+	}
+
+	if b.Config.SentenceOptions.EnableNavigation {
+		sentences = append(sentences,
+			talker.Replace(navigation.GenerateRMC(fix, variation), talkerID),
+			talker.Replace(navigation.GenerateVTG(fix.COG, fix.SOG, variation), talkerID),
+		)
+	}
+
+	if b.Config.SentenceOptions.EnableGNSS {
+		sentences = append(sentences, position.GenerateGSA(fix)...)
+		sentences = append(sentences, position.GenerateGSV(fix)...)
+		sentences = append(sentences,
+			talker.Replace(position.GenerateGST(fix), talkerID),
+			talker.Replace(position.GenerateGNS(fix), talkerID),
+			talker.Replace(position.GenerateZDA(fix.Time), talkerID),
+		)
+	}
+
+	return sentences
+}
+
+// headingSentences returns the heading sentences of every heading sensor, or
+// of own ship's heading through the default compass when none are installed.
+// HDT (true sensors) and HDG (magnetic sensors) belong to the navigation
+// group; THS, HDM and ROT are added when EnableHeading is set.
+func (b *BaseServer) headingSentences(snap simulation.Snapshot) []string {
+	variation := b.variation(snap)
+	sensors := b.Config.Sensors.Kind(simulation.SensorHeading)
+	if len(sensors) == 0 {
+		own := snap.Own
+		h := simulation.ResolveHeading(own.Heading, variation, b.Config.Deviation)
+
+		var sentences []string
+		if b.Config.SentenceOptions.EnableNavigation {
+			sentences = append(sentences, navigation.GenerateHDT(h.True))
+		}
+		if b.Config.SentenceOptions.EnableHeading {
+			sentences = append(sentences,
+				navigation.GenerateHDG(h.Compass, h.Deviation, h.Variation),
+				navigation.GenerateHDM(h.Magnetic),
+				navigation.GenerateTHS(h.True, navigation.ModeAutonomous),
+				navigation.GenerateROT(own.ROT, true),
+			)
+		}
+		return sentences
+	}
+
+	var sentences []string
+	for _, sensor := range sensors {
+		reading := sensor.Reading()
+		if reading.Status == simulation.ReadingNone {
+			continue
+		}
+
+		cfg := sensor.Config()
+		valid := reading.Status == simulation.ReadingValid
+		h := simulation.ResolveHeading(reading.Vessel.Heading, variation, cfg.Deviation)
+		if !valid {
+			h.True, h.Magnetic, h.Compass = math.NaN(), math.NaN(), math.NaN()
+		}
+
+		var out []string
+		if b.Config.SentenceOptions.EnableNavigation {
+			if cfg.Magnetic {
+				out = append(out, navigation.GenerateHDG(h.Compass, h.Deviation, h.Variation))
+			} else {
+				out = append(out, navigation.GenerateHDT(h.True))
+			}
+		}
+		if b.Config.SentenceOptions.EnableHeading {
+			if cfg.Magnetic {
+				out = append(out, navigation.GenerateHDM(h.Magnetic))
+			} else {
+				out = append(out, navigation.GenerateTHS(h.True, navigation.ModeAutonomous))
+			}
+			out = append(out, navigation.GenerateROT(reading.Vessel.ROT, valid))
+		}
+
+		for _, sentence := range out {
+			sentences = append(sentences, talker.Replace(sentence, cfg.Talker))
+		}
+	}
+	return sentences
+}
+
+// depthSentences returns DBT and DPT from a single sounding, with empty depth
+// fields when the sounder has no bottom
+func (b *BaseServer) depthSentences(snap simulation.Snapshot) []string {
+	sounding := snap.Depth
+	depth := sounding.Depth
+	if !sounding.Valid {
+		depth = math.NaN()
+	}
+
+	return []string{
+		environment.GenerateDBT(depth),
+		environment.GenerateDPT(depth, sounding.Offset, sounding.MaxRange),
+	}
+}
+
+// windSentences returns the apparent wind MWV of the environment group and,
+// when EnableWind is set, the true wind MWV followed by MWD, VWR and VWT
+func (b *BaseServer) windSentences(snap simulation.Snapshot) []string {
+	opts := b.Config.SentenceOptions
+	if !opts.EnableEnvironment && !opts.EnableWind {
+		return nil
+	}
+
+	w := simulation.ResolveWind(snap.Wind, snap.Own)
+
+	var sentences []string
+	if opts.EnableEnvironment {
+		sentences = append(sentences, environment.GenerateMWV(w.ApparentAngle, environment.WindRelative, w.ApparentSpeed))
+	}
+	if opts.EnableWind {
+		sentences = append(sentences,
+			environment.GenerateMWV(w.TrueAngle, environment.WindTrue, w.True.Speed),
+			environment.GenerateMWD(w.True.Direction, b.variation(snap), w.True.Speed),
+			environment.GenerateVWR(w.ApparentAngle, w.ApparentSpeed),
+			environment.GenerateVWT(w.TrueAngle, w.True.Speed),
+		)
+	}
+	return sentences
+}
+
+// weatherSentences returns MDA and the XDR air temperature, pressure and
+// humidity when EnableWeather is set
+func (b *BaseServer) weatherSentences(snap simulation.Snapshot) []string {
+	if !b.Config.SentenceOptions.EnableWeather {
+		return nil
+	}
+
+	w := snap.Weather
+	return []string{
+		environment.GenerateMDA(w, snap.Wind, b.variation(snap)),
+		environment.GenerateXDR(environment.WeatherTransducers(w)...),
+	}
+}
+
+// attitudeSentences returns the XDR pitch, roll and heave, HRM and PRDID when
+// EnableAttitude is set
+func (b *BaseServer) attitudeSentences(snap simulation.Snapshot) []string {
+	if !b.Config.SentenceOptions.EnableAttitude {
+		return nil
+	}
+
+	own, a := snap.Own, snap.Attitude
+	return []string{
+		environment.GenerateXDR(environment.AttitudeTransducers(a)...),
+		navigation.GenerateHRM(a),
+		navigation.GeneratePRDID(a.Pitch, a.Roll, own.Heading),
+	}
+}
+
+// engineSentences returns RPM and the engine XDR sentences of every engine
+// when EnableEngine is set
+func (b *BaseServer) engineSentences(snap simulation.Snapshot) []string {
+	if !b.Config.SentenceOptions.EnableEngine {
+		return nil
+	}
+
+	engines := snap.Engines
+	var sentences []string
+	for _, e := range engines {
+		number := engine.Number(e.Instance, len(engines))
+		sentences = append(sentences, engine.GenerateRPM(number, e.RPM))
+		sentences = append(sentences, engine.GenerateXDR(e, number)...)
+	}
+	return sentences
+}
+
+// tankSentences returns the XDR tank levels when EnableTanks is set
+func (b *BaseServer) tankSentences(snap simulation.Snapshot) []string {
+	if !b.Config.SentenceOptions.EnableTanks {
+		return nil
+	}
+	return environment.GenerateXDRs(environment.TankTransducers(snap.Tanks)...)
+}
+
+// rudderSentences returns RSA with the rudder angle of own ship's steering
+// when EnableRudder is set
+func (b *BaseServer) rudderSentences() []string {
+	if !b.Config.SentenceOptions.EnableRudder || b.Config.Autopilot == nil {
+		return nil
 	}
+	return []string{autopilot.GenerateRSA(b.Config.Autopilot.State().Rudder)}
 }
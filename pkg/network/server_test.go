@@ -2,16 +2,19 @@ package network
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
 )
 
 func TestBaseServer_Config(t *testing.T) {
 	cfg := Config{
-		Host: 		 "localhost",
-		Port: 		 8080,
-		Logger:  zerolog.New(os.Stdout),
+		Host:   "localhost",
+		Port:   8080,
+		Logger: zerolog.New(os.Stdout),
 	}
 	server := NewBaseServer(cfg)
 
@@ -23,3 +26,69 @@ func TestBaseServer_Config(t *testing.T) {
 		t.Errorf("expected port %d, got %d", cfg.Port, server.Config.Port)
 	}
 }
+
+func TestBaseServer_VariationConsistent(t *testing.T) {
+	server := NewBaseServer(Config{
+		SentenceOptions: SentenceOptions{EnableNavigation: true, EnableHeading: true},
+	})
+	snap := server.Config.Snapshots.Sample(time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC))
+
+	fields := map[string][]string{}
+	for _, sentence := range append(server.positionSentences(snap), server.headingSentences(snap)...) {
+		parts := strings.Split(strings.Split(sentence, "*")[0], ",")
+		fields[parts[0][3:]] = parts
+	}
+
+	rmc, hdg := fields["RMC"], fields["HDG"]
+	if rmc == nil || hdg == nil {
+		t.Fatalf("Expected RMC and HDG sentences, got %v", fields)
+	}
+	if rmc[10] != hdg[4] || rmc[11] != hdg[5] {
+		t.Errorf("RMC variation %s,%s differs from HDG variation %s,%s", rmc[10], rmc[11], hdg[4], hdg[5])
+	}
+	if v, _ := strconv.ParseFloat(rmc[10], 64); rmc[11] != "E" || v < 4.5 || v > 6 {
+		t.Errorf("Expected WMM variation of about 5.2 E at own ship's default position, got %s,%s", rmc[10], rmc[11])
+	}
+}
+
+func TestBaseServer_WeatherSentences(t *testing.T) {
+	server := NewBaseServer(Config{})
+	snap := server.Config.Snapshots.Sample(time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC))
+
+	if sentences := server.weatherSentences(snap); sentences != nil {
+		t.Errorf("Expected no weather sentences unless enabled, got %v", sentences)
+	}
+
+	server.Config.SentenceOptions.EnableWeather = true
+	sentences := server.weatherSentences(snap)
+	if len(sentences) != 2 || !strings.HasPrefix(sentences[0], "$IIMDA") || !strings.HasPrefix(sentences[1], "$IIXDR") {
+		t.Fatalf("Expected MDA and XDR sentences, got %v", sentences)
+	}
+
+	mda := strings.Split(sentences[0], ",")
+	xdr := strings.Split(sentences[1], ",")
+	if mda[5] != xdr[2] {
+		t.Errorf("MDA air temperature %s differs from XDR %s", mda[5], xdr[2])
+	}
+}
+
+func TestBaseServer_TankSentences(t *testing.T) {
+	server := NewBaseServer(Config{})
+	now := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+
+	if sentences := server.tankSentences(server.Config.Snapshots.Sample(now)); sentences != nil {
+		t.Errorf("Expected no tank sentences unless enabled, got %v", sentences)
+	}
+
+	server.Config.SentenceOptions.EnableTanks = true
+	sentences := server.tankSentences(server.Config.Snapshots.Sample(now))
+	if len(sentences) != 2 || !strings.HasPrefix(sentences[0], "$IIXDR,V,80.0,P,FUEL#0,") {
+		t.Fatalf("Expected the default tanks in 2 XDR sentences, got %v", sentences)
+	}
+
+	// The engine draws on the fuel tank
+	later := strings.Split(server.tankSentences(server.Config.Snapshots.Sample(now.Add(time.Hour)))[0], ",")
+	if level, err := strconv.ParseFloat(later[2], 64); err != nil || level >= 80 {
+		t.Errorf("Expected the fuel level to fall after an hour under way, got %s", later[2])
+	}
+}
@@ -0,0 +1,225 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/signalk"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+	"github.com/gorilla/websocket"
+)
+
+// SignalKServer serves the simulated state as Signal K deltas over WebSocket
+// and as the full model over HTTP
+type SignalKServer struct {
+	*BaseServer
+	upgrader websocket.Upgrader
+	clients  map[*websocket.Conn]bool
+	clientMu sync.Mutex
+	self     string
+	model    *signalk.Model
+}
+
+// NewSignalKServer creates a Signal K server for own ship
+func NewSignalKServer(cfg Config) *SignalKServer {
+	base := NewBaseServer(cfg)
+	own := base.Config.Fleet.OwnShip()
+	self := signalk.Self(own.MMSI)
+	return &SignalKServer{
+		BaseServer: base,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: func(_ *http.Request) bool {
+				return true // Signal K apps are served from other origins
+			},
+		},
+		clients: make(map[*websocket.Conn]bool),
+		self:    self,
+		model:   signalk.NewModel(self, own.Name, own.MMSI),
+	}
+}
+
+// Handler returns the HTTP handler serving the discovery document, the full
+// model under /signalk/v1/api/ and the delta stream at /signalk/v1/stream
+func (s *SignalKServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /signalk", s.handleDiscovery)
+	mux.HandleFunc("GET /signalk/v1/api/", s.handleAPI)
+	mux.HandleFunc("/signalk/v1/stream", s.handleStream)
+	return mux
+}
+
+// Start begins the Signal K server
+func (s *SignalKServer) Start(ctx context.Context) error {
+	addr := fmt.Sprintf("%s:%d", s.Config.Host, s.Config.Port)
+	server := &http.Server{
+		Addr:    addr,
+		Handler: s.Handler(),
+	}
+
+	s.Config.Logger.Info().Str("addr", addr).Msg("starting Signal K server")
+
+	// Handle server shutdown
+	go func() {
+		<-ctx.Done()
+		s.Config.Logger.Info().Msg("shutting down Signal K server")
+		server.Close()
+	}()
+
+	go s.broadcastLoop(ctx)
+
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Stop closes all client connections and stops the server
+func (s *SignalKServer) Stop() error {
+	s.clientMu.Lock()
+	defer s.clientMu.Unlock()
+
+	select {
+	case <-s.Done:
+		return nil
+	default:
+		close(s.Done)
+	}
+
+	for client := range s.clients {
+		client.Close()
+		delete(s.clients, client)
+	}
+	return nil
+}
+
+func (s *SignalKServer) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	discovery := map[string]interface{}{
+		"endpoints": map[string]interface{}{
+			"v1": map[string]string{
+				"version":      signalk.Version,
+				"signalk-http": "http://" + r.Host + "/signalk/v1/api/",
+				"signalk-ws":   "ws://" + r.Host + "/signalk/v1/stream",
+			},
+		},
+		"server": map[string]string{"id": signalk.Label, "version": signalk.Version},
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(discovery); err != nil {
+		s.Config.Logger.Error().Err(err).Msg("failed to encode Signal K discovery")
+	}
+}
+
+func (s *SignalKServer) handleAPI(w http.ResponseWriter, r *http.Request) {
+	var path []string
+	if rest := strings.Trim(strings.TrimPrefix(r.URL.Path, "/signalk/v1/api"), "/"); rest != "" {
+		path = strings.Split(rest, "/")
+	}
+
+	data, ok := s.model.JSON(path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// handleStream sends the hello message and then the deltas of every update
+// interval, unless the client subscribes to none
+func (s *SignalKServer) handleStream(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		s.Config.Logger.Error().Err(err).Msg("websocket upgrade failed")
+		return
+	}
+
+	s.clientMu.Lock()
+	err = conn.WriteJSON(signalk.NewHello(s.self, time.Now()))
+	if err == nil && r.URL.Query().Get("subscribe") != "none" {
+		s.clients[conn] = true
+	}
+	s.clientMu.Unlock()
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	s.Config.Logger.Info().Str("remote", conn.RemoteAddr().String()).Msg("new Signal K client connected")
+
+	// Read until the client disconnects, ignoring its messages
+	go func() {
+		defer func() {
+			s.clientMu.Lock()
+			delete(s.clients, conn)
+			s.clientMu.Unlock()
+			conn.Close()
+			s.Config.Logger.Info().Str("remote", conn.RemoteAddr().String()).Msg("Signal K client disconnected")
+		}()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func (s *SignalKServer) broadcastLoop(ctx context.Context) {
+	snapshots, unsubscribe := s.Config.Snapshots.Subscribe()
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.Done:
+			return
+		case snap := <-snapshots:
+			delta := signalk.NewDelta(s.self, s.signalKState(snap))
+			s.model.Apply(delta)
+			s.broadcast(delta)
+		}
+	}
+}
+
+func (s *SignalKServer) broadcast(delta signalk.Delta) {
+	s.clientMu.Lock()
+	defer s.clientMu.Unlock()
+
+	for client := range s.clients {
+		if err := client.WriteJSON(delta); err != nil {
+			s.Config.Logger.Error().Err(err).
+				Str("remote", client.RemoteAddr().String()).
+				Msg("failed to send delta")
+			client.Close()
+			delete(s.clients, client)
+		}
+	}
+}
+
+// signalKState returns own ship's state in a snapshot for a delta, with the
+// rudder when an autopilot steers own ship
+func (b *BaseServer) signalKState(snap simulation.Snapshot) signalk.State {
+	own := snap.Own
+	state := signalk.State{
+		Time:    snap.Time,
+		Fix:     snap.Fix,
+		Heading: simulation.ResolveHeading(own.Heading, b.variation(snap), b.Config.Deviation),
+		Vessel:  own,
+		Depth:   snap.Depth,
+		Wind:    simulation.ResolveWind(snap.Wind, own),
+		Weather: snap.Weather,
+		Engines: snap.Engines,
+		Tanks:   snap.Tanks,
+	}
+	if b.Config.Autopilot != nil {
+		pilot := b.Config.Autopilot.State()
+		state.Autopilot = &pilot
+	}
+	return state
+}
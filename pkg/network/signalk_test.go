@@ -0,0 +1,101 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/signalk"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+)
+
+func TestSignalKServer(t *testing.T) {
+	server := NewSignalKServer(Config{
+		UpdateInterval: 20 * time.Millisecond,
+		Logger:         zerolog.Nop(),
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go server.broadcastLoop(ctx)
+	defer server.Stop()
+
+	s := httptest.NewServer(server.Handler())
+	defer s.Close()
+	wsURL := "ws" + strings.TrimPrefix(s.URL, "http") + "/signalk/v1/stream"
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect to the stream: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var hello signalk.Hello
+	if err := conn.ReadJSON(&hello); err != nil || hello.Self != "vessels.urn:mrn:imo:mmsi:244000001" || hello.Version != signalk.Version {
+		t.Fatalf("Unexpected hello %+v: %v", hello, err)
+	}
+
+	var delta struct {
+		Context string `json:"context"`
+		Updates []struct {
+			Source signalk.Source `json:"source"`
+			Values []struct {
+				Path  string          `json:"path"`
+				Value json.RawMessage `json:"value"`
+			} `json:"values"`
+		} `json:"updates"`
+	}
+	if err := conn.ReadJSON(&delta); err != nil || delta.Context != hello.Self {
+		t.Fatalf("Unexpected delta %+v: %v", delta, err)
+	}
+	paths := make(map[string]bool)
+	for _, u := range delta.Updates {
+		for _, v := range u.Values {
+			paths[v.Path] = true
+		}
+	}
+	for _, path := range []string{"navigation.position", "navigation.courseOverGroundTrue", "environment.depth.belowTransducer", "environment.wind.speedApparent", "propulsion.main.revolutions"} {
+		if !paths[path] {
+			t.Errorf("Expected %s in the delta", path)
+		}
+	}
+
+	resp, err := http.Get(s.URL + "/signalk/v1/api/vessels/self/navigation/speedOverGround")
+	if err != nil {
+		t.Fatalf("Failed to get speed over ground: %v", err)
+	}
+	defer resp.Body.Close()
+	var sog struct {
+		Value  float64 `json:"value"`
+		Source string  `json:"$source"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&sog); err != nil || sog.Source != "nmeasim.gnss" {
+		t.Errorf("Unexpected speed over ground %+v: %v", sog, err)
+	}
+
+	resp, err = http.Get(s.URL + "/signalk/v1/api/vessels/self/unknown")
+	if err != nil {
+		t.Fatalf("Failed to get unknown path: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unknown path, got %v", resp.Status)
+	}
+
+	resp, err = http.Get(s.URL + "/signalk")
+	if err != nil {
+		t.Fatalf("Failed to get discovery: %v", err)
+	}
+	defer resp.Body.Close()
+	var discovery struct {
+		Endpoints map[string]map[string]string `json:"endpoints"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil || !strings.HasSuffix(discovery.Endpoints["v1"]["signalk-ws"], "/signalk/v1/stream") {
+		t.Errorf("Unexpected discovery %+v: %v", discovery, err)
+	}
+}
@@ -0,0 +1,88 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// Snapshots samples the simulation once every update interval and delivers
+// the same snapshot to every subscribed output, so that the outputs of both
+// protocols agree on the noisy values of an instant. It samples only while
+// there are subscribers.
+type Snapshots struct {
+	controls *Controls
+	sampler  simulation.Sampler
+
+	mu   sync.Mutex
+	subs map[chan simulation.Snapshot]bool
+	stop chan struct{} // Closed when the last subscriber leaves
+}
+
+// NewSnapshots creates snapshots of the sampler's models at the update
+// interval of controls
+func NewSnapshots(controls *Controls, sampler simulation.Sampler) *Snapshots {
+	return &Snapshots{
+		controls: controls,
+		sampler:  sampler,
+		subs:     make(map[chan simulation.Snapshot]bool),
+	}
+}
+
+// Subscribe returns a channel delivering a snapshot every update interval and
+// a function ending the subscription. Snapshots are dropped for slow
+// subscribers, as ticks are.
+func (s *Snapshots) Subscribe() (<-chan simulation.Snapshot, func()) {
+	ch := make(chan simulation.Snapshot, 1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.subs) == 0 {
+		s.stop = make(chan struct{})
+		go s.run(s.stop)
+	}
+	s.subs[ch] = true
+
+	return ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if !s.subs[ch] {
+			return
+		}
+		delete(s.subs, ch)
+		if len(s.subs) == 0 {
+			close(s.stop)
+		}
+	}
+}
+
+// Sample samples the models at time now without delivering the snapshot
+func (s *Snapshots) Sample(now time.Time) simulation.Snapshot {
+	return s.sampler.Sample(now)
+}
+
+func (s *Snapshots) run(stop chan struct{}) {
+	ticker := s.controls.NewTicker()
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			snap := s.Sample(now)
+
+			s.mu.Lock()
+			if s.stop == stop {
+				for ch := range s.subs {
+					select {
+					case ch <- snap:
+					default:
+					}
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+func TestSnapshotsShared(t *testing.T) {
+	snapshots := NewSnapshots(NewControls(MinInterval), simulation.Sampler{
+		Fleet:   simulation.NewFleet(simulation.DefaultOwnShip()),
+		GNSS:    simulation.NewGNSSReceiver(simulation.DefaultGNSSConfig()),
+		Wind:    simulation.NewWindModel(simulation.DefaultWindConfig()),
+		Engines: simulation.NewEngineModel(simulation.DefaultEngineConfig()),
+	})
+
+	first, unsubscribeFirst := snapshots.Subscribe()
+	second, unsubscribeSecond := snapshots.Subscribe()
+	defer unsubscribeSecond()
+
+	receive := func(ch <-chan simulation.Snapshot) simulation.Snapshot {
+		t.Helper()
+		select {
+		case snap := <-ch:
+			return snap
+		case <-time.After(time.Second):
+			t.Fatal("Expected a snapshot every update interval")
+			return simulation.Snapshot{}
+		}
+	}
+
+	// Every output sees the same noisy values of an update
+	a, b := receive(first), receive(second)
+	if !a.Time.Equal(b.Time) || a.Fix.Latitude != b.Fix.Latitude || a.Wind != b.Wind || a.Engines[0].RPM != b.Engines[0].RPM {
+		t.Errorf("Expected the same snapshot for both subscribers, got %+v and %+v", a, b)
+	}
+
+	// Sampling continues while a subscriber remains
+	unsubscribeFirst()
+	unsubscribeFirst()
+	receive(second)
+}
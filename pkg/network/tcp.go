@@ -4,12 +4,11 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"sort"
 	"strings"
-	"time"
 
-	"github.com/captv89/nmea-simulator/pkg/nmea0183/environment"
-	"github.com/captv89/nmea-simulator/pkg/nmea0183/navigation"
-	"github.com/captv89/nmea-simulator/pkg/nmea0183/position"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/ais"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/talker"
 )
 
 // TCPServer implements NMEA sentence streaming over TCP
@@ -17,6 +16,7 @@ type TCPServer struct {
 	*BaseServer
 	listener net.Listener
 	clients  map[net.Conn]bool
+	budget   byteBudget
 }
 
 // NewTCPServer creates a new TCP server instance
@@ -62,6 +62,13 @@ func (s *TCPServer) acceptLoop(ctx context.Context) {
 					Str("remote", conn.RemoteAddr().String()).
 					Msg("new TCP client connected")
 
+				// Handle the sentences the client sends
+				go readLines(conn, func(line string) {
+					if s.handleSentence(line) {
+						s.relay(conn, line)
+					}
+				})
+
 				// Monitor connection for closure
 				go func(conn net.Conn) {
 					select {
@@ -79,11 +86,8 @@ func (s *TCPServer) acceptLoop(ctx context.Context) {
 }
 
 func (s *TCPServer) broadcastLoop(ctx context.Context) {
-	ticker := time.NewTicker(s.Config.UpdateInterval)
-	defer ticker.Stop()
-
-	// Calculate bytes per interval based on baud rate
-	bytesPerInterval := int(float64(s.Config.BaudRate) * s.Config.UpdateInterval.Seconds() / 8)
+	snapshots, unsubscribe := s.Config.Snapshots.Subscribe()
+	defer unsubscribe()
 
 	for {
 		select {
@@ -91,28 +95,31 @@ func (s *TCPServer) broadcastLoop(ctx context.Context) {
 			return
 		case <-s.Done:
 			return
-		case <-ticker.C:
-			sentences := s.generateSentences()
+		case snap := <-snapshots:
+			// Calculate bytes per interval based on baud rate
+			bytesPerInterval := int(float64(s.Config.BaudRate) * s.Config.Controls.Interval().Seconds() / 8)
+			sentences := s.generateSentences(snap)
 			s.broadcast(sentences, bytesPerInterval)
 		}
 	}
 }
 
 func (s *TCPServer) broadcast(sentences []string, bytesPerInterval int) {
+	sentences, dropped := s.budget.limit(sentences, bytesPerInterval)
+	if dropped > 0 && !s.budget.warned {
+		s.budget.warned = true
+		s.Config.Logger.Warn().
+			Int("baud", s.Config.BaudRate).
+			Int("dropped", dropped).
+			Msg("output exceeds the baud rate, sending the messages in turn over several intervals")
+	}
+
 	s.Mu.Lock()
 	defer s.Mu.Unlock()
 
-	var totalBytes int
 	for conn := range s.clients {
 		for _, sentence := range sentences {
-			if totalBytes >= bytesPerInterval {
-				return // Respect baud rate limit
-			}
-
-			data := []byte(sentence + "\r\n")
-			totalBytes += len(data)
-
-			_, err := conn.Write(data)
+			_, err := conn.Write([]byte(sentence + "\r\n"))
 			if err != nil {
 				s.Config.Logger.Error().
 					Err(err).
@@ -126,36 +133,120 @@ func (s *TCPServer) broadcast(sentences []string, bytesPerInterval int) {
 	}
 }
 
-func (s *TCPServer) generateSentences() []string {
-	var sentences []string
+// byteBudget limits the output of a serial line to the bytes its baud rate
+// carries in an interval. It drops whole messages, never a part of a
+// multi-sentence message, and sends first the messages left out longest, so
+// that every message is sent in turn. AIS reports are not repeated every
+// interval, so a dropped report is kept until it is sent or replaced by the
+// next report of the same vessel and type.
+type byteBudget struct {
+	interval int            // Number of intervals limited
+	sent     map[string]int // Interval each message was last sent in
+	pending  []budgetMessage
+	warned   bool // Set once the output has exceeded the budget
+}
+
+// budgetMessage is a message of one or more sentences with the key telling it
+// apart from the other messages of an interval
+type budgetMessage struct {
+	key       string
+	sentences []string
+	report    bool // An AIS report
+}
+
+// limit returns the sentences of the messages fitting into limit bytes with
+// their CR LF terminators, in their original order after the AIS reports left
+// over from earlier intervals, and the number of messages dropped. A message
+// larger than limit is sent on its own when its turn comes.
+func (b *byteBudget) limit(sentences []string, limit int) ([]string, int) {
+	if b.sent == nil {
+		b.sent = make(map[string]int)
+	}
+	b.interval++
+
+	// A message is known by its address and its place among the messages of
+	// the same address, e.g. the second $GPGSV message, an AIS report by its
+	// type and MMSI
+	var current []budgetMessage
+	keys := make(map[string]bool)
+	seen := make(map[string]int)
+	for _, group := range talker.Groups(sentences) {
+		address, _, _ := strings.Cut(group[0], ",")
+		m := budgetMessage{key: fmt.Sprintf("%s#%d", address, seen[address]), sentences: group}
+		if report, ok := ais.ReportKey(group[0]); ok {
+			m.key, m.report = address+"#"+report, true
+		} else {
+			seen[address]++
+		}
+		current = append(current, m)
+		keys[m.key] = true
+	}
+
+	var messages []budgetMessage
+	for _, m := range b.pending {
+		if !keys[m.key] {
+			messages = append(messages, m)
+		}
+	}
+	messages = append(messages, current...)
 
-	if s.Config.SentenceOptions.EnablePosition {
-		sentences = append(sentences,
-			position.GenerateGGA(),
-			position.GenerateGLL(),
-		)
+	order := make([]int, len(messages))
+	for i := range order {
+		order[i] = i
 	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return b.sent[messages[order[i]].key] < b.sent[messages[order[j]].key]
+	})
 
-	if s.Config.SentenceOptions.EnableNavigation {
-		sentences = append(sentences,
-			navigation.GenerateRMC(),
-			navigation.GenerateHDT(),
-			navigation.GenerateVTG(),
-			navigation.GenerateXTE(),
-		)
+	send := make([]bool, len(messages))
+	var total, sent int
+	for _, i := range order {
+		size := 0
+		for _, sentence := range messages[i].sentences {
+			size += len(sentence) + 2
+		}
+		if total > 0 && total+size > limit {
+			continue
+		}
+		total += size
+		send[i] = true
+		b.sent[messages[i].key] = b.interval
+		sent++
 	}
 
-	if s.Config.SentenceOptions.EnableEnvironment {
-		sentences = append(sentences,
-			environment.GenerateDBT(),
-			environment.GenerateMTW(),
-			environment.GenerateMWV(),
-			environment.GenerateVHW(),
-			environment.GenerateDPT(),
-		)
+	b.pending = b.pending[:0]
+	out := make([]string, 0, len(sentences))
+	for i, m := range messages {
+		switch {
+		case send[i]:
+			out = append(out, m.sentences...)
+		case m.report:
+			b.pending = append(b.pending, m)
+		}
 	}
+	return out, len(messages) - sent
+}
 
-	return sentences
+// relay echoes or forwards a sentence received from a client
+func (s *TCPServer) relay(sender net.Conn, sentence string) {
+	if !s.Config.Echo && !s.Config.Forward {
+		return
+	}
+
+	s.Mu.Lock()
+	defer s.Mu.Unlock()
+
+	data := []byte(sentence + "\r\n")
+	for _, conn := range relayed(s.Config, sender, s.clients) {
+		if _, err := conn.Write(data); err != nil {
+			s.Config.Logger.Error().
+				Err(err).
+				Str("remote", conn.RemoteAddr().String()).
+				Msg("failed to relay message")
+			conn.Close()
+			delete(s.clients, conn)
+		}
+	}
 }
 
 // Stop closes all client connections and stops the server
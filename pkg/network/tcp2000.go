@@ -2,8 +2,11 @@ package network
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"net"
+	"strconv"
+	"strings"
 
 	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
 )
@@ -60,7 +63,7 @@ func (s *TCP2000Server) Stop() error {
 
 // SendPGN sends a NMEA 2000 message to all connected clients
 func (s *TCP2000Server) SendPGN(msg pgn.Message) error {
-	frame := formatPGNMessage(msg)
+	frame := strings.Join(formatPGNFrames(msg, s.Config.PGNSource), "")
 	var failedClients []net.Conn
 
 	// Read lock for iterating
@@ -105,15 +108,37 @@ func (s *TCP2000Server) acceptLoop(ctx context.Context) {
 			s.Config.Logger.Info().
 				Str("remote", conn.RemoteAddr().String()).
 				Msg("New NMEA 2000 client connected")
+
+			// Pass the messages the client sends to the handler
+			go func(conn net.Conn) {
+				assembler := pgn.NewAssembler()
+				readLines(conn, func(line string) { s.handlePGNLine(assembler, line) })
+			}(conn)
 		}
 	}
 }
 
+// formatPGNFrames formats every CAN frame of a NMEA 2000 message, emitting
+// fast-packet PGNs as one line per 8-byte frame
+func formatPGNFrames(msg pgn.Message, withSource bool) []string {
+	frames := msg.Frames()
+	lines := make([]string, 0, len(frames))
+	for _, frame := range frames {
+		lines = append(lines, formatPGNMessage(pgn.Message{PGN: msg.PGN, Data: frame, Source: msg.Source}, withSource))
+	}
+	return lines
+}
+
 // formatPGNMessage formats a NMEA 2000 message for TCP transport
-// Format: $PNMEA2K,PGN,Length,Data*Checksum
-func formatPGNMessage(msg pgn.Message) string {
+// Format: $PNMEA2K,PGN,Length,Data*Checksum, or with withSource set
+// $PNMEA2K,PGN,Length,Data,Source*Checksum
+func formatPGNMessage(msg pgn.Message, withSource bool) string {
 	var checksum byte
 	data := fmt.Sprintf("$PNMEA2K,%d,%d,", msg.PGN, len(msg.Data))
+	var source string
+	if withSource {
+		source = fmt.Sprintf(",%d", msg.Source)
+	}
 
 	// Calculate checksum (XOR of all bytes after $ and before *)
 	for i := 1; i < len(data); i++ {
@@ -122,6 +147,50 @@ func formatPGNMessage(msg pgn.Message) string {
 	for _, b := range msg.Data {
 		checksum ^= b
 	}
+	for i := 0; i < len(source); i++ {
+		checksum ^= source[i]
+	}
+
+	return fmt.Sprintf("%s%X%s*%02X\r\n", data, msg.Data, source, checksum)
+}
+
+// parsePGNMessage parses a NMEA 2000 frame received in the TCP transport
+// format, with or without the source address, verifying its checksum
+func parsePGNMessage(line string) (pgn.Message, error) {
+	body, checksum, ok := strings.Cut(strings.TrimSpace(line), "*")
+	if !ok || !strings.HasPrefix(body, "$PNMEA2K,") {
+		return pgn.Message{}, fmt.Errorf("not a $PNMEA2K frame: %q", line)
+	}
+
+	fields := strings.Split(body, ",")
+	if len(fields) != 4 && len(fields) != 5 {
+		return pgn.Message{}, fmt.Errorf("expected 4 or 5 fields, got %d", len(fields))
+	}
+	number, err := strconv.ParseUint(fields[1], 10, 32)
+	if err != nil {
+		return pgn.Message{}, fmt.Errorf("invalid PGN %q", fields[1])
+	}
+	length, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return pgn.Message{}, fmt.Errorf("invalid length %q", fields[2])
+	}
+	data, err := hex.DecodeString(fields[3])
+	if err != nil || len(data) != length {
+		return pgn.Message{}, fmt.Errorf("invalid data %q for length %d", fields[3], length)
+	}
 
-	return fmt.Sprintf("%s%X*%02X\r\n", data, msg.Data, checksum)
+	msg := pgn.Message{PGN: uint32(number), Data: data}
+	withSource := len(fields) == 5
+	if withSource {
+		source, err := strconv.ParseUint(fields[4], 10, 8)
+		if err != nil {
+			return pgn.Message{}, fmt.Errorf("invalid source %q", fields[4])
+		}
+		msg.Source = uint8(source)
+	}
+
+	if want := formatPGNMessage(msg, withSource); !strings.EqualFold(want[len(want)-4:len(want)-2], checksum) {
+		return pgn.Message{}, fmt.Errorf("checksum mismatch: got %s, expected %s", checksum, want[len(want)-4:len(want)-2])
+	}
+	return msg, nil
 }
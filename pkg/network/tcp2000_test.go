@@ -0,0 +1,70 @@
+package network
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+)
+
+func TestFormatPGNFrames(t *testing.T) {
+	single := formatPGNFrames(pgn.Message{PGN: 128267, Data: make([]byte, 8)}, false)
+	if len(single) != 1 {
+		t.Fatalf("Expected 1 line for single-frame PGN, got %d", len(single))
+	}
+	if !strings.HasPrefix(single[0], "$PNMEA2K,128267,8,") {
+		t.Errorf("Unexpected single-frame line: %s", single[0])
+	}
+
+	fast := formatPGNFrames(pgn.Message{PGN: 129794, Data: make([]byte, 75)}, false)
+	if len(fast) != 11 {
+		t.Fatalf("Expected 11 lines for a 75 byte fast-packet, got %d", len(fast))
+	}
+	for _, line := range fast {
+		if !strings.HasPrefix(line, "$PNMEA2K,129794,8,") || !strings.HasSuffix(line, "\r\n") {
+			t.Errorf("Unexpected fast-packet line: %q", line)
+		}
+	}
+}
+
+func TestFormatPGNMessageSource(t *testing.T) {
+	msg := pgn.Message{PGN: 127250, Data: []byte{1, 2, 3, 4, 5, 6, 7, 8}, Source: 35}
+	if line := formatPGNMessage(msg, false); !strings.HasPrefix(line, "$PNMEA2K,127250,8,0102030405060708*") {
+		t.Errorf("Unexpected line without source address: %q", line)
+	}
+	if line := formatPGNMessage(msg, true); !strings.HasPrefix(line, "$PNMEA2K,127250,8,0102030405060708,35*") {
+		t.Errorf("Unexpected line with source address: %q", line)
+	}
+}
+
+func TestParsePGNMessage(t *testing.T) {
+	want := pgn.Message{PGN: 127245, Data: []byte{0, 0xF8, 0x10, 0x27, 0xFF, 0x7F, 0xFF, 0xFF}, Source: 12}
+	line := formatPGNMessage(want, true)
+
+	msg, err := parsePGNMessage(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.PGN != want.PGN || msg.Source != want.Source || string(msg.Data) != string(want.Data) {
+		t.Errorf("Expected %+v, got %+v", want, msg)
+	}
+
+	// Lines without the source address are accepted from source 0
+	msg, err = parsePGNMessage(formatPGNMessage(want, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.PGN != want.PGN || msg.Source != 0 || string(msg.Data) != string(want.Data) {
+		t.Errorf("Expected %+v from source 0, got %+v", want, msg)
+	}
+
+	for _, bad := range []string{
+		strings.Replace(line, ",12*", ",13*", 1),
+		"$PNMEA2K,127245,9,00F81027FF7FFFFF,12*00",
+		"$GPHDT,90.0,T*0C",
+	} {
+		if _, err := parsePGNMessage(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
@@ -3,9 +3,12 @@ package network
 import (
 	"io"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/talker"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
 	"github.com/rs/zerolog"
 )
 
@@ -114,6 +117,52 @@ func TestNewTCPServer(t *testing.T) {
 	}
 }
 
+func TestTCPServerBroadcast_WholeMessages(t *testing.T) {
+	server := NewTCPServer(Config{Logger: zerolog.Nop(), BaudRate: 4800})
+	conn := newMockConn()
+	server.clients[conn] = true
+
+	sentences := []string{
+		util.AppendChecksum("$GPGGA,120000.00,5400.0000,N,01000.0000,E,1,08,0.9,5.0,M,40.0,M,,"),
+		util.AppendChecksum("$GPGSV,3,1,09,01,40,083,46,02,17,308,41,03,22,120,40,04,61,045,47"),
+		util.AppendChecksum("$GPGSV,3,2,09,05,40,083,46,06,17,308,41,07,22,120,40,08,61,045,47"),
+		util.AppendChecksum("$GPGSV,3,3,09,09,40,083,46"),
+		util.AppendChecksum("$GPXTE,A,A,0.00,L,N,A"),
+		util.AppendChecksum("!AIVDM,2,1,3,A,55NBsv02>tk0T4p00000000000000000000000000000000000000,0"),
+		util.AppendChecksum("!AIVDM,2,2,3,A,88888888880,2"),
+		util.AppendChecksum("!AIVDO,1,1,,A,13u?etPv2;0n:dDPwUM1U1Cb069D,0"),
+	}
+
+	// A budget of two messages sends every message in turn, each one whole
+	sent := map[string]bool{}
+	for interval := 0; interval < 4; interval++ {
+		server.broadcast(sentences, 300)
+
+		var out []string
+		for len(conn.writeData) > 0 {
+			out = append(out, strings.TrimSuffix(string(<-conn.writeData), "\r\n"))
+		}
+		joined := strings.Join(out, "\n")
+		for _, group := range talker.Groups(sentences) {
+			partial := false
+			for _, sentence := range group {
+				partial = partial || strings.Contains(joined, sentence)
+			}
+			if partial && !strings.Contains(joined, strings.Join(group, "\n")) {
+				t.Errorf("Interval %d: expected whole messages, got part of %v in %v", interval, group, out)
+			}
+		}
+		for _, sentence := range out {
+			sent[sentence] = true
+		}
+	}
+	for _, sentence := range sentences {
+		if !sent[sentence] {
+			t.Errorf("Expected %s to be sent in turn", sentence)
+		}
+	}
+}
+
 // func TestTCPServerStartStop(t *testing.T) {
 // 	cfg := Config{
 // 		Host:           "localhost",
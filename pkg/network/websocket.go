@@ -1,6 +1,7 @@
 package network
 
 import (
+	"bytes"
 	"context"
 	"embed"
 	"fmt"
@@ -10,9 +11,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/captv89/nmea-simulator/pkg/nmea0183/environment"
-	"github.com/captv89/nmea-simulator/pkg/nmea0183/navigation"
-	"github.com/captv89/nmea-simulator/pkg/nmea0183/position"
 	"github.com/gorilla/websocket"
 )
 
@@ -103,6 +101,9 @@ func (s *WebSocketServer) Start(ctx context.Context) error {
 	// Handle WebSocket path
 	mux.HandleFunc("/ws", s.handleWebSocket)
 
+	// Handle control API for the shared simulation state
+	mux.Handle("/api/", s.controlAPI().Handler())
+
 	// Create server with logging middleware
 	handler := s.loggingMiddleware(mux)
 	server := &http.Server{
@@ -155,14 +156,19 @@ func (s *WebSocketServer) handleWebSocket(w http.ResponseWriter, r *http.Request
 		}()
 
 		for {
-			// Read messages from client (if any)
-			_, _, err := conn.ReadMessage()
+			// Read messages from client (if any) and handle their sentences
+			_, data, err := conn.ReadMessage()
 			if err != nil {
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 					s.Config.Logger.Error().Err(err).Str("remote", conn.RemoteAddr().String()).Msg("websocket error")
 				}
 				return
 			}
+			readLines(bytes.NewReader(data), func(line string) {
+				if s.handleSentence(line) {
+					s.relay(conn, line)
+				}
+			})
 		}
 	}()
 
@@ -189,9 +195,8 @@ func (s *WebSocketServer) Stop() error {
 }
 
 func (s *WebSocketServer) broadcastLoop(ctx context.Context) {
-	ticker := s.Config.UpdateInterval
-	t := time.NewTicker(ticker)
-	defer t.Stop()
+	snapshots, unsubscribe := s.Config.Snapshots.Subscribe()
+	defer unsubscribe()
 
 	for {
 		select {
@@ -199,43 +204,31 @@ func (s *WebSocketServer) broadcastLoop(ctx context.Context) {
 			return
 		case <-s.Done:
 			return
-		case <-t.C:
-			sentences := s.generateSentences()
+		case snap := <-snapshots:
+			sentences := s.generateSentences(snap)
 			s.broadcast(sentences)
 		}
 	}
 }
 
-func (s *WebSocketServer) generateSentences() []string {
-	var sentences []string
-
-	if s.Config.SentenceOptions.EnablePosition {
-		sentences = append(sentences,
-			position.GenerateGGA(),
-			position.GenerateGLL(),
-		)
+// relay echoes or forwards a sentence received from a client
+func (s *WebSocketServer) relay(sender *websocket.Conn, sentence string) {
+	if !s.Config.Echo && !s.Config.Forward {
+		return
 	}
 
-	if s.Config.SentenceOptions.EnableNavigation {
-		sentences = append(sentences,
-			navigation.GenerateRMC(),
-			navigation.GenerateHDT(),
-			navigation.GenerateVTG(),
-			navigation.GenerateXTE(),
-		)
-	}
+	s.clientMu.Lock()
+	defer s.clientMu.Unlock()
 
-	if s.Config.SentenceOptions.EnableEnvironment {
-		sentences = append(sentences,
-			environment.GenerateDBT(),
-			environment.GenerateMTW(),
-			environment.GenerateMWV(),
-			environment.GenerateVHW(),
-			environment.GenerateDPT(),
-		)
+	for _, client := range relayed(s.Config, sender, s.clients) {
+		if err := client.WriteMessage(websocket.TextMessage, []byte(sentence)); err != nil {
+			s.Config.Logger.Error().Err(err).
+				Str("remote", client.RemoteAddr().String()).
+				Msg("failed to relay message")
+			client.Close()
+			delete(s.clients, client)
+		}
 	}
-
-	return sentences
 }
 
 func (s *WebSocketServer) broadcast(sentences []string) {
@@ -2,6 +2,7 @@
 package network
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/fs"
@@ -77,6 +78,9 @@ func (s *WebSocket2000Server) Start(ctx context.Context) error {
 	// Handle WebSocket path for NMEA 2000
 	mux.HandleFunc("/nmea2000", s.handleWebSocket)
 
+	// Handle control API for the shared simulation state
+	mux.Handle("/api/", s.controlAPI().Handler())
+
 	// Create server with logging middleware
 	handler := s.loggingMiddleware(mux)
 	server := &http.Server{
@@ -113,18 +117,21 @@ func (s *WebSocket2000Server) SendPGN(msg pgn.Message) error {
 	s.clientMu.Lock()
 	defer s.clientMu.Unlock()
 
-	frame := formatPGNMessage(msg)
+	frames := formatPGNFrames(msg, s.Config.PGNSource)
 
 	for client := range s.clients {
-		err := client.WriteMessage(websocket.TextMessage, []byte(frame))
-		if err != nil {
-			s.Config.Logger.Error().
-				Err(err).
-				Str("remote", client.RemoteAddr().String()).
-				Msg("Failed to send message")
-
-			client.Close()
-			delete(s.clients, client)
+		for _, frame := range frames {
+			err := client.WriteMessage(websocket.TextMessage, []byte(frame))
+			if err != nil {
+				s.Config.Logger.Error().
+					Err(err).
+					Str("remote", client.RemoteAddr().String()).
+					Msg("Failed to send message")
+
+				client.Close()
+				delete(s.clients, client)
+				break
+			}
 		}
 	}
 	return nil
@@ -160,15 +167,17 @@ func (s *WebSocket2000Server) handleWebSocket(w http.ResponseWriter, r *http.Req
 				Msg("NMEA 2000 client disconnected")
 		}()
 
+		assembler := pgn.NewAssembler()
 		for {
-			// Read messages from client (if any)
-			_, _, err := conn.ReadMessage()
+			// Read messages from client (if any), passing their frames to the handler
+			_, data, err := conn.ReadMessage()
 			if err != nil {
 				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 					s.Config.Logger.Error().Err(err).Msg("WebSocket read error")
 				}
 				return
 			}
+			readLines(bytes.NewReader(data), func(line string) { s.handlePGNLine(assembler, line) })
 		}
 	}()
 
@@ -0,0 +1,236 @@
+// Package ais provides AIS message encoding and NMEA-0183 VDM/VDO sentence generation
+package ais
+
+import (
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// Reporting intervals for the simulated AIS stations
+const (
+	ClassAFastInterval = 2 * time.Second
+	ClassAInterval     = 10 * time.Second
+	ClassASlowInterval = 3 * time.Minute
+	ClassBInterval     = 30 * time.Second
+	ClassBSlowInterval = 3 * time.Minute
+	StaticInterval     = 6 * time.Minute
+	AidToNavInterval   = 3 * time.Minute
+)
+
+// ownShipReportChannel is the radio channel reported in own ship VDO sentences
+const ownShipReportChannel = "A"
+
+// Generator produces AIS sentences for a fleet, tracking per-vessel reporting schedules
+type Generator struct {
+	lastPosition map[uint32]time.Time
+	lastStatic   map[uint32]time.Time
+	seqID        int
+	channel      int
+}
+
+// NewGenerator creates a new AIS sentence generator
+func NewGenerator() *Generator {
+	return &Generator{
+		lastPosition: make(map[uint32]time.Time),
+		lastStatic:   make(map[uint32]time.Time),
+	}
+}
+
+// Generate returns the !AIVDO sentences for own ship and !AIVDM sentences for every
+// target whose position or static report is due at the given time
+func (g *Generator) Generate(fleet *simulation.Fleet, now time.Time) []string {
+	var sentences []string
+
+	own := fleet.OwnShip()
+	for _, payload := range g.due(own, now) {
+		sentences = append(sentences, g.encapsulate("VDO", payload, ownShipReportChannel)...)
+	}
+
+	for _, target := range fleet.Targets() {
+		channel := "A"
+		if g.channel%2 == 1 {
+			channel = "B"
+		}
+		g.channel++
+
+		for _, payload := range g.due(target, now) {
+			sentences = append(sentences, g.encapsulate("VDM", payload, channel)...)
+		}
+	}
+
+	return sentences
+}
+
+// Message is an AIS message that can be encoded into an armored payload
+type Message interface {
+	Encode() (payload string, fillBits int)
+}
+
+// due returns the messages for v whose reporting interval has elapsed
+func (g *Generator) due(v simulation.Vessel, now time.Time) []Message {
+	var messages []Message
+
+	if now.Sub(g.lastPosition[v.MMSI]) >= PositionInterval(v) {
+		g.lastPosition[v.MMSI] = now
+		messages = append(messages, PositionMessage(v, now))
+	}
+
+	if v.Class != simulation.AidToNavigation && now.Sub(g.lastStatic[v.MMSI]) >= StaticInterval {
+		g.lastStatic[v.MMSI] = now
+		messages = append(messages, StaticMessages(v, now)...)
+	}
+
+	return messages
+}
+
+func (g *Generator) encapsulate(formatter string, msg Message, channel string) []string {
+	payload, fill := msg.Encode()
+	sentences := Encapsulate(formatter, payload, fill, channel, g.seqID)
+	if len(sentences) > 1 {
+		g.seqID = (g.seqID + 1) % 10
+	}
+	return sentences
+}
+
+// PositionInterval returns the nominal reporting interval for a vessel's position report
+func PositionInterval(v simulation.Vessel) time.Duration {
+	switch v.Class {
+	case simulation.AidToNavigation:
+		return AidToNavInterval
+	case simulation.ClassB:
+		if v.SOG <= 2 {
+			return ClassBSlowInterval
+		}
+		return ClassBInterval
+	default:
+		switch {
+		case v.SOG <= 3 && (v.NavStatus == StatusAtAnchor || v.NavStatus == StatusMoored):
+			return ClassASlowInterval
+		case v.SOG > 23 || v.ROT != 0:
+			return ClassAFastInterval
+		default:
+			return ClassAInterval
+		}
+	}
+}
+
+// PositionReportType returns the message type of a Class A position report:
+// 2 on an assigned schedule, 3 at anchor or moored and 1 otherwise
+func PositionReportType(v simulation.Vessel) uint8 {
+	switch {
+	case v.Assigned:
+		return 2
+	case v.NavStatus == StatusAtAnchor || v.NavStatus == StatusMoored:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// PositionMessage returns the position report appropriate for the vessel class
+func PositionMessage(v simulation.Vessel, now time.Time) Message {
+	switch v.Class {
+	case simulation.AidToNavigation:
+		return AidToNavigationReport{
+			MMSI:        v.MMSI,
+			AidType:     v.AidType,
+			Name:        v.Name,
+			Longitude:   v.Longitude,
+			Latitude:    v.Latitude,
+			ToBow:       v.ToBow,
+			ToStern:     v.ToStern,
+			ToPort:      v.ToPort,
+			ToStarboard: v.ToStarboard,
+			EPFD:        EPFDGPS,
+			Second:      now.UTC().Second(),
+		}
+	case simulation.ClassB:
+		return ClassBPosition{
+			MMSI:      v.MMSI,
+			SOG:       v.SOG,
+			Longitude: v.Longitude,
+			Latitude:  v.Latitude,
+			COG:       v.COG,
+			Heading:   v.Heading,
+			Second:    now.UTC().Second(),
+		}
+	default:
+		return PositionReport{
+			Type:      PositionReportType(v),
+			MMSI:      v.MMSI,
+			NavStatus: v.NavStatus,
+			ROT:       v.ROT,
+			SOG:       v.SOG,
+			Longitude: v.Longitude,
+			Latitude:  v.Latitude,
+			COG:       v.COG,
+			Heading:   v.Heading,
+			Second:    now.UTC().Second(),
+		}
+	}
+}
+
+// StaticMessages returns the static data messages appropriate for the vessel class
+func StaticMessages(v simulation.Vessel, now time.Time) []Message {
+	switch v.Class {
+	case simulation.AidToNavigation:
+		return nil
+	case simulation.ClassB:
+		return []Message{
+			StaticDataReport{
+				MMSI: v.MMSI,
+				Name: v.Name,
+			},
+			StaticDataReport{
+				MMSI:        v.MMSI,
+				PartB:       true,
+				ShipType:    v.ShipType,
+				VendorID:    "SIM",
+				CallSign:    v.CallSign,
+				ToBow:       v.ToBow,
+				ToStern:     v.ToStern,
+				ToPort:      v.ToPort,
+				ToStarboard: v.ToStarboard,
+			},
+			ClassBExtended{
+				MMSI:        v.MMSI,
+				SOG:         v.SOG,
+				Longitude:   v.Longitude,
+				Latitude:    v.Latitude,
+				COG:         v.COG,
+				Heading:     v.Heading,
+				Second:      now.UTC().Second(),
+				Name:        v.Name,
+				ShipType:    v.ShipType,
+				ToBow:       v.ToBow,
+				ToStern:     v.ToStern,
+				ToPort:      v.ToPort,
+				ToStarboard: v.ToStarboard,
+				EPFD:        EPFDGPS,
+			},
+		}
+	default:
+		eta := now.UTC().Add(24 * time.Hour)
+		return []Message{
+			StaticVoyageData{
+				MMSI:        v.MMSI,
+				IMO:         v.IMO,
+				CallSign:    v.CallSign,
+				Name:        v.Name,
+				ShipType:    v.ShipType,
+				ToBow:       v.ToBow,
+				ToStern:     v.ToStern,
+				ToPort:      v.ToPort,
+				ToStarboard: v.ToStarboard,
+				EPFD:        EPFDGPS,
+				ETAMonth:    int(eta.Month()),
+				ETADay:      eta.Day(),
+				ETAHour:     eta.Hour(),
+				ETAMinute:   eta.Minute(),
+				Draught:     v.Draught,
+				Destination: v.Destination,
+			},
+		}
+	}
+}
@@ -0,0 +1,279 @@
+package ais
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// dearmor converts an armored payload back into individual bits
+func dearmor(payload string) []byte {
+	var bits []byte
+	for _, c := range []byte(payload) {
+		v := c - 48
+		if v > 40 {
+			v -= 8
+		}
+		for i := 5; i >= 0; i-- {
+			bits = append(bits, (v>>uint(i))&1)
+		}
+	}
+	return bits
+}
+
+func readUint(bits []byte, start, n int) uint64 {
+	var v uint64
+	for i := start; i < start+n; i++ {
+		v = v<<1 | uint64(bits[i])
+	}
+	return v
+}
+
+func readInt(bits []byte, start, n int) int64 {
+	v := readUint(bits, start, n)
+	if v&(1<<uint(n-1)) != 0 {
+		return int64(v) - int64(1)<<uint(n)
+	}
+	return int64(v)
+}
+
+func TestArmor(t *testing.T) {
+	var w bitWriter
+	for _, v := range []uint64{0, 39, 40, 63} {
+		w.putUint(v, 6)
+	}
+	w.putUint(1, 2)
+
+	payload, fill := w.armor()
+	if payload != "0W`w@" {
+		t.Errorf("Expected armored payload 0W`w@, got %s", payload)
+	}
+	if fill != 4 {
+		t.Errorf("Expected 4 fill bits, got %d", fill)
+	}
+}
+
+func TestPositionReportEncode(t *testing.T) {
+	report := PositionReport{
+		Type:      1,
+		MMSI:      244123456,
+		NavStatus: StatusUnderWayUsingEngine,
+		ROT:       -10,
+		SOG:       12.3,
+		Longitude: -4.5,
+		Latitude:  51.25,
+		COG:       271.4,
+		Heading:   270,
+		Second:    42,
+	}
+
+	payload, fill := report.Encode()
+	if len(payload) != 28 || fill != 0 {
+		t.Fatalf("Expected 28 characters with no fill bits, got %d characters and %d fill bits", len(payload), fill)
+	}
+
+	bits := dearmor(payload)
+	checks := []struct {
+		name     string
+		got      int64
+		expected int64
+	}{
+		{"type", int64(readUint(bits, 0, 6)), 1},
+		{"mmsi", int64(readUint(bits, 8, 30)), 244123456},
+		{"rot", readInt(bits, 42, 8), -15},
+		{"sog", int64(readUint(bits, 50, 10)), 123},
+		{"longitude", readInt(bits, 61, 28), -2700000},
+		{"latitude", readInt(bits, 89, 27), 30750000},
+		{"cog", int64(readUint(bits, 116, 12)), 2714},
+		{"heading", int64(readUint(bits, 128, 9)), 270},
+		{"second", int64(readUint(bits, 137, 6)), 42},
+	}
+	for _, c := range checks {
+		if c.got != c.expected {
+			t.Errorf("Field %s: expected %d, got %d", c.name, c.expected, c.got)
+		}
+	}
+}
+
+func TestStaticVoyageDataFragmentation(t *testing.T) {
+	msg := StaticVoyageData{
+		MMSI:        244123456,
+		CallSign:    "PD1234",
+		Name:        "test vessel",
+		ShipType:    70,
+		Destination: "ROTTERDAM",
+	}
+
+	payload, fill := msg.Encode()
+	if len(payload) != 71 || fill != 2 {
+		t.Fatalf("Expected 71 characters with 2 fill bits, got %d characters and %d fill bits", len(payload), fill)
+	}
+
+	bits := dearmor(payload)
+	if name := readString(bits, 112, 20); name != "TEST VESSEL" {
+		t.Errorf("Expected vessel name TEST VESSEL, got %q", name)
+	}
+
+	sentences := Encapsulate("VDM", payload, fill, "B", 3)
+	if len(sentences) != 2 {
+		t.Fatalf("Expected 2 sentences, got %d", len(sentences))
+	}
+
+	for i, sentence := range sentences {
+		parts := strings.Split(strings.Split(sentence, "*")[0], ",")
+		if len(parts) != 7 {
+			t.Errorf("Expected 7 fields in VDM sentence, got %d", len(parts))
+			continue
+		}
+		if parts[0] != "!AIVDM" || parts[1] != "2" || parts[3] != "3" || parts[4] != "B" {
+			t.Errorf("Unexpected VDM header in fragment %d: %s", i+1, sentence)
+		}
+		if len(sentence) > 82 {
+			t.Errorf("Sentence exceeds 82 characters: %s", sentence)
+		}
+		if util.AppendChecksum(strings.Split(sentence, "*")[0]) != sentence {
+			t.Errorf("Invalid checksum: %s", sentence)
+		}
+	}
+
+	if !strings.HasSuffix(strings.Split(sentences[0], "*")[0], ",0") {
+		t.Errorf("Expected no fill bits on first fragment: %s", sentences[0])
+	}
+	if !strings.HasSuffix(strings.Split(sentences[1], "*")[0], ",2") {
+		t.Errorf("Expected 2 fill bits on last fragment: %s", sentences[1])
+	}
+}
+
+func TestPositionReportTypes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		assigned bool
+		status   uint8
+		msgType  uint64
+	}{
+		{"under way", false, StatusUnderWayUsingEngine, 1},
+		{"assigned schedule", true, StatusUnderWayUsingEngine, 2},
+		{"at anchor", false, StatusAtAnchor, 3},
+		{"moored", false, StatusMoored, 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := simulation.Vessel{MMSI: 244123456, Class: simulation.ClassA, Assigned: tc.assigned, NavStatus: tc.status}
+			payload, _ := PositionMessage(v, time.Now()).Encode()
+			if got := readUint(dearmor(payload), 0, 6); got != tc.msgType {
+				t.Errorf("Expected message type %d, got %d", tc.msgType, got)
+			}
+		})
+	}
+}
+
+func TestMessageLengths(t *testing.T) {
+	testCases := []struct {
+		name    string
+		msg     Message
+		msgType uint64
+		bits    int
+	}{
+		{"Class B position", ClassBPosition{MMSI: 1}, 18, 168},
+		{"Class B extended", ClassBExtended{MMSI: 1}, 19, 312},
+		{"Static data part A", StaticDataReport{MMSI: 1}, 24, 160},
+		{"Static data part B", StaticDataReport{MMSI: 1, PartB: true}, 24, 168},
+		{"Aid to navigation", AidToNavigationReport{MMSI: 1, Name: "BUOY"}, 21, 272},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			payload, fill := tc.msg.Encode()
+			if len(payload)*6-fill != tc.bits {
+				t.Errorf("Expected %d bits, got %d", tc.bits, len(payload)*6-fill)
+			}
+			if got := readUint(dearmor(payload), 0, 6); got != tc.msgType {
+				t.Errorf("Expected message type %d, got %d", tc.msgType, got)
+			}
+		})
+	}
+}
+
+func TestGeneratorSchedule(t *testing.T) {
+	own := simulation.DefaultOwnShip()
+	fleet := simulation.NewFleet(own, simulation.RandomTargets(own, 4, 5)...)
+	gen := NewGenerator()
+	now := time.Now()
+
+	sentences := gen.Generate(fleet, now)
+
+	var vdo, vdm int
+	for _, sentence := range sentences {
+		switch {
+		case strings.HasPrefix(sentence, "!AIVDO"):
+			vdo++
+		case strings.HasPrefix(sentence, "!AIVDM"):
+			vdm++
+		default:
+			t.Errorf("Unexpected sentence: %s", sentence)
+		}
+	}
+	if vdo == 0 {
+		t.Error("Expected own ship VDO sentences")
+	}
+	if vdm == 0 {
+		t.Error("Expected target VDM sentences")
+	}
+
+	if again := gen.Generate(fleet, now.Add(time.Second)); len(again) != 0 {
+		t.Errorf("Expected no sentences before reporting intervals elapse, got %d", len(again))
+	}
+
+	later := gen.Generate(fleet, now.Add(ClassAInterval))
+	if len(later) == 0 {
+		t.Error("Expected position reports once the Class A interval elapses")
+	}
+	for _, sentence := range later {
+		if strings.HasPrefix(sentence[3:], "VDM,2,") || strings.HasPrefix(sentence[3:], "VDO,2,") {
+			t.Errorf("Static data should not repeat before %s: %s", StaticInterval, sentence)
+		}
+	}
+}
+
+func readString(bits []byte, start, n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		c := byte(readUint(bits, start+i*6, 6))
+		if c < 32 {
+			c += 64
+		}
+		sb.WriteByte(c)
+	}
+	return strings.TrimRight(sb.String(), "@ ")
+}
+
+func TestReportKey(t *testing.T) {
+	tests := []struct {
+		msg  Message
+		want string
+	}{
+		{PositionReport{Type: 1, MMSI: 235000001}, "1/235000001"},
+		{StaticVoyageData{MMSI: 235000001, Name: "SIMULATOR"}, "5/235000001"},
+		{StaticDataReport{MMSI: 235000002, PartB: true}, "24/235000002/1"},
+	}
+
+	for _, tt := range tests {
+		payload, fill := tt.msg.Encode()
+		sentences := Encapsulate("VDM", payload, fill, "A", 1)
+		if got, ok := ReportKey(sentences[0]); !ok || got != tt.want {
+			t.Errorf("Expected key %s, got %s (%v)", tt.want, got, ok)
+		}
+		if len(sentences) > 1 {
+			if _, ok := ReportKey(sentences[1]); ok {
+				t.Errorf("Expected no key for a following fragment %s", sentences[1])
+			}
+		}
+	}
+	if _, ok := ReportKey(util.AppendChecksum("$GPGGA,120000.00,,,,,0,00,,,M,,M,,")); ok {
+		t.Error("Expected no key for a GGA sentence")
+	}
+}
@@ -0,0 +1,81 @@
+package ais
+
+import (
+	"strings"
+)
+
+// bitWriter accumulates the binary payload of an AIS message
+type bitWriter struct {
+	bits []byte // One bit per element, most significant bit first
+}
+
+// putUint appends the n least significant bits of v
+func (w *bitWriter) putUint(v uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		w.bits = append(w.bits, byte(v>>uint(i))&1)
+	}
+}
+
+// putInt appends v as an n-bit two's complement integer
+func (w *bitWriter) putInt(v int64, n int) {
+	w.putUint(uint64(v)&(1<<uint(n)-1), n)
+}
+
+// putBool appends a single bit
+func (w *bitWriter) putBool(b bool) {
+	if b {
+		w.putUint(1, 1)
+	} else {
+		w.putUint(0, 1)
+	}
+}
+
+// putString appends s as n six-bit characters, padded with '@'
+func (w *bitWriter) putString(s string, n int) {
+	s = strings.ToUpper(s)
+	for i := 0; i < n; i++ {
+		var c byte = '@'
+		if i < len(s) {
+			c = s[i]
+		}
+		w.putUint(uint64(sixBitChar(c)), 6)
+	}
+}
+
+// sixBitChar maps an ASCII character onto the AIS six-bit character set.
+// Characters outside the set are replaced with '?'.
+func sixBitChar(c byte) byte {
+	switch {
+	case c >= '@' && c <= '_':
+		return c - '@'
+	case c >= ' ' && c <= '?':
+		return c
+	default:
+		return '?'
+	}
+}
+
+// armor converts the payload into the six-bit ASCII armoring used in VDM/VDO
+// sentences and returns it together with the number of fill bits
+func (w *bitWriter) armor() (string, int) {
+	fill := (6 - len(w.bits)%6) % 6
+	bits := w.bits
+	for i := 0; i < fill; i++ {
+		bits = append(bits, 0)
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(bits); i += 6 {
+		var v byte
+		for j := 0; j < 6; j++ {
+			v = v<<1 | bits[i+j]
+		}
+		if v < 40 {
+			sb.WriteByte(v + 48)
+		} else {
+			sb.WriteByte(v + 56)
+		}
+	}
+
+	return sb.String(), fill
+}
@@ -0,0 +1,331 @@
+package ais
+
+import (
+	"math"
+)
+
+// Field values meaning "not available"
+const (
+	HeadingNotAvailable = 511
+	ROTNotAvailable     = -128
+	SecondNotAvailable  = 60
+)
+
+// Navigational status values
+const (
+	StatusUnderWayUsingEngine = 0
+	StatusAtAnchor            = 1
+	StatusMoored              = 5
+	StatusUnderWaySailing     = 8
+	StatusNotDefined          = 15
+)
+
+// EPFD fix types
+const (
+	EPFDUndefined = 0
+	EPFDGPS       = 1
+)
+
+// PositionReport represents message types 1, 2 and 3 (Class A position report)
+type PositionReport struct {
+	Type      uint8 // 1, 2 or 3
+	MMSI      uint32
+	NavStatus uint8
+	ROT       float64 // Degrees per minute, positive to starboard
+	SOG       float64 // Knots
+	Accuracy  bool    // True for DGNSS quality fix
+	Longitude float64 // Degrees
+	Latitude  float64 // Degrees
+	COG       float64 // Degrees true
+	Heading   float64 // Degrees true
+	Second    int     // UTC second of the report
+}
+
+// Encode returns the armored payload and fill bits for the message
+func (m PositionReport) Encode() (string, int) {
+	var w bitWriter
+	msgType := m.Type
+	if msgType < 1 || msgType > 3 {
+		msgType = 1
+	}
+	w.putUint(uint64(msgType), 6)
+	w.putUint(0, 2) // Repeat indicator
+	w.putUint(uint64(m.MMSI), 30)
+	w.putUint(uint64(m.NavStatus), 4)
+	w.putInt(int64(encodeROT(m.ROT)), 8)
+	w.putUint(uint64(encodeSOG(m.SOG)), 10)
+	w.putBool(m.Accuracy)
+	w.putInt(int64(encodeCoordinate(m.Longitude)), 28)
+	w.putInt(int64(encodeCoordinate(m.Latitude)), 27)
+	w.putUint(uint64(encodeCOG(m.COG)), 12)
+	w.putUint(uint64(encodeHeading(m.Heading)), 9)
+	w.putUint(uint64(m.Second), 6)
+	w.putUint(0, 2)  // Manoeuvre indicator
+	w.putUint(0, 3)  // Spare
+	w.putBool(false) // RAIM
+	w.putUint(0, 19) // Radio status
+	return w.armor()
+}
+
+// StaticVoyageData represents message type 5 (Class A static and voyage related data)
+type StaticVoyageData struct {
+	MMSI        uint32
+	IMO         uint32
+	CallSign    string
+	Name        string
+	ShipType    uint8
+	ToBow       float64 // Meters
+	ToStern     float64 // Meters
+	ToPort      float64 // Meters
+	ToStarboard float64 // Meters
+	EPFD        uint8
+	ETAMonth    int
+	ETADay      int
+	ETAHour     int
+	ETAMinute   int
+	Draught     float64 // Meters
+	Destination string
+}
+
+// Encode returns the armored payload and fill bits for the message
+func (m StaticVoyageData) Encode() (string, int) {
+	var w bitWriter
+	w.putUint(5, 6)
+	w.putUint(0, 2) // Repeat indicator
+	w.putUint(uint64(m.MMSI), 30)
+	w.putUint(0, 2) // AIS version
+	w.putUint(uint64(m.IMO), 30)
+	w.putString(m.CallSign, 7)
+	w.putString(m.Name, 20)
+	w.putUint(uint64(m.ShipType), 8)
+	putDimensions(&w, m.ToBow, m.ToStern, m.ToPort, m.ToStarboard)
+	w.putUint(uint64(m.EPFD), 4)
+	w.putUint(uint64(m.ETAMonth), 4)
+	w.putUint(uint64(m.ETADay), 5)
+	w.putUint(uint64(m.ETAHour), 5)
+	w.putUint(uint64(m.ETAMinute), 6)
+	w.putUint(uint64(clamp(math.Round(m.Draught*10), 0, 255)), 8)
+	w.putString(m.Destination, 20)
+	w.putBool(false) // DTE ready
+	w.putUint(0, 1)  // Spare
+	return w.armor()
+}
+
+// ClassBPosition represents message type 18 (standard Class B position report)
+type ClassBPosition struct {
+	MMSI      uint32
+	SOG       float64 // Knots
+	Accuracy  bool
+	Longitude float64 // Degrees
+	Latitude  float64 // Degrees
+	COG       float64 // Degrees true
+	Heading   float64 // Degrees true
+	Second    int
+}
+
+// Encode returns the armored payload and fill bits for the message
+func (m ClassBPosition) Encode() (string, int) {
+	var w bitWriter
+	w.putUint(18, 6)
+	w.putUint(0, 2) // Repeat indicator
+	w.putUint(uint64(m.MMSI), 30)
+	w.putUint(0, 8) // Reserved
+	w.putUint(uint64(encodeSOG(m.SOG)), 10)
+	w.putBool(m.Accuracy)
+	w.putInt(int64(encodeCoordinate(m.Longitude)), 28)
+	w.putInt(int64(encodeCoordinate(m.Latitude)), 27)
+	w.putUint(uint64(encodeCOG(m.COG)), 12)
+	w.putUint(uint64(encodeHeading(m.Heading)), 9)
+	w.putUint(uint64(m.Second), 6)
+	w.putUint(0, 2)  // Regional reserved
+	w.putBool(true)  // CS unit
+	w.putBool(false) // Display
+	w.putBool(false) // DSC
+	w.putBool(true)  // Band
+	w.putBool(true)  // Message 22
+	w.putBool(false) // Assigned mode
+	w.putBool(false) // RAIM
+	w.putUint(0, 20) // Radio status
+	return w.armor()
+}
+
+// ClassBExtended represents message type 19 (extended Class B position report)
+type ClassBExtended struct {
+	MMSI        uint32
+	SOG         float64 // Knots
+	Accuracy    bool
+	Longitude   float64 // Degrees
+	Latitude    float64 // Degrees
+	COG         float64 // Degrees true
+	Heading     float64 // Degrees true
+	Second      int
+	Name        string
+	ShipType    uint8
+	ToBow       float64 // Meters
+	ToStern     float64 // Meters
+	ToPort      float64 // Meters
+	ToStarboard float64 // Meters
+	EPFD        uint8
+}
+
+// Encode returns the armored payload and fill bits for the message
+func (m ClassBExtended) Encode() (string, int) {
+	var w bitWriter
+	w.putUint(19, 6)
+	w.putUint(0, 2) // Repeat indicator
+	w.putUint(uint64(m.MMSI), 30)
+	w.putUint(0, 8) // Reserved
+	w.putUint(uint64(encodeSOG(m.SOG)), 10)
+	w.putBool(m.Accuracy)
+	w.putInt(int64(encodeCoordinate(m.Longitude)), 28)
+	w.putInt(int64(encodeCoordinate(m.Latitude)), 27)
+	w.putUint(uint64(encodeCOG(m.COG)), 12)
+	w.putUint(uint64(encodeHeading(m.Heading)), 9)
+	w.putUint(uint64(m.Second), 6)
+	w.putUint(0, 4) // Regional reserved
+	w.putString(m.Name, 20)
+	w.putUint(uint64(m.ShipType), 8)
+	putDimensions(&w, m.ToBow, m.ToStern, m.ToPort, m.ToStarboard)
+	w.putUint(uint64(m.EPFD), 4)
+	w.putBool(false) // RAIM
+	w.putBool(false) // DTE ready
+	w.putBool(false) // Assigned mode
+	w.putUint(0, 4)  // Spare
+	return w.armor()
+}
+
+// StaticDataReport represents message type 24 (Class B static data report).
+// Part A carries the vessel name, part B the remaining static data.
+type StaticDataReport struct {
+	MMSI        uint32
+	PartB       bool
+	Name        string // Part A only
+	ShipType    uint8  // Part B only
+	VendorID    string // Part B only
+	CallSign    string // Part B only
+	ToBow       float64
+	ToStern     float64
+	ToPort      float64
+	ToStarboard float64
+}
+
+// Encode returns the armored payload and fill bits for the message
+func (m StaticDataReport) Encode() (string, int) {
+	var w bitWriter
+	w.putUint(24, 6)
+	w.putUint(0, 2) // Repeat indicator
+	w.putUint(uint64(m.MMSI), 30)
+	if !m.PartB {
+		w.putUint(0, 2)
+		w.putString(m.Name, 20)
+		return w.armor()
+	}
+
+	w.putUint(1, 2)
+	w.putUint(uint64(m.ShipType), 8)
+	w.putString(m.VendorID, 3)
+	w.putUint(0, 4)  // Unit model code
+	w.putUint(0, 20) // Serial number
+	w.putString(m.CallSign, 7)
+	putDimensions(&w, m.ToBow, m.ToStern, m.ToPort, m.ToStarboard)
+	w.putUint(0, 6) // Spare
+	return w.armor()
+}
+
+// AidToNavigationReport represents message type 21 (aid-to-navigation report)
+type AidToNavigationReport struct {
+	MMSI        uint32
+	AidType     uint8
+	Name        string
+	Accuracy    bool
+	Longitude   float64 // Degrees
+	Latitude    float64 // Degrees
+	ToBow       float64 // Meters
+	ToStern     float64 // Meters
+	ToPort      float64 // Meters
+	ToStarboard float64 // Meters
+	EPFD        uint8
+	Second      int
+	OffPosition bool
+	Virtual     bool
+}
+
+// Encode returns the armored payload and fill bits for the message
+func (m AidToNavigationReport) Encode() (string, int) {
+	var w bitWriter
+	w.putUint(21, 6)
+	w.putUint(0, 2) // Repeat indicator
+	w.putUint(uint64(m.MMSI), 30)
+	w.putUint(uint64(m.AidType), 5)
+
+	// Names longer than 20 characters continue in the name extension field
+	name := m.Name
+	if len(name) > 34 {
+		name = name[:34]
+	}
+	w.putString(name, 20)
+	w.putBool(m.Accuracy)
+	w.putInt(int64(encodeCoordinate(m.Longitude)), 28)
+	w.putInt(int64(encodeCoordinate(m.Latitude)), 27)
+	putDimensions(&w, m.ToBow, m.ToStern, m.ToPort, m.ToStarboard)
+	w.putUint(uint64(m.EPFD), 4)
+	w.putUint(uint64(m.Second), 6)
+	w.putBool(m.OffPosition)
+	w.putUint(0, 8)  // Regional reserved
+	w.putBool(false) // RAIM
+	w.putBool(m.Virtual)
+	w.putBool(false) // Assigned mode
+	w.putUint(0, 1)  // Spare
+	if len(name) > 20 {
+		w.putString(name[20:], len(name)-20)
+	}
+	return w.armor()
+}
+
+// putDimensions appends the reference point dimension fields shared by several messages
+func putDimensions(w *bitWriter, bow, stern, port, starboard float64) {
+	w.putUint(uint64(clamp(math.Round(bow), 0, 511)), 9)
+	w.putUint(uint64(clamp(math.Round(stern), 0, 511)), 9)
+	w.putUint(uint64(clamp(math.Round(port), 0, 63)), 6)
+	w.putUint(uint64(clamp(math.Round(starboard), 0, 63)), 6)
+}
+
+// encodeCoordinate converts degrees to the 1/10000 minute resolution used by AIS
+func encodeCoordinate(deg float64) int32 {
+	return int32(math.Round(deg * 600000))
+}
+
+// encodeSOG converts knots to the 0.1 knot resolution used by AIS
+func encodeSOG(knots float64) uint16 {
+	return uint16(clamp(math.Round(knots*10), 0, 1022))
+}
+
+// encodeCOG converts degrees to the 0.1 degree resolution used by AIS
+func encodeCOG(deg float64) uint16 {
+	return uint16(math.Round(deg*10)) % 3600
+}
+
+// encodeHeading converts degrees to whole degrees, 511 if not available
+func encodeHeading(deg float64) uint16 {
+	if deg < 0 || deg >= 360 {
+		return HeadingNotAvailable
+	}
+	return uint16(math.Round(deg)) % 360
+}
+
+// encodeROT converts degrees per minute to the AIS rate of turn indicator
+func encodeROT(rot float64) int8 {
+	if math.IsNaN(rot) {
+		return ROTNotAvailable
+	}
+	v := math.Round(4.733 * math.Sqrt(math.Abs(rot)))
+	v = clamp(v, 0, 126)
+	if rot < 0 {
+		return int8(-v)
+	}
+	return int8(v)
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
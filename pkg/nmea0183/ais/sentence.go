@@ -0,0 +1,80 @@
+package ais
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+)
+
+// MaxPayloadPerSentence is the number of armored characters carried by one
+// fragment, keeping each sentence within the 82 character NMEA limit
+const MaxPayloadPerSentence = 60
+
+// Encapsulate wraps an armored payload into one or more !AIVDM or !AIVDO sentences.
+// formatter is "VDM" for received targets or "VDO" for own ship, channel is "A" or "B"
+// and seqID is the sequential message identifier used for multi-sentence messages.
+func Encapsulate(formatter, payload string, fill int, channel string, seqID int) []string {
+	total := (len(payload) + MaxPayloadPerSentence - 1) / MaxPayloadPerSentence
+	if total == 0 {
+		total = 1
+	}
+
+	seq := ""
+	if total > 1 {
+		seq = fmt.Sprintf("%d", seqID%10)
+	}
+
+	sentences := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		start := i * MaxPayloadPerSentence
+		end := start + MaxPayloadPerSentence
+		if end > len(payload) {
+			end = len(payload)
+		}
+
+		fragmentFill := 0
+		if i == total-1 {
+			fragmentFill = fill
+		}
+
+		sentence := fmt.Sprintf(
+			"!AI%s,%d,%d,%s,%s,%s,%d",
+			formatter, total, i+1, seq, channel, payload[start:end], fragmentFill,
+		)
+		sentences = append(sentences, util.AppendChecksum(sentence))
+	}
+
+	return sentences
+}
+
+// ReportKey identifies the report carried by the first sentence of an
+// encapsulated message by its message type and MMSI, with the part number for
+// type 24 static data reports. It returns false for other sentences.
+func ReportKey(sentence string) (string, bool) {
+	body, _, _ := strings.Cut(sentence, "*")
+	fields := strings.Split(body, ",")
+	if len(fields) < 7 || fields[2] != "1" || len(fields[5]) < 7 {
+		return "", false
+	}
+
+	// The first 40 bits hold the type, repeat indicator, MMSI and part number
+	var bits uint64
+	for _, c := range []byte(fields[5][:7]) {
+		v := c - 48
+		if v > 40 {
+			v -= 8
+		}
+		if v > 63 {
+			return "", false
+		}
+		bits = bits<<6 | uint64(v)
+	}
+	bits >>= 2
+	msgType := bits >> 34
+	mmsi := bits >> 2 & (1<<30 - 1)
+	if msgType == 24 {
+		return fmt.Sprintf("%d/%d/%d", msgType, mmsi, bits&0x03), true
+	}
+	return fmt.Sprintf("%d/%d", msgType, mmsi), true
+}
@@ -0,0 +1,149 @@
+// Package autopilot provides the NMEA-0183 autopilot commands and rudder
+// sentence generator
+package autopilot
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// GenerateRSA generates an RSA (Rudder Sensor Angle) sentence for a single
+// rudder in degrees, negative to port
+func GenerateRSA(rudder float64) string {
+	sentence := fmt.Sprintf("$IIRSA,%.1f,A,,V", rudder)
+
+	return util.AppendChecksum(sentence)
+}
+
+// Apply steers the autopilot by an APB, RMB or HSC sentence, converting
+// magnetic bearings with the variation in degrees east. It reports whether
+// the sentence is an autopilot command.
+func Apply(pilot *simulation.Autopilot, s util.Sentence, variation float64) (bool, error) {
+	switch s.Formatter {
+	case "APB":
+		return true, applyAPB(pilot, s, variation)
+	case "RMB":
+		return true, applyRMB(pilot, s)
+	case "HSC":
+		return true, applyHSC(pilot, s, variation)
+	}
+	return false, nil
+}
+
+// applyAPB follows the bearing from the origin to the destination waypoint,
+// correcting the cross-track error, or holds the heading to steer when no
+// bearing is given
+func applyAPB(pilot *simulation.Autopilot, s util.Sentence, variation float64) error {
+	if s.Field(0) == "V" || s.Field(14) == "N" {
+		return fmt.Errorf("APB data not valid")
+	}
+
+	offTrack, err := crossTrack(s, 2, 3)
+	if err != nil {
+		return err
+	}
+	switch s.Field(4) {
+	case "N", "":
+	case "K":
+		offTrack /= 1.852
+	default:
+		return fmt.Errorf("APB field 5: invalid cross-track error units %q", s.Field(4))
+	}
+
+	track, err := bearing(s, 7, 8, variation)
+	if err != nil {
+		return err
+	}
+	if !math.IsNaN(track) {
+		pilot.SetTrack(track, offTrack)
+		return nil
+	}
+
+	heading, err := bearing(s, 12, 13, variation)
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(heading) {
+		return fmt.Errorf("APB without a bearing to the destination or heading to steer")
+	}
+	pilot.SetHeading(heading)
+	return nil
+}
+
+// applyRMB follows the true bearing to the destination waypoint, correcting
+// the cross-track error
+func applyRMB(pilot *simulation.Autopilot, s util.Sentence) error {
+	if s.Field(0) == "V" || s.Field(13) == "N" {
+		return fmt.Errorf("RMB data not valid")
+	}
+
+	offTrack, err := crossTrack(s, 1, 2)
+	if err != nil {
+		return err
+	}
+	track, err := s.Float(10)
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(track) {
+		return fmt.Errorf("RMB without a bearing to the destination")
+	}
+
+	pilot.SetTrack(track, offTrack)
+	return nil
+}
+
+// applyHSC holds the true heading to steer, or the magnetic heading when no
+// true heading is given
+func applyHSC(pilot *simulation.Autopilot, s util.Sentence, variation float64) error {
+	heading, err := bearing(s, 0, 1, variation)
+	if err != nil {
+		return err
+	}
+	if math.IsNaN(heading) {
+		if heading, err = bearing(s, 2, 3, variation); err != nil {
+			return err
+		}
+	}
+	if math.IsNaN(heading) {
+		return fmt.Errorf("HSC without a heading to steer")
+	}
+
+	pilot.SetHeading(heading)
+	return nil
+}
+
+// bearing returns the bearing in field i with its T or M reference in field
+// ref as degrees true, NaN when empty
+func bearing(s util.Sentence, i, ref int, variation float64) (float64, error) {
+	b, err := s.Float(i)
+	if err != nil || math.IsNaN(b) {
+		return b, err
+	}
+	switch s.Field(ref) {
+	case "T":
+		return b, nil
+	case "M":
+		return simulation.NormalizeDegrees(b + variation), nil
+	}
+	return math.NaN(), fmt.Errorf("%s field %d: invalid bearing reference %q", s.Formatter, ref+1, s.Field(ref))
+}
+
+// crossTrack returns the cross-track error in field i with the direction to
+// steer in field dir as nautical miles right of the track
+func crossTrack(s util.Sentence, i, dir int) (float64, error) {
+	xte, err := s.Float(i)
+	if err != nil || math.IsNaN(xte) {
+		return 0, err
+	}
+	switch s.Field(dir) {
+	case "L":
+		return xte, nil
+	case "R":
+		return -xte, nil
+	}
+	return 0, fmt.Errorf("%s field %d: invalid direction to steer %q", s.Formatter, dir+1, s.Field(dir))
+}
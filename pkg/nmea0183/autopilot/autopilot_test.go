@@ -0,0 +1,70 @@
+package autopilot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+func parse(t *testing.T, sentence string) util.Sentence {
+	t.Helper()
+	s, err := util.ParseSentence(util.AppendChecksum(sentence))
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", sentence, err)
+	}
+	return s
+}
+
+func TestGenerateRSA(t *testing.T) {
+	if rsa := GenerateRSA(-12.34); !strings.HasPrefix(rsa, "$IIRSA,-12.3,A,,V*") {
+		t.Errorf("Unexpected RSA sentence: %s", rsa)
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name     string
+		sentence string
+		mode     simulation.AutopilotMode
+		heading  float64
+	}{
+		{"HSC true", "$GPHSC,123.0,T,118.0,M", simulation.AutopilotHeading, 123},
+		{"HSC magnetic", "$GPHSC,,T,118.0,M", simulation.AutopilotHeading, 123},
+		{"APB right of track", "$GPAPB,A,A,0.10,L,N,V,V,011.0,T,DEST,012.0,T,005.0,T,A", simulation.AutopilotTrack, 1},
+		{"APB cross-track error in kilometers", "$GPAPB,A,A,0.1852,L,K,V,V,011.0,T,DEST,012.0,T,005.0,T,A", simulation.AutopilotTrack, 1},
+		{"APB magnetic heading to steer", "$GPAPB,A,A,0.00,R,N,V,V,,,DEST,,,355.0,M", simulation.AutopilotHeading, 0},
+		{"RMB left of track", "$GPRMB,A,0.05,R,ORIG,DEST,4812.000,N,01622.000,E,1.5,090.0,6.0,V,A", simulation.AutopilotTrack, 95},
+	}
+	for _, tt := range tests {
+		pilot := simulation.NewAutopilot(simulation.DefaultAutopilotConfig())
+		handled, err := Apply(pilot, parse(t, tt.sentence), 5)
+		if !handled || err != nil {
+			t.Errorf("%s: expected the command to be applied, got %v, %v", tt.name, handled, err)
+			continue
+		}
+		if s := pilot.State(); s.Mode != tt.mode || s.HeadingToSteer != tt.heading {
+			t.Errorf("%s: expected %s steering %.0f, got %s steering %.1f", tt.name, tt.mode, tt.heading, s.Mode, s.HeadingToSteer)
+		}
+	}
+
+	pilot := simulation.NewAutopilot(simulation.DefaultAutopilotConfig())
+	for _, sentence := range []string{
+		"$GPAPB,V,A,0.10,L,N,V,V,011.0,T,DEST,012.0,T,005.0,T,A",
+		"$GPAPB,A,A,0.10,L,M,V,V,011.0,T,DEST,012.0,T,005.0,T,A",
+		"$GPRMB,A,0.05,X,ORIG,DEST,4812.000,N,01622.000,E,1.5,090.0,6.0,V,A",
+		"$GPHSC,,T,,M",
+	} {
+		if handled, err := Apply(pilot, parse(t, sentence), 0); !handled || err == nil {
+			t.Errorf("Expected an error for %s", sentence)
+		}
+	}
+	if s := pilot.State(); s.Mode != simulation.AutopilotStandby {
+		t.Errorf("Expected invalid commands to leave the autopilot in standby, got %s", s.Mode)
+	}
+
+	if handled, _ := Apply(pilot, parse(t, "$GPGGA,,,,,,0,,,,,,,,"), 0); handled {
+		t.Error("Expected GGA not to be an autopilot command")
+	}
+}
@@ -0,0 +1,52 @@
+// Package engine provides NMEA-0183 engine and propulsion sentence generators
+package engine
+
+import (
+	"fmt"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/environment"
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// Number returns the RPM engine number of an engine instance: 0 for a single
+// engine, otherwise odd numbers to starboard and even numbers to port
+func Number(instance uint8, count int) int {
+	if count <= 1 {
+		return 0
+	}
+	if instance%2 == 1 {
+		return int(instance)
+	}
+	return int(instance) + 2
+}
+
+// GenerateRPM generates an RPM (Revolutions) sentence for an engine with the
+// given engine number. Propeller pitch is left empty.
+func GenerateRPM(number int, rpm float64) string {
+	sentence := fmt.Sprintf(
+		"$ERRPM,E,%d,%.1f,,A",
+		number, rpm,
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// GenerateXDR generates the XDR (Transducer Measurements) sentences of an
+// engine with the given engine number: speed, oil pressure and temperature,
+// coolant temperature and alternator voltage. The measurements are split over
+// two sentences to stay within the 82 character sentence length.
+func GenerateXDR(e simulation.Engine, number int) []string {
+	transducers := []environment.Transducer{
+		{Type: environment.TransducerTachometer, Value: e.RPM, Precision: 0, Units: "R", Name: fmt.Sprintf("ENGINE#%d", number)},
+		{Type: environment.TransducerPressure, Value: e.OilPressure / 100, Precision: 2, Units: "B", Name: fmt.Sprintf("ENGOILP#%d", number)},
+		{Type: environment.TransducerTemperature, Value: e.OilTemperature, Precision: 1, Units: "C", Name: fmt.Sprintf("ENGOILT#%d", number)},
+		{Type: environment.TransducerTemperature, Value: e.CoolantTemperature, Precision: 1, Units: "C", Name: fmt.Sprintf("ENGTEMP#%d", number)},
+		{Type: environment.TransducerVoltage, Value: e.AlternatorVoltage, Precision: 1, Units: "V", Name: fmt.Sprintf("ALTVOLT#%d", number)},
+	}
+
+	return []string{
+		environment.GenerateXDR(transducers[:3]...),
+		environment.GenerateXDR(transducers[3:]...),
+	}
+}
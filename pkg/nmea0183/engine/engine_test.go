@@ -0,0 +1,41 @@
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+func TestNumber(t *testing.T) {
+	if n := Number(0, 1); n != 0 {
+		t.Errorf("Expected a single engine to be number 0, got %d", n)
+	}
+	if port, stbd := Number(0, 2), Number(1, 2); port != 2 || stbd != 1 {
+		t.Errorf("Expected port engine 2 and starboard engine 1, got %d and %d", port, stbd)
+	}
+}
+
+func TestGenerateRPM(t *testing.T) {
+	if rpm := GenerateRPM(1, 2412.34); !strings.HasPrefix(rpm, "$ERRPM,E,1,2412.3,,A*") {
+		t.Errorf("Unexpected RPM sentence: %s", rpm)
+	}
+}
+
+func TestGenerateXDR(t *testing.T) {
+	e := simulation.Engine{RPM: 2400.4, OilPressure: 352, OilTemperature: 92.04, CoolantTemperature: 82.26, AlternatorVoltage: 14.2}
+	xdr := GenerateXDR(e, 2)
+
+	want := []string{
+		"$IIXDR,T,2400,R,ENGINE#2,P,3.52,B,ENGOILP#2,C,92.0,C,ENGOILT#2*",
+		"$IIXDR,C,82.3,C,ENGTEMP#2,U,14.2,V,ALTVOLT#2*",
+	}
+	if len(xdr) != len(want) {
+		t.Fatalf("Expected %d XDR sentences, got %v", len(want), xdr)
+	}
+	for i := range want {
+		if !strings.HasPrefix(xdr[i], want[i]) || len(xdr[i]) > 80 {
+			t.Errorf("Unexpected engine XDR sentence: %s", xdr[i])
+		}
+	}
+}
@@ -3,28 +3,28 @@ package environment
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 
 	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
 )
 
-// GenerateDBT generates a DBT (Depth Below Transducer) sentence
-func GenerateDBT() string {
-	depthMeters := 5.0 + util.RandomFloat(0, 95.0)
-	depthFeet := depthMeters * 3.28084
-	depthFathoms := depthMeters * 0.546807
-
+// GenerateDBT generates a DBT (Depth Below Transducer) sentence from a depth
+// in meters. A NaN depth leaves the fields empty, as a sounder without a
+// bottom return does.
+func GenerateDBT(depthMeters float64) string {
 	sentence := fmt.Sprintf(
-		"$IIDBT,%.1f,f,%.1f,M,%.1f,F",
-		depthFeet, depthMeters, depthFathoms,
+		"$IIDBT,%s,f,%s,M,%s,F",
+		formatDepth(depthMeters*3.28084), formatDepth(depthMeters), formatDepth(depthMeters*0.546807),
 	)
 
 	return util.AppendChecksum(sentence)
 }
 
-// GenerateMTW generates an MTW (Mean Temperature of Water) sentence
-func GenerateMTW() string {
-	tempC := 10.0 + util.RandomFloat(0, 20.0)
-
+// GenerateMTW generates an MTW (Mean Temperature of Water) sentence from a
+// temperature in °C
+func GenerateMTW(tempC float64) string {
 	sentence := fmt.Sprintf(
 		"$IIMTW,%.1f,C",
 		tempC,
@@ -33,42 +33,218 @@ func GenerateMTW() string {
 	return util.AppendChecksum(sentence)
 }
 
-// GenerateMWV generates an MWV (Wind Speed and Angle) sentence
-func GenerateMWV() string {
-	windAngle := util.RandomFloat(0, 360.0)
-	windSpeed := util.RandomFloat(0, 60.0)
+// GenerateMDA generates an MDA (Meteorological Composite) sentence from the
+// weather, the true wind and the variation (east positive). Absolute humidity
+// is left empty.
+func GenerateMDA(w simulation.Weather, wind simulation.Wind, variation float64) string {
+	sentence := fmt.Sprintf(
+		"$IIMDA,%.2f,I,%.4f,B,%.1f,C,%.1f,C,%.1f,,%.1f,C,%.1f,T,%.1f,M,%.1f,N,%.1f,M",
+		w.Pressure*hPaToInchesOfMercury, w.Pressure/1000, w.AirTemperature, w.WaterTemperature,
+		w.Humidity, w.DewPoint,
+		formatAngle(wind.Direction), formatAngle(wind.Direction-variation), wind.Speed, wind.Speed*knotsToMetersPerSecond,
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// hPaToInchesOfMercury converts hectopascals to inches of mercury
+const hPaToInchesOfMercury = 0.0295300
+
+// XDR transducer types
+const (
+	TransducerTemperature  = "C" // Units C, degrees Celsius
+	TransducerPressure     = "P" // Units B, bars
+	TransducerHumidity     = "H" // Units P, percent
+	TransducerAngular      = "A" // Units D, degrees
+	TransducerDisplacement = "D" // Units M, meters
+	TransducerTachometer   = "T" // Units R, RPM
+	TransducerVoltage      = "U" // Units V, volts
+	TransducerVolume       = "V" // Units P, percent of capacity
+)
+
+// maxSentenceLength is the NMEA 0183 limit on the length of a sentence,
+// including the checksum and excluding the line ending
+const maxSentenceLength = 82
+
+// Transducer is a single XDR measurement
+type Transducer struct {
+	Type      string // One of the Transducer* types
+	Value     float64
+	Precision int // Decimal places of the value
+	Units     string
+	Name      string
+}
+
+// GenerateXDR generates an XDR (Transducer Measurements) sentence carrying
+// the given measurements. A NaN value leaves its field empty.
+func GenerateXDR(measurements ...Transducer) string {
+	sentence := "$IIXDR"
+	for _, m := range measurements {
+		value := ""
+		if !math.IsNaN(m.Value) {
+			value = strconv.FormatFloat(m.Value, 'f', m.Precision, 64)
+		}
+		sentence += fmt.Sprintf(",%s,%s,%s,%s", m.Type, value, m.Units, m.Name)
+	}
+
+	return util.AppendChecksum(sentence)
+}
+
+// GenerateXDRs generates as few XDR sentences as carry the given measurements
+// within the 82 character sentence length, in order
+func GenerateXDRs(measurements ...Transducer) []string {
+	var sentences []string
+	start := 0
+	for i := 1; i <= len(measurements); i++ {
+		if i-start > 1 && len(GenerateXDR(measurements[start:i]...)) > maxSentenceLength {
+			sentences = append(sentences, GenerateXDR(measurements[start:i-1]...))
+			start = i - 1
+		}
+	}
+	if start < len(measurements) {
+		sentences = append(sentences, GenerateXDR(measurements[start:]...))
+	}
+	return sentences
+}
+
+// tankNames are the XDR names of the tank fluid types
+var tankNames = map[simulation.FluidType]string{
+	simulation.FluidFuel:       "FUEL",
+	simulation.FluidFreshWater: "FRESHWATER",
+	simulation.FluidGrayWater:  "WASTEWATER",
+	simulation.FluidLiveWell:   "LIVEWELL",
+	simulation.FluidOil:        "OIL",
+	simulation.FluidBlackWater: "BLACKWATER",
+}
+
+// TankTransducers returns the XDR measurements of the tank levels, named by
+// fluid and instance as FUEL#0
+func TankTransducers(tanks []simulation.Tank) []Transducer {
+	transducers := make([]Transducer, 0, len(tanks))
+	for _, t := range tanks {
+		transducers = append(transducers, Transducer{
+			Type:      TransducerVolume,
+			Value:     t.Level,
+			Precision: 1,
+			Units:     "P",
+			Name:      fmt.Sprintf("%s#%d", tankNames[t.Config.Fluid], t.Config.Instance),
+		})
+	}
+	return transducers
+}
 
-	reference := "R"
-	speedUnit := "N"
-	status := "A"
+// WeatherTransducers returns the XDR measurements of the air temperature,
+// barometric pressure and relative humidity
+func WeatherTransducers(w simulation.Weather) []Transducer {
+	return []Transducer{
+		{Type: TransducerTemperature, Value: w.AirTemperature, Precision: 1, Units: "C", Name: "AIRTEMP"},
+		{Type: TransducerPressure, Value: w.Pressure / 1000, Precision: 5, Units: "B", Name: "BARO"},
+		{Type: TransducerHumidity, Value: w.Humidity, Precision: 1, Units: "P", Name: "HUMIDITY"},
+	}
+}
 
+// GenerateVBW generates a VBW (Dual Ground/Water Speed) sentence from the
+// longitudinal and transverse speeds through the water and over ground in
+// knots, transverse speeds positive to starboard. Stern speeds are left empty.
+func GenerateVBW(waterLong, waterTrans, groundLong, groundTrans float64) string {
 	sentence := fmt.Sprintf(
-		"$IIMWV,%.1f,%s,%.1f,%s,%s",
-		windAngle, reference, windSpeed, speedUnit, status,
+		"$IIVBW,%.1f,%.1f,A,%.1f,%.1f,A,,V,,V",
+		waterLong, waterTrans, groundLong, groundTrans,
 	)
 
 	return util.AppendChecksum(sentence)
 }
 
-// GenerateDPT generates a DPT (Depth of Water) sentence
-func GenerateDPT() string {
-	depthMeters := 5.0 + util.RandomFloat(0, 95.0)
-	offset := -1.5
-	maxRange := 200.0
+// MWV wind angle references
+const (
+	WindRelative = "R" // Apparent wind, relative to the bow
+	WindTrue     = "T" // True wind, relative to the bow
+)
 
+// GenerateMWV generates an MWV (Wind Speed and Angle) sentence from a wind
+// angle off the bow in degrees, a reference and a speed in knots
+func GenerateMWV(angle float64, reference string, speedKnots float64) string {
 	sentence := fmt.Sprintf(
-		"$IIDPT,%.1f,%.1f,%.1f",
-		depthMeters, offset, maxRange,
+		"$IIMWV,%.1f,%s,%.1f,N,A",
+		formatAngle(angle), reference, speedKnots,
 	)
 
 	return util.AppendChecksum(sentence)
 }
 
-// GenerateVHW generates a VHW (Water Speed and Heading) sentence
-func GenerateVHW() string {
-	headingTrue := util.RandomFloat(0, 360.0)
-	headingMagnetic := headingTrue - 2.0
-	speedKnots := util.RandomFloat(0, 20.0)
+// GenerateMWD generates an MWD (Wind Direction & Speed) sentence from the
+// direction the true wind blows from, the variation (east positive) and the
+// speed in knots
+func GenerateMWD(directionTrue, variation, speedKnots float64) string {
+	sentence := fmt.Sprintf(
+		"$IIMWD,%.1f,T,%.1f,M,%.1f,N,%.1f,M",
+		formatAngle(directionTrue), formatAngle(directionTrue-variation), speedKnots, speedKnots*knotsToMetersPerSecond,
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// GenerateVWR generates a VWR (Relative Wind Speed and Angle) sentence from
+// the apparent wind angle off the bow in degrees clockwise and the speed in knots
+func GenerateVWR(angle, speedKnots float64) string {
+	return generateWindSentence("$IIVWR", angle, speedKnots)
+}
+
+// GenerateVWT generates a VWT (True Wind Speed and Angle) sentence from the
+// true wind angle off the bow in degrees clockwise and the speed in knots
+func GenerateVWT(angle, speedKnots float64) string {
+	return generateWindSentence("$IIVWT", angle, speedKnots)
+}
+
+// knotsToMetersPerSecond converts knots to meters per second
+const knotsToMetersPerSecond = 1852.0 / 3600
+
+// generateWindSentence formats a VWR or VWT sentence, splitting the angle into
+// 0-180 degrees and a Left or Right side of the bow
+func generateWindSentence(prefix string, angle, speedKnots float64) string {
+	angle = formatAngle(angle)
+	side := "R"
+	if angle > 180 {
+		angle, side = 360-angle, "L"
+	}
+
+	sentence := fmt.Sprintf(
+		"%s,%.1f,%s,%.1f,N,%.1f,M,%.1f,K",
+		prefix, angle, side, speedKnots, speedKnots*knotsToMetersPerSecond, speedKnots*1.852,
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// formatAngle normalizes an angle to [0, 360) after rounding to one decimal
+func formatAngle(angle float64) float64 {
+	return math.Mod(math.Mod(math.Round(angle*10)/10, 360)+360, 360)
+}
+
+// GenerateDPT generates a DPT (Depth of Water) sentence from the depth below
+// the transducer, the transducer offset (positive to the waterline, negative
+// to the keel) and the maximum range, in meters. A NaN depth leaves the depth empty.
+func GenerateDPT(depthMeters, offset, maxRange float64) string {
+	sentence := fmt.Sprintf(
+		"$IIDPT,%s,%.1f,%.1f",
+		formatDepth(depthMeters), offset, maxRange,
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// formatDepth formats a depth with one decimal, or as an empty field when NaN
+func formatDepth(depth float64) string {
+	if math.IsNaN(depth) {
+		return ""
+	}
+	return fmt.Sprintf("%.1f", depth)
+}
+
+// GenerateVHW generates a VHW (Water Speed and Heading) sentence from a true
+// heading, the variation (east positive) and the speed through water in knots
+func GenerateVHW(headingTrue, variation, speedKnots float64) string {
+	headingMagnetic := math.Mod(headingTrue-variation+360, 360)
 	speedKmh := speedKnots * 1.852
 
 	sentence := fmt.Sprintf(
@@ -78,3 +254,12 @@ func GenerateVHW() string {
 
 	return util.AppendChecksum(sentence)
 }
+
+// AttitudeTransducers returns the XDR measurements of pitch, roll and heave
+func AttitudeTransducers(a simulation.Attitude) []Transducer {
+	return []Transducer{
+		{Type: TransducerAngular, Value: a.Pitch, Precision: 1, Units: "D", Name: "PITCH"},
+		{Type: TransducerAngular, Value: a.Roll, Precision: 1, Units: "D", Name: "ROLL"},
+		{Type: TransducerDisplacement, Value: a.Heave, Precision: 2, Units: "M", Name: "HEAVE"},
+	}
+}
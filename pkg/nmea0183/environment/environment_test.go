@@ -1,13 +1,16 @@
 package environment
 
 import (
+	"math"
 	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation"
 )
 
 func TestGenerateDBT(t *testing.T) {
-	dbt := GenerateDBT()
+	dbt := GenerateDBT(10)
 
 	if !strings.HasPrefix(dbt, "$IIDBT") {
 		t.Errorf("DBT sentence should start with $IIDBT, got: %s", dbt)
@@ -21,10 +24,18 @@ func TestGenerateDBT(t *testing.T) {
 	if parts[2] != "f" || parts[4] != "M" || parts[6] != "F" {
 		t.Error("Invalid units in DBT sentence")
 	}
+
+	if parts[1] != "32.8" || parts[3] != "10.0" || parts[5] != "5.5" {
+		t.Errorf("Unexpected depths in DBT sentence: %s", dbt)
+	}
+
+	if invalid := GenerateDBT(math.NaN()); !strings.HasPrefix(invalid, "$IIDBT,,f,,M,,F*") {
+		t.Errorf("Expected empty depth fields, got: %s", invalid)
+	}
 }
 
 func TestGenerateMTW(t *testing.T) {
-	mtw := GenerateMTW()
+	mtw := GenerateMTW(16.4)
 
 	if !strings.HasPrefix(mtw, "$IIMTW") {
 		t.Errorf("MTW sentence should start with $IIMTW, got: %s", mtw)
@@ -36,7 +47,7 @@ func TestGenerateMTW(t *testing.T) {
 	}
 
 	temp, err := strconv.ParseFloat(parts[1], 64)
-	if err != nil || temp < 10.0 || temp > 30.0 {
+	if err != nil || temp != 16.4 {
 		t.Errorf("Invalid temperature value in MTW sentence: %s", parts[1])
 	}
 
@@ -45,8 +56,50 @@ func TestGenerateMTW(t *testing.T) {
 	}
 }
 
+func TestGenerateMDA(t *testing.T) {
+	w := simulation.Weather{AirTemperature: 18.46, WaterTemperature: 16, DewPoint: 13.2, Humidity: 71.5, Pressure: 1020}
+	mda := GenerateMDA(w, simulation.Wind{Direction: 225, Speed: 12}, 5)
+
+	if !strings.HasPrefix(mda, "$IIMDA") {
+		t.Errorf("MDA sentence should start with $IIMDA, got: %s", mda)
+	}
+
+	parts := strings.Split(strings.Split(mda, "*")[0], ",")
+	if len(parts) != 21 {
+		t.Fatalf("Expected 21 fields in MDA sentence, got %d", len(parts))
+	}
+
+	expected := map[int]string{
+		1: "30.12", 2: "I", 3: "1.0200", 4: "B", 5: "18.5", 7: "16.0", 9: "71.5", 10: "",
+		11: "13.2", 13: "225.0", 15: "220.0", 17: "12.0", 19: "6.2",
+	}
+	for i, want := range expected {
+		if parts[i] != want {
+			t.Errorf("Expected field %d to be %q, got %q in %s", i, want, parts[i], mda)
+		}
+	}
+}
+
+func TestGenerateXDR(t *testing.T) {
+	w := simulation.Weather{AirTemperature: 18.46, Humidity: 71.53, Pressure: 1013.25}
+	xdr := GenerateXDR(WeatherTransducers(w)...)
+
+	if !strings.HasPrefix(xdr, "$IIXDR,C,18.5,C,AIRTEMP,P,1.01325,B,BARO,H,71.5,P,HUMIDITY*") {
+		t.Errorf("Unexpected XDR sentence: %s", xdr)
+	}
+
+	attitude := GenerateXDR(AttitudeTransducers(simulation.Attitude{Pitch: 2.04, Roll: -5.06, Heave: 0.314})...)
+	if !strings.HasPrefix(attitude, "$IIXDR,A,2.0,D,PITCH,A,-5.1,D,ROLL,D,0.31,M,HEAVE*") {
+		t.Errorf("Unexpected attitude XDR sentence: %s", attitude)
+	}
+
+	if empty := GenerateXDR(Transducer{Type: TransducerAngular, Value: math.NaN(), Units: "D", Name: "PITCH"}); !strings.HasPrefix(empty, "$IIXDR,A,,D,PITCH*") {
+		t.Errorf("Expected an empty value, got: %s", empty)
+	}
+}
+
 func TestGenerateMWV(t *testing.T) {
-	mwv := GenerateMWV()
+	mwv := GenerateMWV(359.96, WindRelative, 14.2)
 
 	if !strings.HasPrefix(mwv, "$IIMWV") {
 		t.Errorf("MWV sentence should start with $IIMWV, got: %s", mwv)
@@ -65,10 +118,31 @@ func TestGenerateMWV(t *testing.T) {
 	if parts[2] != "R" || parts[4] != "N" || parts[5] != "A" {
 		t.Error("Invalid reference, speed unit or status in MWV sentence")
 	}
+
+	if parts[1] != "0.0" {
+		t.Errorf("Expected wind angle to wrap to 0.0, got %s", parts[1])
+	}
+}
+
+func TestGenerateWindSentences(t *testing.T) {
+	mwd := strings.Split(strings.Split(GenerateMWD(2, 4.5, 10), "*")[0], ",")
+	if mwd[0] != "$IIMWD" || mwd[1] != "2.0" || mwd[3] != "357.5" || mwd[5] != "10.0" || mwd[7] != "5.1" {
+		t.Errorf("Unexpected MWD fields: %v", mwd)
+	}
+
+	vwr := strings.Split(strings.Split(GenerateVWR(300, 10), "*")[0], ",")
+	if vwr[0] != "$IIVWR" || vwr[1] != "60.0" || vwr[2] != "L" || vwr[7] != "18.5" {
+		t.Errorf("Unexpected VWR fields: %v", vwr)
+	}
+
+	vwt := strings.Split(strings.Split(GenerateVWT(45, 10), "*")[0], ",")
+	if vwt[0] != "$IIVWT" || vwt[1] != "45.0" || vwt[2] != "R" || len(vwt) != 9 {
+		t.Errorf("Unexpected VWT fields: %v", vwt)
+	}
 }
 
 func TestGenerateDPT(t *testing.T) {
-	dpt := GenerateDPT()
+	dpt := GenerateDPT(12.34, -1.3, 200)
 
 	if !strings.HasPrefix(dpt, "$IIDPT") {
 		t.Errorf("DPT sentence should start with $IIDPT, got: %s", dpt)
@@ -80,13 +154,13 @@ func TestGenerateDPT(t *testing.T) {
 	}
 
 	depth, err := strconv.ParseFloat(parts[1], 64)
-	if err != nil || depth < 5.0 || depth > 100.0 {
-		t.Error("Invalid depth value in DPT sentence")
+	if err != nil || depth != 12.3 || parts[2] != "-1.3" || parts[3] != "200.0" {
+		t.Errorf("Invalid depth, offset or range in DPT sentence: %s", dpt)
 	}
 }
 
 func TestGenerateVHW(t *testing.T) {
-	vhw := GenerateVHW()
+	vhw := GenerateVHW(10, 12.5, 5.2)
 
 	if !strings.HasPrefix(vhw, "$IIVHW") {
 		t.Errorf("VHW sentence should start with $IIVHW, got: %s", vhw)
@@ -100,4 +174,44 @@ func TestGenerateVHW(t *testing.T) {
 	if parts[2] != "T" || parts[4] != "M" || parts[6] != "N" || parts[8] != "K" {
 		t.Error("Invalid units in VHW sentence")
 	}
-}
\ No newline at end of file
+
+	if parts[3] != "357.5" {
+		t.Errorf("Expected magnetic heading 357.5, got %s", parts[3])
+	}
+}
+func TestGenerateVBW(t *testing.T) {
+	vbw := GenerateVBW(6, 0, 5.2, -1.3)
+
+	parts := strings.Split(strings.Split(vbw, "*")[0], ",")
+	if parts[0] != "$IIVBW" || len(parts) != 11 {
+		t.Fatalf("Expected 11 VBW fields, got %v", parts)
+	}
+	if parts[1] != "6.0" || parts[4] != "5.2" || parts[5] != "-1.3" || parts[3] != "A" || parts[6] != "A" {
+		t.Errorf("Unexpected VBW fields: %v", parts)
+	}
+}
+
+func TestGenerateXDRsTanks(t *testing.T) {
+	tanks := []simulation.Tank{
+		{Config: simulation.TankConfig{Fluid: simulation.FluidFuel}, Level: 80.04},
+		{Config: simulation.TankConfig{Fluid: simulation.FluidFreshWater}, Level: 70},
+		{Config: simulation.TankConfig{Fluid: simulation.FluidGrayWater}, Level: 20},
+		{Config: simulation.TankConfig{Fluid: simulation.FluidBlackWater, Instance: 1}, Level: 9.96},
+	}
+
+	sentences := GenerateXDRs(TankTransducers(tanks)...)
+	if len(sentences) != 2 {
+		t.Fatalf("Expected the tanks split over 2 sentences, got %d: %v", len(sentences), sentences)
+	}
+	if !strings.HasPrefix(sentences[0], "$IIXDR,V,80.0,P,FUEL#0,V,70.0,P,FRESHWATER#0,V,20.0,P,WASTEWATER#0*") {
+		t.Errorf("Unexpected first tank XDR sentence: %s", sentences[0])
+	}
+	if !strings.HasPrefix(sentences[1], "$IIXDR,V,10.0,P,BLACKWATER#1*") {
+		t.Errorf("Unexpected second tank XDR sentence: %s", sentences[1])
+	}
+	for _, s := range sentences {
+		if len(s) > 82 {
+			t.Errorf("Sentence longer than 82 characters: %s", s)
+		}
+	}
+}
@@ -3,62 +3,178 @@ package navigation
 
 import (
 	"fmt"
-	"time"
+	"math"
 
 	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
 )
 
-// GenerateRMC generates an RMC (Recommended Minimum Navigation Information) sentence
-func GenerateRMC() string {
-	now := time.Now().UTC()
-	utcTime := util.FormatUTCTime(now)
-	date := now.Format("020106") // ddmmyy
+// GenerateRMC generates an RMC (Recommended Minimum Navigation Information)
+// sentence from a fix. Variation is in degrees, east positive.
+func GenerateRMC(fix simulation.GNSSFix, variation float64) string {
+	utcTime := util.FormatUTCTime(fix.Time.UTC())
+	date := fix.Time.UTC().Format("020106") // ddmmyy
 
 	status := "A"
-	latitude := 4811.7646
-	latDirection := "N"
-	longitude := 1621.4916
-	lonDirection := "E"
-
-	speedKnots := util.RandomFloat(0, 20.0)
-	trackTrue := util.RandomFloat(0, 360.0)
-	magVar := 5.0 + util.RandomFloat(0, 2.0)
-	magVarDirection := "E"
+	if !fix.Valid {
+		status = "V"
+	}
+	latitude, latDirection := util.FormatLatitude(fix.Latitude)
+	longitude, lonDirection := util.FormatLongitude(fix.Longitude)
+	magVar, magVarDirection := formatVariation(variation)
 
 	sentence := fmt.Sprintf(
-		"$GPRMC,%s,%s,%.4f,%s,%.4f,%s,%.1f,%.1f,%s,%.1f,%s",
+		"$GPRMC,%s,%s,%s,%s,%s,%s,%.1f,%s,%s,%s,%s",
 		utcTime, status,
 		latitude, latDirection,
 		longitude, lonDirection,
-		speedKnots, trackTrue,
+		fix.SOG, formatHeading(fix.COG),
 		date, magVar, magVarDirection,
 	)
 
 	return util.AppendChecksum(sentence)
 }
 
-// GenerateHDT generates an HDT (Heading - True) sentence
-func GenerateHDT() string {
-	heading := util.RandomFloat(0, 360.0)
+// GenerateHDT generates an HDT (Heading - True) sentence. A NaN heading
+// leaves the field empty, as a compass without a valid heading does.
+func GenerateHDT(heading float64) string {
+	sentence := fmt.Sprintf(
+		"$HEHDT,%s,T",
+		formatHeading(heading),
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// THS mode indicators
+const (
+	ModeAutonomous = "A"
+	ModeEstimated  = "E"
+	ModeManual     = "M"
+	ModeSimulator  = "S"
+	ModeInvalid    = "V"
+)
+
+// GenerateHDG generates an HDG (Heading - Deviation & Variation) sentence from
+// a compass heading. Deviation and variation are east positive; a NaN heading
+// leaves the heading field empty.
+func GenerateHDG(heading, deviation, variation float64) string {
+	devDir, varDir := "E", "E"
+	if deviation < 0 {
+		devDir = "W"
+	}
+	if variation < 0 {
+		varDir = "W"
+	}
+
+	sentence := fmt.Sprintf(
+		"$HCHDG,%s,%.1f,%s,%.1f,%s",
+		formatHeading(heading), math.Abs(deviation), devDir, math.Abs(variation), varDir,
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// GenerateHDM generates an HDM (Heading - Magnetic) sentence. A NaN heading
+// leaves the field empty.
+func GenerateHDM(heading float64) string {
+	sentence := fmt.Sprintf(
+		"$HCHDM,%s,M",
+		formatHeading(heading),
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// GenerateTHS generates a THS (True Heading and Status) sentence. A NaN
+// heading leaves the field empty and reports ModeInvalid.
+func GenerateTHS(heading float64, mode string) string {
+	if math.IsNaN(heading) {
+		mode = ModeInvalid
+	}
+
+	sentence := fmt.Sprintf(
+		"$HETHS,%s,%s",
+		formatHeading(heading), mode,
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// GenerateROT generates a ROT (Rate Of Turn) sentence in degrees per minute,
+// negative to port
+func GenerateROT(rot float64, valid bool) string {
+	status := "A"
+	if !valid {
+		status = "V"
+	}
+
+	sentence := fmt.Sprintf(
+		"$HEROT,%.1f,%s",
+		rot, status,
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// formatVariation formats a variation, east positive, as a magnitude and E/W direction
+func formatVariation(variation float64) (string, string) {
+	if variation < 0 {
+		return fmt.Sprintf("%.1f", -variation), "W"
+	}
+	return fmt.Sprintf("%.1f", variation), "E"
+}
+
+// formatHeading formats a heading with one decimal in [0, 360), or as an empty field when NaN
+func formatHeading(heading float64) string {
+	if math.IsNaN(heading) {
+		return ""
+	}
+	heading = math.Round(heading*10) / 10
+	if heading >= 360 {
+		heading -= 360
+	}
+	return fmt.Sprintf("%.1f", heading)
+}
+
+// GenerateHRM generates an HRM (Heel Angle, Roll Period and Roll Amplitude)
+// sentence from an attitude, negative to port. The status is void until the
+// first complete roll.
+func GenerateHRM(a simulation.Attitude) string {
+	status := "A"
+	if a.RollPeriod == 0 {
+		status = "V"
+	}
+	reset := a.PeakSince.UTC()
+
+	sentence := fmt.Sprintf(
+		"$IIHRM,%.1f,%.1f,%.1f,%.1f,%s,%.1f,%.1f,%s,%02d,%02d",
+		a.Roll, a.RollPeriod, a.RollAmplitudePort, a.RollAmplitudeStbd, status,
+		a.RollPeakPort, a.RollPeakStbd, util.FormatUTCTime(reset), reset.Day(), int(reset.Month()),
+	)
+
+	return util.AppendChecksum(sentence)
+}
 
+// GeneratePRDID generates a proprietary PRDID sentence with pitch (positive
+// bow up), roll (positive starboard down) and true heading in degrees
+func GeneratePRDID(pitch, roll, heading float64) string {
 	sentence := fmt.Sprintf(
-		"$HEHDT,%.1f,T",
-		heading,
+		"$PRDID,%.2f,%.2f,%.2f",
+		pitch, roll, heading,
 	)
 
 	return util.AppendChecksum(sentence)
 }
 
-// GenerateVTG generates a VTG (Track Made Good and Ground Speed) sentence
-func GenerateVTG() string {
-	trackTrue := util.RandomFloat(0, 360.0)
-	trackMag := trackTrue - 2.0
-	speedKnots := util.RandomFloat(0, 20.0)
+// GenerateVTG generates a VTG (Track Made Good and Ground Speed) sentence.
+// The magnetic track is the true track less the variation, east positive.
+func GenerateVTG(trackTrue, speedKnots, variation float64) string {
 	speedKmh := speedKnots * 1.852
 
 	sentence := fmt.Sprintf(
-		"$GPVTG,%.1f,T,%.1f,M,%.1f,N,%.1f,K",
-		trackTrue, trackMag, speedKnots, speedKmh,
+		"$GPVTG,%s,T,%s,M,%.1f,N,%.1f,K",
+		formatHeading(trackTrue), formatHeading(math.Mod(trackTrue-variation+360, 360)), speedKnots, speedKmh,
 	)
 
 	return util.AppendChecksum(sentence)
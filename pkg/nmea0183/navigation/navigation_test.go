@@ -1,13 +1,25 @@
 package navigation
 
 import (
+	"math"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation"
 )
 
 func TestGenerateRMC(t *testing.T) {
-	rmc := GenerateRMC()
+	fix := simulation.GNSSFix{
+		Time:      time.Date(2025, 4, 29, 15, 4, 5, 0, time.UTC),
+		Valid:     true,
+		Latitude:  48.196077,
+		Longitude: 16.358193,
+		COG:       45,
+		SOG:       6.5,
+	}
+	rmc := GenerateRMC(fix, -3.26)
 
 	if !strings.HasPrefix(rmc, "$GPRMC") {
 		t.Errorf("RMC sentence should start with $GPRMC, got: %s", rmc)
@@ -22,10 +34,18 @@ func TestGenerateRMC(t *testing.T) {
 	if parts[2] != "A" && parts[2] != "V" {
 		t.Errorf("Invalid status in RMC sentence: %s", parts[2])
 	}
+
+	if parts[5] != "01621.4916" || parts[9] != "290425" {
+		t.Errorf("Unexpected longitude or date in RMC sentence: %s", rmc)
+	}
+
+	if parts[10] != "3.3" || parts[11] != "W" {
+		t.Errorf("Expected variation 3.3,W, got %s,%s", parts[10], parts[11])
+	}
 }
 
 func TestGenerateHDT(t *testing.T) {
-	hdt := GenerateHDT()
+	hdt := GenerateHDT(123.45)
 
 	if !strings.HasPrefix(hdt, "$HEHDT") {
 		t.Errorf("HDT sentence should start with $HEHDT, got: %s", hdt)
@@ -40,10 +60,14 @@ func TestGenerateHDT(t *testing.T) {
 	if err != nil || heading < 0 || heading > 360 {
 		t.Errorf("Invalid heading value in HDT sentence: %s", parts[1])
 	}
+
+	if invalid := GenerateHDT(math.NaN()); !strings.HasPrefix(invalid, "$HEHDT,,T*") {
+		t.Errorf("Expected empty heading field, got: %s", invalid)
+	}
 }
 
 func TestGenerateVTG(t *testing.T) {
-	vtg := GenerateVTG()
+	vtg := GenerateVTG(1.5, 6.5, 4.5)
 
 	if !strings.HasPrefix(vtg, "$GPVTG") {
 		t.Errorf("VTG sentence should start with $GPVTG, got: %s", vtg)
@@ -57,6 +81,10 @@ func TestGenerateVTG(t *testing.T) {
 	if parts[2] != "T" || parts[4] != "M" || parts[6] != "N" || parts[8] != "K" {
 		t.Error("Invalid units in VTG sentence")
 	}
+
+	if parts[3] != "357.0" {
+		t.Errorf("Expected magnetic track 357.0, got %s", parts[3])
+	}
 }
 
 func TestGenerateXTE(t *testing.T) {
@@ -78,4 +106,49 @@ func TestGenerateXTE(t *testing.T) {
 	if parts[4] != "L" && parts[4] != "R" {
 		t.Errorf("Invalid direction in XTE sentence: %s", parts[4])
 	}
-}
\ No newline at end of file
+}
+
+func TestGenerateCompassSentences(t *testing.T) {
+	if hdg := GenerateHDG(123.45, -2.25, 4.5); !strings.HasPrefix(hdg, "$HCHDG,123.5,2.2,W,4.5,E*") {
+		t.Errorf("Unexpected HDG sentence: %s", hdg)
+	}
+	if hdg := GenerateHDG(math.NaN(), 1, -3); !strings.HasPrefix(hdg, "$HCHDG,,1.0,E,3.0,W*") {
+		t.Errorf("Unexpected invalid HDG sentence: %s", hdg)
+	}
+	if hdm := GenerateHDM(10); !strings.HasPrefix(hdm, "$HCHDM,10.0,M*") {
+		t.Errorf("Unexpected HDM sentence: %s", hdm)
+	}
+	if ths := GenerateTHS(359.96, ModeAutonomous); !strings.HasPrefix(ths, "$HETHS,0.0,A*") {
+		t.Errorf("Unexpected THS sentence: %s", ths)
+	}
+	if ths := GenerateTHS(math.NaN(), ModeAutonomous); !strings.HasPrefix(ths, "$HETHS,,V*") {
+		t.Errorf("Unexpected invalid THS sentence: %s", ths)
+	}
+	if rot := GenerateROT(-12.34, true); !strings.HasPrefix(rot, "$HEROT,-12.3,A*") {
+		t.Errorf("Unexpected ROT sentence: %s", rot)
+	}
+}
+
+func TestGenerateMotionSentences(t *testing.T) {
+	a := simulation.Attitude{
+		Roll:              -4.26,
+		RollPeriod:        6.04,
+		RollAmplitudePort: 5.1,
+		RollAmplitudeStbd: 4.8,
+		RollPeakPort:      9.3,
+		RollPeakStbd:      8.7,
+		PeakSince:         time.Date(2025, 4, 29, 12, 30, 5, 0, time.UTC),
+	}
+	if hrm := GenerateHRM(a); !strings.HasPrefix(hrm, "$IIHRM,-4.3,6.0,5.1,4.8,A,9.3,8.7,123005.00,29,04*") {
+		t.Errorf("Unexpected HRM sentence: %s", hrm)
+	}
+
+	a.RollPeriod = 0
+	if hrm := GenerateHRM(a); strings.Split(hrm, ",")[5] != "V" {
+		t.Errorf("Expected a void HRM before the first roll, got: %s", hrm)
+	}
+
+	if prdid := GeneratePRDID(1.234, -3.456, 45); !strings.HasPrefix(prdid, "$PRDID,1.23,-3.46,45.00*") {
+		t.Errorf("Unexpected PRDID sentence: %s", prdid)
+	}
+}
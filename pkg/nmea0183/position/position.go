@@ -3,65 +3,60 @@ package position
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
 )
 
 // GenerateGGA generates a GGA (Global Positioning System Fix Data) sentence
-func GenerateGGA() string {
+func GenerateGGA(fix simulation.GNSSFix) string {
 	// 1. Time (UTC)
-	now := time.Now().UTC()
-	utcTime := util.FormatUTCTime(now)
+	utcTime := util.FormatUTCTime(fix.Time.UTC())
 
 	// 2. Latitude and N/S
-	latitude := 4811.7646
-	latDirection := "N"
+	latitude, latDirection := util.FormatLatitude(fix.Latitude)
 
 	// 3. Longitude and E/W
-	longitude := 1621.4916
-	lonDirection := "E"
+	longitude, lonDirection := util.FormatLongitude(fix.Longitude)
 
 	// 4. GPS Quality Indicator
-	gpsQuality := util.RandomInt(0, 2)
+	gpsQuality := 0
+	if fix.Valid {
+		gpsQuality = 1
+	}
 
-	// 5. Number of satellites
-	numSatellites := util.RandomInt(4, 12)
+	// 5. Number of satellites in use
+	numSatellites := len(fix.Used())
 
 	// 6. HDOP
-	hdop := 0.5 + util.RandomFloat(0, 4.5)
-
-	// 7. Altitude
-	altitude := 100.0 + util.RandomFloat(0, 200.0)
-
-	// 8. Geoidal separation
-	geoidalSeparation := -5.0 + util.RandomFloat(0, 10.0)
+	hdop := fix.DOP.HDOP
 
 	// Format the sentence
 	sentence := fmt.Sprintf(
-		"$GPGGA,%s,%.4f,%s,%.4f,%s,%d,%02d,%.1f,%.1f,M,%.1f,M,,",
+		"$GPGGA,%s,%s,%s,%s,%s,%d,%02d,%.1f,%.1f,M,%.1f,M,,",
 		utcTime, latitude, latDirection, longitude, lonDirection,
-		gpsQuality, numSatellites, hdop, altitude, geoidalSeparation,
+		gpsQuality, numSatellites, hdop, fix.Altitude, fix.GeoidSeparation,
 	)
 
 	return util.AppendChecksum(sentence)
 }
 
 // GenerateGLL generates a GLL (Geographic Position - Latitude/Longitude) sentence
-func GenerateGLL() string {
-	// Reuse position data for consistency
-	latitude := 4811.7646
-	latDirection := "N"
-	longitude := 1621.4916
-	lonDirection := "E"
+func GenerateGLL(fix simulation.GNSSFix) string {
+	latitude, latDirection := util.FormatLatitude(fix.Latitude)
+	longitude, lonDirection := util.FormatLongitude(fix.Longitude)
 
-	now := time.Now().UTC()
-	utcTime := util.FormatUTCTime(now)
+	utcTime := util.FormatUTCTime(fix.Time.UTC())
 
 	status := "A"
+	if !fix.Valid {
+		status = "V"
+	}
 
 	sentence := fmt.Sprintf(
-		"$GPGLL,%.4f,%s,%.4f,%s,%s,%s",
+		"$GPGLL,%s,%s,%s,%s,%s,%s",
 		latitude, latDirection,
 		longitude, lonDirection,
 		utcTime, status,
@@ -69,3 +64,132 @@ func GenerateGLL() string {
 
 	return util.AppendChecksum(sentence)
 }
+
+// GenerateGSA generates GSA (GNSS DOP and Active Satellites) sentences.
+// A single-system fix produces one classic sentence; a multi-system fix produces
+// one $GNGSA per system carrying the NMEA 4.10 system ID.
+func GenerateGSA(fix simulation.GNSSFix) []string {
+	mode := 1 // No fix
+	if fix.Valid {
+		mode = 3 // 3D fix
+	}
+
+	systems := fix.Systems()
+	var sentences []string
+	for _, system := range systems {
+		prns := make([]string, 12)
+		n := 0
+		for _, s := range fix.Used() {
+			if s.System == system && n < len(prns) {
+				prns[n] = fmt.Sprintf("%02d", s.PRN)
+				n++
+			}
+		}
+
+		talker, systemID := system.Talker(), ""
+		if len(systems) > 1 {
+			talker, systemID = "GN", fmt.Sprintf(",%d", system)
+		}
+
+		sentence := fmt.Sprintf(
+			"$%sGSA,A,%d,%s,%.1f,%.1f,%.1f%s",
+			talker, mode, strings.Join(prns, ","),
+			fix.DOP.PDOP, fix.DOP.HDOP, fix.DOP.VDOP, systemID,
+		)
+		sentences = append(sentences, util.AppendChecksum(sentence))
+	}
+
+	return sentences
+}
+
+// GenerateGSV generates GSV (GNSS Satellites in View) sentences, four
+// satellites per sentence, with one group of sentences per system
+func GenerateGSV(fix simulation.GNSSFix) []string {
+	var sentences []string
+	for _, system := range fix.Systems() {
+		var sats []simulation.Satellite
+		for _, s := range fix.Satellites {
+			if s.System == system {
+				sats = append(sats, s)
+			}
+		}
+
+		total := (len(sats) + 3) / 4
+		for i := 0; i < total; i++ {
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "$%sGSV,%d,%d,%02d", system.Talker(), total, i+1, len(sats))
+			for j := i * 4; j < len(sats) && j < (i+1)*4; j++ {
+				s := sats[j]
+				fmt.Fprintf(&sb, ",%02d,%02.0f,%03.0f,%02.0f", s.PRN, s.Elevation, s.Azimuth, s.SNR)
+			}
+			sentences = append(sentences, util.AppendChecksum(sb.String()))
+		}
+	}
+
+	return sentences
+}
+
+// GenerateGST generates a GST (GNSS Pseudorange Error Statistics) sentence
+func GenerateGST(fix simulation.GNSSFix) string {
+	talker := "GP"
+	if len(fix.Systems()) > 1 {
+		talker = "GN"
+	}
+
+	sentence := fmt.Sprintf(
+		"$%sGST,%s,%.1f,%.1f,%.1f,%.1f,%.1f,%.1f,%.1f",
+		talker, util.FormatUTCTime(fix.Time.UTC()),
+		fix.RMS, fix.SigmaMajor, fix.SigmaMinor, fix.Orientation,
+		fix.SigmaLat, fix.SigmaLon, fix.SigmaAlt,
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// GenerateGNS generates a GNS (GNSS Fix Data) sentence with a mode indicator
+// character for each of GPS, GLONASS, Galileo and BeiDou
+func GenerateGNS(fix simulation.GNSSFix) string {
+	latitude, latDirection := util.FormatLatitude(fix.Latitude)
+	longitude, lonDirection := util.FormatLongitude(fix.Longitude)
+
+	used := make(map[simulation.GNSSSystem]bool)
+	for _, s := range fix.Used() {
+		used[s.System] = true
+	}
+
+	var mode strings.Builder
+	for _, system := range []simulation.GNSSSystem{simulation.GPS, simulation.GLONASS, simulation.Galileo, simulation.BeiDou} {
+		if fix.Valid && used[system] {
+			mode.WriteByte('A') // Autonomous
+		} else {
+			mode.WriteByte('N') // No fix
+		}
+	}
+
+	navStatus := "S" // Safe
+	if !fix.Valid {
+		navStatus = "V" // Not valid for navigation
+	}
+
+	sentence := fmt.Sprintf(
+		"$GNGNS,%s,%s,%s,%s,%s,%s,%02d,%.1f,%.1f,%.1f,,,%s",
+		util.FormatUTCTime(fix.Time.UTC()),
+		latitude, latDirection, longitude, lonDirection,
+		mode.String(), len(fix.Used()), fix.DOP.HDOP,
+		fix.Altitude, fix.GeoidSeparation, navStatus,
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// GenerateZDA generates a ZDA (Time and Date) sentence
+func GenerateZDA(t time.Time) string {
+	t = t.UTC()
+
+	sentence := fmt.Sprintf(
+		"$GPZDA,%s,%02d,%02d,%04d,00,00",
+		util.FormatUTCTime(t), t.Day(), int(t.Month()), t.Year(),
+	)
+
+	return util.AppendChecksum(sentence)
+}
@@ -1,12 +1,24 @@
 package position
 
 import (
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation"
 )
 
+func testFix() simulation.GNSSFix {
+	cfg := simulation.DefaultGNSSConfig()
+	cfg.Errors = simulation.GNSSErrorModel{}
+	receiver := simulation.NewGNSSReceiver(cfg)
+	return receiver.Fix(simulation.DefaultOwnShip(), time.Date(2025, 4, 29, 15, 4, 5, 0, time.UTC))
+}
+
 func TestGenerateGGA(t *testing.T) {
-	gga := GenerateGGA()
+	fix := testFix()
+	gga := GenerateGGA(fix)
 
 	if !strings.HasPrefix(gga, "$GPGGA") {
 		t.Errorf("GGA sentence does not start with $GPGGA: %s", gga)
@@ -20,10 +32,19 @@ func TestGenerateGGA(t *testing.T) {
 	if len(parts) < 15 {
 		t.Errorf("GGA sentence has insufficient fields: %s", gga)
 	}
+
+	if parts[2] != "4811.7646" || parts[4] != "01621.4916" {
+		t.Errorf("GGA position does not match own ship: %s", gga)
+	}
+
+	numSatellites, _ := strconv.Atoi(parts[7])
+	if numSatellites != len(fix.Used()) {
+		t.Errorf("GGA reports %d satellites, fix uses %d", numSatellites, len(fix.Used()))
+	}
 }
 
 func TestGenerateGLL(t *testing.T) {
-	gll := GenerateGLL()
+	gll := GenerateGLL(testFix())
 
 	if !strings.HasPrefix(gll, "$GPGLL") {
 		t.Errorf("GLL sentence should start with $GPGLL, got: %s", gll)
@@ -43,4 +64,79 @@ func TestGenerateGLL(t *testing.T) {
 	if status != "A" && status != "V" {
 		t.Errorf("Invalid status in GLL sentence: %s", status)
 	}
-}
\ No newline at end of file
+}
+
+func TestGenerateGSA(t *testing.T) {
+	fix := testFix()
+	sentences := GenerateGSA(fix)
+
+	if len(sentences) != len(fix.Systems()) {
+		t.Fatalf("Expected one GSA per system, got %d", len(sentences))
+	}
+
+	used := 0
+	for _, gsa := range sentences {
+		parts := strings.Split(strings.Split(gsa, "*")[0], ",")
+		if parts[0] != "$GNGSA" || len(parts) != 19 {
+			t.Errorf("Expected 19 fields in multi-system GSA sentence, got %d: %s", len(parts), gsa)
+			continue
+		}
+		for _, prn := range parts[3:15] {
+			if prn != "" {
+				used++
+			}
+		}
+	}
+	if used != len(fix.Used()) {
+		t.Errorf("GSA lists %d satellites, fix uses %d", used, len(fix.Used()))
+	}
+
+	gps := simulation.NewGNSSReceiver(simulation.GNSSConfig{Systems: []simulation.GNSSSystem{simulation.GPS}, ElevationMask: 5, MinSNR: 25, UERE: 3})
+	sentences = GenerateGSA(gps.Fix(simulation.DefaultOwnShip(), time.Now()))
+	parts := strings.Split(strings.Split(sentences[0], "*")[0], ",")
+	if len(sentences) != 1 || parts[0] != "$GPGSA" || len(parts) != 18 {
+		t.Errorf("Expected a single classic GPGSA sentence, got %v", sentences)
+	}
+}
+
+func TestGenerateGSV(t *testing.T) {
+	fix := testFix()
+	sentences := GenerateGSV(fix)
+
+	inView := 0
+	for _, gsv := range sentences {
+		parts := strings.Split(strings.Split(gsv, "*")[0], ",")
+		if !strings.HasSuffix(parts[0], "GSV") || (len(parts)-4)%4 != 0 {
+			t.Errorf("Invalid GSV sentence: %s", gsv)
+			continue
+		}
+		inView += (len(parts) - 4) / 4
+	}
+
+	if inView != len(fix.Satellites) {
+		t.Errorf("GSV lists %d satellites, %d in view", inView, len(fix.Satellites))
+	}
+}
+
+func TestGenerateGSTGNSZDA(t *testing.T) {
+	fix := testFix()
+
+	gst := GenerateGST(fix)
+	if parts := strings.Split(strings.Split(gst, "*")[0], ","); parts[0] != "$GNGST" || len(parts) != 9 {
+		t.Errorf("Expected 9 fields in GST sentence: %s", gst)
+	}
+
+	gns := GenerateGNS(fix)
+	parts := strings.Split(strings.Split(gns, "*")[0], ",")
+	if parts[0] != "$GNGNS" || len(parts) != 14 {
+		t.Fatalf("Expected 14 fields in GNS sentence, got %d: %s", len(parts), gns)
+	}
+	if parts[6] != "AAAA" || parts[13] != "S" {
+		t.Errorf("Expected all systems in autonomous mode and safe status: %s", gns)
+	}
+
+	zda := GenerateZDA(fix.Time)
+	if !strings.HasPrefix(zda, "$GPZDA,150405.00,29,04,2025,00,00*") {
+		t.Errorf("Unexpected ZDA sentence: %s", zda)
+	}
+}
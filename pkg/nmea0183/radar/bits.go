@@ -0,0 +1,42 @@
+package radar
+
+import (
+	"strings"
+)
+
+// bitWriter accumulates the binary payload of TTD sentences
+type bitWriter struct {
+	bits []byte // One bit per element, most significant bit first
+}
+
+// putUint appends the n least significant bits of v
+func (w *bitWriter) putUint(v uint64, n int) {
+	for i := n - 1; i >= 0; i-- {
+		w.bits = append(w.bits, byte(v>>uint(i))&1)
+	}
+}
+
+// armor converts the payload into six-bit ASCII armoring and returns it
+// together with the number of fill bits
+func (w *bitWriter) armor() (string, int) {
+	fill := (6 - len(w.bits)%6) % 6
+	bits := w.bits
+	for i := 0; i < fill; i++ {
+		bits = append(bits, 0)
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(bits); i += 6 {
+		var v byte
+		for j := 0; j < 6; j++ {
+			v = v<<1 | bits[i+j]
+		}
+		if v < 40 {
+			sb.WriteByte(v + 48)
+		} else {
+			sb.WriteByte(v + 56)
+		}
+	}
+
+	return sb.String(), fill
+}
@@ -0,0 +1,167 @@
+// Package radar provides NMEA-0183 radar tracking sentence generators (TTM, TLL, TTD, OSD)
+package radar
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// Generator produces radar tracking sentences for the targets of a fleet. One
+// generator is shared by the outputs, so that they report the same track
+// numbers: a scan at the time of the previous one returns its sentences.
+type Generator struct {
+	mu        sync.Mutex
+	tracker   *Tracker
+	seqID     int
+	scanned   time.Time
+	sentences []string
+}
+
+// NewGenerator creates a radar sentence generator with the given tracker configuration
+func NewGenerator(cfg TrackerConfig) *Generator {
+	return &Generator{tracker: NewTracker(cfg)}
+}
+
+// Generate performs a radar scan and returns OSD followed by TTM and TLL for
+// every track and the TTD sentences carrying all tracks
+func (g *Generator) Generate(fleet *simulation.Fleet, now time.Time) []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.scanned.IsZero() && now.Equal(g.scanned) {
+		return g.sentences
+	}
+
+	own := fleet.OwnShip()
+	tracks := g.tracker.Update(fleet, now)
+
+	sentences := []string{GenerateOSD(own)}
+	for _, track := range tracks {
+		sentences = append(sentences,
+			GenerateTTM(track, now),
+			GenerateTLL(track, now),
+		)
+	}
+
+	if len(tracks) > 0 {
+		sentences = append(sentences, GenerateTTD(tracks, g.seqID)...)
+		g.seqID = (g.seqID + 1) % 10
+	}
+
+	g.scanned, g.sentences = now, sentences
+	return sentences
+}
+
+// GenerateTTM generates a TTM (Tracked Target Message) sentence
+func GenerateTTM(track Track, now time.Time) string {
+	utcTime := util.FormatUTCTime(now.UTC())
+
+	sentence := fmt.Sprintf(
+		"$RATTM,%02d,%.2f,%.1f,T,%.1f,%.1f,T,%.2f,%.1f,N,,%s,,%s,A",
+		track.Number, track.Range, track.Bearing,
+		track.Speed, track.Course,
+		track.CPA, track.TCPA.Minutes(),
+		track.Status, utcTime,
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// GenerateTLL generates a TLL (Target Latitude and Longitude) sentence
+func GenerateTLL(track Track, now time.Time) string {
+	utcTime := util.FormatUTCTime(now.UTC())
+	latitude, latDirection := util.FormatLatitude(track.Latitude)
+	longitude, lonDirection := util.FormatLongitude(track.Longitude)
+
+	sentence := fmt.Sprintf(
+		"$RATLL,%02d,%s,%s,%s,%s,,%s,%s,",
+		track.Number, latitude, latDirection, longitude, lonDirection,
+		utcTime, track.Status,
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// GenerateOSD generates an OSD (Own Ship Data) sentence
+func GenerateOSD(own simulation.Vessel) string {
+	sentence := fmt.Sprintf(
+		"$RAOSD,%.1f,A,%.1f,P,%.1f,P,,,N",
+		own.Heading, own.COG, own.SOG,
+	)
+
+	return util.AppendChecksum(sentence)
+}
+
+// TTD target status codes
+const (
+	ttdNonTracking = 0
+	ttdAcquiring   = 1
+	ttdLost        = 2
+	ttdTracking    = 4
+)
+
+// tracksPerTTD is the number of 90-bit targets carried by one TTD sentence
+const tracksPerTTD = 4
+
+// GenerateTTD generates the TTD (Tracked Target Data) sentences carrying the
+// tracks in six-bit encapsulated form, four targets per sentence
+func GenerateTTD(tracks []Track, seqID int) []string {
+	total := (len(tracks) + tracksPerTTD - 1) / tracksPerTTD
+
+	sentences := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		end := (i + 1) * tracksPerTTD
+		if end > len(tracks) {
+			end = len(tracks)
+		}
+
+		var w bitWriter
+		for _, track := range tracks[i*tracksPerTTD : end] {
+			putTTDTarget(&w, track)
+		}
+		payload, fill := w.armor()
+
+		sentence := fmt.Sprintf(
+			"!RATTD,%02X,%02X,%d,%s,%d",
+			total, i+1, seqID%10, payload, fill,
+		)
+		sentences = append(sentences, util.AppendChecksum(sentence))
+	}
+
+	return sentences
+}
+
+// putTTDTarget appends the 90-bit TTD representation of a track
+func putTTDTarget(w *bitWriter, track Track) {
+	status := ttdNonTracking
+	switch track.Status {
+	case StatusAcquiring:
+		status = ttdAcquiring
+	case StatusTracking:
+		status = ttdTracking
+	case StatusLost:
+		status = ttdLost
+	}
+
+	w.putUint(0, 2) // Protocol version
+	w.putUint(uint64(track.Number), 10)
+	w.putUint(uint64(math.Round(track.Bearing*10))%3600, 12)
+	w.putUint(uint64(math.Min(math.Round(track.Speed*10), 4094)), 12)
+	w.putUint(uint64(math.Round(track.Course*10))%3600, 12)
+	w.putUint(3600, 12) // AIS heading not available
+	w.putUint(uint64(status), 3)
+	w.putUint(0, 1) // Autonomous operation
+	w.putUint(uint64(math.Min(math.Round(track.Range*100), 16383)), 14)
+	w.putUint(0, 1) // True speed and course
+	w.putUint(0, 1) // Stabilised over ground
+	w.putUint(0, 2) // Reserved
+	if track.AIS {
+		w.putUint(uint64(track.Number), 8) // Correlated with the AIS target
+	} else {
+		w.putUint(0, 8) // No correlation with AIS
+	}
+}
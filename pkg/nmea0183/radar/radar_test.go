@@ -0,0 +1,182 @@
+package radar
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+func noiselessConfig() TrackerConfig {
+	cfg := DefaultTrackerConfig()
+	cfg.RangeNoise = 0
+	cfg.BearingNoise = 0
+	cfg.LossProbability = 0
+	return cfg
+}
+
+func TestTrackerAcquisition(t *testing.T) {
+	own := simulation.Vessel{Latitude: 50, Longitude: -4, COG: 0, Heading: 0, SOG: 10}
+	near := simulation.Vessel{MMSI: 1, Latitude: 50.05, Longitude: -4, COG: 270, Heading: 270, SOG: 12}
+	far := simulation.Vessel{MMSI: 2, Latitude: 51, Longitude: -4, COG: 90, SOG: 10}
+	fleet := simulation.NewFleet(own, near, far)
+
+	tracker := NewTracker(noiselessConfig())
+	start := time.Now()
+
+	tracks := tracker.Update(fleet, start)
+	if len(tracks) != 1 {
+		t.Fatalf("Expected only the target within radar range to be tracked, got %d tracks", len(tracks))
+	}
+	if tracks[0].Status != StatusAcquiring || tracks[0].Number != 1 || tracks[0].MMSI != 1 {
+		t.Errorf("Unexpected initial track: %+v", tracks[0])
+	}
+
+	var track Track
+	for i := 1; i <= 180; i++ {
+		fleet.Step(time.Second)
+		tracks = tracker.Update(fleet, start.Add(time.Duration(i)*time.Second))
+		track = tracks[0]
+		if i < 60 && track.Status != StatusAcquiring {
+			t.Fatalf("Track established after only %d seconds", i)
+		}
+	}
+
+	if track.Status != StatusTracking {
+		t.Errorf("Expected track to be established, got status %s", track.Status)
+	}
+	if math.Abs(track.Speed-12) > 0.5 {
+		t.Errorf("Expected estimated speed near 12 knots, got %.2f", track.Speed)
+	}
+	if math.Abs(track.Course-270) > 3 {
+		t.Errorf("Expected estimated course near 270, got %.1f", track.Course)
+	}
+
+	truthCPA, truthTCPA := simulation.CPA(fleet.OwnShip(), fleet.Targets()[0])
+	if math.Abs(track.CPA-truthCPA) > 0.05 || math.Abs((track.TCPA-truthTCPA).Seconds()) > 30 {
+		t.Errorf("Tracked CPA %.3f/%s differs from ground truth %.3f/%s", track.CPA, track.TCPA, truthCPA, truthTCPA)
+	}
+}
+
+func TestTrackerLoss(t *testing.T) {
+	own := simulation.Vessel{Latitude: 50, Longitude: -4}
+	fleet := simulation.NewFleet(own, simulation.Vessel{MMSI: 7, Latitude: 50.02, Longitude: -4})
+
+	cfg := noiselessConfig()
+	tracker := NewTracker(cfg)
+	start := time.Now()
+	tracker.Update(fleet, start)
+
+	fleet.RemoveTarget(7)
+
+	if tracks := tracker.Update(fleet, start.Add(cfg.CoastTime/2)); len(tracks) != 1 || tracks[0].Status == StatusLost {
+		t.Errorf("Expected track to coast before being lost, got %+v", tracks)
+	}
+	if tracks := tracker.Update(fleet, start.Add(cfg.CoastTime)); len(tracks) != 1 || tracks[0].Status != StatusLost {
+		t.Errorf("Expected lost track, got %+v", tracks)
+	}
+	if tracks := tracker.Update(fleet, start.Add(cfg.CoastTime+cfg.LostTime)); len(tracks) != 0 {
+		t.Errorf("Expected lost track to be dropped, got %+v", tracks)
+	}
+}
+
+func TestGenerateSentences(t *testing.T) {
+	track := Track{
+		Number:    3,
+		Status:    StatusTracking,
+		Latitude:  50.1,
+		Longitude: -4.2,
+		Course:    123.4,
+		Speed:     9.8,
+		Range:     2.5,
+		Bearing:   45,
+		CPA:       0.4,
+		TCPA:      -90 * time.Second,
+	}
+	now := time.Date(2025, 4, 29, 15, 4, 5, 0, time.UTC)
+
+	ttm := GenerateTTM(track, now)
+	parts := strings.Split(strings.Split(ttm, "*")[0], ",")
+	if parts[0] != "$RATTM" || len(parts) != 16 {
+		t.Fatalf("Expected 16 fields in TTM sentence, got %d: %s", len(parts), ttm)
+	}
+	if parts[1] != "03" || parts[9] != "-1.5" || parts[12] != "T" || parts[14] != "150405.00" {
+		t.Errorf("Unexpected TTM fields: %s", ttm)
+	}
+
+	tll := GenerateTLL(track, now)
+	parts = strings.Split(strings.Split(tll, "*")[0], ",")
+	if parts[0] != "$RATLL" || len(parts) != 10 {
+		t.Fatalf("Expected 10 fields in TLL sentence, got %d: %s", len(parts), tll)
+	}
+	if parts[2] != "5006.0000" || parts[3] != "N" || parts[4] != "00412.0000" || parts[5] != "W" {
+		t.Errorf("Unexpected TLL position: %s", tll)
+	}
+
+	osd := GenerateOSD(simulation.Vessel{Heading: 10, COG: 12, SOG: 6})
+	parts = strings.Split(strings.Split(osd, "*")[0], ",")
+	if parts[0] != "$RAOSD" || len(parts) != 10 {
+		t.Errorf("Expected 10 fields in OSD sentence, got %d: %s", len(parts), osd)
+	}
+
+	tracks := make([]Track, 6)
+	ttd := GenerateTTD(tracks, 4)
+	if len(ttd) != 2 {
+		t.Fatalf("Expected 2 TTD sentences for 6 tracks, got %d", len(ttd))
+	}
+	parts = strings.Split(strings.Split(ttd[0], "*")[0], ",")
+	if parts[0] != "!RATTD" || parts[1] != "02" || parts[2] != "01" || parts[3] != "4" || len(parts[4]) != 60 || parts[5] != "0" {
+		t.Errorf("Unexpected first TTD sentence: %s", ttd[0])
+	}
+	parts = strings.Split(strings.Split(ttd[1], "*")[0], ",")
+	if len(parts[4]) != 30 {
+		t.Errorf("Expected 30 payload characters for two tracks, got %d", len(parts[4]))
+	}
+}
+
+func TestArmor(t *testing.T) {
+	var w bitWriter
+	for _, v := range []uint64{0, 39, 40, 63} {
+		w.putUint(v, 6)
+	}
+	w.putUint(1, 2)
+
+	payload, fill := w.armor()
+	if payload != "0W`w@" {
+		t.Errorf("Expected armored payload 0W`w@, got %s", payload)
+	}
+	if fill != 4 {
+		t.Errorf("Expected 4 fill bits, got %d", fill)
+	}
+}
+
+func TestTTDCorrelation(t *testing.T) {
+	// The correlation number is the last of a target's 90 bits, 15 characters
+	correlation := func(track Track) string {
+		payload := strings.Split(GenerateTTD([]Track{track}, 0)[0], ",")[4]
+		return payload[len(payload)-2:]
+	}
+
+	if got := correlation(Track{Number: 5}); got != "00" {
+		t.Errorf("Expected no AIS correlation, got %s", got)
+	}
+	if got := correlation(Track{Number: 5, AIS: true}); got != "05" {
+		t.Errorf("Expected AIS correlation number 5, got %s", got)
+	}
+}
+
+func TestGeneratorShared(t *testing.T) {
+	own := simulation.Vessel{Latitude: 50, Longitude: -4}
+	fleet := simulation.NewFleet(own, simulation.Vessel{MMSI: 1, Latitude: 50.05, Longitude: -4, SOG: 10})
+	generator := NewGenerator(DefaultTrackerConfig())
+	now := time.Now()
+
+	// Outputs sending the same update get the same scan
+	first := generator.Generate(fleet, now)
+	second := generator.Generate(fleet, now)
+	if strings.Join(first, "") != strings.Join(second, "") {
+		t.Errorf("Expected the same scan for the same update, got %v and %v", first, second)
+	}
+}
@@ -0,0 +1,230 @@
+package radar
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// TrackStatus is the ARPA state of a tracked target
+type TrackStatus string
+
+// Track states as reported in TTM and TLL
+const (
+	StatusAcquiring TrackStatus = "Q"
+	StatusTracking  TrackStatus = "T"
+	StatusLost      TrackStatus = "L"
+)
+
+// TrackerConfig holds the radar and tracking filter parameters
+type TrackerConfig struct {
+	Range           float64       // Maximum detection range in nautical miles
+	RangeNoise      float64       // Standard deviation of range measurements in nautical miles
+	BearingNoise    float64       // Standard deviation of bearing measurements in degrees
+	AcquireTime     time.Duration // Time from first detection until the track is established
+	CoastTime       time.Duration // Time without detections before a track is declared lost
+	LostTime        time.Duration // Time a lost track is reported before being dropped
+	LossProbability float64       // Probability of missing a target on each scan
+	Alpha           float64       // Alpha-beta filter position gain
+	Beta            float64       // Alpha-beta filter velocity gain
+	AIS             bool          // The targets also report by AIS, correlating their tracks
+}
+
+// DefaultTrackerConfig returns tracking parameters typical of a small-craft ARPA radar
+func DefaultTrackerConfig() TrackerConfig {
+	return TrackerConfig{
+		Range:           12,
+		RangeNoise:      0.01,
+		BearingNoise:    0.5,
+		AcquireTime:     time.Minute,
+		CoastTime:       10 * time.Second,
+		LostTime:        30 * time.Second,
+		LossProbability: 0.01,
+		Alpha:           0.2,
+		Beta:            0.01,
+	}
+}
+
+// Track is the tracker's estimate of a target
+type Track struct {
+	Number    int
+	MMSI      uint32 // Simulated target behind the track, not known to a real radar
+	Status    TrackStatus
+	AIS       bool    // Correlated with the target's AIS reports
+	Latitude  float64 // Estimated position, degrees
+	Longitude float64
+	Course    float64 // Estimated course over ground, degrees true
+	Speed     float64 // Estimated speed over ground, knots
+	Range     float64 // Measured range from own ship, nautical miles
+	Bearing   float64 // Measured bearing from own ship, degrees true
+	CPA       float64 // Nautical miles, from the estimated motion
+	TCPA      time.Duration
+
+	acquired time.Time
+	lastSeen time.Time
+	updated  time.Time
+	x, y     float64 // Estimated position in the tracker's local frame, nautical miles
+	vx, vy   float64 // Estimated velocity, knots
+}
+
+// Tracker simulates ARPA target acquisition and tracking of the fleet's targets
+type Tracker struct {
+	config   TrackerConfig
+	tracks   map[uint32]*Track
+	next     int
+	originLa float64
+	originLo float64
+}
+
+// NewTracker creates a tracker with the given configuration.
+// A zero configuration selects DefaultTrackerConfig.
+func NewTracker(cfg TrackerConfig) *Tracker {
+	if cfg == (TrackerConfig{}) {
+		cfg = DefaultTrackerConfig()
+	}
+	return &Tracker{
+		config: cfg,
+		tracks: make(map[uint32]*Track),
+		next:   1,
+	}
+}
+
+// Update performs a radar scan of the fleet and returns the current tracks ordered by target number
+func (t *Tracker) Update(fleet *simulation.Fleet, now time.Time) []Track {
+	own := fleet.OwnShip()
+	if len(t.tracks) == 0 {
+		t.originLa, t.originLo = own.Latitude, own.Longitude
+	}
+
+	seen := make(map[uint32]bool)
+	for _, target := range fleet.Targets() {
+		bearing, distance := simulation.BearingDistance(own.Latitude, own.Longitude, target.Latitude, target.Longitude)
+		if distance > t.config.Range || util.RandomFloat(0, 1) < t.config.LossProbability {
+			continue
+		}
+		seen[target.MMSI] = true
+
+		// Noisy polar measurement converted into the local frame
+		measuredRange := math.Max(0, util.RandomNormal(distance, t.config.RangeNoise))
+		measuredBearing := simulation.NormalizeDegrees(util.RandomNormal(bearing, t.config.BearingNoise))
+		lat, lon := simulation.Destination(own.Latitude, own.Longitude, measuredBearing, measuredRange)
+		mx, my := t.local(lat, lon)
+
+		track, ok := t.tracks[target.MMSI]
+		if !ok || track.Status == StatusLost {
+			track = &Track{
+				Number:   t.nextNumber(),
+				MMSI:     target.MMSI,
+				Status:   StatusAcquiring,
+				AIS:      t.config.AIS,
+				acquired: now,
+				updated:  now,
+				x:        mx,
+				y:        my,
+			}
+			t.tracks[target.MMSI] = track
+		} else {
+			t.filter(track, mx, my, now)
+		}
+
+		track.lastSeen = now
+		track.Range = measuredRange
+		track.Bearing = measuredBearing
+		if track.Status == StatusAcquiring && now.Sub(track.acquired) >= t.config.AcquireTime {
+			track.Status = StatusTracking
+		}
+	}
+
+	// Tracks that are not detected coast on their estimated motion, are then
+	// reported as lost and are finally dropped
+	for mmsi, track := range t.tracks {
+		if seen[mmsi] {
+			continue
+		}
+		missing := now.Sub(track.lastSeen)
+		switch {
+		case missing >= t.config.CoastTime+t.config.LostTime:
+			delete(t.tracks, mmsi)
+		case missing >= t.config.CoastTime:
+			track.Status = StatusLost
+		}
+	}
+
+	tracks := make([]Track, 0, len(t.tracks))
+	for _, track := range t.tracks {
+		t.derive(track, own, now)
+		tracks = append(tracks, *track)
+	}
+	sort.Slice(tracks, func(i, j int) bool { return tracks[i].Number < tracks[j].Number })
+	return tracks
+}
+
+// filter applies an alpha-beta filter update with a new position measurement
+func (t *Tracker) filter(track *Track, mx, my float64, now time.Time) {
+	dt := now.Sub(track.updated).Hours()
+	track.updated = now
+	if dt <= 0 {
+		return
+	}
+
+	px, py := track.x+track.vx*dt, track.y+track.vy*dt
+	rx, ry := mx-px, my-py
+
+	track.x = px + t.config.Alpha*rx
+	track.y = py + t.config.Alpha*ry
+	track.vx += t.config.Beta * rx / dt
+	track.vy += t.config.Beta * ry / dt
+}
+
+// derive computes the reported position, course, speed and CPA of a track from
+// its filter state, extrapolating coasting tracks to the current time
+func (t *Tracker) derive(track *Track, own simulation.Vessel, now time.Time) {
+	dt := now.Sub(track.updated).Hours()
+	track.Latitude, track.Longitude = t.global(track.x+track.vx*dt, track.y+track.vy*dt)
+	track.Speed = math.Hypot(track.vx, track.vy)
+	track.Course = simulation.NormalizeDegrees(math.Atan2(track.vx, track.vy) * 180 / math.Pi)
+
+	estimate := simulation.Vessel{
+		Latitude:  track.Latitude,
+		Longitude: track.Longitude,
+		COG:       track.Course,
+		SOG:       track.Speed,
+	}
+	track.CPA, track.TCPA = simulation.CPA(own, estimate)
+}
+
+// nextNumber returns the next free target number in the range 1-99
+func (t *Tracker) nextNumber() int {
+	for {
+		n := t.next
+		t.next = t.next%99 + 1
+
+		inUse := false
+		for _, track := range t.tracks {
+			if track.Number == n {
+				inUse = true
+				break
+			}
+		}
+		if !inUse || len(t.tracks) >= 99 {
+			return n
+		}
+	}
+}
+
+// local converts a position into the tracker's flat local frame in nautical miles
+func (t *Tracker) local(lat, lon float64) (float64, float64) {
+	x := (lon - t.originLo) * 60 * math.Cos(t.originLa*math.Pi/180)
+	y := (lat - t.originLa) * 60
+	return x, y
+}
+
+// global converts a position in the local frame back into degrees
+func (t *Tracker) global(x, y float64) (float64, float64) {
+	lat := t.originLa + y/60
+	lon := t.originLo + x/(60*math.Cos(t.originLa*math.Pi/180))
+	return lat, lon
+}
@@ -0,0 +1,195 @@
+// Package talker rewrites the talker ID of generated NMEA 0183 sentences
+package talker
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+)
+
+// Devices maps simulated device names to the sentence formatters they produce
+var Devices = map[string][]string{
+	"gnss":        {"GGA", "GLL", "GNS", "GST", "RMC", "VTG", "XTE", "ZDA"},
+	"heading":     {"HDG", "HDM", "HDT", "ROT", "THS"},
+	"depth":       {"DBT", "DPT"},
+	"wind":        {"MWD", "MWV", "VWR", "VWT"},
+	"log":         {"VBW", "VHW"},
+	"temperature": {"MTW"},
+	"weather":     {"MDA", "XDR"},
+	"propulsion":  {"RPM"},
+	"radar":       {"OSD", "TLL", "TTD", "TTM"},
+	"rudder":      {"RSA"},
+	"ais":         {"VDM", "VDO"},
+}
+
+// multipart holds the formatters of messages split over several sentences
+var multipart = map[string]bool{"GSV": true, "TTD": true, "VDM": true, "VDO": true}
+
+// Config maps sentence formatters to the talker IDs they are emitted with.
+// A formatter with several talkers is emitted once per talker, mimicking
+// redundant sensors.
+type Config map[string][]string
+
+// Parse parses a talker specification such as "GGA=GN,heading=HC+HE,depth=SD".
+// Keys are sentence formatters or device names from Devices; a formatter key
+// takes precedence over the device it belongs to regardless of order.
+func Parse(spec string) (Config, error) {
+	cfg := Config{}
+	if strings.TrimSpace(spec) == "" {
+		return cfg, nil
+	}
+
+	deviceTalkers := map[string][]string{}
+	for _, entry := range strings.Split(spec, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || key == "" || value == "" {
+			return nil, fmt.Errorf("invalid talker entry %q: expected key=talker[+talker]", entry)
+		}
+
+		talkers := strings.Split(strings.ToUpper(value), "+")
+		for _, t := range talkers {
+			if len(t) != 2 {
+				return nil, fmt.Errorf("invalid talker ID %q: must be two characters", t)
+			}
+		}
+
+		if formatters, ok := Devices[strings.ToLower(key)]; ok {
+			for _, f := range formatters {
+				deviceTalkers[f] = talkers
+			}
+			continue
+		}
+
+		key = strings.ToUpper(key)
+		if len(key) != 3 {
+			return nil, fmt.Errorf("unknown sentence or device %q", key)
+		}
+		cfg[key] = talkers
+	}
+
+	for f, talkers := range deviceTalkers {
+		if _, ok := cfg[f]; !ok {
+			cfg[f] = talkers
+		}
+	}
+
+	return cfg, nil
+}
+
+// String formats the configuration in the form accepted by Parse
+func (c Config) String() string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	entries := make([]string, len(keys))
+	for i, k := range keys {
+		entries[i] = k + "=" + strings.Join(c[k], "+")
+	}
+	return strings.Join(entries, ",")
+}
+
+// Apply rewrites the talker IDs of the given sentences. Sentences without a
+// configured formatter and proprietary sentences are passed through unchanged.
+// A message split over several sentences is emitted whole once per talker,
+// each talker's copy with its own sequential message ID.
+func (c Config) Apply(sentences []string) []string {
+	if len(c) == 0 {
+		return sentences
+	}
+
+	out := make([]string, 0, len(sentences))
+	for i := 0; i < len(sentences); {
+		talkers, ok := c[formatter(sentences[i])]
+		if !ok {
+			out = append(out, sentences[i])
+			i++
+			continue
+		}
+
+		group := sentences[i : i+groupLen(sentences[i:])]
+		for k, t := range talkers {
+			for _, s := range group {
+				out = append(out, Replace(offsetSeqID(s, k), t))
+			}
+		}
+		i += len(group)
+	}
+	return out
+}
+
+// Groups splits sentences into messages, keeping the sentences of a message
+// split over several sentences together
+func Groups(sentences []string) [][]string {
+	var groups [][]string
+	for i := 0; i < len(sentences); {
+		n := groupLen(sentences[i:])
+		groups = append(groups, sentences[i:i+n])
+		i += n
+	}
+	return groups
+}
+
+// groupLen returns the number of sentences of the message at the start of
+// sentences: its total for the first sentence of a multi-sentence message,
+// otherwise 1. Counts are read as hexadecimal, as TTD sends them, which reads
+// decimal counts below ten alike.
+func groupLen(sentences []string) int {
+	if !multipart[formatter(sentences[0])] {
+		return 1
+	}
+	body, _, _ := strings.Cut(sentences[0], "*")
+	fields := strings.Split(body, ",")
+	if len(fields) < 3 {
+		return 1
+	}
+	total, err := strconv.ParseUint(fields[1], 16, 8)
+	if err != nil || total < 2 || int(total) > len(sentences) {
+		return 1
+	}
+	if number, err := strconv.ParseUint(fields[2], 16, 8); err != nil || number != 1 {
+		return 1
+	}
+	return int(total)
+}
+
+// offsetSeqID returns an encapsulated sentence with offset added to its
+// sequential message ID, which is empty for single-sentence messages
+func offsetSeqID(sentence string, offset int) string {
+	if offset == 0 || sentence[0] != '!' {
+		return sentence
+	}
+	body, _, _ := strings.Cut(sentence, "*")
+	fields := strings.Split(body, ",")
+	if len(fields) < 4 {
+		return sentence
+	}
+	id, err := strconv.Atoi(fields[3])
+	if err != nil {
+		return sentence
+	}
+	fields[3] = strconv.Itoa((id + offset) % 10)
+	return util.AppendChecksum(strings.Join(fields, ","))
+}
+
+// Replace returns the sentence with its talker ID replaced and the checksum recomputed
+func Replace(sentence, talker string) string {
+	if len(sentence) < 3 || len(talker) != 2 || sentence[1] == 'P' {
+		return sentence
+	}
+	body, _, _ := strings.Cut(sentence, "*")
+	return util.AppendChecksum(body[:1] + talker + body[3:])
+}
+
+// formatter returns the three character sentence formatter, or "" for proprietary sentences
+func formatter(sentence string) string {
+	if len(sentence) < 6 || sentence[1] == 'P' {
+		return ""
+	}
+	return sentence[3:6]
+}
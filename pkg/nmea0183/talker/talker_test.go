@@ -0,0 +1,107 @@
+package talker
+
+import (
+	"testing"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+)
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse("HDT=HE, heading=HC, GGA=gn, depth=SD+II")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if got := cfg.String(); got != "DBT=SD+II,DPT=SD+II,GGA=GN,HDG=HC,HDM=HC,HDT=HE,ROT=HC,THS=HC" {
+		t.Errorf("Unexpected config %q", got)
+	}
+
+	for _, spec := range []string{"GGA", "GGA=G", "GGAX=GP", "engine=EI", "GGA=GN+"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	cfg := Config{"HDT": {"HC", "HE"}, "VDM": {"BS"}}
+	in := []string{
+		util.AppendChecksum("$HEHDT,45.0,T"),
+		util.AppendChecksum("$IIDBT,32.8,f,10.0,M,5.5,F"),
+		util.AppendChecksum("!AIVDM,1,1,,A,13u?etPv2;0n:dDPwUM1U1Cb069D,0"),
+		util.AppendChecksum("$PNMEA2K,127250,8,FF"),
+	}
+
+	out := cfg.Apply(in)
+	want := []string{
+		util.AppendChecksum("$HCHDT,45.0,T"),
+		util.AppendChecksum("$HEHDT,45.0,T"),
+		in[1],
+		util.AppendChecksum("!BSVDM,1,1,,A,13u?etPv2;0n:dDPwUM1U1Cb069D,0"),
+		in[3],
+	}
+
+	if len(out) != len(want) {
+		t.Fatalf("Expected %d sentences, got %d: %v", len(want), len(out), out)
+	}
+	for i := range want {
+		if out[i] != want[i] {
+			t.Errorf("Sentence %d: expected %s, got %s", i, want[i], out[i])
+		}
+	}
+}
+
+func TestApplyMultipart(t *testing.T) {
+	cfg := Config{"VDM": {"AI", "AB"}, "GSV": {"GP", "GN"}}
+	in := []string{
+		util.AppendChecksum("!AIVDM,2,1,3,A,55NBsv02>tk0,0"),
+		util.AppendChecksum("!AIVDM,2,2,3,A,88888888880,2"),
+		util.AppendChecksum("$GPGSV,2,1,05,01,40,083,46"),
+		util.AppendChecksum("$GPGSV,2,2,05,02,17,308,41"),
+	}
+
+	// Each talker's copy is sent whole, the copies of encapsulated messages
+	// with their own sequential message ID
+	want := []string{
+		in[0],
+		in[1],
+		util.AppendChecksum("!ABVDM,2,1,4,A,55NBsv02>tk0,0"),
+		util.AppendChecksum("!ABVDM,2,2,4,A,88888888880,2"),
+		in[2],
+		in[3],
+		util.AppendChecksum("$GNGSV,2,1,05,01,40,083,46"),
+		util.AppendChecksum("$GNGSV,2,2,05,02,17,308,41"),
+	}
+
+	out := cfg.Apply(in)
+	if len(out) != len(want) {
+		t.Fatalf("Expected %d sentences, got %d: %v", len(want), len(out), out)
+	}
+	for i := range want {
+		if out[i] != want[i] {
+			t.Errorf("Sentence %d: expected %s, got %s", i, want[i], out[i])
+		}
+	}
+}
+
+func TestGroups(t *testing.T) {
+	in := []string{
+		util.AppendChecksum("$GPGGA,120000.00,,,,,0,00,,,M,,M,,"),
+		util.AppendChecksum("$GPGSV,2,1,05,01,40,083,46"),
+		util.AppendChecksum("$GPGSV,2,2,05,02,17,308,41"),
+		util.AppendChecksum("!AIVDM,1,1,,A,13u?etPv2;0n:dDPwUM1U1Cb069D,0"),
+		util.AppendChecksum("!AIVDM,2,1,3,A,55NBsv02>tk0,0"),
+		util.AppendChecksum("!AIVDM,2,2,3,A,88888888880,2"),
+	}
+
+	groups := Groups(in)
+	want := []int{1, 2, 1, 2}
+	if len(groups) != len(want) {
+		t.Fatalf("Expected %d messages, got %v", len(want), groups)
+	}
+	for i, n := range want {
+		if len(groups[i]) != n {
+			t.Errorf("Message %d: expected %d sentences, got %v", i, n, groups[i])
+		}
+	}
+}
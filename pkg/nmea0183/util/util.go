@@ -3,7 +3,10 @@ package util
 
 import (
 	"fmt"
+	"math"
 	"math/rand"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,15 +25,54 @@ func RandomInt(min, max int) int {
 	return min + rand.Intn(max-min+1)
 }
 
+// RandomNormal generates a normally distributed float64 with the given mean and standard deviation
+func RandomNormal(mean, stddev float64) float64 {
+	return mean + rand.NormFloat64()*stddev
+}
+
+// FormatLatitude formats decimal degrees as NMEA latitude (ddmm.mmmm) and hemisphere
+func FormatLatitude(lat float64) (string, string) {
+	hemisphere := "N"
+	if lat < 0 {
+		hemisphere = "S"
+		lat = -lat
+	}
+	deg, min := splitDegrees(lat)
+	return fmt.Sprintf("%02d%07.4f", deg, min), hemisphere
+}
+
+// FormatLongitude formats decimal degrees as NMEA longitude (dddmm.mmmm) and hemisphere
+func FormatLongitude(lon float64) (string, string) {
+	hemisphere := "E"
+	if lon < 0 {
+		hemisphere = "W"
+		lon = -lon
+	}
+	deg, min := splitDegrees(lon)
+	return fmt.Sprintf("%03d%07.4f", deg, min), hemisphere
+}
+
+// splitDegrees splits decimal degrees into whole degrees and minutes rounded to 4 decimals
+func splitDegrees(v float64) (int, float64) {
+	deg := math.Floor(v)
+	min := math.Round((v-deg)*60*10000) / 10000
+	if min >= 60 {
+		deg++
+		min -= 60
+	}
+	return int(deg), min
+}
+
 // AppendChecksum calculates and appends the checksum to an NMEA sentence.
-// The checksum is calculated by XOR'ing all characters between $ and * (exclusive).
+// The checksum is calculated by XOR'ing all characters between $ (or ! for
+// encapsulated sentences such as AIS) and * (exclusive).
 func AppendChecksum(sentence string) string {
 	var checksum uint8
 	var i int
 
-	// Find the start of the sentence (after $)
+	// Find the start of the sentence (after $ or !)
 	for i = 0; i < len(sentence); i++ {
-		if sentence[i] == '$' {
+		if sentence[i] == '$' || sentence[i] == '!' {
 			i++
 			break
 		}
@@ -44,3 +86,96 @@ func AppendChecksum(sentence string) string {
 	// Format the checksum as a two-character uppercase hexadecimal
 	return fmt.Sprintf("%s*%02X", sentence, checksum)
 }
+
+// Sentence is a parsed NMEA 0183 sentence
+type Sentence struct {
+	Talker    string   // Talker ID, or "P" for proprietary sentences
+	Formatter string   // Sentence formatter, or the manufacturer code and type of proprietary sentences
+	Fields    []string // Data fields after the address field
+}
+
+// ParseSentence parses a sentence, verifying its checksum
+func ParseSentence(line string) (Sentence, error) {
+	line = strings.TrimSpace(line)
+	if len(line) == 0 || (line[0] != '$' && line[0] != '!') {
+		return Sentence{}, fmt.Errorf("sentence must start with $ or !")
+	}
+
+	star := strings.LastIndexByte(line, '*')
+	if star < 0 || len(line)-star != 3 {
+		return Sentence{}, fmt.Errorf("missing checksum")
+	}
+	want, err := strconv.ParseUint(line[star+1:], 16, 8)
+	if err != nil {
+		return Sentence{}, fmt.Errorf("invalid checksum %q", line[star+1:])
+	}
+	if expected := AppendChecksum(line[:star]); !strings.EqualFold(expected[len(expected)-2:], line[star+1:]) {
+		return Sentence{}, fmt.Errorf("checksum mismatch: got %02X, expected %s", want, expected[len(expected)-2:])
+	}
+
+	parts := strings.Split(line[1:star], ",")
+	address := parts[0]
+	switch {
+	case strings.HasPrefix(address, "P") && len(address) > 1:
+		return Sentence{Talker: "P", Formatter: address[1:], Fields: parts[1:]}, nil
+	case len(address) == 5:
+		return Sentence{Talker: address[:2], Formatter: address[2:], Fields: parts[1:]}, nil
+	}
+	return Sentence{}, fmt.Errorf("invalid address field %q", address)
+}
+
+// Field returns data field i, or an empty string when the sentence is shorter
+func (s Sentence) Field(i int) string {
+	if i < 0 || i >= len(s.Fields) {
+		return ""
+	}
+	return s.Fields[i]
+}
+
+// Float returns data field i as a number, NaN when it is empty
+func (s Sentence) Float(i int) (float64, error) {
+	f := s.Field(i)
+	if f == "" {
+		return math.NaN(), nil
+	}
+	v, err := strconv.ParseFloat(f, 64)
+	if err != nil {
+		return math.NaN(), fmt.Errorf("%s field %d: invalid number %q", s.Formatter, i+1, f)
+	}
+	return v, nil
+}
+
+// LatLon returns the position in fields i to i+3 (ddmm.mm,N,dddmm.mm,E) in
+// decimal degrees
+func (s Sentence) LatLon(i int) (float64, float64, error) {
+	lat, err := s.coordinate(i, "N", "S")
+	if err != nil {
+		return 0, 0, err
+	}
+	lon, err := s.coordinate(i+2, "E", "W")
+	if err != nil {
+		return 0, 0, err
+	}
+	return lat, lon, nil
+}
+
+// coordinate returns the degrees and minutes in field i with the hemisphere
+// in field i+1 as decimal degrees, negative in the neg hemisphere
+func (s Sentence) coordinate(i int, pos, neg string) (float64, error) {
+	v, err := s.Float(i)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(v) {
+		return 0, fmt.Errorf("%s field %d: missing coordinate", s.Formatter, i+1)
+	}
+	deg := math.Floor(v/100) + math.Mod(v, 100)/60
+
+	switch s.Field(i + 1) {
+	case pos:
+		return deg, nil
+	case neg:
+		return -deg, nil
+	}
+	return 0, fmt.Errorf("%s field %d: invalid hemisphere %q", s.Formatter, i+2, s.Field(i+1))
+}
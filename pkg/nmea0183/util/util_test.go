@@ -1,6 +1,7 @@
 package util
 
 import (
+	"math"
 	"strings"
 	"testing"
 	"time"
@@ -62,6 +63,11 @@ func TestAppendChecksum(t *testing.T) {
 			input:    "$GPGGA,,,,,,,,,,,,,,,",
 			expected: "$GPGGA,,,,,,,,,,,,,,,*7A",
 		},
+		{
+			name:     "Encapsulated sentence",
+			input:    "!AIVDM,1,1,,A,13aEOK?P00PD2wVMdLDRhgvL289?,0",
+			expected: "!AIVDM,1,1,,A,13aEOK?P00PD2wVMdLDRhgvL289?,0*26",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -119,3 +125,79 @@ func TestRandomIntDistribution(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatLatLon(t *testing.T) {
+	testCases := []struct {
+		value      float64
+		latitude   bool
+		expected   string
+		hemisphere string
+	}{
+		{48.196077, true, "4811.7646", "N"},
+		{-33.5, true, "3330.0000", "S"},
+		{16.358193, false, "01621.4916", "E"},
+		{-122.345833, false, "12220.7500", "W"},
+		{9.9999999, false, "01000.0000", "E"},
+	}
+
+	for _, tc := range testCases {
+		var value, hemisphere string
+		if tc.latitude {
+			value, hemisphere = FormatLatitude(tc.value)
+		} else {
+			value, hemisphere = FormatLongitude(tc.value)
+		}
+		if value != tc.expected || hemisphere != tc.hemisphere {
+			t.Errorf("Formatting %f: expected %s,%s got %s,%s", tc.value, tc.expected, tc.hemisphere, value, hemisphere)
+		}
+	}
+}
+
+func TestParseSentence(t *testing.T) {
+	s, err := ParseSentence(AppendChecksum("$GPHSC,090.0,T,085.2,M") + "\r\n")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if s.Talker != "GP" || s.Formatter != "HSC" || len(s.Fields) != 4 {
+		t.Errorf("Unexpected sentence %+v", s)
+	}
+	if v, err := s.Float(0); err != nil || v != 90 {
+		t.Errorf("Expected 90, got %v (%v)", v, err)
+	}
+	if v, err := s.Float(7); err != nil || !math.IsNaN(v) {
+		t.Errorf("Expected NaN for a missing field, got %v (%v)", v, err)
+	}
+
+	if p, err := ParseSentence(AppendChecksum("$PRDID,1.0,2.0,090.0")); err != nil || p.Talker != "P" || p.Formatter != "RDID" {
+		t.Errorf("Unexpected proprietary sentence %+v (%v)", p, err)
+	}
+
+	for _, line := range []string{
+		"GPHSC,090.0,T,085.2,M*00",
+		"$GPHSC,090.0,T,085.2,M",
+		"$GPHSC,090.0,T,085.2,M*00",
+		AppendChecksum("$GPHSCX,090.0,T"),
+	} {
+		if _, err := ParseSentence(line); err == nil {
+			t.Errorf("Expected an error for %q", line)
+		}
+	}
+}
+
+func TestSentenceLatLon(t *testing.T) {
+	s, err := ParseSentence(AppendChecksum("$GPWPL,4811.7646,N,01621.4916,W,HOME"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lat, lon, err := s.LatLon(0)
+	if err != nil || math.Abs(lat-48.196077) > 1e-6 || math.Abs(lon+16.358193) > 1e-6 {
+		t.Errorf("Expected 48.196077 -16.358193, got %f %f (%v)", lat, lon, err)
+	}
+
+	for _, line := range []string{"$GPWPL,,N,01621.4916,E,HOME", "$GPWPL,4811.7646,X,01621.4916,E,HOME"} {
+		s, _ := ParseSentence(AppendChecksum(line))
+		if _, _, err := s.LatLon(0); err == nil {
+			t.Errorf("Expected an error for %q", line)
+		}
+	}
+}
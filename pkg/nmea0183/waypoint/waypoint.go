@@ -0,0 +1,106 @@
+// Package waypoint loads waypoints and routes from NMEA-0183 WPL and RTE
+// sentences
+package waypoint
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// Loader stores the waypoints of WPL sentences and the routes of RTE
+// sentences, assembling routes sent over several sentences
+type Loader struct {
+	store *simulation.Waypoints
+
+	mu      sync.Mutex
+	partial map[string]*partialRoute // Routes by name waiting for their remaining sentences
+}
+
+// partialRoute is a route waiting for its remaining RTE sentences
+type partialRoute struct {
+	total     int
+	next      int
+	waypoints []string
+}
+
+// NewLoader creates a loader adding waypoints and routes to the store
+func NewLoader(store *simulation.Waypoints) *Loader {
+	return &Loader{store: store, partial: make(map[string]*partialRoute)}
+}
+
+// Load stores the waypoint of a WPL sentence or the route of the last RTE
+// sentence of a route. It reports whether the sentence is a WPL or RTE sentence.
+func (l *Loader) Load(s util.Sentence) (bool, error) {
+	switch s.Formatter {
+	case "WPL":
+		wp, err := ParseWPL(s)
+		if err != nil {
+			return true, err
+		}
+		l.store.SetWaypoint(wp)
+		return true, nil
+	case "RTE":
+		return true, l.loadRTE(s)
+	}
+	return false, nil
+}
+
+// ParseWPL returns the waypoint of a WPL sentence
+func ParseWPL(s util.Sentence) (simulation.Waypoint, error) {
+	lat, lon, err := s.LatLon(0)
+	if err != nil {
+		return simulation.Waypoint{}, err
+	}
+	name := s.Field(4)
+	if name == "" {
+		return simulation.Waypoint{}, fmt.Errorf("WPL without a waypoint name")
+	}
+	return simulation.Waypoint{Name: name, Latitude: lat, Longitude: lon}, nil
+}
+
+// loadRTE adds the waypoints of an RTE sentence to its route, storing the
+// route once its last sentence has arrived. Sentences arriving out of order
+// drop the route.
+func (l *Loader) loadRTE(s util.Sentence) error {
+	total, err := strconv.Atoi(s.Field(0))
+	if err != nil || total < 1 {
+		return fmt.Errorf("RTE field 1: invalid number of sentences %q", s.Field(0))
+	}
+	number, err := strconv.Atoi(s.Field(1))
+	if err != nil || number < 1 || number > total {
+		return fmt.Errorf("RTE field 2: invalid sentence number %q", s.Field(1))
+	}
+	if mode := s.Field(2); mode != "c" && mode != "w" {
+		return fmt.Errorf("RTE field 3: invalid route mode %q", mode)
+	}
+	name := s.Field(3)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	p := l.partial[name]
+	if number == 1 {
+		p = &partialRoute{total: total, next: 1}
+		l.partial[name] = p
+	}
+	if p == nil || p.total != total || p.next != number {
+		delete(l.partial, name)
+		return fmt.Errorf("RTE sentence %d of %d for route %q out of order", number, total, name)
+	}
+
+	for _, wp := range s.Fields[4:] {
+		if wp != "" {
+			p.waypoints = append(p.waypoints, wp)
+		}
+	}
+	p.next++
+	if number == total {
+		delete(l.partial, name)
+		l.store.SetRoute(simulation.Route{Name: name, Waypoints: p.waypoints})
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package waypoint
+
+import (
+	"math"
+	"testing"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+func parse(t *testing.T, sentence string) util.Sentence {
+	t.Helper()
+	s, err := util.ParseSentence(util.AppendChecksum(sentence))
+	if err != nil {
+		t.Fatalf("Failed to parse %s: %v", sentence, err)
+	}
+	return s
+}
+
+func TestParseWPL(t *testing.T) {
+	wp, err := ParseWPL(parse(t, "$GPWPL,4917.16,N,12310.64,W,003"))
+	if err != nil {
+		t.Fatalf("Failed to parse WPL: %v", err)
+	}
+	if wp.Name != "003" || math.Abs(wp.Latitude-49.286) > 1e-6 || math.Abs(wp.Longitude+123.1773333) > 1e-6 {
+		t.Errorf("Unexpected waypoint %+v", wp)
+	}
+
+	for _, sentence := range []string{"$GPWPL,4917.16,N,12310.64,W,", "$GPWPL,,N,12310.64,W,003"} {
+		if _, err := ParseWPL(parse(t, sentence)); err == nil {
+			t.Errorf("Expected %s to be rejected", sentence)
+		}
+	}
+}
+
+func TestLoader(t *testing.T) {
+	store := simulation.NewWaypoints()
+	l := NewLoader(store)
+
+	tests := []struct {
+		sentence string
+		ok       bool
+		err      bool
+	}{
+		{"$GPWPL,4917.16,N,12310.64,W,003", true, false},
+		{"$GPRTE,2,1,c,0,PBRCPK,PBRTO,PTELGR,PPLAND", true, false},
+		{"$GPRTE,2,2,c,0,PYAMBU,PPFAIR,PWARRN,,", true, false},
+		{"$GPRTE,2,2,c,1,PBRCPK", true, true},
+		{"$GPRTE,1,1,x,1,PBRCPK", true, true},
+		{"$GPGGA,", false, false},
+	}
+	for _, tt := range tests {
+		ok, err := l.Load(parse(t, tt.sentence))
+		if ok != tt.ok || (err != nil) != tt.err {
+			t.Errorf("Load(%s) = %v, %v", tt.sentence, ok, err)
+		}
+	}
+
+	if _, ok := store.Waypoint("003"); !ok {
+		t.Error("Expected waypoint 003 to be stored")
+	}
+	r, ok := store.Route("0")
+	if !ok || len(r.Waypoints) != 7 || r.Waypoints[0] != "PBRCPK" || r.Waypoints[6] != "PWARRN" {
+		t.Errorf("Expected route 0 over two sentences, got %+v", r)
+	}
+	if _, ok := store.Route("1"); ok {
+		t.Error("Expected the out of order route not to be stored")
+	}
+}
@@ -0,0 +1,150 @@
+package nmea2000
+
+import (
+	"math"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/ais"
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// knotsToMS converts knots to meters per second
+const knotsToMS = 1852.0 / 3600.0
+
+// aisSchedule tracks when each vessel's AIS PGNs were last sent, using the
+// same reporting intervals as the NMEA 0183 AIS generator
+type aisSchedule struct {
+	lastPosition map[uint32]time.Time
+	lastStatic   map[uint32]time.Time
+}
+
+func newAISSchedule() *aisSchedule {
+	return &aisSchedule{
+		lastPosition: make(map[uint32]time.Time),
+		lastStatic:   make(map[uint32]time.Time),
+	}
+}
+
+// messages returns the AIS PGNs due at the given time for own ship and every
+// Class A and Class B target in the fleet
+func (a *aisSchedule) messages(fleet *simulation.Fleet, now time.Time) []pgn.Message {
+	var msgs []pgn.Message
+
+	msgs = append(msgs, a.due(fleet.OwnShip(), pgn.AISOwnInformation, now)...)
+	for i, target := range fleet.Targets() {
+		msgs = append(msgs, a.due(target, uint8(i%2), now)...)
+	}
+
+	return msgs
+}
+
+func (a *aisSchedule) due(v simulation.Vessel, transceiver uint8, now time.Time) []pgn.Message {
+	if v.Class == simulation.AidToNavigation {
+		return nil
+	}
+
+	var msgs []pgn.Message
+	if now.Sub(a.lastPosition[v.MMSI]) >= ais.PositionInterval(v) {
+		a.lastPosition[v.MMSI] = now
+		msgs = append(msgs, AISPosition(v, transceiver, now))
+	}
+	if now.Sub(a.lastStatic[v.MMSI]) >= ais.StaticInterval {
+		a.lastStatic[v.MMSI] = now
+		msgs = append(msgs, AISStatic(v, transceiver, now)...)
+	}
+
+	return msgs
+}
+
+// AISPosition returns PGN 129038 for Class A vessels or PGN 129039 for Class B vessels
+func AISPosition(v simulation.Vessel, transceiver uint8, now time.Time) pgn.Message {
+	if v.Class == simulation.ClassB {
+		return pgn.Message{
+			PGN: 129039,
+			Data: pgn.EncodeAISClassBPosition(pgn.AISClassBPosition{
+				MMSI:        v.MMSI,
+				Longitude:   v.Longitude,
+				Latitude:    v.Latitude,
+				Second:      uint8(now.UTC().Second()),
+				COG:         degToRad(v.COG),
+				SOG:         v.SOG * knotsToMS,
+				Transceiver: transceiver,
+				Heading:     degToRad(v.Heading),
+			}),
+		}
+	}
+
+	return pgn.Message{
+		PGN: 129038,
+		Data: pgn.EncodeAISClassAPosition(pgn.AISClassAPosition{
+			MessageID:   ais.PositionReportType(v),
+			MMSI:        v.MMSI,
+			Longitude:   v.Longitude,
+			Latitude:    v.Latitude,
+			Second:      uint8(now.UTC().Second()),
+			COG:         degToRad(v.COG),
+			SOG:         v.SOG * knotsToMS,
+			Transceiver: transceiver,
+			Heading:     degToRad(v.Heading),
+			ROT:         degToRad(v.ROT) / 60,
+			NavStatus:   v.NavStatus,
+		}),
+	}
+}
+
+// AISStatic returns PGN 129794 for Class A vessels or PGNs 129809 and 129810 for Class B vessels
+func AISStatic(v simulation.Vessel, transceiver uint8, now time.Time) []pgn.Message {
+	if v.Class == simulation.ClassB {
+		return []pgn.Message{
+			{
+				PGN: 129809,
+				Data: pgn.EncodeAISClassBStaticA(pgn.AISClassBStaticA{
+					MMSI:        v.MMSI,
+					Name:        v.Name,
+					Transceiver: transceiver,
+				}),
+			},
+			{
+				PGN: 129810,
+				Data: pgn.EncodeAISClassBStaticB(pgn.AISClassBStaticB{
+					MMSI:        v.MMSI,
+					ShipType:    v.ShipType,
+					VendorID:    "SIM",
+					CallSign:    v.CallSign,
+					Length:      v.Length(),
+					Beam:        v.Beam(),
+					RefStbd:     v.ToStarboard,
+					RefBow:      v.ToBow,
+					Transceiver: transceiver,
+				}),
+			},
+		}
+	}
+
+	return []pgn.Message{
+		{
+			PGN: 129794,
+			Data: pgn.EncodeAISClassAStatic(pgn.AISClassAStatic{
+				MMSI:        v.MMSI,
+				IMO:         v.IMO,
+				CallSign:    v.CallSign,
+				Name:        v.Name,
+				ShipType:    v.ShipType,
+				Length:      v.Length(),
+				Beam:        v.Beam(),
+				RefStbd:     v.ToStarboard,
+				RefBow:      v.ToBow,
+				ETA:         now.Add(24 * time.Hour),
+				Draught:     v.Draught,
+				Destination: v.Destination,
+				GNSSType:    ais.EPFDGPS,
+				Transceiver: transceiver,
+			}),
+		},
+	}
+}
+
+func degToRad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,53 @@
+package nmea2000
+
+import (
+	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+func TestAISScheduleMessages(t *testing.T) {
+	own := simulation.DefaultOwnShip()
+	targets := []simulation.Vessel{
+		{MMSI: 1, Class: simulation.ClassA, SOG: 10},
+		{MMSI: 2, Class: simulation.ClassB, SOG: 5},
+		{MMSI: 3, Class: simulation.AidToNavigation},
+	}
+	fleet := simulation.NewFleet(own, targets...)
+	schedule := newAISSchedule()
+	now := time.Now()
+
+	counts := make(map[uint32]int)
+	for _, msg := range schedule.messages(fleet, now) {
+		counts[msg.PGN]++
+	}
+
+	expected := map[uint32]int{
+		129038: 2, // Own ship and Class A target
+		129794: 2,
+		129039: 1,
+		129809: 1,
+		129810: 1,
+	}
+	for pgn, n := range expected {
+		if counts[pgn] != n {
+			t.Errorf("Expected %d messages for PGN %d, got %d", n, pgn, counts[pgn])
+		}
+	}
+
+	if msgs := schedule.messages(fleet, now.Add(time.Second)); len(msgs) != 0 {
+		t.Errorf("Expected no messages before reporting intervals elapse, got %d", len(msgs))
+	}
+}
+
+func TestAISPositionMessageID(t *testing.T) {
+	v := simulation.Vessel{MMSI: 1, Class: simulation.ClassA, NavStatus: 1} // At anchor
+	if msg := AISPosition(v, 0, time.Now()); msg.Data[0]&0x3F != 3 {
+		t.Errorf("Expected message ID 3 at anchor, got %d", msg.Data[0]&0x3F)
+	}
+	v.Assigned = true
+	if msg := AISPosition(v, 0, time.Now()); msg.Data[0]&0x3F != 2 {
+		t.Errorf("Expected message ID 2 on an assigned schedule, got %d", msg.Data[0]&0x3F)
+	}
+}
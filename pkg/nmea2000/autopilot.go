@@ -0,0 +1,173 @@
+package nmea2000
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// steeringModes maps autopilot modes to PGN 127237 steering modes
+var steeringModes = map[simulation.AutopilotMode]uint8{
+	simulation.AutopilotStandby: pgn.SteeringMain,
+	simulation.AutopilotHeading: pgn.SteeringHeadingControl,
+	simulation.AutopilotTrack:   pgn.SteeringTrackControl,
+}
+
+// RudderMessage returns PGN 127245 Rudder with the rudder order and position
+func RudderMessage(state simulation.AutopilotState) pgn.Message {
+	return pgn.Message{
+		PGN: 127245,
+		Data: pgn.EncodeRudder(pgn.Rudder{
+			DirectionOrder: rudderDirection(state),
+			AngleOrder:     degToRad(state.RudderOrder),
+			Position:       degToRad(state.Rudder),
+		}),
+	}
+}
+
+// AutopilotMessages returns PGN 127245 Rudder with the rudder order and
+// position, and PGN 127237 Heading/Track Control with the autopilot's mode,
+// heading to steer and own ship's heading
+func AutopilotMessages(state simulation.AutopilotState, cfg simulation.AutopilotConfig, heading float64) []pgn.Message {
+	mode := steeringModes[state.Mode]
+	if state.Mode == simulation.AutopilotStandby && !math.IsNaN(state.RudderOrder) {
+		mode = pgn.SteeringFollowUp
+	}
+
+	return []pgn.Message{
+		RudderMessage(state),
+		{
+			PGN: 127237,
+			Data: pgn.EncodeHeadingTrackControl(pgn.HeadingTrackControl{
+				SteeringMode:     mode,
+				HeadingReference: pgn.HeadingTrue,
+				RudderDirection:  rudderDirection(state),
+				RudderAngle:      degToRad(state.RudderOrder),
+				HeadingToSteer:   degToRad(state.HeadingToSteer),
+				Track:            degToRad(state.Track),
+				RudderLimit:      degToRad(cfg.RudderLimit),
+				OffHeadingLimit:  math.NaN(),
+				RateOfTurnOrder:  math.NaN(),
+				OffTrackLimit:    math.NaN(),
+				VesselHeading:    degToRad(heading),
+			}),
+		},
+	}
+}
+
+// rudderDirection returns the direction the rudder is ordered to move
+func rudderDirection(state simulation.AutopilotState) uint8 {
+	switch {
+	case state.RudderOrder > state.Rudder:
+		return pgn.RudderStarboard
+	case state.RudderOrder < state.Rudder:
+		return pgn.RudderPort
+	}
+	return pgn.RudderNoOrder
+}
+
+// ApplyAutopilotCommand steers the autopilot by a PGN 127237 Heading/Track
+// Control or Raymarine pilot mode and locked heading message, converting
+// magnetic headings with the variation in degrees east. Engaging the
+// autopilot without a heading holds own ship's heading. It reports whether
+// the message is an autopilot command.
+func ApplyAutopilotCommand(pilot *simulation.Autopilot, msg pgn.Message, own simulation.Vessel, variation float64) (bool, error) {
+	switch msg.PGN {
+	case 127237:
+		c, err := pgn.DecodeHeadingTrackControl(msg.Data)
+		if err != nil {
+			return true, err
+		}
+		return true, applyHeadingTrackControl(pilot, c, own, variation)
+
+	case pgn.PGNSeatalkPilotMode:
+		m, err := pgn.DecodeSeatalkPilotMode(msg.Data)
+		if err != nil {
+			return true, err
+		}
+		switch m.Mode {
+		case pgn.SeatalkStandby:
+			pilot.Standby()
+		case pgn.SeatalkAuto:
+			pilot.SetHeading(own.Heading)
+		default:
+			return true, fmt.Errorf("unsupported Seatalk pilot mode 0x%04X", m.Mode)
+		}
+		return true, nil
+
+	case pgn.PGNSeatalkLockedHeading:
+		h, err := pgn.DecodeSeatalkLockedHeading(msg.Data)
+		if err != nil {
+			return true, err
+		}
+		heading := radToDeg(h.HeadingTrue)
+		if math.IsNaN(heading) {
+			heading = radToDeg(h.HeadingMagnetic) + variation
+		}
+		if math.IsNaN(heading) {
+			return true, fmt.Errorf("locked heading not available")
+		}
+		pilot.SetHeading(heading)
+		return true, nil
+	}
+	return false, nil
+}
+
+// applyHeadingTrackControl follows the steering mode of a PGN 127237 command
+func applyHeadingTrackControl(pilot *simulation.Autopilot, c pgn.HeadingTrackControl, own simulation.Vessel, variation float64) error {
+	heading := radToDeg(c.HeadingToSteer)
+	track := radToDeg(c.Track)
+	if c.HeadingReference == pgn.HeadingMagnetic {
+		heading += variation
+		track += variation
+	}
+
+	switch c.SteeringMode {
+	case pgn.SteeringMain:
+		pilot.Standby()
+
+	case pgn.SteeringNonFollowUp:
+		// The lever moves the rudder towards hard over, or holds it
+		limit := pilot.Config().RudderLimit
+		switch c.RudderDirection {
+		case pgn.RudderStarboard:
+			pilot.SetRudder(limit)
+		case pgn.RudderPort:
+			pilot.SetRudder(-limit)
+		default:
+			pilot.SetRudder(pilot.State().Rudder)
+		}
+
+	case pgn.SteeringFollowUp:
+		if math.IsNaN(c.RudderAngle) {
+			return fmt.Errorf("follow-up steering without a rudder angle")
+		}
+		pilot.SetRudder(radToDeg(c.RudderAngle))
+
+	case pgn.SteeringHeadingStandalone, pgn.SteeringHeadingControl:
+		if math.IsNaN(heading) {
+			heading = own.Heading
+		}
+		pilot.SetHeading(heading)
+
+	case pgn.SteeringTrackControl:
+		if math.IsNaN(track) {
+			track = heading
+		}
+		if math.IsNaN(track) {
+			return fmt.Errorf("track control without a track or heading to steer")
+		}
+		pilot.SetTrack(track, 0)
+
+	default:
+		return fmt.Errorf("unsupported steering mode %d", c.SteeringMode)
+	}
+	return nil
+}
+
+// radToDeg converts radians to degrees
+func radToDeg(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
@@ -0,0 +1,74 @@
+package nmea2000
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+func TestAutopilotMessages(t *testing.T) {
+	state := simulation.AutopilotState{
+		Mode:           simulation.AutopilotHeading,
+		HeadingToSteer: 90,
+		Track:          math.NaN(),
+		RudderOrder:    math.NaN(),
+		Rudder:         -10,
+	}
+	msgs := AutopilotMessages(state, simulation.DefaultAutopilotConfig(), 80)
+	if len(msgs) != 2 || msgs[0].PGN != 127245 || msgs[1].PGN != 127237 {
+		t.Fatalf("Expected PGNs 127245 and 127237, got %+v", msgs)
+	}
+
+	if pos := int16(binary.LittleEndian.Uint16(msgs[0].Data[4:6])); pos != -1745 {
+		t.Errorf("Expected rudder position -1745, got %d", pos)
+	}
+
+	c, err := pgn.DecodeHeadingTrackControl(msgs[1].Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.SteeringMode != pgn.SteeringHeadingControl || math.Abs(c.HeadingToSteer-math.Pi/2) > 1e-4 {
+		t.Errorf("Expected heading control to 90°, got %+v", c)
+	}
+}
+
+func TestApplyAutopilotCommand(t *testing.T) {
+	pilot := simulation.NewAutopilot(simulation.DefaultAutopilotConfig())
+	own := simulation.Vessel{Heading: 45}
+
+	cmd := pgn.Message{PGN: 127237, Data: pgn.EncodeHeadingTrackControl(pgn.HeadingTrackControl{
+		SteeringMode:     pgn.SteeringHeadingControl,
+		HeadingReference: pgn.HeadingMagnetic,
+		RudderAngle:      math.NaN(),
+		HeadingToSteer:   degToRad(100),
+		Track:            math.NaN(),
+		RudderLimit:      math.NaN(),
+		OffHeadingLimit:  math.NaN(),
+		RateOfTurnOrder:  math.NaN(),
+		OffTrackLimit:    math.NaN(),
+		VesselHeading:    math.NaN(),
+	})}
+	if ok, err := ApplyAutopilotCommand(pilot, cmd, own, -5); !ok || err != nil {
+		t.Fatalf("Expected a command, got %v, %v", ok, err)
+	}
+	if s := pilot.State(); s.Mode != simulation.AutopilotHeading || math.Abs(s.HeadingToSteer-95) > 0.01 {
+		t.Errorf("Expected to hold 95° true, got %+v", s)
+	}
+
+	standby := pgn.Message{PGN: pgn.PGNSeatalkPilotMode, Data: []byte{0x3B, 0x9F, 0x00, 0x00, 0x00, 0x00, 0xFF, 0xFF}}
+	if ok, err := ApplyAutopilotCommand(pilot, standby, own, 0); !ok || err != nil || pilot.State().Mode != simulation.AutopilotStandby {
+		t.Errorf("Expected standby, got %v, %v, %+v", ok, err, pilot.State())
+	}
+
+	auto := pgn.Message{PGN: pgn.PGNSeatalkPilotMode, Data: []byte{0x3B, 0x9F, 0x40, 0x00, 0x00, 0x00, 0xFF, 0xFF}}
+	if _, err := ApplyAutopilotCommand(pilot, auto, own, 0); err != nil || pilot.State().HeadingToSteer != 45 {
+		t.Errorf("Expected to lock own ship's heading, got %v, %+v", err, pilot.State())
+	}
+
+	if ok, _ := ApplyAutopilotCommand(pilot, pgn.Message{PGN: 127245}, own, 0); ok {
+		t.Error("Expected rudder status not to be a command")
+	}
+}
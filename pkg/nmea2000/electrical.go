@@ -0,0 +1,130 @@
+package nmea2000
+
+import (
+	"math"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// batteryConfigurationInterval is the period of PGN 127513, which changes only
+// when the batteries are replaced
+const batteryConfigurationInterval = 10 * time.Second
+
+// ElectricalMessages returns PGN 127508 Battery Status and 127506 DC Detailed
+// Status for each battery and charging source, and 127507 Charger Status for
+// the shore power charger
+func ElectricalMessages(e simulation.Electrical, sid uint8) []pgn.Message {
+	msgs := make([]pgn.Message, 0, 2*len(e.Batteries)+5)
+	for _, b := range e.Batteries {
+		msgs = append(msgs,
+			pgn.Message{
+				PGN: 127508,
+				Data: pgn.EncodeBatteryStatus(pgn.BatteryStatus{
+					Instance:    b.Instance,
+					Voltage:     b.Voltage,
+					Current:     b.Current,
+					Temperature: b.Temperature + celsiusToKelvin,
+					SID:         sid,
+				}),
+			},
+			pgn.Message{
+				PGN: 127506,
+				Data: pgn.EncodeDCDetailedStatus(pgn.DCDetailedStatus{
+					SID:           sid,
+					Instance:      b.Instance,
+					Type:          pgn.DCTypeBattery,
+					SoC:           b.SoC,
+					Health:        b.Config.Health,
+					TimeRemaining: b.TimeRemaining,
+					Ripple:        b.Ripple,
+					AmpHours:      b.SoC / 100 * b.Config.Capacity,
+				}),
+			},
+		)
+	}
+
+	sources := []struct {
+		source simulation.DCSource
+		dcType uint8
+	}{
+		{e.Solar, pgn.DCTypeSolarCell},
+		{e.Alternator, pgn.DCTypeAlternator},
+	}
+	for _, s := range sources {
+		msgs = append(msgs,
+			pgn.Message{
+				PGN: 127508,
+				Data: pgn.EncodeBatteryStatus(pgn.BatteryStatus{
+					Instance:    s.source.Instance,
+					Voltage:     s.source.Voltage,
+					Current:     s.source.Current,
+					Temperature: math.NaN(),
+					SID:         sid,
+				}),
+			},
+			pgn.Message{
+				PGN: 127506,
+				Data: pgn.EncodeDCDetailedStatus(pgn.DCDetailedStatus{
+					SID:           sid,
+					Instance:      s.source.Instance,
+					Type:          s.dcType,
+					SoC:           math.NaN(),
+					Health:        math.NaN(),
+					TimeRemaining: math.NaN(),
+					Ripple:        math.NaN(),
+					AmpHours:      math.NaN(),
+				}),
+			},
+		)
+	}
+
+	return append(msgs, pgn.Message{
+		PGN: 127507,
+		Data: pgn.EncodeChargerStatus(pgn.ChargerStatus{
+			Instance:        e.Charger.Instance,
+			BatteryInstance: e.Charger.BatteryInstance,
+			State:           uint8(e.Charger.State),
+			Enabled:         e.Charger.Enabled,
+		}),
+	})
+}
+
+// BatteryConfigurationMessages returns PGN 127513 Battery Configuration Status
+// for the house and start batteries
+func BatteryConfigurationMessages(cfg simulation.ElectricalConfig) []pgn.Message {
+	batteries := []struct {
+		instance uint8
+		battery  simulation.BatteryConfig
+	}{
+		{simulation.DCHouse, cfg.House},
+		{simulation.DCStart, cfg.Start},
+	}
+
+	msgs := make([]pgn.Message, 0, len(batteries))
+	for _, b := range batteries {
+		config := pgn.BatteryConfiguration{
+			Instance:               b.instance,
+			Type:                   uint8(b.battery.Type),
+			NominalVoltage:         pgn.NominalVoltage12,
+			Chemistry:              pgn.ChemistryLeadAcid,
+			Capacity:               b.battery.Capacity,
+			TemperatureCoefficient: -1,
+			Peukert:                1.25,
+			ChargeEfficiency:       90,
+		}
+		switch b.battery.Type {
+		case simulation.BatteryFlooded:
+			config.SupportsEqualization = true
+		case simulation.BatteryLithium:
+			config.Type = pgn.BatteryNotAvailable
+			config.Chemistry = pgn.ChemistryLithium
+			config.TemperatureCoefficient = 0
+			config.Peukert = 1.05
+			config.ChargeEfficiency = 99
+		}
+		msgs = append(msgs, pgn.Message{PGN: 127513, Data: pgn.EncodeBatteryConfiguration(config)})
+	}
+	return msgs
+}
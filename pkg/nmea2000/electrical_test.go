@@ -0,0 +1,54 @@
+package nmea2000
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+func TestElectricalMessages(t *testing.T) {
+	model := simulation.NewElectricalModel(simulation.DefaultElectricalConfig())
+	e := model.Sample(simulation.Vessel{}, nil, time.Date(2025, 6, 21, 12, 0, 0, 0, time.UTC))
+
+	msgs := ElectricalMessages(e, 9)
+	want := []uint32{127508, 127506, 127508, 127506, 127508, 127506, 127508, 127506, 127507}
+	if len(msgs) != len(want) {
+		t.Fatalf("Expected %d electrical messages, got %d", len(want), len(msgs))
+	}
+	for i, msg := range msgs {
+		if msg.PGN != want[i] {
+			t.Errorf("Message %d: expected PGN %d, got %d", i, want[i], msg.PGN)
+		}
+	}
+
+	house := msgs[1].Data
+	if house[1] != simulation.DCHouse || house[2] != pgn.DCTypeBattery || house[3] != 85 {
+		t.Errorf("Expected the house bank at 85%%, got % X", house)
+	}
+	if solar := msgs[5].Data; solar[1] != simulation.DCSolar || solar[2] != pgn.DCTypeSolarCell {
+		t.Errorf("Expected the solar panels, got % X", solar)
+	}
+	if current := int16(binary.LittleEndian.Uint16(msgs[4].Data[3:5])); current < 290 {
+		t.Errorf("Expected about 29 A of solar current at noon, got %d", current)
+	}
+}
+
+func TestBatteryConfigurationMessages(t *testing.T) {
+	cfg := simulation.DefaultElectricalConfig()
+	cfg.House.Type = simulation.BatteryLithium
+
+	msgs := BatteryConfigurationMessages(cfg)
+	if len(msgs) != 2 {
+		t.Fatalf("Expected 2 battery configurations, got %d", len(msgs))
+	}
+	house, start := msgs[0].Data, msgs[1].Data
+	if house[1]&0x0F != pgn.BatteryNotAvailable || house[2]>>4 != pgn.ChemistryLithium {
+		t.Errorf("Expected a lithium house bank, got % X", house)
+	}
+	if start[0] != simulation.DCStart || start[1]&0x0F != pgn.BatteryFlooded || binary.LittleEndian.Uint16(start[3:5]) != 100 {
+		t.Errorf("Expected a 100 Ah flooded start battery, got % X", start)
+	}
+}
@@ -0,0 +1,54 @@
+package nmea2000
+
+import (
+	"math"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// EngineMessages returns PGN 127488 Engine Parameters, Rapid Update, 127489
+// Engine Parameters, Dynamic and 127493 Transmission Parameters for each engine
+func EngineMessages(engines []simulation.Engine) []pgn.Message {
+	msgs := make([]pgn.Message, 0, 3*len(engines))
+	for _, e := range engines {
+		msgs = append(msgs,
+			pgn.Message{
+				PGN: 127488,
+				Data: pgn.EncodeEngineRapid(pgn.EngineRapid{
+					Instance:      e.Instance,
+					Speed:         e.RPM,
+					BoostPressure: e.BoostPressure * 1000,
+				}),
+			},
+			pgn.Message{
+				PGN: 127489,
+				Data: pgn.EncodeEngineDynamic(pgn.EngineDynamic{
+					Instance:           e.Instance,
+					OilPressure:        e.OilPressure * 1000,
+					OilTemperature:     e.OilTemperature + celsiusToKelvin,
+					CoolantTemperature: e.CoolantTemperature + celsiusToKelvin,
+					AlternatorVoltage:  e.AlternatorVoltage,
+					FuelRate:           e.FuelRate,
+					Hours:              e.Hours,
+					CoolantPressure:    math.NaN(),
+					FuelPressure:       math.NaN(),
+					Status1:            uint16(e.Alarms),
+					Status2:            uint16(e.Alarms >> 16),
+					Load:               int8(math.Round(e.Load)),
+					Torque:             int8(math.Round(e.Torque)),
+				}),
+			},
+			pgn.Message{
+				PGN: 127493,
+				Data: pgn.EncodeTransmission(pgn.Transmission{
+					Instance:       e.Instance,
+					Gear:           uint8(e.Gear),
+					OilPressure:    e.TransmissionPressure * 1000,
+					OilTemperature: e.TransmissionTemperature + celsiusToKelvin,
+				}),
+			},
+		)
+	}
+	return msgs
+}
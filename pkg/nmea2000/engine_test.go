@@ -0,0 +1,37 @@
+package nmea2000
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+func TestEngineMessages(t *testing.T) {
+	engines := []simulation.Engine{
+		{Instance: 0, RPM: 2400, Load: 51.2, Gear: simulation.GearForward},
+		{Instance: 1, RPM: 2424, Load: 52.7, Gear: simulation.GearForward, Alarms: simulation.AlarmOverTemperature | simulation.AlarmWarningLevel1},
+	}
+
+	msgs := EngineMessages(engines)
+	want := []uint32{127488, 127489, 127493, 127488, 127489, 127493}
+	if len(msgs) != len(want) {
+		t.Fatalf("Expected %d engine messages, got %d", len(want), len(msgs))
+	}
+	for i, msg := range msgs {
+		if msg.PGN != want[i] || msg.Data[0] != uint8(i/3) {
+			t.Errorf("Message %d: expected PGN %d for instance %d, got %d for instance %d", i, want[i], i/3, msg.PGN, msg.Data[0])
+		}
+	}
+
+	if speed := binary.LittleEndian.Uint16(msgs[3].Data[1:3]); speed != 9696 {
+		t.Errorf("Expected 9696 quarter RPM, got %d", speed)
+	}
+	dynamic := msgs[4].Data
+	if status1, status2 := binary.LittleEndian.Uint16(dynamic[20:22]), binary.LittleEndian.Uint16(dynamic[22:24]); status1 != 0x02 || status2 != 0x01 {
+		t.Errorf("Expected over temperature and warning level 1, got status 0x%04X and 0x%04X", status1, status2)
+	}
+	if dynamic[24] != 53 {
+		t.Errorf("Expected a load of 53%%, got %d", dynamic[24])
+	}
+}
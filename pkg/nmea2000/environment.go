@@ -0,0 +1,163 @@
+package nmea2000
+
+import (
+	"math"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// DepthMessage returns PGN 128267 Water Depth for a sounding, with the depth
+// not available when the sounder has no bottom
+func DepthMessage(s simulation.Sounding, sid uint8) pgn.Message {
+	depth := s.Depth
+	if !s.Valid {
+		depth = math.NaN()
+	}
+
+	return pgn.Message{
+		PGN: 128267,
+		Data: pgn.EncodeWaterDepth(pgn.WaterDepth{
+			SID:      sid,
+			Depth:    depth,
+			Offset:   s.Offset,
+			MaxRange: s.MaxRange,
+		}),
+	}
+}
+
+// WindMessages returns PGN 130306 Wind Data for the apparent wind, the true
+// wind relative to the bow and the true wind referenced to true north
+func WindMessages(w simulation.RelativeWind, sid uint8) []pgn.Message {
+	winds := []pgn.WindData{
+		{SID: sid, WindSpeed: w.ApparentSpeed, WindAngle: w.ApparentAngle, Reference: pgn.WindApparent},
+		{SID: sid, WindSpeed: w.True.Speed, WindAngle: w.TrueAngle, Reference: pgn.WindTrueBoat},
+		{SID: sid, WindSpeed: w.True.Speed, WindAngle: w.True.Direction, Reference: pgn.WindTrueNorth},
+	}
+
+	msgs := make([]pgn.Message, 0, len(winds))
+	for _, wind := range winds {
+		wind.WindSpeed *= knotsToMS
+		wind.WindAngle = degToRad(wind.WindAngle)
+		msgs = append(msgs, pgn.Message{PGN: 130306, Data: pgn.EncodeWindData(wind)})
+	}
+	return msgs
+}
+
+// MotionMessages returns PGN 128259 Speed with own ship's speed through water
+// and over ground, and PGN 130577 Direction Data adding heading, course and
+// the set and drift of the current
+func MotionMessages(own simulation.Vessel, current simulation.Current, sid uint8) []pgn.Message {
+	return []pgn.Message{
+		{
+			PGN: 128259,
+			Data: pgn.EncodeSpeed(pgn.Speed{
+				SID:         sid,
+				WaterSpeed:  own.STW * knotsToMS,
+				GroundSpeed: own.SOG * knotsToMS,
+			}),
+		},
+		{
+			PGN: 130577,
+			Data: pgn.EncodeDirectionData(pgn.DirectionData{
+				SID:     sid,
+				COG:     degToRad(own.COG),
+				SOG:     own.SOG * knotsToMS,
+				Heading: degToRad(own.Heading),
+				STW:     own.STW * knotsToMS,
+				Set:     degToRad(current.Set),
+				Drift:   current.Drift * knotsToMS,
+			}),
+		},
+	}
+}
+
+// celsiusToKelvin is the offset from degrees Celsius to Kelvin
+const celsiusToKelvin = 273.15
+
+// WeatherMessages returns the environmental PGNs for the weather: 130310 and
+// 130311 Environmental Parameters, 130312 Temperature and 130316 Temperature,
+// Extended Range for the sea and outside air, 130313 Humidity and 130314
+// Actual Pressure
+func WeatherMessages(w simulation.Weather, sid uint8) []pgn.Message {
+	water, air, pressure := w.WaterTemperature+celsiusToKelvin, w.AirTemperature+celsiusToKelvin, w.Pressure*100
+	temperatures := []pgn.Temperature{
+		{SID: sid, Source: pgn.TemperatureSea, Actual: water, Set: math.NaN()},
+		{SID: sid, Source: pgn.TemperatureOutside, Actual: air, Set: math.NaN()},
+	}
+
+	msgs := []pgn.Message{
+		{
+			PGN: 130310,
+			Data: pgn.EncodeOutsideEnvironment(pgn.OutsideEnvironment{
+				SID:              sid,
+				WaterTemperature: water,
+				AirTemperature:   air,
+				Pressure:         pressure,
+			}),
+		},
+		{
+			PGN: 130311,
+			Data: pgn.EncodeEnvironmentalParameters(pgn.EnvironmentalParameters{
+				SID:               sid,
+				TemperatureSource: pgn.TemperatureOutside,
+				HumiditySource:    pgn.HumidityOutside,
+				Temperature:       air,
+				Humidity:          w.Humidity,
+				Pressure:          pressure,
+			}),
+		},
+	}
+	for _, t := range temperatures {
+		msgs = append(msgs, pgn.Message{PGN: 130312, Data: pgn.EncodeTemperature(t)})
+	}
+	msgs = append(msgs,
+		pgn.Message{
+			PGN:  130313,
+			Data: pgn.EncodeHumidity(pgn.Humidity{SID: sid, Source: pgn.HumidityOutside, Actual: w.Humidity, Set: math.NaN()}),
+		},
+		pgn.Message{
+			PGN:  130314,
+			Data: pgn.EncodeActualPressure(pgn.ActualPressure{SID: sid, Source: pgn.PressureAtmospheric, Pressure: pressure}),
+		},
+	)
+	for _, t := range temperatures {
+		msgs = append(msgs, pgn.Message{PGN: 130316, Data: pgn.EncodeTemperatureExtended(t)})
+	}
+	return msgs
+}
+
+// SeaTemperatureMessage returns PGN 130312 Temperature of the sea, sent in
+// place of the weather PGNs when the weather is not output
+func SeaTemperatureMessage(w simulation.Weather, sid uint8) pgn.Message {
+	return pgn.Message{
+		PGN: 130312,
+		Data: pgn.EncodeTemperature(pgn.Temperature{
+			SID:    sid,
+			Source: pgn.TemperatureSea,
+			Actual: w.WaterTemperature + celsiusToKelvin,
+			Set:    math.NaN(),
+		}),
+	}
+}
+
+// AttitudeMessages returns PGN 127257 Attitude, with the yaw of own ship's
+// heading plus the wave-induced oscillation, and PGN 127252 Heave
+func AttitudeMessages(own simulation.Vessel, a simulation.Attitude, sid uint8) []pgn.Message {
+	yaw := math.Mod(own.Heading+a.Yaw+540, 360) - 180
+	return []pgn.Message{
+		{
+			PGN: 127257,
+			Data: pgn.EncodeAttitude(pgn.Attitude{
+				SID:   sid,
+				Yaw:   degToRad(yaw),
+				Pitch: degToRad(a.Pitch),
+				Roll:  degToRad(a.Roll),
+			}),
+		},
+		{
+			PGN:  127252,
+			Data: pgn.EncodeHeave(pgn.Heave{SID: sid, Heave: a.Heave}),
+		},
+	}
+}
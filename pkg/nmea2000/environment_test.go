@@ -0,0 +1,148 @@
+package nmea2000
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+func TestWindMessages(t *testing.T) {
+	own := simulation.Vessel{Heading: 0, COG: 0, SOG: 10}
+	w := simulation.ResolveWind(simulation.Wind{Direction: 90, Speed: 10}, own)
+
+	msgs := WindMessages(w, 4)
+	if len(msgs) != 3 {
+		t.Fatalf("Expected 3 wind messages, got %d", len(msgs))
+	}
+
+	want := []struct {
+		reference uint8
+		speed     float64 // Knots
+		angle     float64 // Degrees
+	}{
+		{pgn.WindApparent, 10 * math.Sqrt2, 45},
+		{pgn.WindTrueBoat, 10, 90},
+		{pgn.WindTrueNorth, 10, 90},
+	}
+	for i, msg := range msgs {
+		if msg.PGN != 130306 || msg.Data[0] != 4 {
+			t.Errorf("Message %d: expected PGN 130306 with SID 4, got %d with SID %d", i, msg.PGN, msg.Data[0])
+		}
+		if ref := msg.Data[5] & 0x07; ref != want[i].reference {
+			t.Errorf("Message %d: expected reference %d, got %d", i, want[i].reference, ref)
+		}
+		speed := float64(binary.LittleEndian.Uint16(msg.Data[1:3])) / 100 / knotsToMS
+		if math.Abs(speed-want[i].speed) > 0.02 {
+			t.Errorf("Message %d: expected %.2f kn, got %.2f", i, want[i].speed, speed)
+		}
+		angle := float64(binary.LittleEndian.Uint16(msg.Data[3:5])) / 10000 * 180 / math.Pi
+		if math.Abs(angle-want[i].angle) > 0.01 {
+			t.Errorf("Message %d: expected %.1f°, got %.2f°", i, want[i].angle, angle)
+		}
+	}
+}
+
+func TestMotionMessages(t *testing.T) {
+	fleet := simulation.NewFleet(simulation.Vessel{Heading: 0, STW: 6})
+	fleet.SetCurrent(simulation.ConstantCurrent{Set: 90, Drift: 2})
+
+	msgs := MotionMessages(fleet.OwnShip(), fleet.Current(), 1)
+	if len(msgs) != 2 || msgs[0].PGN != 128259 || msgs[1].PGN != 130577 {
+		t.Fatalf("Expected PGNs 128259 and 130577, got %+v", msgs)
+	}
+
+	speed := msgs[0].Data
+	if stw := binary.LittleEndian.Uint16(speed[1:3]); stw != 309 {
+		t.Errorf("Expected STW 309 (6 kn), got %d", stw)
+	}
+	if sog := binary.LittleEndian.Uint16(speed[3:5]); sog != 325 {
+		t.Errorf("Expected SOG 325 (6.32 kn), got %d", sog)
+	}
+
+	direction := msgs[1].Data
+	if len(direction) != 14 {
+		t.Fatalf("Expected 14 bytes of direction data, got %d", len(direction))
+	}
+	if set := binary.LittleEndian.Uint16(direction[10:12]); set != 15708 {
+		t.Errorf("Expected set 15708 (090°), got %d", set)
+	}
+	if drift := binary.LittleEndian.Uint16(direction[12:14]); drift != 103 {
+		t.Errorf("Expected drift 103 (2 kn), got %d", drift)
+	}
+	if heading, cog := binary.LittleEndian.Uint16(direction[6:8]), binary.LittleEndian.Uint16(direction[2:4]); heading != 0 || cog != 3218 {
+		t.Errorf("Expected heading 0 and COG 3218 (18.4°), got %d and %d", heading, cog)
+	}
+}
+
+func TestDepthMessage(t *testing.T) {
+	msg := DepthMessage(simulation.Sounding{Valid: true, Depth: 8.25, Offset: 0.5, MaxRange: 100}, 2)
+	if msg.PGN != 128267 || msg.Data[0] != 2 {
+		t.Fatalf("Expected PGN 128267 with SID 2, got %d with SID %d", msg.PGN, msg.Data[0])
+	}
+	if depth := binary.LittleEndian.Uint32(msg.Data[1:5]); depth != 825 {
+		t.Errorf("Expected depth 825, got %d", depth)
+	}
+
+	msg = DepthMessage(simulation.Sounding{Offset: 0.5, MaxRange: 100}, 2)
+	if depth := binary.LittleEndian.Uint32(msg.Data[1:5]); depth != 0xFFFFFFFF {
+		t.Errorf("Expected depth not available without a bottom, got %d", depth)
+	}
+}
+
+func TestWeatherMessages(t *testing.T) {
+	w := simulation.Weather{AirTemperature: 18.5, WaterTemperature: 16, Humidity: 75, Pressure: 1005.4}
+
+	msgs := WeatherMessages(w, 6)
+	want := []uint32{130310, 130311, 130312, 130312, 130313, 130314, 130316, 130316}
+	if len(msgs) != len(want) {
+		t.Fatalf("Expected %d weather messages, got %d", len(want), len(msgs))
+	}
+	for i, msg := range msgs {
+		if msg.PGN != want[i] || msg.Data[0] != 6 {
+			t.Errorf("Message %d: expected PGN %d with SID 6, got %d with SID %d", i, want[i], msg.PGN, msg.Data[0])
+		}
+	}
+
+	// Sea then outside air temperature
+	if source, temp := msgs[2].Data[2], binary.LittleEndian.Uint16(msgs[2].Data[3:5]); source != pgn.TemperatureSea || temp != 28915 {
+		t.Errorf("Expected sea temperature 28915, got source %d and %d", source, temp)
+	}
+	if source, temp := msgs[3].Data[2], binary.LittleEndian.Uint16(msgs[3].Data[3:5]); source != pgn.TemperatureOutside || temp != 29165 {
+		t.Errorf("Expected outside temperature 29165, got source %d and %d", source, temp)
+	}
+	if pressure := int32(binary.LittleEndian.Uint32(msgs[5].Data[3:7])); pressure != 1005400 {
+		t.Errorf("Expected pressure 1005400, got %d", pressure)
+	}
+}
+
+func TestSeaTemperatureMessage(t *testing.T) {
+	msg := SeaTemperatureMessage(simulation.Weather{AirTemperature: 18.5, WaterTemperature: 16}, 6)
+	if msg.PGN != 130312 || msg.Data[0] != 6 {
+		t.Fatalf("Expected PGN 130312 with SID 6, got %d with SID %d", msg.PGN, msg.Data[0])
+	}
+	if source, temp := msg.Data[2], binary.LittleEndian.Uint16(msg.Data[3:5]); source != pgn.TemperatureSea || temp != 28915 {
+		t.Errorf("Expected sea temperature 28915, got source %d and %d", source, temp)
+	}
+}
+
+func TestAttitudeMessages(t *testing.T) {
+	own := simulation.DefaultOwnShip()
+	own.Heading = 270
+	msgs := AttitudeMessages(own, simulation.Attitude{Pitch: 2, Roll: -5, Yaw: 1.5, Heave: 0.35}, 7)
+	if len(msgs) != 2 || msgs[0].PGN != 127257 || msgs[1].PGN != 127252 {
+		t.Fatalf("Expected PGNs 127257 and 127252, got %+v", msgs)
+	}
+
+	if yaw := int16(binary.LittleEndian.Uint16(msgs[0].Data[1:3])); yaw != int16(math.Round(-88.5*math.Pi/180*10000)) {
+		t.Errorf("Expected yaw of 271.5° as -88.5°, got %d", yaw)
+	}
+	if roll := int16(binary.LittleEndian.Uint16(msgs[0].Data[5:7])); roll != int16(math.Round(-5*math.Pi/180*10000)) {
+		t.Errorf("Expected roll of -5°, got %d", roll)
+	}
+	if heave := int16(binary.LittleEndian.Uint16(msgs[1].Data[1:3])); heave != 35 {
+		t.Errorf("Expected heave 35, got %d", heave)
+	}
+}
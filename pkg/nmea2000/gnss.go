@@ -0,0 +1,88 @@
+package nmea2000
+
+import (
+	"math"
+	"sort"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// PositionMessages returns PGN 129025 Position Rapid Update and PGN 129026
+// COG & SOG Rapid Update for a fix, sent as not available when it is invalid
+func PositionMessages(fix simulation.GNSSFix, sid uint8) []pgn.Message {
+	position := pgn.Position{Latitude: math.NaN(), Longitude: math.NaN()}
+	course := pgn.COGSOG{SID: sid, COG: math.NaN(), SOG: math.NaN()}
+	if fix.Valid {
+		position = pgn.Position{Latitude: fix.Latitude, Longitude: fix.Longitude}
+		course.COG, course.SOG = degToRad(fix.COG), fix.SOG*knotsToMS
+	}
+
+	return []pgn.Message{
+		{PGN: 129025, Data: pgn.EncodePosition(position)},
+		{PGN: 129026, Data: pgn.EncodeCOGSOG(course)},
+	}
+}
+
+// GNSSMessages returns PGN 129539 GNSS DOPs and PGN 129540 GNSS Sats in View for a fix
+func GNSSMessages(fix simulation.GNSSFix, sid uint8) []pgn.Message {
+	mode := uint8(pgn.GNSSMode1D)
+	if fix.Valid {
+		mode = pgn.GNSSMode3D
+	}
+
+	sats := make([]pgn.SatelliteInView, 0, len(fix.Satellites))
+	for _, s := range fix.Satellites {
+		status := uint8(pgn.SatTracked)
+		if s.Used {
+			status = pgn.SatUsed
+		}
+		sats = append(sats, pgn.SatelliteInView{
+			PRN:       n2kPRN(s),
+			Elevation: degToRad(s.Elevation),
+			Azimuth:   degToRad(s.Azimuth),
+			SNR:       s.SNR,
+			Status:    status,
+		})
+	}
+
+	// Satellites used in the solution are kept when the list is truncated
+	sort.SliceStable(sats, func(i, j int) bool {
+		return sats[i].Status == pgn.SatUsed && sats[j].Status != pgn.SatUsed
+	})
+
+	return []pgn.Message{
+		{
+			PGN: 129539,
+			Data: pgn.EncodeGNSSDOPs(pgn.GNSSDOPs{
+				SID:         sid,
+				DesiredMode: pgn.GNSSModeAuto,
+				ActualMode:  mode,
+				HDOP:        fix.DOP.HDOP,
+				VDOP:        fix.DOP.VDOP,
+				TDOP:        fix.DOP.TDOP,
+			}),
+		},
+		{
+			PGN: 129540,
+			Data: pgn.EncodeSatsInView(pgn.SatsInView{
+				SID:        sid,
+				Satellites: sats,
+			}),
+		},
+	}
+}
+
+// n2kPRN maps a satellite onto a single PRN space. PGN 129540 carries no system
+// field, so Galileo and BeiDou satellites are offset into ranges unused by GPS
+// (1-32), SBAS (33-64) and GLONASS (65-96).
+func n2kPRN(s simulation.Satellite) uint8 {
+	switch s.System {
+	case simulation.Galileo:
+		return uint8(200 + s.PRN)
+	case simulation.BeiDou:
+		return uint8(140 + s.PRN)
+	default:
+		return uint8(s.PRN)
+	}
+}
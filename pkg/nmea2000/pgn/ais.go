@@ -0,0 +1,216 @@
+package pgn
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// AIS transceiver information values
+const (
+	AISChannelAReception    = 0
+	AISChannelBReception    = 1
+	AISChannelATransmission = 2
+	AISChannelBTransmission = 3
+	AISOwnInformation       = 4
+)
+
+// AISClassAPosition represents PGN 129038 data
+type AISClassAPosition struct {
+	MessageID   uint8 // 1, 2 or 3
+	MMSI        uint32
+	Longitude   float64 // Degrees
+	Latitude    float64 // Degrees
+	Accuracy    bool
+	Second      uint8
+	COG         float64 // Radians
+	SOG         float64 // Meters per second
+	Transceiver uint8
+	Heading     float64 // Radians
+	ROT         float64 // Radians per second
+	NavStatus   uint8
+}
+
+// EncodeAISClassAPosition encodes PGN 129038 data
+func EncodeAISClassAPosition(p AISClassAPosition) []byte {
+	data := make([]byte, 28)
+
+	data[0] = p.MessageID & 0x3F // Repeat indicator 0
+	binary.LittleEndian.PutUint32(data[1:5], p.MMSI)
+	binary.LittleEndian.PutUint32(data[5:9], uint32(int32(math.Round(p.Longitude*1e7))))
+	binary.LittleEndian.PutUint32(data[9:13], uint32(int32(math.Round(p.Latitude*1e7))))
+	data[13] = boolBit(p.Accuracy) | p.Second<<2
+	binary.LittleEndian.PutUint16(data[14:16], uint16(math.Round(p.COG*10000)))
+	binary.LittleEndian.PutUint16(data[16:18], uint16(math.Round(p.SOG*100)))
+
+	// Communication state (19 bits) followed by transceiver information (5 bits)
+	data[18] = 0
+	data[19] = 0
+	data[20] = (p.Transceiver & 0x1F) << 3
+
+	binary.LittleEndian.PutUint16(data[21:23], uint16(math.Round(p.Heading*10000)))
+	binary.LittleEndian.PutUint16(data[23:25], uint16(int16(math.Round(p.ROT/3.125e-5))))
+	data[25] = p.NavStatus&0x0F | 0xC0 // Special manoeuvre not available
+	data[26] = 0xF8                    // AIS spare and reserved
+	data[27] = 0xFF                    // Sequence ID not available
+
+	return data
+}
+
+// AISClassBPosition represents PGN 129039 data
+type AISClassBPosition struct {
+	MMSI        uint32
+	Longitude   float64 // Degrees
+	Latitude    float64 // Degrees
+	Accuracy    bool
+	Second      uint8
+	COG         float64 // Radians
+	SOG         float64 // Meters per second
+	Transceiver uint8
+	Heading     float64 // Radians
+}
+
+// EncodeAISClassBPosition encodes PGN 129039 data
+func EncodeAISClassBPosition(p AISClassBPosition) []byte {
+	data := make([]byte, 27)
+
+	data[0] = 18
+	binary.LittleEndian.PutUint32(data[1:5], p.MMSI)
+	binary.LittleEndian.PutUint32(data[5:9], uint32(int32(math.Round(p.Longitude*1e7))))
+	binary.LittleEndian.PutUint32(data[9:13], uint32(int32(math.Round(p.Latitude*1e7))))
+	data[13] = boolBit(p.Accuracy) | p.Second<<2
+	binary.LittleEndian.PutUint16(data[14:16], uint16(math.Round(p.COG*10000)))
+	binary.LittleEndian.PutUint16(data[16:18], uint16(math.Round(p.SOG*100)))
+	data[20] = (p.Transceiver & 0x1F) << 3
+	binary.LittleEndian.PutUint16(data[21:23], uint16(math.Round(p.Heading*10000)))
+	data[23] = 0xFF // Regional application
+
+	// Unit type CS, no display, no DSC, whole band, handles message 22, autonomous mode
+	data[24] = 0x03 | 1<<2 | 1<<5 | 1<<6
+	data[25] = 0xFE // Communication state selector (SOTDMA) and reserved
+	data[26] = 0xFF // Sequence ID not available
+
+	return data
+}
+
+// AISClassAStatic represents PGN 129794 data
+type AISClassAStatic struct {
+	MMSI        uint32
+	IMO         uint32
+	CallSign    string
+	Name        string
+	ShipType    uint8
+	Length      float64 // Meters
+	Beam        float64 // Meters
+	RefStbd     float64 // Meters from starboard side to position reference
+	RefBow      float64 // Meters from bow to position reference
+	ETA         time.Time
+	Draught     float64 // Meters
+	Destination string
+	GNSSType    uint8
+	Transceiver uint8
+}
+
+// EncodeAISClassAStatic encodes PGN 129794 data
+func EncodeAISClassAStatic(s AISClassAStatic) []byte {
+	data := make([]byte, 75)
+
+	data[0] = 5
+	binary.LittleEndian.PutUint32(data[1:5], s.MMSI)
+	binary.LittleEndian.PutUint32(data[5:9], s.IMO)
+	putAISString(data[9:16], s.CallSign)
+	putAISString(data[16:36], s.Name)
+	data[36] = s.ShipType
+	binary.LittleEndian.PutUint16(data[37:39], uint16(math.Round(s.Length*10)))
+	binary.LittleEndian.PutUint16(data[39:41], uint16(math.Round(s.Beam*10)))
+	binary.LittleEndian.PutUint16(data[41:43], uint16(math.Round(s.RefStbd*10)))
+	binary.LittleEndian.PutUint16(data[43:45], uint16(math.Round(s.RefBow*10)))
+
+	if s.ETA.IsZero() {
+		binary.LittleEndian.PutUint16(data[45:47], 0xFFFF)
+		binary.LittleEndian.PutUint32(data[47:51], 0xFFFFFFFF)
+	} else {
+		eta := s.ETA.UTC()
+		midnight := eta.Truncate(24 * time.Hour)
+		binary.LittleEndian.PutUint16(data[45:47], uint16(eta.Unix()/86400))
+		binary.LittleEndian.PutUint32(data[47:51], uint32(eta.Sub(midnight)/(100*time.Microsecond)))
+	}
+
+	binary.LittleEndian.PutUint16(data[51:53], uint16(math.Round(s.Draught*100)))
+	putAISString(data[53:73], s.Destination)
+	data[73] = (s.GNSSType&0x0F)<<2 | 0xC0 // AIS version 0, DTE not ready, reserved
+	data[74] = s.Transceiver&0x1F | 0xE0
+
+	return data
+}
+
+// AISClassBStaticA represents PGN 129809 data
+type AISClassBStaticA struct {
+	MMSI        uint32
+	Name        string
+	Transceiver uint8
+}
+
+// EncodeAISClassBStaticA encodes PGN 129809 data
+func EncodeAISClassBStaticA(s AISClassBStaticA) []byte {
+	data := make([]byte, 27)
+
+	data[0] = 24
+	binary.LittleEndian.PutUint32(data[1:5], s.MMSI)
+	putAISString(data[5:25], s.Name)
+	data[25] = s.Transceiver&0x1F | 0xE0
+	data[26] = 0xFF // Sequence ID not available
+
+	return data
+}
+
+// AISClassBStaticB represents PGN 129810 data
+type AISClassBStaticB struct {
+	MMSI        uint32
+	ShipType    uint8
+	VendorID    string
+	CallSign    string
+	Length      float64 // Meters
+	Beam        float64 // Meters
+	RefStbd     float64 // Meters from starboard side to position reference
+	RefBow      float64 // Meters from bow to position reference
+	Transceiver uint8
+}
+
+// EncodeAISClassBStaticB encodes PGN 129810 data
+func EncodeAISClassBStaticB(s AISClassBStaticB) []byte {
+	data := make([]byte, 34)
+
+	data[0] = 24
+	binary.LittleEndian.PutUint32(data[1:5], s.MMSI)
+	data[5] = s.ShipType
+	putAISString(data[6:13], s.VendorID)
+	putAISString(data[13:20], s.CallSign)
+	binary.LittleEndian.PutUint16(data[20:22], uint16(math.Round(s.Length*10)))
+	binary.LittleEndian.PutUint16(data[22:24], uint16(math.Round(s.Beam*10)))
+	binary.LittleEndian.PutUint16(data[24:26], uint16(math.Round(s.RefStbd*10)))
+	binary.LittleEndian.PutUint16(data[26:28], uint16(math.Round(s.RefBow*10)))
+	binary.LittleEndian.PutUint32(data[28:32], 0) // Mothership MMSI
+	data[32] = 0xFF                               // Reserved and spare
+	data[33] = s.Transceiver&0x1F | 0xE0
+
+	return data
+}
+
+// putAISString writes s into a fixed-width text field padded with '@'
+func putAISString(field []byte, s string) {
+	for i := range field {
+		if i < len(s) {
+			field[i] = s[i]
+		} else {
+			field[i] = '@'
+		}
+	}
+}
+
+func boolBit(b bool) uint8 {
+	if b {
+		return 1
+	}
+	return 0
+}
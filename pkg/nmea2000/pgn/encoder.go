@@ -2,6 +2,7 @@ package pgn
 
 import (
 	"encoding/binary"
+	"math"
 )
 
 // VesselHeading represents PGN 127250 data
@@ -17,8 +18,11 @@ type VesselHeading struct {
 func EncodeVesselHeading(h VesselHeading) []byte {
 	data := make([]byte, 8)
 
-	// Convert heading to radians * 10000
+	// Convert heading to radians * 10000, NaN is sent as not available
 	heading := uint16(h.Heading * 10000)
+	if math.IsNaN(h.Heading) {
+		heading = 0xFFFF
+	}
 	binary.LittleEndian.PutUint16(data[0:2], heading)
 
 	// Deviation
@@ -36,62 +40,874 @@ func EncodeVesselHeading(h VesselHeading) []byte {
 	return data
 }
 
+// RateOfTurn represents PGN 127251 data
+type RateOfTurn struct {
+	SID  uint8
+	Rate float64 // Radians per second, positive to starboard
+}
+
+// EncodeRateOfTurn encodes PGN 127251 data, sending NaN as not available
+func EncodeRateOfTurn(r RateOfTurn) []byte {
+	data := make([]byte, 8)
+
+	data[0] = r.SID
+
+	// Rate of turn in units of 3.125e-8 rad/s
+	rate := int32(math.MaxInt32)
+	if !math.IsNaN(r.Rate) {
+		rate = int32(math.Round(r.Rate / 3.125e-8))
+	}
+	binary.LittleEndian.PutUint32(data[1:5], uint32(rate))
+
+	// Reserved bytes
+	data[5] = 0xFF
+	data[6] = 0xFF
+	data[7] = 0xFF
+
+	return data
+}
+
+// EngineRapid represents PGN 127488 data
+type EngineRapid struct {
+	Instance      uint8
+	Speed         float64 // RPM
+	BoostPressure float64 // Pascals
+	TiltTrim      int8    // Percent
+}
+
+// EncodeEngineRapid encodes PGN 127488 data, sending NaN as not available
+func EncodeEngineRapid(e EngineRapid) []byte {
+	data := make([]byte, 8)
+
+	data[0] = e.Instance
+
+	// Engine speed in units of 0.25 RPM
+	speed := uint16(0xFFFF)
+	if !math.IsNaN(e.Speed) {
+		speed = uint16(math.Round(e.Speed * 4))
+	}
+	binary.LittleEndian.PutUint16(data[1:3], speed)
+	binary.LittleEndian.PutUint16(data[3:5], encodePressure(e.BoostPressure))
+	data[5] = uint8(e.TiltTrim)
+
+	// Reserved bytes
+	data[6] = 0xFF
+	data[7] = 0xFF
+
+	return data
+}
+
+// EngineDynamic represents PGN 127489 data
+type EngineDynamic struct {
+	Instance           uint8
+	OilPressure        float64 // Pascals
+	OilTemperature     float64 // Kelvin
+	CoolantTemperature float64 // Kelvin
+	AlternatorVoltage  float64 // Volts
+	FuelRate           float64 // Liters per hour
+	Hours              float64 // Total engine hours
+	CoolantPressure    float64 // Pascals
+	FuelPressure       float64 // Pascals
+	Status1            uint16  // Discrete status 1 bits, bit 0 Check Engine, 1 Over Temperature, 2 Low Oil Pressure
+	Status2            uint16  // Discrete status 2 bits, bit 0 Warning Level 1, 1 Warning Level 2
+	Load               int8    // Percent
+	Torque             int8    // Percent
+}
+
+// EncodeEngineDynamic encodes PGN 127489 data, a fast-packet PGN, sending NaN as not available
+func EncodeEngineDynamic(e EngineDynamic) []byte {
+	data := make([]byte, 26)
+
+	data[0] = e.Instance
+	binary.LittleEndian.PutUint16(data[1:3], encodePressure(e.OilPressure))
+
+	// Oil temperature in units of 0.1 K
+	oilTemp := uint16(0xFFFF)
+	if !math.IsNaN(e.OilTemperature) {
+		oilTemp = uint16(math.Round(e.OilTemperature * 10))
+	}
+	binary.LittleEndian.PutUint16(data[3:5], oilTemp)
+	binary.LittleEndian.PutUint16(data[5:7], encodeTemperature(e.CoolantTemperature))
+
+	// Alternator potential in units of 0.01 V
+	voltage := int16(math.MaxInt16)
+	if !math.IsNaN(e.AlternatorVoltage) {
+		voltage = int16(math.Round(e.AlternatorVoltage * 100))
+	}
+	binary.LittleEndian.PutUint16(data[7:9], uint16(voltage))
+
+	// Fuel rate in units of 0.1 L/h
+	fuelRate := int16(math.MaxInt16)
+	if !math.IsNaN(e.FuelRate) {
+		fuelRate = int16(math.Round(e.FuelRate * 10))
+	}
+	binary.LittleEndian.PutUint16(data[9:11], uint16(fuelRate))
+
+	// Total engine hours in seconds
+	hours := uint32(0xFFFFFFFF)
+	if !math.IsNaN(e.Hours) {
+		hours = uint32(math.Round(e.Hours * 3600))
+	}
+	binary.LittleEndian.PutUint32(data[11:15], hours)
+
+	binary.LittleEndian.PutUint16(data[15:17], encodePressure(e.CoolantPressure))
+
+	// Fuel pressure in units of 1 kPa
+	fuelPressure := uint16(0xFFFF)
+	if !math.IsNaN(e.FuelPressure) {
+		fuelPressure = uint16(math.Round(e.FuelPressure / 1000))
+	}
+	binary.LittleEndian.PutUint16(data[17:19], fuelPressure)
+
+	// Reserved byte
+	data[19] = 0xFF
+
+	binary.LittleEndian.PutUint16(data[20:22], e.Status1)
+	binary.LittleEndian.PutUint16(data[22:24], e.Status2)
+	data[24] = uint8(e.Load)
+	data[25] = uint8(e.Torque)
+
+	return data
+}
+
+// Transmission gears used in PGN 127493
+const (
+	GearForward uint8 = 0
+	GearNeutral uint8 = 1
+	GearReverse uint8 = 2
+)
+
+// Transmission represents PGN 127493 data
+type Transmission struct {
+	Instance       uint8
+	Gear           uint8   // One of the Gear* positions
+	OilPressure    float64 // Pascals
+	OilTemperature float64 // Kelvin
+}
+
+// EncodeTransmission encodes PGN 127493 data, sending NaN as not available
+func EncodeTransmission(t Transmission) []byte {
+	data := make([]byte, 8)
+
+	data[0] = t.Instance
+	data[1] = 0xFC | t.Gear&0x03
+	binary.LittleEndian.PutUint16(data[2:4], encodePressure(t.OilPressure))
+
+	// Oil temperature in units of 0.1 K
+	oilTemp := uint16(0xFFFF)
+	if !math.IsNaN(t.OilTemperature) {
+		oilTemp = uint16(math.Round(t.OilTemperature * 10))
+	}
+	binary.LittleEndian.PutUint16(data[4:6], oilTemp)
+
+	// No discrete status, reserved byte
+	data[6] = 0x00
+	data[7] = 0xFF
+
+	return data
+}
+
+// FluidLevel represents PGN 127505 data
+type FluidLevel struct {
+	Instance uint8   // 0-15
+	Type     uint8   // 0=Fuel, 1=Fresh water, 2=Waste water, 3=Live well, 4=Oil, 5=Black water
+	Level    float64 // Percent
+	Capacity float64 // Liters
+}
+
+// EncodeFluidLevel encodes PGN 127505 data, sending NaN as not available
+func EncodeFluidLevel(f FluidLevel) []byte {
+	data := make([]byte, 8)
+
+	data[0] = f.Instance&0x0F | f.Type<<4
+
+	// Level in units of 0.004 %
+	level := int16(math.MaxInt16)
+	if !math.IsNaN(f.Level) {
+		level = int16(math.Round(f.Level / 0.004))
+	}
+	binary.LittleEndian.PutUint16(data[1:3], uint16(level))
+
+	// Capacity in units of 0.1 L
+	capacity := uint32(0xFFFFFFFF)
+	if !math.IsNaN(f.Capacity) {
+		capacity = uint32(math.Round(f.Capacity * 10))
+	}
+	binary.LittleEndian.PutUint32(data[3:7], capacity)
+
+	// Reserved byte
+	data[7] = 0xFF
+
+	return data
+}
+
+// DC source types used in PGN 127506
+const (
+	DCTypeBattery       uint8 = 0
+	DCTypeAlternator    uint8 = 1
+	DCTypeConverter     uint8 = 2
+	DCTypeSolarCell     uint8 = 3
+	DCTypeWindGenerator uint8 = 4
+	DCTypeNotAvailable  uint8 = 0xFF
+)
+
+// DCDetailedStatus represents PGN 127506 data
+type DCDetailedStatus struct {
+	SID           uint8
+	Instance      uint8
+	Type          uint8   // One of the DCType* sources
+	SoC           float64 // State of charge, percent
+	Health        float64 // State of health, percent
+	TimeRemaining float64 // Minutes
+	Ripple        float64 // Ripple voltage, volts
+	AmpHours      float64 // Remaining capacity, amp hours
+}
+
+// EncodeDCDetailedStatus encodes PGN 127506 data, a fast-packet PGN, sending NaN as not available
+func EncodeDCDetailedStatus(d DCDetailedStatus) []byte {
+	data := make([]byte, 11)
+
+	data[0] = d.SID
+	data[1] = d.Instance
+	data[2] = d.Type
+
+	// State of charge and health in percent
+	data[3], data[4] = 0xFF, 0xFF
+	if !math.IsNaN(d.SoC) {
+		data[3] = uint8(math.Round(math.Min(math.Max(d.SoC, 0), 100)))
+	}
+	if !math.IsNaN(d.Health) {
+		data[4] = uint8(math.Round(math.Min(math.Max(d.Health, 0), 100)))
+	}
+
+	// Time remaining in minutes
+	remaining := uint16(0xFFFF)
+	if !math.IsNaN(d.TimeRemaining) {
+		remaining = uint16(math.Min(math.Round(d.TimeRemaining), 0xFFFE))
+	}
+	binary.LittleEndian.PutUint16(data[5:7], remaining)
+
+	// Ripple voltage in units of 0.001 V
+	ripple := uint16(0xFFFF)
+	if !math.IsNaN(d.Ripple) {
+		ripple = uint16(math.Round(d.Ripple * 1000))
+	}
+	binary.LittleEndian.PutUint16(data[7:9], ripple)
+
+	// Remaining capacity in amp hours
+	ampHours := uint16(0xFFFF)
+	if !math.IsNaN(d.AmpHours) {
+		ampHours = uint16(math.Round(d.AmpHours))
+	}
+	binary.LittleEndian.PutUint16(data[9:11], ampHours)
+
+	return data
+}
+
+// Charger operating states used in PGN 127507
+const (
+	ChargerNotCharging uint8 = 0
+	ChargerBulk        uint8 = 1
+	ChargerAbsorption  uint8 = 2
+	ChargerOvercharge  uint8 = 3
+	ChargerEqualise    uint8 = 4
+	ChargerFloat       uint8 = 5
+	ChargerNoFloat     uint8 = 6
+	ChargerConstantVI  uint8 = 7
+	ChargerDisabled    uint8 = 8
+	ChargerFault       uint8 = 9
+)
+
+// ChargerStatus represents PGN 127507 data
+type ChargerStatus struct {
+	Instance        uint8
+	BatteryInstance uint8
+	State           uint8 // One of the Charger* operating states
+	Mode            uint8 // 0=Standalone, 1=Primary, 2=Secondary, 3=Echo
+	Enabled         bool
+}
+
+// EncodeChargerStatus encodes PGN 127507 data without equalization
+func EncodeChargerStatus(c ChargerStatus) []byte {
+	data := make([]byte, 8)
+
+	data[0] = c.Instance
+	data[1] = c.BatteryInstance
+	data[2] = c.State&0x0F | c.Mode<<4
+
+	// Enabled, no equalization pending, reserved bits
+	data[3] = 0xF0
+	if c.Enabled {
+		data[3] |= 0x01
+	}
+
+	// Equalization time remaining not available, reserved bytes
+	data[4] = 0xFF
+	data[5] = 0xFF
+	data[6] = 0xFF
+	data[7] = 0xFF
+
+	return data
+}
+
+// BatteryStatus represents PGN 127508 data
+type BatteryStatus struct {
+	Instance    uint8
+	Voltage     float64 // Volts
+	Current     float64 // Amps, positive charging
+	Temperature float64 // Kelvin
+	SID         uint8
+}
+
+// EncodeBatteryStatus encodes PGN 127508 data, sending NaN as not available
+func EncodeBatteryStatus(b BatteryStatus) []byte {
+	data := make([]byte, 8)
+
+	data[0] = b.Instance
+
+	// Voltage in units of 0.01 V
+	voltage := int16(math.MaxInt16)
+	if !math.IsNaN(b.Voltage) {
+		voltage = int16(math.Round(b.Voltage * 100))
+	}
+	binary.LittleEndian.PutUint16(data[1:3], uint16(voltage))
+
+	// Current in units of 0.1 A
+	current := int16(math.MaxInt16)
+	if !math.IsNaN(b.Current) {
+		current = int16(math.Round(b.Current * 10))
+	}
+	binary.LittleEndian.PutUint16(data[3:5], uint16(current))
+
+	binary.LittleEndian.PutUint16(data[5:7], encodeTemperature(b.Temperature))
+	data[7] = b.SID
+
+	return data
+}
+
+// Battery types used in PGN 127513
+const (
+	BatteryFlooded      uint8 = 0
+	BatteryGel          uint8 = 1
+	BatteryAGM          uint8 = 2
+	BatteryNotAvailable uint8 = 0x0F
+)
+
+// Battery chemistries used in PGN 127513
+const (
+	ChemistryLeadAcid uint8 = 0
+	ChemistryLithium  uint8 = 1
+	ChemistryNiCd     uint8 = 2
+	ChemistryZnO      uint8 = 3
+	ChemistryNiMH     uint8 = 4
+)
+
+// Nominal battery voltages used in PGN 127513
+const (
+	NominalVoltage6  uint8 = 0
+	NominalVoltage12 uint8 = 1
+	NominalVoltage24 uint8 = 2
+	NominalVoltage32 uint8 = 3
+	NominalVoltage48 uint8 = 6
+)
+
+// BatteryConfiguration represents PGN 127513 data
+type BatteryConfiguration struct {
+	Instance               uint8
+	Type                   uint8 // One of the Battery* types
+	SupportsEqualization   bool
+	NominalVoltage         uint8   // One of the NominalVoltage* values
+	Chemistry              uint8   // One of the Chemistry* values
+	Capacity               float64 // Amp hours
+	TemperatureCoefficient int8    // Percent per °C
+	Peukert                float64 // Peukert exponent, 1 to 1.5
+	ChargeEfficiency       int8    // Percent
+}
+
+// EncodeBatteryConfiguration encodes PGN 127513 data, a fast-packet PGN, sending NaN as not available
+func EncodeBatteryConfiguration(b BatteryConfiguration) []byte {
+	data := make([]byte, 8)
+
+	data[0] = b.Instance
+
+	// Type, equalization support and reserved bits
+	data[1] = 0xC0 | b.Type&0x0F
+	if b.SupportsEqualization {
+		data[1] |= 0x10
+	}
+	data[2] = b.NominalVoltage&0x0F | b.Chemistry<<4
+
+	// Capacity in amp hours
+	capacity := uint16(0xFFFF)
+	if !math.IsNaN(b.Capacity) {
+		capacity = uint16(math.Round(b.Capacity))
+	}
+	binary.LittleEndian.PutUint16(data[3:5], capacity)
+
+	data[5] = uint8(b.TemperatureCoefficient)
+
+	// Peukert exponent in units of 0.002 above 1
+	data[6] = 0xFF
+	if !math.IsNaN(b.Peukert) {
+		data[6] = uint8(math.Round(math.Min(math.Max(b.Peukert-1, 0), 0.5) / 0.002))
+	}
+	data[7] = uint8(b.ChargeEfficiency)
+
+	return data
+}
+
+// Heave represents PGN 127252 data
+type Heave struct {
+	SID   uint8
+	Heave float64 // Meters, positive up
+}
+
+// EncodeHeave encodes PGN 127252 data without a measurement delay, sending NaN as not available
+func EncodeHeave(h Heave) []byte {
+	data := make([]byte, 8)
+
+	data[0] = h.SID
+
+	// Heave in centimeters
+	heave := int16(math.MaxInt16)
+	if !math.IsNaN(h.Heave) {
+		heave = int16(math.Round(h.Heave * 100))
+	}
+	binary.LittleEndian.PutUint16(data[1:3], uint16(heave))
+
+	// Delay, delay source and reserved bytes not available
+	for i := 3; i < 8; i++ {
+		data[i] = 0xFF
+	}
+
+	return data
+}
+
+// Attitude represents PGN 127257 data
+type Attitude struct {
+	SID   uint8
+	Yaw   float64 // Radians
+	Pitch float64 // Radians, positive bow up
+	Roll  float64 // Radians, positive with the starboard side down
+}
+
+// EncodeAttitude encodes PGN 127257 data, sending NaN as not available
+func EncodeAttitude(a Attitude) []byte {
+	data := make([]byte, 8)
+
+	data[0] = a.SID
+	binary.LittleEndian.PutUint16(data[1:3], uint16(encodeSignedAngle(a.Yaw)))
+	binary.LittleEndian.PutUint16(data[3:5], uint16(encodeSignedAngle(a.Pitch)))
+	binary.LittleEndian.PutUint16(data[5:7], uint16(encodeSignedAngle(a.Roll)))
+
+	// Reserved byte
+	data[7] = 0xFF
+
+	return data
+}
+
+// encodeSignedAngle converts a signed angle in radians to 1e-4 rad units, NaN as not available
+func encodeSignedAngle(rad float64) int16 {
+	if math.IsNaN(rad) {
+		return math.MaxInt16
+	}
+	return int16(math.Round(rad * 10000))
+}
+
+// Magnetic variation sources used in PGN 127258
+const (
+	VariationManual      uint8 = 0
+	VariationChart       uint8 = 1
+	VariationTable       uint8 = 2
+	VariationCalculation uint8 = 3 // Computed from a magnetic model
+)
+
+// MagneticVariation represents PGN 127258 data
+type MagneticVariation struct {
+	SID          uint8
+	Source       uint8   // One of the Variation* sources
+	AgeOfService uint16  // Days since 1970-01-01 of the date the variation applies to
+	Variation    float64 // Radians, east positive
+}
+
+// EncodeMagneticVariation encodes PGN 127258 data
+func EncodeMagneticVariation(v MagneticVariation) []byte {
+	data := make([]byte, 8)
+
+	data[0] = v.SID
+	data[1] = 0xF0 | v.Source&0x0F
+	binary.LittleEndian.PutUint16(data[2:4], v.AgeOfService)
+
+	// Variation in units of 1e-4 rad
+	variation := int16(math.Round(v.Variation * 10000))
+	binary.LittleEndian.PutUint16(data[4:6], uint16(variation))
+
+	// Reserved bytes
+	data[6] = 0xFF
+	data[7] = 0xFF
+
+	return data
+}
+
+// Speed represents PGN 128259 data
+type Speed struct {
+	SID         uint8
+	WaterSpeed  float64 // Speed through water, meters per second
+	GroundSpeed float64 // Speed over ground, meters per second
+}
+
+// EncodeSpeed encodes PGN 128259 data with a paddle wheel water speed sensor
+func EncodeSpeed(s Speed) []byte {
+	data := make([]byte, 8)
+
+	data[0] = s.SID
+	binary.LittleEndian.PutUint16(data[1:3], encodeSpeed(s.WaterSpeed))
+	binary.LittleEndian.PutUint16(data[3:5], encodeSpeed(s.GroundSpeed))
+
+	// Speed water referenced type (paddle wheel), direction forward and reserved
+	data[5] = 0x00
+	data[6] = 0xF0
+	data[7] = 0xFF
+
+	return data
+}
+
+// COGSOG represents PGN 129026 data
+type COGSOG struct {
+	SID uint8
+	COG float64 // Radians, true
+	SOG float64 // Meters per second
+}
+
+// EncodeCOGSOG encodes PGN 129026 data, sending NaN as not available
+func EncodeCOGSOG(c COGSOG) []byte {
+	data := make([]byte, 8)
+
+	data[0] = c.SID
+	data[1] = 0xFC // True reference, reserved bits set
+	binary.LittleEndian.PutUint16(data[2:4], encodeAngle(c.COG))
+	binary.LittleEndian.PutUint16(data[4:6], encodeSpeed(c.SOG))
+
+	// Reserved bytes
+	data[6] = 0xFF
+	data[7] = 0xFF
+
+	return data
+}
+
+// DirectionData represents PGN 130577 data
+type DirectionData struct {
+	SID     uint8
+	COG     float64 // Radians, true
+	SOG     float64 // Meters per second
+	Heading float64 // Radians, true
+	STW     float64 // Meters per second
+	Set     float64 // Radians, true, the direction the current flows towards
+	Drift   float64 // Meters per second
+}
+
+// EncodeDirectionData encodes PGN 130577 data, a fast-packet PGN
+func EncodeDirectionData(d DirectionData) []byte {
+	data := make([]byte, 14)
+
+	data[0] = 0xC0 // Autonomous data mode, true COG reference, reserved bits set
+	data[1] = d.SID
+	binary.LittleEndian.PutUint16(data[2:4], encodeAngle(d.COG))
+	binary.LittleEndian.PutUint16(data[4:6], encodeSpeed(d.SOG))
+	binary.LittleEndian.PutUint16(data[6:8], encodeAngle(d.Heading))
+	binary.LittleEndian.PutUint16(data[8:10], encodeSpeed(d.STW))
+	binary.LittleEndian.PutUint16(data[10:12], encodeAngle(d.Set))
+	binary.LittleEndian.PutUint16(data[12:14], encodeSpeed(d.Drift))
+
+	return data
+}
+
+// encodeAngle converts an angle in radians to 1e-4 rad units, NaN as not available
+func encodeAngle(rad float64) uint16 {
+	if math.IsNaN(rad) {
+		return 0xFFFF
+	}
+	return uint16(math.Round(rad * 10000))
+}
+
+// encodeSpeed converts a speed in meters per second to 0.01 m/s units, NaN as not available
+func encodeSpeed(ms float64) uint16 {
+	if math.IsNaN(ms) {
+		return 0xFFFF
+	}
+	return uint16(math.Round(ms * 100))
+}
+
 // WaterDepth represents PGN 128267 data
 type WaterDepth struct {
-	Depth    float64 // Meters
-	Offset   float64 // Meters
+	SID      uint8
+	Depth    float64 // Below the transducer, meters
+	Offset   float64 // Meters, positive to the waterline or negative to the keel
 	MaxRange float64 // Meters
 }
 
-// EncodeWaterDepth encodes PGN 128267 data
+// EncodeWaterDepth encodes PGN 128267 data, sending a NaN depth as not available
 func EncodeWaterDepth(d WaterDepth) []byte {
 	data := make([]byte, 8)
 
-	// Convert depth to centimeters (0.01m resolution)
-	depth := uint32(d.Depth * 100)
-	binary.LittleEndian.PutUint32(data[0:4], depth)
+	data[0] = d.SID
+
+	// Depth in centimeters (0.01m resolution)
+	depth := uint32(0xFFFFFFFF)
+	if !math.IsNaN(d.Depth) {
+		depth = uint32(math.Round(d.Depth * 100))
+	}
+	binary.LittleEndian.PutUint32(data[1:5], depth)
 
-	// Offset
-	offset := int16(d.Offset * 100)
-	binary.LittleEndian.PutUint16(data[4:6], uint16(offset))
+	// Offset in millimeters
+	offset := int16(math.Round(d.Offset * 1000))
+	binary.LittleEndian.PutUint16(data[5:7], uint16(offset))
 
-	// Range
-	maxRange := uint16(d.MaxRange * 100)
-	binary.LittleEndian.PutUint16(data[6:8], maxRange)
+	// Range in units of 10 meters
+	data[7] = uint8(math.Min(d.MaxRange/10, 0xFE))
 
 	return data
 }
 
+// Wind references used in PGN 130306
+const (
+	WindTrueNorth     uint8 = 0 // True wind, ground referenced to true north
+	WindMagneticNorth uint8 = 1 // True wind, ground referenced to magnetic north
+	WindApparent      uint8 = 2 // Apparent wind, relative to the bow
+	WindTrueBoat      uint8 = 3 // True wind, relative to the bow
+	WindTrueWater     uint8 = 4 // True wind, water referenced, relative to the bow
+)
+
 // WindData represents PGN 130306 data
 type WindData struct {
+	SID       uint8
 	WindSpeed float64 // Meters per second
-	WindAngle float64 // Radians
-	Reference uint8   // 0=True, 1=Apparent
+	WindAngle float64 // Radians, a direction for the north references and an angle off the bow otherwise
+	Reference uint8   // One of the Wind* references
 }
 
 // EncodeWindData encodes PGN 130306 data
 func EncodeWindData(w WindData) []byte {
 	data := make([]byte, 8)
 
+	data[0] = w.SID
+
 	// Wind speed (0.01 m/s resolution)
-	speed := uint16(w.WindSpeed * 100)
-	binary.LittleEndian.PutUint16(data[0:2], speed)
+	speed := uint16(math.Round(w.WindSpeed * 100))
+	binary.LittleEndian.PutUint16(data[1:3], speed)
 
 	// Wind angle (0.0001 radian resolution)
-	angle := uint16(w.WindAngle * 10000)
-	binary.LittleEndian.PutUint16(data[2:4], angle)
+	angle := uint16(math.Round(w.WindAngle * 10000))
+	binary.LittleEndian.PutUint16(data[3:5], angle)
 
-	// Reference
-	data[4] = w.Reference
+	// Reference in the low 3 bits, the rest reserved
+	data[5] = 0xF8 | w.Reference&0x07
 
 	// Reserved bytes
-	data[5] = 0xFF
 	data[6] = 0xFF
 	data[7] = 0xFF
 
 	return data
 }
 
+// Temperature sources used in PGNs 130311, 130312 and 130316
+const (
+	TemperatureSea        uint8 = 0
+	TemperatureOutside    uint8 = 1
+	TemperatureInside     uint8 = 2
+	TemperatureEngineRoom uint8 = 3
+	TemperatureMainCabin  uint8 = 4
+)
+
+// Humidity sources used in PGNs 130311 and 130313
+const (
+	HumidityInside  uint8 = 0
+	HumidityOutside uint8 = 1
+)
+
+// Pressure sources used in PGN 130314
+const (
+	PressureAtmospheric uint8 = 0
+	PressureWater       uint8 = 1
+)
+
+// OutsideEnvironment represents PGN 130310 data
+type OutsideEnvironment struct {
+	SID              uint8
+	WaterTemperature float64 // Kelvin
+	AirTemperature   float64 // Kelvin
+	Pressure         float64 // Pascals
+}
+
+// EncodeOutsideEnvironment encodes PGN 130310 data, sending NaN as not available
+func EncodeOutsideEnvironment(e OutsideEnvironment) []byte {
+	data := make([]byte, 8)
+
+	data[0] = e.SID
+	binary.LittleEndian.PutUint16(data[1:3], encodeTemperature(e.WaterTemperature))
+	binary.LittleEndian.PutUint16(data[3:5], encodeTemperature(e.AirTemperature))
+	binary.LittleEndian.PutUint16(data[5:7], encodePressure(e.Pressure))
+
+	// Reserved byte
+	data[7] = 0xFF
+
+	return data
+}
+
+// EnvironmentalParameters represents PGN 130311 data
+type EnvironmentalParameters struct {
+	SID               uint8
+	TemperatureSource uint8   // One of the Temperature* sources
+	HumiditySource    uint8   // One of the Humidity* sources
+	Temperature       float64 // Kelvin
+	Humidity          float64 // Percent
+	Pressure          float64 // Pascals
+}
+
+// EncodeEnvironmentalParameters encodes PGN 130311 data, sending NaN as not available
+func EncodeEnvironmentalParameters(e EnvironmentalParameters) []byte {
+	data := make([]byte, 8)
+
+	data[0] = e.SID
+	data[1] = e.TemperatureSource&0x3F | e.HumiditySource<<6
+	binary.LittleEndian.PutUint16(data[2:4], encodeTemperature(e.Temperature))
+	binary.LittleEndian.PutUint16(data[4:6], encodeHumidity(e.Humidity))
+	binary.LittleEndian.PutUint16(data[6:8], encodePressure(e.Pressure))
+
+	return data
+}
+
+// Temperature represents PGN 130312 and 130316 data
+type Temperature struct {
+	SID      uint8
+	Instance uint8
+	Source   uint8   // One of the Temperature* sources
+	Actual   float64 // Kelvin
+	Set      float64 // Kelvin, NaN when there is no set point
+}
+
+// EncodeTemperature encodes PGN 130312 data, sending NaN as not available
+func EncodeTemperature(t Temperature) []byte {
+	data := make([]byte, 8)
+
+	data[0] = t.SID
+	data[1] = t.Instance
+	data[2] = t.Source
+	binary.LittleEndian.PutUint16(data[3:5], encodeTemperature(t.Actual))
+	binary.LittleEndian.PutUint16(data[5:7], encodeTemperature(t.Set))
+
+	// Reserved byte
+	data[7] = 0xFF
+
+	return data
+}
+
+// EncodeTemperatureExtended encodes PGN 130316 data, with the actual
+// temperature in 0.001 K units, sending NaN as not available
+func EncodeTemperatureExtended(t Temperature) []byte {
+	data := make([]byte, 8)
+
+	data[0] = t.SID
+	data[1] = t.Instance
+	data[2] = t.Source
+
+	// Actual temperature as a 24-bit value
+	actual := uint32(0xFFFFFF)
+	if !math.IsNaN(t.Actual) {
+		actual = uint32(math.Round(t.Actual * 1000))
+	}
+	data[3] = byte(actual)
+	data[4] = byte(actual >> 8)
+	data[5] = byte(actual >> 16)
+
+	// Set temperature in 0.1 K units
+	set := uint16(0xFFFF)
+	if !math.IsNaN(t.Set) {
+		set = uint16(math.Round(t.Set * 10))
+	}
+	binary.LittleEndian.PutUint16(data[6:8], set)
+
+	return data
+}
+
+// Humidity represents PGN 130313 data
+type Humidity struct {
+	SID      uint8
+	Instance uint8
+	Source   uint8   // One of the Humidity* sources
+	Actual   float64 // Percent
+	Set      float64 // Percent, NaN when there is no set point
+}
+
+// EncodeHumidity encodes PGN 130313 data, sending NaN as not available
+func EncodeHumidity(h Humidity) []byte {
+	data := make([]byte, 8)
+
+	data[0] = h.SID
+	data[1] = h.Instance
+	data[2] = h.Source
+	binary.LittleEndian.PutUint16(data[3:5], encodeHumidity(h.Actual))
+	binary.LittleEndian.PutUint16(data[5:7], encodeHumidity(h.Set))
+
+	// Reserved byte
+	data[7] = 0xFF
+
+	return data
+}
+
+// ActualPressure represents PGN 130314 data
+type ActualPressure struct {
+	SID      uint8
+	Instance uint8
+	Source   uint8   // One of the Pressure* sources
+	Pressure float64 // Pascals
+}
+
+// EncodeActualPressure encodes PGN 130314 data, sending NaN as not available
+func EncodeActualPressure(p ActualPressure) []byte {
+	data := make([]byte, 8)
+
+	data[0] = p.SID
+	data[1] = p.Instance
+	data[2] = p.Source
+
+	// Pressure in units of 0.1 Pa
+	pressure := int32(math.MaxInt32)
+	if !math.IsNaN(p.Pressure) {
+		pressure = int32(math.Round(p.Pressure * 10))
+	}
+	binary.LittleEndian.PutUint32(data[3:7], uint32(pressure))
+
+	// Reserved byte
+	data[7] = 0xFF
+
+	return data
+}
+
+// encodeTemperature converts a temperature in Kelvin to 0.01 K units, NaN as not available
+func encodeTemperature(k float64) uint16 {
+	if math.IsNaN(k) {
+		return 0xFFFF
+	}
+	return uint16(math.Round(k * 100))
+}
+
+// encodeHumidity converts a relative humidity in percent to 0.004 % units, NaN as not available
+func encodeHumidity(percent float64) uint16 {
+	if math.IsNaN(percent) {
+		return 0x7FFF
+	}
+	return uint16(int16(math.Round(percent / 0.004)))
+}
+
+// encodePressure converts a pressure in Pascals to hPa units, NaN as not available
+func encodePressure(pa float64) uint16 {
+	if math.IsNaN(pa) {
+		return 0xFFFF
+	}
+	return uint16(math.Round(pa / 100))
+}
+
 // Position represents PGN 129025 data
 type Position struct {
 	Latitude  float64 // Degrees
@@ -102,12 +918,18 @@ type Position struct {
 func EncodePosition(p Position) []byte {
 	data := make([]byte, 8)
 
-	// Convert latitude to 1e-7 degrees
+	// Convert latitude to 1e-7 degrees, NaN is sent as not available
 	lat := int32(p.Latitude * 1e7)
+	if math.IsNaN(p.Latitude) {
+		lat = math.MaxInt32
+	}
 	binary.LittleEndian.PutUint32(data[0:4], uint32(lat))
 
 	// Convert longitude to 1e-7 degrees
 	lon := int32(p.Longitude * 1e7)
+	if math.IsNaN(p.Longitude) {
+		lon = math.MaxInt32
+	}
 	binary.LittleEndian.PutUint32(data[4:8], uint32(lon))
 
 	return data
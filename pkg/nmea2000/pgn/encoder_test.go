@@ -0,0 +1,263 @@
+package pgn
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestEncodeMagneticVariation(t *testing.T) {
+	data := EncodeMagneticVariation(MagneticVariation{SID: 3, Source: VariationCalculation, AgeOfService: 20000, Variation: -0.0873})
+
+	if len(data) != 8 {
+		t.Fatalf("Expected 8 bytes, got %d", len(data))
+	}
+	if data[1]&0x0F != VariationCalculation {
+		t.Errorf("Expected source %d, got %d", VariationCalculation, data[1]&0x0F)
+	}
+	if age := binary.LittleEndian.Uint16(data[2:4]); age != 20000 {
+		t.Errorf("Expected age of service 20000, got %d", age)
+	}
+	if variation := int16(binary.LittleEndian.Uint16(data[4:6])); variation != -873 {
+		t.Errorf("Expected variation -873, got %d", variation)
+	}
+}
+
+func TestEncodeWindData(t *testing.T) {
+	data := EncodeWindData(WindData{SID: 9, WindSpeed: 6.17, WindAngle: 5.4978, Reference: WindApparent})
+
+	if data[0] != 9 {
+		t.Errorf("Expected SID 9, got %d", data[0])
+	}
+	if speed := binary.LittleEndian.Uint16(data[1:3]); speed != 617 {
+		t.Errorf("Expected speed 617, got %d", speed)
+	}
+	if angle := binary.LittleEndian.Uint16(data[3:5]); angle != 54978 {
+		t.Errorf("Expected angle 54978, got %d", angle)
+	}
+	if data[5]&0x07 != WindApparent {
+		t.Errorf("Expected reference %d, got %d", WindApparent, data[5]&0x07)
+	}
+}
+
+func TestEncodeCOGSOG(t *testing.T) {
+	data := EncodeCOGSOG(COGSOG{SID: 2, COG: 0.7854, SOG: 3.09})
+
+	if data[1]&0x03 != 0 {
+		t.Errorf("Expected true COG reference, got %d", data[1]&0x03)
+	}
+	if cog := binary.LittleEndian.Uint16(data[2:4]); cog != 7854 {
+		t.Errorf("Expected COG 7854, got %d", cog)
+	}
+	if sog := binary.LittleEndian.Uint16(data[4:6]); sog != 309 {
+		t.Errorf("Expected SOG 309, got %d", sog)
+	}
+
+	invalid := EncodeCOGSOG(COGSOG{COG: math.NaN(), SOG: math.NaN()})
+	if binary.LittleEndian.Uint16(invalid[2:4]) != 0xFFFF || binary.LittleEndian.Uint16(invalid[4:6]) != 0xFFFF {
+		t.Error("Expected NaN to be sent as not available")
+	}
+}
+
+func TestEncodeWaterDepth(t *testing.T) {
+	data := EncodeWaterDepth(WaterDepth{SID: 1, Depth: 12.34, Offset: -1.3, MaxRange: 200})
+
+	if depth := binary.LittleEndian.Uint32(data[1:5]); depth != 1234 {
+		t.Errorf("Expected depth 1234, got %d", depth)
+	}
+	if offset := int16(binary.LittleEndian.Uint16(data[5:7])); offset != -1300 {
+		t.Errorf("Expected offset -1300, got %d", offset)
+	}
+	if data[7] != 20 {
+		t.Errorf("Expected range 20, got %d", data[7])
+	}
+
+	if invalid := EncodeWaterDepth(WaterDepth{Depth: math.NaN()}); binary.LittleEndian.Uint32(invalid[1:5]) != 0xFFFFFFFF {
+		t.Error("Expected NaN depth to be sent as not available")
+	}
+}
+
+func TestEncodeEnvironmentalParameters(t *testing.T) {
+	data := EncodeEnvironmentalParameters(EnvironmentalParameters{
+		SID:               2,
+		TemperatureSource: TemperatureOutside,
+		HumiditySource:    HumidityOutside,
+		Temperature:       291.65,
+		Humidity:          75.5,
+		Pressure:          101325,
+	})
+
+	if data[1] != 0x41 {
+		t.Errorf("Expected sources 0x41, got 0x%02X", data[1])
+	}
+	if temp := binary.LittleEndian.Uint16(data[2:4]); temp != 29165 {
+		t.Errorf("Expected temperature 29165, got %d", temp)
+	}
+	if humidity := binary.LittleEndian.Uint16(data[4:6]); humidity != 18875 {
+		t.Errorf("Expected humidity 18875, got %d", humidity)
+	}
+	if pressure := binary.LittleEndian.Uint16(data[6:8]); pressure != 1013 {
+		t.Errorf("Expected pressure 1013, got %d", pressure)
+	}
+}
+
+func TestEncodeTemperature(t *testing.T) {
+	temp := Temperature{SID: 3, Instance: 0, Source: TemperatureSea, Actual: 289.15, Set: math.NaN()}
+
+	data := EncodeTemperature(temp)
+	if actual := binary.LittleEndian.Uint16(data[3:5]); actual != 28915 {
+		t.Errorf("Expected temperature 28915, got %d", actual)
+	}
+	if set := binary.LittleEndian.Uint16(data[5:7]); set != 0xFFFF {
+		t.Errorf("Expected set temperature not available, got 0x%04X", set)
+	}
+
+	extended := EncodeTemperatureExtended(temp)
+	if actual := uint32(extended[3]) | uint32(extended[4])<<8 | uint32(extended[5])<<16; actual != 289150 {
+		t.Errorf("Expected extended temperature 289150, got %d", actual)
+	}
+	if set := binary.LittleEndian.Uint16(extended[6:8]); set != 0xFFFF {
+		t.Errorf("Expected extended set temperature not available, got 0x%04X", set)
+	}
+}
+
+func TestEncodeActualPressure(t *testing.T) {
+	data := EncodeActualPressure(ActualPressure{SID: 4, Source: PressureAtmospheric, Pressure: 101325.5})
+
+	if pressure := int32(binary.LittleEndian.Uint32(data[3:7])); pressure != 1013255 {
+		t.Errorf("Expected pressure 1013255, got %d", pressure)
+	}
+
+	humidity := EncodeHumidity(Humidity{Source: HumidityOutside, Actual: 50, Set: math.NaN()})
+	if actual := binary.LittleEndian.Uint16(humidity[3:5]); actual != 12500 {
+		t.Errorf("Expected humidity 12500, got %d", actual)
+	}
+	if set := binary.LittleEndian.Uint16(humidity[5:7]); set != 0x7FFF {
+		t.Errorf("Expected set humidity not available, got 0x%04X", set)
+	}
+}
+
+func TestEncodeAttitude(t *testing.T) {
+	data := EncodeAttitude(Attitude{SID: 5, Yaw: 0.01, Pitch: -0.05, Roll: math.NaN()})
+
+	if yaw := int16(binary.LittleEndian.Uint16(data[1:3])); yaw != 100 {
+		t.Errorf("Expected yaw 100, got %d", yaw)
+	}
+	if pitch := int16(binary.LittleEndian.Uint16(data[3:5])); pitch != -500 {
+		t.Errorf("Expected pitch -500, got %d", pitch)
+	}
+	if roll := binary.LittleEndian.Uint16(data[5:7]); roll != 0x7FFF {
+		t.Errorf("Expected roll not available, got 0x%04X", roll)
+	}
+
+	heave := EncodeHeave(Heave{SID: 5, Heave: -0.42})
+	if h := int16(binary.LittleEndian.Uint16(heave[1:3])); h != -42 {
+		t.Errorf("Expected heave -42, got %d", h)
+	}
+}
+
+func TestEncodeEngineParameters(t *testing.T) {
+	rapid := EncodeEngineRapid(EngineRapid{Instance: 1, Speed: 2400.25, BoostPressure: 75000})
+	if rapid[0] != 1 || binary.LittleEndian.Uint16(rapid[1:3]) != 9601 {
+		t.Errorf("Expected instance 1 at 9601 quarter RPM, got %d and %d", rapid[0], binary.LittleEndian.Uint16(rapid[1:3]))
+	}
+	if boost := binary.LittleEndian.Uint16(rapid[3:5]); boost != 750 {
+		t.Errorf("Expected boost pressure 750, got %d", boost)
+	}
+
+	dynamic := EncodeEngineDynamic(EngineDynamic{
+		OilPressure:        350000,
+		OilTemperature:     365.15,
+		CoolantTemperature: 355.15,
+		AlternatorVoltage:  14.2,
+		FuelRate:           9.5,
+		Hours:              1250.5,
+		CoolantPressure:    math.NaN(),
+		FuelPressure:       math.NaN(),
+		Status1:            0x0003,
+		Status2:            0x0001,
+		Load:               51,
+		Torque:             64,
+	})
+	if len(dynamic) != 26 {
+		t.Fatalf("Expected 26 bytes, got %d", len(dynamic))
+	}
+	checks := []struct {
+		name string
+		got  uint32
+		want uint32
+	}{
+		{"oil pressure", uint32(binary.LittleEndian.Uint16(dynamic[1:3])), 3500},
+		{"oil temperature", uint32(binary.LittleEndian.Uint16(dynamic[3:5])), 3652},
+		{"coolant temperature", uint32(binary.LittleEndian.Uint16(dynamic[5:7])), 35515},
+		{"alternator voltage", uint32(binary.LittleEndian.Uint16(dynamic[7:9])), 1420},
+		{"fuel rate", uint32(binary.LittleEndian.Uint16(dynamic[9:11])), 95},
+		{"hours", binary.LittleEndian.Uint32(dynamic[11:15]), 4501800},
+		{"coolant pressure", uint32(binary.LittleEndian.Uint16(dynamic[15:17])), 0xFFFF},
+		{"status 1", uint32(binary.LittleEndian.Uint16(dynamic[20:22])), 3},
+		{"status 2", uint32(binary.LittleEndian.Uint16(dynamic[22:24])), 1},
+		{"load", uint32(dynamic[24]), 51},
+	}
+	for _, c := range checks {
+		if c.got != c.want {
+			t.Errorf("Expected %s %d, got %d", c.name, c.want, c.got)
+		}
+	}
+
+	transmission := EncodeTransmission(Transmission{Gear: GearNeutral, OilPressure: 400000, OilTemperature: 333.15})
+	if transmission[1]&0x03 != GearNeutral || binary.LittleEndian.Uint16(transmission[2:4]) != 4000 {
+		t.Errorf("Unexpected transmission data % X", transmission)
+	}
+}
+
+func TestEncodeElectrical(t *testing.T) {
+	battery := EncodeBatteryStatus(BatteryStatus{Instance: 1, Voltage: 12.64, Current: -8.3, Temperature: 298.15, SID: 7})
+	if battery[0] != 1 || battery[7] != 7 {
+		t.Errorf("Expected instance 1 and SID 7, got % X", battery)
+	}
+	if voltage, current := binary.LittleEndian.Uint16(battery[1:3]), int16(binary.LittleEndian.Uint16(battery[3:5])); voltage != 1264 || current != -83 {
+		t.Errorf("Expected 1264 and -83, got %d and %d", voltage, current)
+	}
+
+	detailed := EncodeDCDetailedStatus(DCDetailedStatus{Type: DCTypeBattery, SoC: 84.6, Health: 96, TimeRemaining: 1234.4, Ripple: 0.005, AmpHours: 338.4})
+	if len(detailed) != 11 || detailed[3] != 85 || detailed[4] != 96 {
+		t.Fatalf("Unexpected DC detailed status % X", detailed)
+	}
+	if remaining, ripple, ah := binary.LittleEndian.Uint16(detailed[5:7]), binary.LittleEndian.Uint16(detailed[7:9]), binary.LittleEndian.Uint16(detailed[9:11]); remaining != 1234 || ripple != 5 || ah != 338 {
+		t.Errorf("Expected 1234 min, 5 mV and 338 Ah, got %d, %d and %d", remaining, ripple, ah)
+	}
+	charging := EncodeDCDetailedStatus(DCDetailedStatus{SoC: math.NaN(), Health: math.NaN(), TimeRemaining: math.NaN(), Ripple: math.NaN(), AmpHours: math.NaN()})
+	if charging[3] != 0xFF || binary.LittleEndian.Uint16(charging[5:7]) != 0xFFFF {
+		t.Errorf("Expected not available fields, got % X", charging)
+	}
+
+	charger := EncodeChargerStatus(ChargerStatus{Instance: 0, BatteryInstance: 2, State: ChargerAbsorption, Enabled: true})
+	if charger[1] != 2 || charger[2] != ChargerAbsorption || charger[3]&0x03 != 1 {
+		t.Errorf("Unexpected charger status % X", charger)
+	}
+
+	config := EncodeBatteryConfiguration(BatteryConfiguration{
+		Type:             BatteryAGM,
+		NominalVoltage:   NominalVoltage12,
+		Chemistry:        ChemistryLeadAcid,
+		Capacity:         400,
+		Peukert:          1.25,
+		ChargeEfficiency: 90,
+	})
+	if config[1]&0x0F != BatteryAGM || config[2] != 0x01 || binary.LittleEndian.Uint16(config[3:5]) != 400 {
+		t.Errorf("Unexpected battery configuration % X", config)
+	}
+	if config[6] != 125 || config[7] != 90 {
+		t.Errorf("Expected Peukert 125 and efficiency 90, got %d and %d", config[6], config[7])
+	}
+}
+
+func TestEncodeFluidLevel(t *testing.T) {
+	data := EncodeFluidLevel(FluidLevel{Instance: 1, Type: 5, Level: 37.5, Capacity: 60})
+	if data[0] != 0x51 {
+		t.Errorf("Expected instance 1 of black water, got 0x%02X", data[0])
+	}
+	if level, capacity := binary.LittleEndian.Uint16(data[1:3]), binary.LittleEndian.Uint32(data[3:7]); level != 9375 || capacity != 600 {
+		t.Errorf("Expected level 9375 and capacity 600, got %d and %d", level, capacity)
+	}
+}
@@ -0,0 +1,108 @@
+package pgn
+
+// SingleFrameLength is the payload capacity of a single CAN frame
+const SingleFrameLength = 8
+
+// IsFastPacket reports whether a PGN is transmitted using the fast-packet protocol
+func IsFastPacket(pgn uint32) bool {
+	def, ok := CommonPGNs[pgn]
+	return ok && def.FastPacket
+}
+
+// Frames splits the message into the CAN frames used to transmit it.
+// Single-frame PGNs are returned as-is; fast-packet PGNs are split into a first
+// frame carrying the sequence counter and total length followed by frames of
+// seven data bytes each, padded with 0xFF.
+func (m Message) Frames() [][]byte {
+	if !IsFastPacket(m.PGN) {
+		return [][]byte{m.Data}
+	}
+
+	seq := (m.Sequence & 0x07) << 5
+
+	n := len(m.Data)
+	if n > 6 {
+		n = 6
+	}
+	first := append([]byte{seq, byte(len(m.Data))}, m.Data[:n]...)
+	frames := [][]byte{padFrame(first)}
+
+	for counter, offset := byte(1), n; offset < len(m.Data); counter++ {
+		end := offset + 7
+		if end > len(m.Data) {
+			end = len(m.Data)
+		}
+		frame := append([]byte{seq | (counter & 0x1F)}, m.Data[offset:end]...)
+		frames = append(frames, padFrame(frame))
+		offset = end
+	}
+
+	return frames
+}
+
+// padFrame pads a frame to eight bytes with 0xFF
+func padFrame(frame []byte) []byte {
+	for len(frame) < SingleFrameLength {
+		frame = append(frame, 0xFF)
+	}
+	return frame
+}
+
+// Assembler reassembles fast-packet messages from their frames, keeping a
+// partial message per PGN and source address
+type Assembler struct {
+	partial map[uint32]*partialPacket
+}
+
+// partialPacket is a fast-packet message waiting for its remaining frames
+type partialPacket struct {
+	sequence uint8
+	next     uint8 // Expected frame counter
+	length   int
+	data     []byte
+}
+
+// NewAssembler creates an empty fast-packet assembler
+func NewAssembler() *Assembler {
+	return &Assembler{partial: make(map[uint32]*partialPacket)}
+}
+
+// Add adds a received frame and returns the message once complete.
+// Single-frame PGNs are returned as-is. Frames arriving out of order drop
+// the partial message.
+func (a *Assembler) Add(frame Message) (Message, bool) {
+	if !IsFastPacket(frame.PGN) {
+		return frame, true
+	}
+	if len(frame.Data) < 2 {
+		return Message{}, false
+	}
+
+	key := uint32(frame.Source)<<24 | frame.PGN
+	seq, counter := frame.Data[0]>>5, frame.Data[0]&0x1F
+
+	if counter == 0 {
+		n := int(frame.Data[1])
+		p := &partialPacket{sequence: seq, next: 1, length: n, data: make([]byte, 0, n)}
+		a.partial[key] = p
+		return a.append(key, p, frame, frame.Data[2:])
+	}
+
+	p, ok := a.partial[key]
+	if !ok || p.sequence != seq || p.next != counter {
+		delete(a.partial, key)
+		return Message{}, false
+	}
+	p.next++
+	return a.append(key, p, frame, frame.Data[1:])
+}
+
+// append adds frame data to a partial message, returning it once all its bytes have arrived
+func (a *Assembler) append(key uint32, p *partialPacket, frame Message, data []byte) (Message, bool) {
+	p.data = append(p.data, data...)
+	if len(p.data) < p.length {
+		return Message{}, false
+	}
+	delete(a.partial, key)
+	return Message{PGN: frame.PGN, Data: p.data[:p.length], Sequence: p.sequence, Source: frame.Source}, true
+}
@@ -0,0 +1,141 @@
+package pgn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestFramesSingleFrame(t *testing.T) {
+	msg := Message{PGN: 129025, Data: EncodePosition(Position{Latitude: 48.1, Longitude: 16.3})}
+
+	frames := msg.Frames()
+	if len(frames) != 1 {
+		t.Fatalf("Expected 1 frame for single-frame PGN, got %d", len(frames))
+	}
+	if !bytes.Equal(frames[0], msg.Data) {
+		t.Error("Single frame should carry the message data unchanged")
+	}
+}
+
+func TestFramesFastPacket(t *testing.T) {
+	data := make([]byte, 28)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	msg := Message{PGN: 129038, Data: data, Sequence: 5}
+
+	frames := msg.Frames()
+	if len(frames) != 5 {
+		t.Fatalf("Expected 5 frames for 28 bytes, got %d", len(frames))
+	}
+
+	if frames[0][0] != 5<<5 || frames[0][1] != 28 {
+		t.Errorf("Unexpected first frame header: % X", frames[0][:2])
+	}
+
+	var reassembled []byte
+	for i, frame := range frames {
+		if len(frame) != 8 {
+			t.Errorf("Frame %d has %d bytes, expected 8", i, len(frame))
+		}
+		if frame[0]>>5 != 5 || frame[0]&0x1F != byte(i) {
+			t.Errorf("Frame %d has invalid sequence/counter byte %02X", i, frame[0])
+		}
+		if i == 0 {
+			reassembled = append(reassembled, frame[2:]...)
+		} else {
+			reassembled = append(reassembled, frame[1:]...)
+		}
+	}
+
+	if !bytes.Equal(reassembled[:28], data) {
+		t.Error("Reassembled frames do not match the original data")
+	}
+	for _, b := range reassembled[28:] {
+		if b != 0xFF {
+			t.Errorf("Expected 0xFF padding, got %02X", b)
+		}
+	}
+}
+
+func TestAssembler(t *testing.T) {
+	data := EncodeHeadingTrackControl(HeadingTrackControl{SteeringMode: SteeringHeadingControl, HeadingToSteer: 1.5})
+	msg := Message{PGN: 127237, Data: data, Sequence: 3, Source: 7}
+	a := NewAssembler()
+
+	frames := msg.Frames()
+	for i, frame := range frames {
+		got, ok := a.Add(Message{PGN: msg.PGN, Data: frame, Source: msg.Source})
+		if ok != (i == len(frames)-1) {
+			t.Fatalf("Frame %d: complete = %v", i, ok)
+		}
+		if ok && (!bytes.Equal(got.Data, data) || got.Source != 7 || got.Sequence != 3) {
+			t.Errorf("Reassembled % X from %d, expected % X from 7", got.Data, got.Source, data)
+		}
+	}
+
+	// A missing frame drops the message
+	a.Add(Message{PGN: msg.PGN, Data: frames[0], Source: msg.Source})
+	if _, ok := a.Add(Message{PGN: msg.PGN, Data: frames[2], Source: msg.Source}); ok {
+		t.Error("Expected the message to be dropped after a missing frame")
+	}
+	if _, ok := a.Add(Message{PGN: msg.PGN, Data: frames[3], Source: msg.Source}); ok {
+		t.Error("Expected later frames to be ignored")
+	}
+
+	single := Message{PGN: 127245, Data: EncodeRudder(Rudder{Position: 0.1})}
+	if got, ok := a.Add(single); !ok || !bytes.Equal(got.Data, single.Data) {
+		t.Error("Expected single-frame PGNs to pass through")
+	}
+}
+
+func TestEncodeAISLengths(t *testing.T) {
+	testCases := []struct {
+		pgn  uint32
+		data []byte
+	}{
+		{129038, EncodeAISClassAPosition(AISClassAPosition{MMSI: 1})},
+		{129039, EncodeAISClassBPosition(AISClassBPosition{MMSI: 1})},
+		{129794, EncodeAISClassAStatic(AISClassAStatic{MMSI: 1})},
+		{129809, EncodeAISClassBStaticA(AISClassBStaticA{MMSI: 1})},
+		{129810, EncodeAISClassBStaticB(AISClassBStaticB{MMSI: 1})},
+	}
+
+	for _, tc := range testCases {
+		def := CommonPGNs[tc.pgn]
+		if !def.FastPacket {
+			t.Errorf("PGN %d should be marked as fast-packet", tc.pgn)
+		}
+		if len(tc.data) != int(def.Length) {
+			t.Errorf("PGN %d encoded to %d bytes, expected %d", tc.pgn, len(tc.data), def.Length)
+		}
+		if binary.LittleEndian.Uint32(tc.data[1:5]) != 1 {
+			t.Errorf("PGN %d does not carry the MMSI in bytes 1-4", tc.pgn)
+		}
+	}
+}
+
+func TestEncodeAISClassAPosition(t *testing.T) {
+	data := EncodeAISClassAPosition(AISClassAPosition{
+		MessageID: 1,
+		MMSI:      244123456,
+		Longitude: -4.5,
+		Latitude:  51.25,
+		SOG:       5.14,
+		NavStatus: 5,
+	})
+
+	if lon := int32(binary.LittleEndian.Uint32(data[5:9])); lon != -45000000 {
+		t.Errorf("Expected longitude -45000000, got %d", lon)
+	}
+	if lat := int32(binary.LittleEndian.Uint32(data[9:13])); lat != 512500000 {
+		t.Errorf("Expected latitude 512500000, got %d", lat)
+	}
+	if sog := binary.LittleEndian.Uint16(data[16:18]); sog != 514 {
+		t.Errorf("Expected SOG 514, got %d", sog)
+	}
+	if data[25]&0x0F != 5 {
+		t.Errorf("Expected navigational status 5, got %d", data[25]&0x0F)
+	}
+}
@@ -0,0 +1,87 @@
+package pgn
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// GNSS modes used by PGN 129539
+const (
+	GNSSMode1D   = 0
+	GNSSMode2D   = 1
+	GNSSMode3D   = 2
+	GNSSModeAuto = 3
+)
+
+// Satellite status values used by PGN 129540
+const (
+	SatNotTracked = 0
+	SatTracked    = 1
+	SatUsed       = 2
+)
+
+// MaxSatsInView is the number of satellites that fit in a single PGN 129540 fast-packet
+const MaxSatsInView = 18
+
+// GNSSDOPs represents PGN 129539 data
+type GNSSDOPs struct {
+	SID         uint8
+	DesiredMode uint8
+	ActualMode  uint8
+	HDOP        float64
+	VDOP        float64
+	TDOP        float64
+}
+
+// EncodeGNSSDOPs encodes PGN 129539 data
+func EncodeGNSSDOPs(d GNSSDOPs) []byte {
+	data := make([]byte, 8)
+
+	data[0] = d.SID
+	data[1] = d.DesiredMode&0x07 | (d.ActualMode&0x07)<<3 | 0xC0
+	binary.LittleEndian.PutUint16(data[2:4], uint16(int16(math.Round(d.HDOP*100))))
+	binary.LittleEndian.PutUint16(data[4:6], uint16(int16(math.Round(d.VDOP*100))))
+	binary.LittleEndian.PutUint16(data[6:8], uint16(int16(math.Round(d.TDOP*100))))
+
+	return data
+}
+
+// SatelliteInView represents a single satellite entry of PGN 129540
+type SatelliteInView struct {
+	PRN       uint8
+	Elevation float64 // Radians
+	Azimuth   float64 // Radians
+	SNR       float64 // dB
+	Status    uint8
+}
+
+// SatsInView represents PGN 129540 data
+type SatsInView struct {
+	SID        uint8
+	Satellites []SatelliteInView
+}
+
+// EncodeSatsInView encodes PGN 129540 data, truncating to MaxSatsInView satellites
+func EncodeSatsInView(s SatsInView) []byte {
+	sats := s.Satellites
+	if len(sats) > MaxSatsInView {
+		sats = sats[:MaxSatsInView]
+	}
+
+	data := make([]byte, 3+12*len(sats))
+	data[0] = s.SID
+	data[1] = 0xFC // Range residuals used in computation, reserved
+	data[2] = uint8(len(sats))
+
+	for i, sat := range sats {
+		b := data[3+12*i:]
+		b[0] = sat.PRN
+		binary.LittleEndian.PutUint16(b[1:3], uint16(int16(math.Round(sat.Elevation*10000))))
+		binary.LittleEndian.PutUint16(b[3:5], uint16(math.Round(sat.Azimuth*10000)))
+		binary.LittleEndian.PutUint16(b[5:7], uint16(math.Round(sat.SNR*100)))
+		binary.LittleEndian.PutUint32(b[7:11], 0x7FFFFFFF) // Range residuals not available
+		b[11] = sat.Status&0x0F | 0xF0
+	}
+
+	return data
+}
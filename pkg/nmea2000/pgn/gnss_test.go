@@ -0,0 +1,38 @@
+package pgn
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestEncodeGNSSDOPs(t *testing.T) {
+	data := EncodeGNSSDOPs(GNSSDOPs{SID: 7, DesiredMode: GNSSModeAuto, ActualMode: GNSSMode3D, HDOP: 0.85, VDOP: 1.2, TDOP: 0.6})
+
+	if len(data) != 8 {
+		t.Fatalf("Expected 8 bytes, got %d", len(data))
+	}
+	if data[1]&0x07 != GNSSModeAuto || (data[1]>>3)&0x07 != GNSSMode3D {
+		t.Errorf("Unexpected mode byte 0x%02X", data[1])
+	}
+	if hdop := binary.LittleEndian.Uint16(data[2:4]); hdop != 85 {
+		t.Errorf("Expected HDOP 85, got %d", hdop)
+	}
+}
+
+func TestEncodeSatsInView(t *testing.T) {
+	sats := make([]SatelliteInView, MaxSatsInView+4)
+	for i := range sats {
+		sats[i] = SatelliteInView{PRN: uint8(i + 1), SNR: 42, Status: SatUsed}
+	}
+
+	data := EncodeSatsInView(SatsInView{SID: 1, Satellites: sats})
+	if len(data) != 3+12*MaxSatsInView {
+		t.Fatalf("Expected %d bytes, got %d", 3+12*MaxSatsInView, len(data))
+	}
+	if data[2] != MaxSatsInView {
+		t.Errorf("Expected %d satellites, got %d", MaxSatsInView, data[2])
+	}
+	if snr := binary.LittleEndian.Uint16(data[3+5 : 3+7]); snr != 4200 {
+		t.Errorf("Expected SNR 4200, got %d", snr)
+	}
+}
@@ -0,0 +1,224 @@
+package pgn
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Rudder direction orders used in PGNs 127245 and 127237
+const (
+	RudderNoOrder   uint8 = 0
+	RudderStarboard uint8 = 1
+	RudderPort      uint8 = 2
+)
+
+// Steering modes used in PGN 127237
+const (
+	SteeringMain              uint8 = 0
+	SteeringNonFollowUp       uint8 = 1
+	SteeringFollowUp          uint8 = 2
+	SteeringHeadingStandalone uint8 = 3
+	SteeringHeadingControl    uint8 = 4
+	SteeringTrackControl      uint8 = 5
+)
+
+// Heading references used in PGN 127237
+const (
+	HeadingTrue     uint8 = 0
+	HeadingMagnetic uint8 = 1
+)
+
+// Rudder represents PGN 127245 data. Angles are positive to starboard.
+type Rudder struct {
+	Instance       uint8
+	DirectionOrder uint8   // One of the Rudder* orders
+	AngleOrder     float64 // Radians
+	Position       float64 // Radians
+}
+
+// EncodeRudder encodes PGN 127245 data, sending NaN as not available
+func EncodeRudder(r Rudder) []byte {
+	data := make([]byte, 8)
+
+	data[0] = r.Instance
+	data[1] = 0xF8 | r.DirectionOrder&0x07
+	binary.LittleEndian.PutUint16(data[2:4], uint16(encodeSignedAngle(r.AngleOrder)))
+	binary.LittleEndian.PutUint16(data[4:6], uint16(encodeSignedAngle(r.Position)))
+
+	// Reserved bytes
+	data[6] = 0xFF
+	data[7] = 0xFF
+
+	return data
+}
+
+// HeadingTrackControl represents PGN 127237 data. Angles are in radians and
+// rudder angles are positive to starboard.
+type HeadingTrackControl struct {
+	SteeringMode     uint8 // One of the Steering* modes
+	HeadingReference uint8 // HeadingTrue or HeadingMagnetic
+	RudderDirection  uint8 // Commanded rudder direction, one of the Rudder* orders
+	RudderAngle      float64
+	HeadingToSteer   float64
+	Track            float64
+	RudderLimit      float64
+	OffHeadingLimit  float64
+	RateOfTurnOrder  float64 // Radians per second
+	OffTrackLimit    float64 // Meters
+	VesselHeading    float64
+}
+
+// HeadingTrackControlLength is the length of PGN 127237
+const HeadingTrackControlLength = 21
+
+// EncodeHeadingTrackControl encodes PGN 127237 data without limit alarms,
+// override or turn mode, sending NaN as not available
+func EncodeHeadingTrackControl(c HeadingTrackControl) []byte {
+	data := make([]byte, HeadingTrackControlLength)
+
+	// Limit exceeded and override flags off, turn mode not available
+	data[0] = 0x00
+	data[1] = c.SteeringMode&0x07 | 0x07<<3 | c.HeadingReference<<6
+	data[2] = 0xF8 | c.RudderDirection&0x07
+
+	binary.LittleEndian.PutUint16(data[3:5], uint16(encodeSignedAngle(c.RudderAngle)))
+	binary.LittleEndian.PutUint16(data[5:7], encodeAngle(c.HeadingToSteer))
+	binary.LittleEndian.PutUint16(data[7:9], encodeAngle(c.Track))
+	binary.LittleEndian.PutUint16(data[9:11], encodeAngle(c.RudderLimit))
+	binary.LittleEndian.PutUint16(data[11:13], encodeAngle(c.OffHeadingLimit))
+
+	// Radius of turn order not available
+	binary.LittleEndian.PutUint16(data[13:15], uint16(math.MaxInt16))
+
+	// Rate of turn order in units of 3.125e-5 rad/s
+	rot := int16(math.MaxInt16)
+	if !math.IsNaN(c.RateOfTurnOrder) {
+		rot = int16(math.Round(c.RateOfTurnOrder / 3.125e-5))
+	}
+	binary.LittleEndian.PutUint16(data[15:17], uint16(rot))
+
+	// Off-track limit in meters
+	offTrack := int16(math.MaxInt16)
+	if !math.IsNaN(c.OffTrackLimit) {
+		offTrack = int16(math.Round(c.OffTrackLimit))
+	}
+	binary.LittleEndian.PutUint16(data[17:19], uint16(offTrack))
+
+	binary.LittleEndian.PutUint16(data[19:21], encodeAngle(c.VesselHeading))
+
+	return data
+}
+
+// DecodeHeadingTrackControl decodes PGN 127237 data, returning fields that
+// are not available as NaN
+func DecodeHeadingTrackControl(data []byte) (HeadingTrackControl, error) {
+	if len(data) < HeadingTrackControlLength {
+		return HeadingTrackControl{}, fmt.Errorf("PGN 127237: %d bytes, expected %d", len(data), HeadingTrackControlLength)
+	}
+
+	c := HeadingTrackControl{
+		SteeringMode:     data[1] & 0x07,
+		HeadingReference: data[1] >> 6,
+		RudderDirection:  data[2] & 0x07,
+		RudderAngle:      decodeSignedAngle(data[3:5]),
+		HeadingToSteer:   decodeAngle(data[5:7]),
+		Track:            decodeAngle(data[7:9]),
+		RudderLimit:      decodeAngle(data[9:11]),
+		OffHeadingLimit:  decodeAngle(data[11:13]),
+		RateOfTurnOrder:  math.NaN(),
+		OffTrackLimit:    math.NaN(),
+		VesselHeading:    decodeAngle(data[19:21]),
+	}
+	if rot := int16(binary.LittleEndian.Uint16(data[15:17])); rot != math.MaxInt16 {
+		c.RateOfTurnOrder = float64(rot) * 3.125e-5
+	}
+	if offTrack := int16(binary.LittleEndian.Uint16(data[17:19])); offTrack != math.MaxInt16 {
+		c.OffTrackLimit = float64(offTrack)
+	}
+	return c, nil
+}
+
+// Raymarine proprietary PGNs used by its autopilots
+const (
+	PGNSeatalkLockedHeading uint32 = 65360
+	PGNSeatalkPilotMode     uint32 = 65379
+)
+
+// Raymarine manufacturer code in the marine industry group
+const raymarineManufacturer = 1851
+
+// Seatalk pilot modes used in PGN 65379
+const (
+	SeatalkStandby uint16 = 0x0000
+	SeatalkAuto    uint16 = 0x0040
+	SeatalkWind    uint16 = 0x0100
+	SeatalkTrack   uint16 = 0x0180
+)
+
+// SeatalkPilotMode represents Raymarine PGN 65379 data
+type SeatalkPilotMode struct {
+	Mode    uint16 // One of the Seatalk* modes
+	SubMode uint16
+}
+
+// SeatalkLockedHeading represents Raymarine PGN 65360 data
+type SeatalkLockedHeading struct {
+	SID             uint8
+	HeadingTrue     float64 // Radians
+	HeadingMagnetic float64 // Radians
+}
+
+// DecodeSeatalkPilotMode decodes Raymarine PGN 65379 data
+func DecodeSeatalkPilotMode(data []byte) (SeatalkPilotMode, error) {
+	if err := checkRaymarine(PGNSeatalkPilotMode, data, 6); err != nil {
+		return SeatalkPilotMode{}, err
+	}
+	return SeatalkPilotMode{
+		Mode:    binary.LittleEndian.Uint16(data[2:4]),
+		SubMode: binary.LittleEndian.Uint16(data[4:6]),
+	}, nil
+}
+
+// DecodeSeatalkLockedHeading decodes Raymarine PGN 65360 data, returning
+// headings that are not available as NaN
+func DecodeSeatalkLockedHeading(data []byte) (SeatalkLockedHeading, error) {
+	if err := checkRaymarine(PGNSeatalkLockedHeading, data, 7); err != nil {
+		return SeatalkLockedHeading{}, err
+	}
+	return SeatalkLockedHeading{
+		SID:             data[2],
+		HeadingTrue:     decodeAngle(data[3:5]),
+		HeadingMagnetic: decodeAngle(data[5:7]),
+	}, nil
+}
+
+// checkRaymarine checks the length and manufacturer code of a proprietary PGN
+func checkRaymarine(number uint32, data []byte, length int) error {
+	if len(data) < length {
+		return fmt.Errorf("PGN %d: %d bytes, expected %d", number, len(data), length)
+	}
+	header := binary.LittleEndian.Uint16(data[0:2])
+	if mfr, industry := header&0x07FF, header>>13; mfr != raymarineManufacturer || industry != 4 {
+		return fmt.Errorf("PGN %d: manufacturer %d in industry %d is not Raymarine", number, mfr, industry)
+	}
+	return nil
+}
+
+// decodeAngle converts 1e-4 rad units to radians, not available as NaN
+func decodeAngle(b []byte) float64 {
+	v := binary.LittleEndian.Uint16(b)
+	if v == 0xFFFF {
+		return math.NaN()
+	}
+	return float64(v) / 10000
+}
+
+// decodeSignedAngle converts signed 1e-4 rad units to radians, not available as NaN
+func decodeSignedAngle(b []byte) float64 {
+	v := int16(binary.LittleEndian.Uint16(b))
+	if v == math.MaxInt16 {
+		return math.NaN()
+	}
+	return float64(v) / 10000
+}
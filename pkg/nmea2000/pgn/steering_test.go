@@ -0,0 +1,81 @@
+package pgn
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestEncodeRudder(t *testing.T) {
+	data := EncodeRudder(Rudder{DirectionOrder: RudderPort, AngleOrder: -0.2, Position: math.NaN()})
+	if data[1]&0x07 != RudderPort {
+		t.Errorf("Expected direction order port, got %d", data[1]&0x07)
+	}
+	if order, pos := int16(binary.LittleEndian.Uint16(data[2:4])), int16(binary.LittleEndian.Uint16(data[4:6])); order != -2000 || pos != math.MaxInt16 {
+		t.Errorf("Expected order -2000 and position not available, got %d and %d", order, pos)
+	}
+}
+
+func TestHeadingTrackControlRoundTrip(t *testing.T) {
+	in := HeadingTrackControl{
+		SteeringMode:     SteeringTrackControl,
+		HeadingReference: HeadingMagnetic,
+		RudderDirection:  RudderStarboard,
+		RudderAngle:      -0.1234,
+		HeadingToSteer:   3.1416,
+		Track:            3.0,
+		RudderLimit:      0.6109,
+		OffHeadingLimit:  math.NaN(),
+		RateOfTurnOrder:  -0.005,
+		OffTrackLimit:    185,
+		VesselHeading:    3.2,
+	}
+	data := EncodeHeadingTrackControl(in)
+	if len(data) != HeadingTrackControlLength {
+		t.Fatalf("Expected %d bytes, got %d", HeadingTrackControlLength, len(data))
+	}
+
+	out, err := DecodeHeadingTrackControl(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.SteeringMode != in.SteeringMode || out.HeadingReference != in.HeadingReference || out.RudderDirection != in.RudderDirection {
+		t.Errorf("Mode, reference or direction changed: %+v", out)
+	}
+	for name, pair := range map[string][2]float64{
+		"rudder angle":     {in.RudderAngle, out.RudderAngle},
+		"heading to steer": {in.HeadingToSteer, out.HeadingToSteer},
+		"track":            {in.Track, out.Track},
+		"rudder limit":     {in.RudderLimit, out.RudderLimit},
+		"rate of turn":     {in.RateOfTurnOrder, out.RateOfTurnOrder},
+		"off-track limit":  {in.OffTrackLimit, out.OffTrackLimit},
+		"vessel heading":   {in.VesselHeading, out.VesselHeading},
+	} {
+		if math.Abs(pair[0]-pair[1]) > 1e-4 {
+			t.Errorf("%s: expected %.4f, got %.4f", name, pair[0], pair[1])
+		}
+	}
+	if !math.IsNaN(out.OffHeadingLimit) {
+		t.Errorf("Expected off-heading limit not available, got %f", out.OffHeadingLimit)
+	}
+
+	if _, err := DecodeHeadingTrackControl(data[:8]); err == nil {
+		t.Error("Expected an error for a truncated message")
+	}
+}
+
+func TestDecodeSeatalk(t *testing.T) {
+	mode, err := DecodeSeatalkPilotMode([]byte{0x3B, 0x9F, 0x40, 0x00, 0x00, 0x00, 0xFF, 0xFF})
+	if err != nil || mode.Mode != SeatalkAuto {
+		t.Errorf("Expected auto mode, got %+v, %v", mode, err)
+	}
+
+	heading, err := DecodeSeatalkLockedHeading([]byte{0x3B, 0x9F, 0x01, 0xFF, 0xFF, 0x10, 0x27, 0xFF})
+	if err != nil || !math.IsNaN(heading.HeadingTrue) || heading.HeadingMagnetic != 1 {
+		t.Errorf("Expected magnetic heading 1 rad only, got %+v, %v", heading, err)
+	}
+
+	if _, err := DecodeSeatalkPilotMode([]byte{0x99, 0x9F, 0x40, 0x00, 0x00, 0x00, 0xFF, 0xFF}); err == nil {
+		t.Error("Expected an error for another manufacturer")
+	}
+}
@@ -11,16 +11,117 @@ type PGNDefinition struct {
 	Max         float64
 	Units       string
 	Description string
+	FastPacket  bool // Transmitted as a multi-frame fast-packet
 }
 
 // CommonPGNs defines the most commonly used PGNs in marine applications
 var CommonPGNs = map[uint32]PGNDefinition{
+	65360: {
+		PGN:         65360,
+		Name:        "Seatalk: Pilot Locked Heading",
+		Description: "Raymarine proprietary heading the autopilot is locked to",
+		Length:      8,
+	},
+	65379: {
+		PGN:         65379,
+		Name:        "Seatalk: Pilot Mode",
+		Description: "Raymarine proprietary autopilot mode",
+		Length:      8,
+	},
+	127237: {
+		PGN:         127237,
+		Name:        "Heading/Track Control",
+		Description: "Autopilot steering mode, heading to steer, track and rudder command",
+		Length:      21,
+		FastPacket:  true,
+	},
+	127245: {
+		PGN:         127245,
+		Name:        "Rudder",
+		Description: "Rudder angle order and position, positive to starboard",
+		Length:      8,
+	},
 	127250: {
 		PGN:         127250,
 		Name:        "Vessel Heading",
 		Description: "Heading sensor value with a flag for True or Magnetic",
 		Length:      8,
 	},
+	127251: {
+		PGN:         127251,
+		Name:        "Rate of Turn",
+		Description: "Rate of change of heading, positive to starboard",
+		Length:      8,
+	},
+	127252: {
+		PGN:         127252,
+		Name:        "Heave",
+		Description: "Vertical displacement of the vessel",
+		Length:      8,
+	},
+	127257: {
+		PGN:         127257,
+		Name:        "Attitude",
+		Description: "Yaw, pitch and roll",
+		Length:      8,
+	},
+	127258: {
+		PGN:         127258,
+		Name:        "Magnetic Variation",
+		Description: "Magnetic variation and the method used to obtain it",
+		Length:      8,
+	},
+	127488: {
+		PGN:         127488,
+		Name:        "Engine Parameters, Rapid Update",
+		Description: "Engine speed, boost pressure and tilt/trim",
+		Length:      8,
+	},
+	127489: {
+		PGN:         127489,
+		Name:        "Engine Parameters, Dynamic",
+		Description: "Engine pressures, temperatures, fuel rate, hours and alarm status",
+		Length:      26,
+		FastPacket:  true,
+	},
+	127493: {
+		PGN:         127493,
+		Name:        "Transmission Parameters, Dynamic",
+		Description: "Gear, transmission oil pressure and temperature",
+		Length:      8,
+	},
+	127505: {
+		PGN:         127505,
+		Name:        "Fluid Level",
+		Description: "Tank fluid type, level and capacity",
+		Length:      8,
+	},
+	127506: {
+		PGN:         127506,
+		Name:        "DC Detailed Status",
+		Description: "State of charge, health, time remaining and ripple of a DC source",
+		Length:      11,
+		FastPacket:  true,
+	},
+	127507: {
+		PGN:         127507,
+		Name:        "Charger Status",
+		Description: "Charger operating state and mode",
+		Length:      8,
+	},
+	127508: {
+		PGN:         127508,
+		Name:        "Battery Status",
+		Description: "Battery voltage, current and temperature",
+		Length:      8,
+	},
+	127513: {
+		PGN:         127513,
+		Name:        "Battery Configuration Status",
+		Description: "Battery type, chemistry, nominal voltage and capacity",
+		Length:      8,
+		FastPacket:  true,
+	},
 	128259: {
 		PGN:         128259,
 		Name:        "Speed",
@@ -45,16 +146,109 @@ var CommonPGNs = map[uint32]PGNDefinition{
 		Description: "Course Over Ground and Speed Over Ground",
 		Length:      8,
 	},
+	129038: {
+		PGN:         129038,
+		Name:        "AIS Class A Position Report",
+		Description: "AIS message types 1, 2 and 3",
+		Length:      28,
+		FastPacket:  true,
+	},
+	129039: {
+		PGN:         129039,
+		Name:        "AIS Class B Position Report",
+		Description: "AIS message type 18",
+		Length:      27,
+		FastPacket:  true,
+	},
+	129539: {
+		PGN:         129539,
+		Name:        "GNSS DOPs",
+		Description: "Dilution of precision of the GNSS solution",
+		Length:      8,
+	},
+	129540: {
+		PGN:         129540,
+		Name:        "GNSS Sats in View",
+		Description: "Satellites in view with elevation, azimuth, SNR and status (variable length)",
+		Length:      219,
+		FastPacket:  true,
+	},
+	129794: {
+		PGN:         129794,
+		Name:        "AIS Class A Static and Voyage Related Data",
+		Description: "AIS message type 5",
+		Length:      75,
+		FastPacket:  true,
+	},
+	129809: {
+		PGN:         129809,
+		Name:        "AIS Class B Static Data, Part A",
+		Description: "AIS message type 24 part A",
+		Length:      27,
+		FastPacket:  true,
+	},
+	129810: {
+		PGN:         129810,
+		Name:        "AIS Class B Static Data, Part B",
+		Description: "AIS message type 24 part B",
+		Length:      34,
+		FastPacket:  true,
+	},
 	130306: {
 		PGN:         130306,
 		Name:        "Wind Data",
 		Description: "Wind speed, direction, and reference",
 		Length:      8,
 	},
+	130310: {
+		PGN:         130310,
+		Name:        "Environmental Parameters (obsolete)",
+		Description: "Water temperature, outside air temperature and atmospheric pressure",
+		Length:      8,
+	},
+	130311: {
+		PGN:         130311,
+		Name:        "Environmental Parameters",
+		Description: "Temperature, humidity and atmospheric pressure with their sources",
+		Length:      8,
+	},
+	130312: {
+		PGN:         130312,
+		Name:        "Temperature",
+		Description: "Actual and set temperature of a source instance",
+		Length:      8,
+	},
+	130313: {
+		PGN:         130313,
+		Name:        "Humidity",
+		Description: "Actual and set humidity of a source instance",
+		Length:      8,
+	},
+	130314: {
+		PGN:         130314,
+		Name:        "Actual Pressure",
+		Description: "Pressure of a source instance",
+		Length:      8,
+	},
+	130316: {
+		PGN:         130316,
+		Name:        "Temperature, Extended Range",
+		Description: "Actual and set temperature of a source instance with 0.001 K resolution",
+		Length:      8,
+	},
+	130577: {
+		PGN:         130577,
+		Name:        "Direction Data",
+		Description: "Course, speed, heading and set and drift of the current",
+		Length:      14,
+		FastPacket:  true,
+	},
 }
 
 // Message represents a NMEA 2000 message with its PGN and data
 type Message struct {
-	PGN  uint32
-	Data []byte
-}
\ No newline at end of file
+	PGN      uint32
+	Data     []byte
+	Sequence uint8 // Fast-packet sequence counter (0-7)
+	Source   uint8 // Source address of the sending device
+}
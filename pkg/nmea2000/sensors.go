@@ -0,0 +1,105 @@
+package nmea2000
+
+import (
+	"math"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// VariationMessage returns PGN 127258 Magnetic Variation for the variation in
+// degrees, east positive, valid on the date of t
+func VariationMessage(variation float64, source uint8, t time.Time, sid uint8) pgn.Message {
+	return pgn.Message{
+		PGN: 127258,
+		Data: pgn.EncodeMagneticVariation(pgn.MagneticVariation{
+			SID:          sid,
+			Source:       source,
+			AgeOfService: uint16(t.Unix() / 86400),
+			Variation:    degToRad(variation),
+		}),
+	}
+}
+
+// HeadingMessages returns PGN 127250 Vessel Heading and PGN 127251 Rate of
+// Turn from every heading sensor, or from own ship's true heading when no
+// heading sensors are installed. Magnetic sensors report their compass heading
+// with the deviation from their card; every sensor reports the variation.
+func HeadingMessages(own simulation.Vessel, sensors simulation.Sensors, variation float64, sid uint8) []pgn.Message {
+	headingSensors := sensors.Kind(simulation.SensorHeading)
+	if len(headingSensors) == 0 {
+		h := simulation.ResolveHeading(own.Heading, variation, nil)
+		return headingMessages(h.True, 0, h.Variation, 0, own.ROT, sid, 0)
+	}
+
+	var msgs []pgn.Message
+	for _, sensor := range headingSensors {
+		reading := sensor.Reading()
+		if reading.Status == simulation.ReadingNone {
+			continue
+		}
+
+		cfg := sensor.Config()
+		h := simulation.ResolveHeading(reading.Vessel.Heading, variation, cfg.Deviation)
+		heading, deviation, reference, rot := h.True, 0.0, uint8(0), reading.Vessel.ROT
+		if cfg.Magnetic {
+			heading, deviation, reference = h.Compass, h.Deviation, 1
+		}
+		if reading.Status == simulation.ReadingInvalid {
+			heading, rot = math.NaN(), math.NaN()
+		}
+
+		msgs = append(msgs, headingMessages(heading, deviation, h.Variation, reference, rot, sid, cfg.Source)...)
+	}
+	return msgs
+}
+
+// headingMessages builds PGNs 127250 and 127251 from the given source address.
+// Angles are in degrees and the rate of turn in degrees per minute.
+func headingMessages(heading, deviation, variation float64, reference uint8, rot float64, sid, source uint8) []pgn.Message {
+	return []pgn.Message{
+		{
+			PGN: 127250,
+			Data: pgn.EncodeVesselHeading(pgn.VesselHeading{
+				Heading:   degToRad(heading),
+				Deviation: degToRad(deviation),
+				Variation: degToRad(variation),
+				Reference: reference,
+			}),
+			Source: source,
+		},
+		{
+			PGN: 127251,
+			Data: pgn.EncodeRateOfTurn(pgn.RateOfTurn{
+				SID:  sid,
+				Rate: degToRad(rot) / 60,
+			}),
+			Source: source,
+		},
+	}
+}
+
+// SensorGNSSMessages returns the position and COG & SOG PGNs for every GNSS
+// sensor, each from its own source address, with the GNSS DOP and satellite
+// PGNs when satellites is set
+func SensorGNSSMessages(sensors simulation.Sensors, satellites bool, sid uint8) []pgn.Message {
+	var msgs []pgn.Message
+	for _, sensor := range sensors {
+		reading := sensor.Reading()
+		if reading.Status == simulation.ReadingNone {
+			continue
+		}
+
+		source := sensor.Config().Source
+		sensorMsgs := PositionMessages(reading.Fix, sid)
+		if satellites {
+			sensorMsgs = append(sensorMsgs, GNSSMessages(reading.Fix, sid)...)
+		}
+		for _, msg := range sensorMsgs {
+			msg.Source = source
+			msgs = append(msgs, msg)
+		}
+	}
+	return msgs
+}
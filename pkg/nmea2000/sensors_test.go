@@ -0,0 +1,104 @@
+package nmea2000
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+func TestSensorMessagesUseSourceAddress(t *testing.T) {
+	sensors, err := simulation.NewSensors([]simulation.SensorConfig{
+		{Name: "gps1", Kind: simulation.SensorGNSS, Source: 10},
+		{Name: "gps2", Kind: simulation.SensorGNSS, Source: 11},
+		{Name: "compass", Kind: simulation.SensorHeading, Source: 20},
+		{Name: "satcompass", Kind: simulation.SensorHeading, Source: 21, FailureRate: 1e9, FailureDuration: 60, FailureMode: simulation.FailureInvalid},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fleet := simulation.NewFleet(simulation.DefaultOwnShip())
+	now := time.Now()
+	sensors.Sample(fleet, now)
+	sensors.Sample(fleet, now.Add(time.Second))
+
+	headings := HeadingMessages(fleet.OwnShip(), sensors, 4.5, 1)
+	if len(headings) != 4 || headings[0].Source != 20 || headings[2].Source != 21 {
+		t.Fatalf("Expected heading and rate of turn PGNs from sources 20 and 21, got %+v", headings)
+	}
+	if headings[0].PGN != 127250 || headings[1].PGN != 127251 {
+		t.Errorf("Expected PGNs 127250 and 127251, got %d and %d", headings[0].PGN, headings[1].PGN)
+	}
+	if raw := binary.LittleEndian.Uint16(headings[2].Data[0:2]); raw != 0xFFFF {
+		t.Errorf("Failed compass should send heading not available, got 0x%04X", raw)
+	}
+	if raw := binary.LittleEndian.Uint32(headings[3].Data[1:5]); raw != 0x7FFFFFFF {
+		t.Errorf("Failed compass should send rate of turn not available, got 0x%08X", raw)
+	}
+
+	sources := map[uint8]int{}
+	for _, msg := range SensorGNSSMessages(sensors.Kind(simulation.SensorGNSS), true, 1) {
+		sources[msg.Source]++
+	}
+	if sources[10] != 4 || sources[11] != 4 {
+		t.Errorf("Expected position, COG & SOG, DOP and satellite PGNs per GNSS sensor, got %v", sources)
+	}
+
+	// The DOP and satellite PGNs are only sent when enabled
+	if msgs := SensorGNSSMessages(sensors.Kind(simulation.SensorGNSS), false, 1); len(msgs) != 4 || msgs[0].PGN != 129025 || msgs[1].PGN != 129026 {
+		t.Errorf("Expected only position and COG & SOG PGNs, got %+v", msgs)
+	}
+}
+
+func TestHeadingMessagesMagneticSensor(t *testing.T) {
+	sensors, err := simulation.NewSensors([]simulation.SensorConfig{
+		{Name: "fluxgate", Kind: simulation.SensorHeading, Source: 20, Magnetic: true,
+			Deviation: simulation.DeviationCard{{Heading: 0, Deviation: -2}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	own := simulation.DefaultOwnShip()
+	now := time.Now()
+	sensors[0].Sample(own, now)
+	own.Heading += 1
+	sensors[0].Sample(own, now.Add(time.Second))
+
+	msgs := HeadingMessages(own, sensors, 5, 1)
+	heading := float64(binary.LittleEndian.Uint16(msgs[0].Data[0:2])) / 10000 * 180 / math.Pi
+	deviation := float64(int16(binary.LittleEndian.Uint16(msgs[0].Data[2:4]))) / 10000 * 180 / math.Pi
+
+	// Compass = true - variation - deviation = 46 - 5 + 2
+	if math.Abs(heading-43) > 0.01 || math.Abs(deviation+2) > 0.01 || msgs[0].Data[6] != 1 {
+		t.Errorf("Expected magnetic compass heading 43 with deviation -2, got %.2f and %.2f", heading, deviation)
+	}
+
+	// One degree in one second is 60 degrees per minute
+	rate := float64(int32(binary.LittleEndian.Uint32(msgs[1].Data[1:5]))) * 3.125e-8 * 180 / math.Pi * 60
+	if math.Abs(rate-60) > 0.01 {
+		t.Errorf("Expected rate of turn 60 deg/min, got %.2f", rate)
+	}
+}
+
+func TestVariationMessage(t *testing.T) {
+	own := simulation.DefaultOwnShip()
+	now := time.Date(2025, 4, 29, 0, 0, 0, 0, time.UTC)
+	variation := simulation.WMMVariation(own.Latitude, own.Longitude, now)
+
+	msg := VariationMessage(variation, pgn.VariationCalculation, now, 5)
+	if msg.PGN != 127258 || msg.Data[0] != 5 {
+		t.Fatalf("Expected PGN 127258 with SID 5, got %d with SID %d", msg.PGN, msg.Data[0])
+	}
+	if days := binary.LittleEndian.Uint16(msg.Data[2:4]); days != 20207 {
+		t.Errorf("Expected age of service 20207 days, got %d", days)
+	}
+	raw := int16(binary.LittleEndian.Uint16(msg.Data[4:6]))
+	if got := float64(raw) / 10000 * 180 / math.Pi; math.Abs(got-variation) > 0.01 {
+		t.Errorf("Expected variation %.2f, got %.2f", variation, got)
+	}
+}
@@ -7,31 +7,169 @@ import (
 
 	"github.com/captv89/nmea-simulator/pkg/network"
 	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
 )
 
 // Simulator represents a NMEA 2000 network simulator
 type Simulator struct {
 	transport    network.NMEA2000Server
 	webSocket    network.NMEA2000Server
+	clients      []network.NMEA2000Server
 	updatePeriod time.Duration
+	controls     *network.Controls
+	snapshots    *network.Snapshots
+	fleet        *simulation.Fleet
+	gnss         *simulation.GNSSReceiver
+	sensors      simulation.Sensors
+	variation    simulation.VariationFunc
+	electrical   *simulation.ElectricalModel
+	autopilot    *simulation.Autopilot
+	commands     bool
+	lastBattery  time.Time
+	varSource    uint8
+	enableGNSS   bool
+	enableAIS    bool
+	outputs      outputs
+	ais          *aisSchedule
+	sequence     map[uint32]uint8
+	sid          uint8
 	done         chan struct{}
 }
 
 // Config holds simulator configuration
 type Config struct {
-	Transport    network.NMEA2000Server
-	WebSocket    network.NMEA2000Server
-	UpdatePeriod time.Duration
+	Transport         network.NMEA2000Server
+	WebSocket         network.NMEA2000Server
+	Clients           []network.NMEA2000Server // Outbound transports connecting to remote listeners, started and stopped with the simulator
+	UpdatePeriod      time.Duration
+	Controls          *network.Controls           // Update period and disabled PGNs changed at run time, defaults to UpdatePeriod with every PGN enabled
+	Snapshots         *network.Snapshots          // Samples of the models shared with the NMEA 0183 outputs, defaults to sampling the models below
+	Fleet             *simulation.Fleet           // Own ship and targets, defaults to a stationary own ship
+	GNSS              *simulation.GNSSReceiver    // GNSS receiver model, position PGNs are disabled when nil
+	EnableGNSS        bool                        // Send PGNs 129539 GNSS DOPs and 129540 GNSS Sats in View with the position
+	EnableAIS         bool                        // Send AIS PGNs for the fleet's targets
+	Sensors           simulation.Sensors          // Sensor instances replacing the default GNSS receiver and heading
+	Variation         simulation.VariationFunc    // Magnetic variation source, defaults to the World Magnetic Model; other sources are reported as manual
+	Wind              *simulation.WindModel       // True wind model, defaults to DefaultWindConfig
+	Depth             *simulation.DepthSounder    // Echo sounder, defaults to a flat 25 m bottom
+	Weather           *simulation.WeatherModel    // Weather model, defaults to DefaultWeatherConfig
+	Attitude          *simulation.AttitudeModel   // Wave-driven motion model, defaults to DefaultSeaStateConfig
+	Engines           *simulation.EngineModel     // Own ship's engines, defaults to DefaultEngineConfig
+	Electrical        *simulation.ElectricalModel // Batteries and charging sources, defaults to DefaultElectricalConfig
+	Tanks             *simulation.TankModel       // Tank levels, defaults to DefaultTanks
+	Autopilot         *simulation.Autopilot       // Own ship's autopilot and steering, PGN 127245 Rudder is disabled when nil
+	AutopilotCommands bool                        // Steer the autopilot by PGN 127237 and Raymarine commands, reporting its status in PGN 127237
+	EnableWeather     bool                        // Send the air temperature, humidity and pressure PGNs, otherwise only the sea temperature
+	EnableAttitude    bool                        // Send PGNs 127257 Attitude and 127252 Heave
+	EnableEngine      bool                        // Send PGNs 127488, 127489 and 127493 of the engines
+	EnableTanks       bool                        // Send PGN 127505 Fluid Level
+	EnableElectrical  bool                        // Send the battery, charger and charging source PGNs
+}
+
+// outputs holds the optional PGN groups a simulator sends
+type outputs struct {
+	weather    bool
+	attitude   bool
+	engine     bool
+	tanks      bool
+	electrical bool
 }
 
 // New creates a new NMEA 2000 simulator
 func New(cfg Config) *Simulator {
-	return &Simulator{
+	if cfg.Fleet == nil {
+		cfg.Fleet = simulation.NewFleet(simulation.DefaultOwnShip())
+	}
+	varSource := pgn.VariationManual
+	if cfg.Variation == nil {
+		cfg.Variation = simulation.WMMVariation
+		varSource = pgn.VariationCalculation
+	}
+	if cfg.Wind == nil {
+		cfg.Wind = simulation.NewWindModel(simulation.DefaultWindConfig())
+	}
+	if cfg.Depth == nil {
+		cfg.Depth = simulation.NewDepthSounder(simulation.DefaultDepthConfig(), simulation.FlatBathymetry(25), simulation.TideHeight{})
+	}
+	if cfg.Weather == nil {
+		cfg.Weather = simulation.NewWeatherModel(simulation.DefaultWeatherConfig())
+	}
+	if cfg.Attitude == nil {
+		cfg.Attitude = simulation.NewAttitudeModel(simulation.DefaultSeaStateConfig())
+	}
+	if cfg.Engines == nil {
+		cfg.Engines = simulation.NewEngineModel(simulation.DefaultEngineConfig())
+	}
+	if cfg.Electrical == nil {
+		cfg.Electrical = simulation.NewElectricalModel(simulation.DefaultElectricalConfig())
+	}
+	if cfg.Tanks == nil {
+		// The default tanks are always valid
+		cfg.Tanks, _ = simulation.NewTankModel(simulation.DefaultTanks())
+	}
+	if cfg.Controls == nil {
+		cfg.Controls = network.NewControls(cfg.UpdatePeriod)
+	}
+	if cfg.Snapshots == nil {
+		cfg.Snapshots = network.NewSnapshots(cfg.Controls, simulation.Sampler{
+			Fleet:    cfg.Fleet,
+			GNSS:     cfg.GNSS,
+			Wind:     cfg.Wind,
+			Depth:    cfg.Depth,
+			Weather:  cfg.Weather,
+			Attitude: cfg.Attitude,
+			Engines:  cfg.Engines,
+			Tanks:    cfg.Tanks,
+		})
+	}
+
+	s := &Simulator{
 		transport:    cfg.Transport,
 		webSocket:    cfg.WebSocket,
+		clients:      cfg.Clients,
 		updatePeriod: cfg.UpdatePeriod,
+		controls:     cfg.Controls,
+		snapshots:    cfg.Snapshots,
+		fleet:        cfg.Fleet,
+		gnss:         cfg.GNSS,
+		sensors:      cfg.Sensors,
+		variation:    cfg.Variation,
+		varSource:    varSource,
+		electrical:   cfg.Electrical,
+		autopilot:    cfg.Autopilot,
+		commands:     cfg.AutopilotCommands,
+		enableGNSS:   cfg.EnableGNSS,
+		enableAIS:    cfg.EnableAIS,
+		ais:          newAISSchedule(),
+		sequence:     make(map[uint32]uint8),
 		done:         make(chan struct{}),
+		outputs: outputs{
+			weather:    cfg.EnableWeather,
+			attitude:   cfg.EnableAttitude,
+			engine:     cfg.EnableEngine,
+			tanks:      cfg.EnableTanks,
+			electrical: cfg.EnableElectrical,
+		},
+	}
+
+	if s.autopilot != nil && s.commands {
+		s.transport.HandlePGN(s.handleCommand)
+		if s.webSocket != nil {
+			s.webSocket.HandlePGN(s.handleCommand)
+		}
+		for _, client := range s.clients {
+			client.HandlePGN(s.handleCommand)
+		}
 	}
+	return s
+}
+
+// handleCommand steers the autopilot by a message received from a client
+func (s *Simulator) handleCommand(msg pgn.Message) error {
+	own := s.fleet.OwnShip()
+	variation := s.variation(own.Latitude, own.Longitude, time.Now())
+	_, err := ApplyAutopilotCommand(s.autopilot, msg, own, variation)
+	return err
 }
 
 // Start begins the simulation
@@ -39,6 +177,11 @@ func (s *Simulator) Start(ctx context.Context) error {
 	if err := s.transport.Start(ctx); err != nil {
 		return err
 	}
+	for _, client := range s.clients {
+		if err := client.Start(ctx); err != nil {
+			return err
+		}
+	}
 
 	go s.simulationLoop(ctx)
 	return nil
@@ -50,6 +193,11 @@ func (s *Simulator) Stop() error {
 	if err := s.transport.Stop(); err != nil {
 		return err
 	}
+	for _, client := range s.clients {
+		if err := client.Stop(); err != nil {
+			return err
+		}
+	}
 	if s.webSocket != nil {
 		return s.webSocket.Stop()
 	}
@@ -57,8 +205,8 @@ func (s *Simulator) Stop() error {
 }
 
 func (s *Simulator) simulationLoop(ctx context.Context) {
-	ticker := time.NewTicker(s.updatePeriod)
-	defer ticker.Stop()
+	snapshots, unsubscribe := s.snapshots.Subscribe()
+	defer unsubscribe()
 
 	for {
 		select {
@@ -66,54 +214,135 @@ func (s *Simulator) simulationLoop(ctx context.Context) {
 			return
 		case <-s.done:
 			return
-		case <-ticker.C:
-			s.generateAndSendMessages()
+		case snap := <-snapshots:
+			s.generateAndSendMessages(snap)
 		}
 	}
 }
 
-func (s *Simulator) generateAndSendMessages() {
-	// Generate and send vessel heading
-	heading := pgn.VesselHeading{
-		Heading:   0.5, // ~28.6 degrees
-		Reference: 0,   // True heading
+func (s *Simulator) generateAndSendMessages(snap simulation.Snapshot) {
+	now := snap.Time
+	s.sid = (s.sid + 1) % 253
+
+	// Generate and send magnetic variation and vessel heading
+	own := snap.Own
+	variation := s.variation(own.Latitude, own.Longitude, now)
+	s.send(VariationMessage(variation, s.varSource, now, s.sid))
+	for _, msg := range HeadingMessages(own, s.sensors, variation, s.sid) {
+		s.send(msg)
 	}
-	msg := pgn.Message{
-		PGN:  127250,
-		Data: pgn.EncodeVesselHeading(heading),
+
+	// Generate and send the rudder angle, and the autopilot status when it takes commands
+	if s.autopilot != nil {
+		state := s.autopilot.State()
+		if !s.commands {
+			s.send(RudderMessage(state))
+		} else {
+			for _, msg := range AutopilotMessages(state, s.autopilot.Config(), own.Heading) {
+				s.send(msg)
+			}
+		}
 	}
-	s.transport.SendPGN(msg)
-	if s.webSocket != nil {
-		s.webSocket.SendPGN(msg)
+
+	// Generate and send wave-induced attitude and heave
+	if s.outputs.attitude {
+		for _, msg := range AttitudeMessages(own, snap.Attitude, s.sid) {
+			s.send(msg)
+		}
 	}
 
 	// Generate and send water depth
-	depth := pgn.WaterDepth{
-		Depth:    10.5, // 10.5 meters
-		Offset:   -1.5, // Transducer is 1.5m below water line
-		MaxRange: 100.0,
+	s.send(DepthMessage(snap.Depth, s.sid))
+
+	// Generate and send speed through water and over ground, and set and drift
+	for _, msg := range MotionMessages(own, snap.Current, s.sid) {
+		s.send(msg)
 	}
-	msg = pgn.Message{
-		PGN:  128267,
-		Data: pgn.EncodeWaterDepth(depth),
+
+	// Generate and send apparent and true wind
+	for _, msg := range WindMessages(simulation.ResolveWind(snap.Wind, own), s.sid) {
+		s.send(msg)
 	}
-	s.transport.SendPGN(msg)
-	if s.webSocket != nil {
-		s.webSocket.SendPGN(msg)
+
+	// Generate and send temperature, humidity and pressure, or the sea
+	// temperature alone as MTW is sent without the weather
+	if s.outputs.weather {
+		for _, msg := range WeatherMessages(snap.Weather, s.sid) {
+			s.send(msg)
+		}
+	} else {
+		s.send(SeaTemperatureMessage(snap.Weather, s.sid))
+	}
+
+	// Generate and send engine and transmission parameters
+	if s.outputs.engine {
+		for _, msg := range EngineMessages(snap.Engines) {
+			s.send(msg)
+		}
+	}
+
+	// Generate and send tank levels
+	if s.outputs.tanks {
+		for _, msg := range TankMessages(snap.Tanks) {
+			s.send(msg)
+		}
 	}
 
-	// Generate and send wind data
-	wind := pgn.WindData{
-		WindSpeed: 5.5, // 5.5 m/s
-		WindAngle: 2.0, // ~114.6 degrees
-		Reference: 1,   // Apparent wind
+	// Generate and send battery, charging source and charger status, with the
+	// battery configuration at a slower rate
+	if s.outputs.electrical {
+		for _, msg := range ElectricalMessages(s.electrical.Sample(own, snap.Engines, s.fleet.ModelTime(now)), s.sid) {
+			s.send(msg)
+		}
+		if now.Sub(s.lastBattery) >= batteryConfigurationInterval {
+			s.lastBattery = now
+			for _, msg := range BatteryConfigurationMessages(s.electrical.Config()) {
+				s.send(msg)
+			}
+		}
 	}
-	msg = pgn.Message{
-		PGN:  130306,
-		Data: pgn.EncodeWindData(wind),
+
+	// Generate and send GNSS position and satellite status
+	if gnssSensors := s.sensors.Kind(simulation.SensorGNSS); len(gnssSensors) > 0 {
+		for _, msg := range SensorGNSSMessages(gnssSensors, s.enableGNSS, s.sid) {
+			s.send(msg)
+		}
+	} else if s.gnss != nil {
+		msgs := PositionMessages(snap.Fix, s.sid)
+		if s.enableGNSS {
+			msgs = append(msgs, GNSSMessages(snap.Fix, s.sid)...)
+		}
+		for _, msg := range msgs {
+			s.send(msg)
+		}
 	}
+
+	// Generate and send AIS reports for the simulated fleet
+	if s.enableAIS {
+		for _, msg := range s.ais.messages(s.fleet, now) {
+			s.send(msg)
+		}
+	}
+}
+
+// send delivers a message to the TCP and WebSocket transports and the
+// clients, assigning the fast-packet sequence counter for multi-frame PGNs
+// per source address. Messages of disabled PGNs are dropped.
+func (s *Simulator) send(msg pgn.Message) {
+	if !s.controls.PGNEnabled(msg.PGN) {
+		return
+	}
+	if pgn.IsFastPacket(msg.PGN) {
+		key := uint32(msg.Source)<<24 | msg.PGN
+		msg.Sequence = s.sequence[key]
+		s.sequence[key] = (msg.Sequence + 1) % 8
+	}
+
 	s.transport.SendPGN(msg)
 	if s.webSocket != nil {
 		s.webSocket.SendPGN(msg)
 	}
+	for _, client := range s.clients {
+		client.SendPGN(msg)
+	}
 }
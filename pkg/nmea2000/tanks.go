@@ -0,0 +1,23 @@
+package nmea2000
+
+import (
+	"github.com/captv89/nmea-simulator/pkg/nmea2000/pgn"
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// TankMessages returns PGN 127505 Fluid Level for each tank
+func TankMessages(tanks []simulation.Tank) []pgn.Message {
+	msgs := make([]pgn.Message, 0, len(tanks))
+	for _, t := range tanks {
+		msgs = append(msgs, pgn.Message{
+			PGN: 127505,
+			Data: pgn.EncodeFluidLevel(pgn.FluidLevel{
+				Instance: t.Config.Instance,
+				Type:     t.Config.Fluid.Number(),
+				Level:    t.Level,
+				Capacity: t.Config.Capacity,
+			}),
+		})
+	}
+	return msgs
+}
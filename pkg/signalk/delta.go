@@ -0,0 +1,202 @@
+package signalk
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+// State is a snapshot of own ship's simulated data
+type State struct {
+	Time      time.Time
+	Fix       simulation.GNSSFix         // Position, course and speed over ground
+	Heading   simulation.CompassHeading  // Resolved heading of the compass
+	Vessel    simulation.Vessel          // Own ship, for the speed through water and rate of turn
+	Depth     simulation.Sounding        // Echo sounder
+	Wind      simulation.RelativeWind    // Wind on board
+	Weather   simulation.Weather         // Air and water temperature, pressure and humidity
+	Engines   []simulation.Engine        // Empty when not reported
+	Tanks     []simulation.Tank          // Empty when not reported
+	Autopilot *simulation.AutopilotState // Rudder angle, nil when not reported
+}
+
+// source returns the source of the values of a simulated device
+func source(src string) Source {
+	return Source{Label: Label, Type: "simulator", Src: src}
+}
+
+// NewDelta returns the delta updating own ship's values in the context self
+func NewDelta(self string, s State) Delta {
+	ts := Timestamp(s.Time)
+	update := func(src string, values ...Value) Update {
+		return Update{Source: source(src), Timestamp: ts, Values: values}
+	}
+
+	updates := []Update{
+		update("gnss", navigationValues(s.Fix)...),
+		update("heading", headingValues(s.Heading)...),
+		update("log",
+			Value{Path: "navigation.speedThroughWater", Value: MetersPerSecond(s.Vessel.STW)},
+			Value{Path: "navigation.rateOfTurn", Value: Radians(s.Vessel.ROT / 60)},
+		),
+		update("depth", depthValues(s.Depth)...),
+		update("wind", windValues(s.Wind, s.Heading.Variation)...),
+		update("weather",
+			Value{Path: "environment.outside.temperature", Value: Kelvin(s.Weather.AirTemperature)},
+			Value{Path: "environment.outside.dewPointTemperature", Value: Kelvin(s.Weather.DewPoint)},
+			Value{Path: "environment.outside.pressure", Value: s.Weather.Pressure * 100},
+			Value{Path: "environment.outside.relativeHumidity", Value: s.Weather.Humidity / 100},
+			Value{Path: "environment.water.temperature", Value: Kelvin(s.Weather.WaterTemperature)},
+		),
+	}
+	if len(s.Engines) > 0 {
+		updates = append(updates, update("propulsion", propulsionValues(s.Engines)...))
+	}
+	if len(s.Tanks) > 0 {
+		updates = append(updates, update("tanks", tankValues(s.Tanks)...))
+	}
+	if s.Autopilot != nil {
+		updates = append(updates, update("rudder", Value{Path: "steering.rudderAngle", Value: Radians(s.Autopilot.Rudder)}))
+	}
+	return Delta{Context: self, Updates: updates}
+}
+
+// navigationValues returns the position, course and speed over ground of a
+// fix, not available without a valid fix
+func navigationValues(fix simulation.GNSSFix) []Value {
+	if !fix.Valid {
+		return []Value{
+			{Path: "navigation.position"},
+			{Path: "navigation.courseOverGroundTrue"},
+			{Path: "navigation.speedOverGround"},
+		}
+	}
+	return []Value{
+		{Path: "navigation.position", Value: Position{Latitude: fix.Latitude, Longitude: fix.Longitude}},
+		{Path: "navigation.courseOverGroundTrue", Value: DirectionRadians(fix.COG)},
+		{Path: "navigation.speedOverGround", Value: MetersPerSecond(fix.SOG)},
+	}
+}
+
+// headingValues returns the true and magnetic heading and the variation
+func headingValues(h simulation.CompassHeading) []Value {
+	return []Value{
+		{Path: "navigation.headingTrue", Value: number(DirectionRadians(h.True))},
+		{Path: "navigation.headingMagnetic", Value: number(DirectionRadians(h.Magnetic))},
+		{Path: "navigation.magneticVariation", Value: number(SignedRadians(h.Variation))},
+	}
+}
+
+// depthValues returns the depth below the transducer and, from the offset,
+// the depth below the surface or below the keel. Depths are not available
+// without a bottom.
+func depthValues(d simulation.Sounding) []Value {
+	depth := d.Depth
+	if !d.Valid {
+		depth = math.NaN()
+	}
+
+	values := []Value{{Path: "environment.depth.belowTransducer", Value: number(depth)}}
+	switch {
+	case d.Offset > 0:
+		values = append(values,
+			Value{Path: "environment.depth.surfaceToTransducer", Value: d.Offset},
+			Value{Path: "environment.depth.belowSurface", Value: number(depth + d.Offset)},
+		)
+	case d.Offset < 0:
+		values = append(values,
+			Value{Path: "environment.depth.transducerToKeel", Value: -d.Offset},
+			Value{Path: "environment.depth.belowKeel", Value: number(depth + d.Offset)},
+		)
+	}
+	return values
+}
+
+// windValues returns the apparent wind and the ground referenced true wind,
+// converting its direction to magnetic with the variation in degrees east
+func windValues(w simulation.RelativeWind, variation float64) []Value {
+	return []Value{
+		{Path: "environment.wind.angleApparent", Value: SignedRadians(w.ApparentAngle)},
+		{Path: "environment.wind.speedApparent", Value: MetersPerSecond(w.ApparentSpeed)},
+		{Path: "environment.wind.angleTrueGround", Value: SignedRadians(w.TrueAngle)},
+		{Path: "environment.wind.directionTrue", Value: DirectionRadians(w.True.Direction)},
+		{Path: "environment.wind.directionMagnetic", Value: number(DirectionRadians(w.True.Direction - variation))},
+		{Path: "environment.wind.speedOverGround", Value: MetersPerSecond(w.True.Speed)},
+	}
+}
+
+// gears maps transmission gears to their Signal K names
+var gears = map[simulation.Gear]string{
+	simulation.GearForward: "Forward",
+	simulation.GearNeutral: "Neutral",
+	simulation.GearReverse: "Reverse",
+}
+
+// EngineID returns the propulsion id of an engine: main for a single engine,
+// port and starboard for twin engines and the instance otherwise
+func EngineID(instance uint8, engines int) string {
+	switch {
+	case engines == 1:
+		return "main"
+	case engines == 2 && instance == 0:
+		return "port"
+	case engines == 2 && instance == 1:
+		return "starboard"
+	}
+	return fmt.Sprint(instance)
+}
+
+// propulsionValues returns the engine and transmission values of every engine
+func propulsionValues(engines []simulation.Engine) []Value {
+	var values []Value
+	for _, e := range engines {
+		prefix := "propulsion." + EngineID(e.Instance, len(engines)) + "."
+		state := "stopped"
+		if e.RPM > 0 {
+			state = "started"
+		}
+		values = append(values,
+			Value{Path: prefix + "state", Value: state},
+			Value{Path: prefix + "revolutions", Value: e.RPM / 60},
+			Value{Path: prefix + "engineLoad", Value: e.Load / 100},
+			Value{Path: prefix + "engineTorque", Value: e.Torque / 100},
+			Value{Path: prefix + "boostPressure", Value: e.BoostPressure * 1000},
+			Value{Path: prefix + "oilPressure", Value: e.OilPressure * 1000},
+			Value{Path: prefix + "oilTemperature", Value: Kelvin(e.OilTemperature)},
+			Value{Path: prefix + "coolantTemperature", Value: Kelvin(e.CoolantTemperature)},
+			Value{Path: prefix + "alternatorVoltage", Value: e.AlternatorVoltage},
+			Value{Path: prefix + "fuel.rate", Value: e.FuelRate / 1000 / 3600},
+			Value{Path: prefix + "runTime", Value: e.Hours * 3600},
+			Value{Path: prefix + "transmission.gear", Value: gears[e.Gear]},
+			Value{Path: prefix + "transmission.oilPressure", Value: e.TransmissionPressure * 1000},
+			Value{Path: prefix + "transmission.oilTemperature", Value: Kelvin(e.TransmissionTemperature)},
+		)
+	}
+	return values
+}
+
+// tankTypes maps fluids to their Signal K tank types
+var tankTypes = map[simulation.FluidType]string{
+	simulation.FluidFuel:       "fuel",
+	simulation.FluidFreshWater: "freshWater",
+	simulation.FluidGrayWater:  "wasteWater",
+	simulation.FluidBlackWater: "blackWater",
+	simulation.FluidOil:        "lubrication",
+	simulation.FluidLiveWell:   "liveWell",
+}
+
+// tankValues returns the level, volume and capacity of every tank
+func tankValues(tanks []simulation.Tank) []Value {
+	var values []Value
+	for _, t := range tanks {
+		prefix := fmt.Sprintf("tanks.%s.%d.", tankTypes[t.Config.Fluid], t.Config.Instance)
+		values = append(values,
+			Value{Path: prefix + "currentLevel", Value: t.Level / 100},
+			Value{Path: prefix + "currentVolume", Value: t.Volume / 1000},
+			Value{Path: prefix + "capacity", Value: t.Config.Capacity / 1000},
+		)
+	}
+	return values
+}
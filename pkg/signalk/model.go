@@ -0,0 +1,109 @@
+package signalk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Model is the full Signal K model of own ship, kept up to date by applying
+// deltas
+type Model struct {
+	mu      sync.RWMutex
+	self    string                 // Context of own ship
+	vessels map[string]interface{} // Vessel trees by URN
+	sources map[string]interface{} // Sources by label
+}
+
+// NewModel creates the model of own ship with its name and MMSI
+func NewModel(self, name string, mmsi uint32) *Model {
+	urn := strings.TrimPrefix(self, "vessels.")
+	return &Model{
+		self: self,
+		vessels: map[string]interface{}{
+			urn: map[string]interface{}{
+				"name": name,
+				"mmsi": fmt.Sprintf("%09d", mmsi),
+			},
+		},
+		sources: make(map[string]interface{}),
+	}
+}
+
+// Apply stores the values of a delta with their source and timestamp.
+// Deltas for other contexts than vessels are ignored.
+func (m *Model) Apply(d Delta) {
+	urn, ok := strings.CutPrefix(d.Context, "vessels.")
+	if !ok {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	vessel, ok := m.vessels[urn].(map[string]interface{})
+	if !ok {
+		vessel = make(map[string]interface{})
+		m.vessels[urn] = vessel
+	}
+
+	for _, u := range d.Updates {
+		label, ok := m.sources[u.Source.Label].(map[string]interface{})
+		if !ok {
+			label = map[string]interface{}{"label": u.Source.Label, "type": u.Source.Type}
+			m.sources[u.Source.Label] = label
+		}
+		label[u.Source.Src] = map[string]interface{}{"src": u.Source.Src}
+
+		for _, v := range u.Values {
+			node := vessel
+			keys := strings.Split(v.Path, ".")
+			for _, key := range keys[:len(keys)-1] {
+				child, ok := node[key].(map[string]interface{})
+				if !ok {
+					child = make(map[string]interface{})
+					node[key] = child
+				}
+				node = child
+			}
+			node[keys[len(keys)-1]] = map[string]interface{}{
+				"value":     v.Value,
+				"$source":   u.Source.Ref(),
+				"timestamp": u.Timestamp,
+			}
+		}
+	}
+}
+
+// JSON returns the JSON encoding of the model below a path of keys, with self
+// standing for own ship after vessels. It reports whether the path exists.
+func (m *Model) JSON(path []string) ([]byte, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var node interface{} = map[string]interface{}{
+		"version": Version,
+		"self":    m.self,
+		"vessels": m.vessels,
+		"sources": m.sources,
+	}
+	for i, key := range path {
+		if i == 1 && path[0] == "vessels" && key == "self" {
+			key = strings.TrimPrefix(m.self, "vessels.")
+		}
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if node, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+
+	data, err := json.Marshal(node)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,124 @@
+// Package signalk maps the simulated state into the Signal K data model,
+// producing delta messages and the full model in SI units
+package signalk
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Version is the Signal K specification version the deltas follow
+const Version = "1.7.0"
+
+// Label is the label of every source of the simulator
+const Label = "nmeasim"
+
+// Delta is a Signal K delta message updating values of a context
+type Delta struct {
+	Context string   `json:"context"`
+	Updates []Update `json:"updates"`
+}
+
+// Update is a group of values from one source at one time
+type Update struct {
+	Source    Source  `json:"source"`
+	Timestamp string  `json:"timestamp"`
+	Values    []Value `json:"values"`
+}
+
+// Source identifies the simulated device reporting an update
+type Source struct {
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Src   string `json:"src"` // Simulated device, e.g. gnss or depth
+}
+
+// Ref returns the source reference used as $source in the full model
+func (s Source) Ref() string {
+	return s.Label + "." + s.Src
+}
+
+// Value is the value of a path, nil when not available
+type Value struct {
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// Position is the value of navigation.position in degrees
+type Position struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Hello is the first message of a stream
+type Hello struct {
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	Self      string   `json:"self"`
+	Roles     []string `json:"roles"`
+	Timestamp string   `json:"timestamp"`
+}
+
+// Self returns the context of a vessel identified by its MMSI
+func Self(mmsi uint32) string {
+	return fmt.Sprintf("vessels.urn:mrn:imo:mmsi:%09d", mmsi)
+}
+
+// NewHello returns the hello message of a stream for the vessel self
+func NewHello(self string, now time.Time) Hello {
+	return Hello{
+		Name:      Label,
+		Version:   Version,
+		Self:      self,
+		Roles:     []string{"master", "main"},
+		Timestamp: Timestamp(now),
+	}
+}
+
+// Timestamp formats a time as a Signal K timestamp
+func Timestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// number returns x, or nil when x is not available
+func number(x float64) interface{} {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return nil
+	}
+	return x
+}
+
+// Radians converts degrees to radians
+func Radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// SignedRadians converts an angle in degrees to radians in [-π, π),
+// negative to port
+func SignedRadians(deg float64) float64 {
+	rad := math.Mod(Radians(deg)+math.Pi, 2*math.Pi)
+	if rad < 0 {
+		rad += 2 * math.Pi
+	}
+	return rad - math.Pi
+}
+
+// DirectionRadians converts a direction in degrees to radians in [0, 2π)
+func DirectionRadians(deg float64) float64 {
+	rad := math.Mod(Radians(deg), 2*math.Pi)
+	if rad < 0 {
+		rad += 2 * math.Pi
+	}
+	return rad
+}
+
+// MetersPerSecond converts knots to meters per second
+func MetersPerSecond(knots float64) float64 {
+	return knots * 1852 / 3600
+}
+
+// Kelvin converts degrees Celsius to Kelvin
+func Kelvin(celsius float64) float64 {
+	return celsius + 273.15
+}
@@ -0,0 +1,168 @@
+package signalk
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation"
+)
+
+func TestUnits(t *testing.T) {
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"SignedRadians(270)", SignedRadians(270), -math.Pi / 2},
+		{"SignedRadians(90)", SignedRadians(90), math.Pi / 2},
+		{"DirectionRadians(-90)", DirectionRadians(-90), 3 * math.Pi / 2},
+		{"MetersPerSecond(10)", MetersPerSecond(10), 5.144444},
+		{"Kelvin(20)", Kelvin(20), 293.15},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-6 {
+			t.Errorf("%s = %f, want %f", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if got := Self(244000001); got != "vessels.urn:mrn:imo:mmsi:244000001" {
+		t.Errorf("Unexpected self %q", got)
+	}
+	if got := Timestamp(time.Date(2024, 5, 1, 12, 0, 0, 250e6, time.UTC)); got != "2024-05-01T12:00:00.250Z" {
+		t.Errorf("Unexpected timestamp %q", got)
+	}
+}
+
+// values returns the values of a delta by path
+func values(d Delta) map[string]interface{} {
+	v := make(map[string]interface{})
+	for _, u := range d.Updates {
+		for _, value := range u.Values {
+			v[value.Path] = value.Value
+		}
+	}
+	return v
+}
+
+func testState() State {
+	own := simulation.DefaultOwnShip()
+	own.STW, own.ROT = 6, 30
+	return State{
+		Time:    time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Fix:     simulation.GNSSFix{Valid: true, Latitude: 48.2, Longitude: 16.4, COG: 45, SOG: 6},
+		Heading: simulation.ResolveHeading(40, 5, nil),
+		Vessel:  own,
+		Depth:   simulation.Sounding{Valid: true, Depth: 12, Offset: -1.3},
+		Wind:    simulation.ResolveWind(simulation.Wind{Direction: 90, Speed: 10}, own),
+		Weather: simulation.Weather{AirTemperature: 20, Pressure: 1013, Humidity: 60},
+		Engines: []simulation.Engine{{RPM: 1800, OilPressure: 400, Gear: simulation.GearForward}},
+		Tanks:   []simulation.Tank{{Config: simulation.TankConfig{Fluid: simulation.FluidGrayWater, Capacity: 100}, Level: 25, Volume: 25}},
+	}
+}
+
+func TestNewDelta(t *testing.T) {
+	d := NewDelta(Self(244000001), testState())
+	v := values(d)
+
+	if d.Context != "vessels.urn:mrn:imo:mmsi:244000001" {
+		t.Errorf("Unexpected context %q", d.Context)
+	}
+	if p, ok := v["navigation.position"].(Position); !ok || p.Latitude != 48.2 || p.Longitude != 16.4 {
+		t.Errorf("Unexpected position %v", v["navigation.position"])
+	}
+
+	numbers := map[string]float64{
+		"navigation.courseOverGroundTrue":      math.Pi / 4,
+		"navigation.speedOverGround":           3.086667,
+		"navigation.headingTrue":               Radians(40),
+		"navigation.headingMagnetic":           Radians(35),
+		"navigation.rateOfTurn":                Radians(0.5),
+		"environment.depth.belowTransducer":    12,
+		"environment.depth.belowKeel":          10.7,
+		"environment.depth.transducerToKeel":   1.3,
+		"environment.wind.directionTrue":       math.Pi / 2,
+		"environment.wind.directionMagnetic":   Radians(85),
+		"environment.wind.speedOverGround":     5.144444,
+		"environment.outside.pressure":         101300,
+		"environment.outside.relativeHumidity": 0.6,
+		"propulsion.main.revolutions":          30,
+		"propulsion.main.oilPressure":          400000,
+		"tanks.wasteWater.0.currentLevel":      0.25,
+		"tanks.wasteWater.0.capacity":          0.1,
+	}
+	for path, want := range numbers {
+		got, ok := v[path].(float64)
+		if !ok || math.Abs(got-want) > 1e-6 {
+			t.Errorf("%s = %v, want %f", path, v[path], want)
+		}
+	}
+	if v["propulsion.main.transmission.gear"] != "Forward" || v["propulsion.main.state"] != "started" {
+		t.Errorf("Unexpected transmission %v and state %v", v["propulsion.main.transmission.gear"], v["propulsion.main.state"])
+	}
+	if _, ok := v["steering.rudderAngle"]; ok {
+		t.Error("Expected no rudder angle without an autopilot")
+	}
+
+	for _, u := range d.Updates {
+		if u.Source.Label != Label || u.Source.Src == "" || u.Timestamp != "2024-05-01T12:00:00.000Z" {
+			t.Errorf("Unexpected update source %+v at %s", u.Source, u.Timestamp)
+		}
+	}
+
+	s := testState()
+	s.Fix.Valid, s.Depth.Valid = false, false
+	v = values(NewDelta(Self(244000001), s))
+	for _, path := range []string{"navigation.position", "environment.depth.belowTransducer", "environment.depth.belowKeel"} {
+		if value, ok := v[path]; !ok || value != nil {
+			t.Errorf("Expected %s to be null, got %v", path, value)
+		}
+	}
+	if _, err := json.Marshal(NewDelta(Self(244000001), s)); err != nil {
+		t.Errorf("Failed to encode delta: %v", err)
+	}
+}
+
+func TestModel(t *testing.T) {
+	self := Self(244000001)
+	m := NewModel(self, "NMEA SIMULATOR", 244000001)
+	m.Apply(NewDelta(self, testState()))
+	m.Apply(Delta{Context: "aircraft.urn:x", Updates: []Update{{Values: []Value{{Path: "navigation.position"}}}}})
+
+	data, ok := m.JSON([]string{"vessels", "self", "environment", "depth", "belowTransducer"})
+	if !ok {
+		t.Fatal("Expected the depth below the transducer")
+	}
+	var leaf struct {
+		Value     float64 `json:"value"`
+		Source    string  `json:"$source"`
+		Timestamp string  `json:"timestamp"`
+	}
+	if err := json.Unmarshal(data, &leaf); err != nil || leaf.Value != 12 || leaf.Source != "nmeasim.depth" || leaf.Timestamp == "" {
+		t.Errorf("Unexpected depth %s: %v", data, err)
+	}
+
+	data, ok = m.JSON(nil)
+	var full struct {
+		Version string                            `json:"version"`
+		Self    string                            `json:"self"`
+		Vessels map[string]map[string]interface{} `json:"vessels"`
+		Sources map[string]map[string]interface{} `json:"sources"`
+	}
+	if !ok || json.Unmarshal(data, &full) != nil {
+		t.Fatalf("Failed to decode the full model %s", data)
+	}
+	vessel := full.Vessels["urn:mrn:imo:mmsi:244000001"]
+	if full.Version != Version || full.Self != self || len(full.Vessels) != 1 || vessel["name"] != "NMEA SIMULATOR" || vessel["mmsi"] != "244000001" {
+		t.Errorf("Unexpected full model %s", data)
+	}
+	if _, ok := full.Sources[Label]["gnss"]; !ok {
+		t.Errorf("Expected the gnss source, got %v", full.Sources)
+	}
+
+	for _, path := range [][]string{{"vessels", "other"}, {"vessels", "self", "name", "value"}} {
+		if _, ok := m.JSON(path); ok {
+			t.Errorf("Expected %v not to exist", path)
+		}
+	}
+}
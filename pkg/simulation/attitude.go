@@ -0,0 +1,199 @@
+package simulation
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// SeaStateConfig describes the waves driving own ship's motion
+type SeaStateConfig struct {
+	WaveHeight     float64  `json:"wave_height"`               // Significant wave height, meters
+	WavePeriod     float64  `json:"wave_period,omitempty"`     // Peak period, seconds, derived from the wave height when zero
+	WaveDirection  float64  `json:"wave_direction"`            // Direction the waves come from, degrees true
+	EncounterAngle *float64 `json:"encounter_angle,omitempty"` // Fixed angle of the waves off the bow, degrees clockwise, overriding WaveDirection
+	RollPeriod     float64  `json:"roll_period,omitempty"`     // Natural roll period of the vessel, seconds, 6 when zero
+}
+
+// DefaultSeaStateConfig returns a moderate sea from the west
+func DefaultSeaStateConfig() SeaStateConfig {
+	return SeaStateConfig{
+		WaveHeight:    1.5,
+		WaveDirection: 270,
+		RollPeriod:    6,
+	}
+}
+
+// Wave spectrum and vessel response parameters
+const (
+	gravity          = 9.81 // m/s²
+	waveComponents   = 24
+	rollDamping      = 0.3  // Damping ratio of the roll resonance
+	defaultLength    = 10.0 // Meters, for a vessel without dimensions
+	defaultRollTime  = 6.0  // Seconds
+	yawResponseRatio = 0.5  // Yaw amplitude relative to the wave slope in quartering seas
+)
+
+// Attitude is the wave-induced motion of a vessel. Angles follow PGN 127257:
+// pitch positive bow up, roll positive with the starboard side down.
+type Attitude struct {
+	Pitch float64 // Degrees
+	Roll  float64 // Degrees
+	Yaw   float64 // Oscillation about the mean heading, degrees clockwise
+	Heave float64 // Meters, positive up
+
+	RollPeriod        float64   // Period of the last complete roll, seconds, zero before the first
+	RollAmplitudePort float64   // Largest roll to port during the last roll, degrees
+	RollAmplitudeStbd float64   // Largest roll to starboard during the last roll, degrees
+	RollPeakPort      float64   // Largest roll to port since PeakSince, degrees
+	RollPeakStbd      float64   // Largest roll to starboard since PeakSince, degrees
+	PeakSince         time.Time // Start of the roll peak hold
+}
+
+// waveComponent is a single regular wave of a long-crested sea
+type waveComponent struct {
+	omega     float64 // Wave frequency, rad/s
+	amplitude float64 // Meters
+	phase     float64 // Phase at the last sample, radians
+}
+
+// AttitudeModel simulates pitch, roll, yaw and heave from a Bretschneider
+// wave spectrum met at the encounter frequency of the vessel's heading and
+// speed. The roll responds as a damped oscillator at the natural roll period.
+type AttitudeModel struct {
+	config SeaStateConfig
+
+	mu       sync.Mutex
+	waves    []waveComponent
+	started  bool
+	last     time.Time
+	attitude Attitude
+	upCross  time.Time // Time of the last roll from port to starboard
+
+	cyclePort, cycleStbd float64 // Largest rolls of the roll in progress, degrees
+}
+
+// NewAttitudeModel creates an attitude model for the given sea state
+func NewAttitudeModel(cfg SeaStateConfig) *AttitudeModel {
+	if cfg.WavePeriod <= 0 {
+		// Fully developed sea: Tp ≈ 5√Hs
+		cfg.WavePeriod = 5 * math.Sqrt(math.Max(cfg.WaveHeight, 0.01))
+	}
+	if cfg.RollPeriod <= 0 {
+		cfg.RollPeriod = defaultRollTime
+	}
+
+	// Bretschneider spectrum sampled from half to three times the peak frequency
+	peak := 2 * math.Pi / cfg.WavePeriod
+	lo, hi := 0.5*peak, 3*peak
+	step := (hi - lo) / waveComponents
+	waves := make([]waveComponent, waveComponents)
+	for i := range waves {
+		omega := lo + (float64(i)+0.5)*step
+		s := 5.0 / 16 * cfg.WaveHeight * cfg.WaveHeight * math.Pow(peak, 4) / math.Pow(omega, 5) *
+			math.Exp(-1.25*math.Pow(peak/omega, 4))
+		waves[i] = waveComponent{
+			omega:     omega,
+			amplitude: math.Sqrt(2 * s * step),
+			phase:     rand.Float64() * 2 * math.Pi,
+		}
+	}
+
+	return &AttitudeModel{config: cfg, waves: waves}
+}
+
+// Config returns the sea state, with the derived wave and roll periods
+func (m *AttitudeModel) Config() SeaStateConfig {
+	return m.config
+}
+
+// EncounterAngle returns the angle of the waves off the bow of a vessel,
+// degrees clockwise: 0 in head seas, 90 on the starboard beam, 180 following
+func (m *AttitudeModel) EncounterAngle(v Vessel) float64 {
+	if m.config.EncounterAngle != nil {
+		return NormalizeDegrees(*m.config.EncounterAngle)
+	}
+	return NormalizeDegrees(m.config.WaveDirection - v.Heading)
+}
+
+// Sample advances the waves to time t and returns the vessel's attitude
+func (m *AttitudeModel) Sample(v Vessel, t time.Time) Attitude {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	theta := m.EncounterAngle(v) * math.Pi / 180
+	speed := v.STW * 1852 / 3600
+	length := v.Length()
+	if length <= 0 {
+		length = defaultLength
+	}
+	rollOmega := 2 * math.Pi / m.config.RollPeriod
+
+	var dt float64
+	if !m.started {
+		m.started = true
+		m.attitude.PeakSince = t
+	} else if t.After(m.last) {
+		dt = t.Sub(m.last).Seconds()
+	}
+	if t.After(m.last) {
+		m.last = t
+	}
+
+	prevRoll := m.attitude.Roll
+	var pitch, roll, yaw, heave float64
+	for i := range m.waves {
+		w := &m.waves[i]
+
+		// Head seas shorten the encounter period, following seas lengthen it
+		k := w.omega * w.omega / gravity
+		encounter := w.omega + k*speed*math.Cos(theta)
+		w.phase = math.Mod(w.phase+encounter*dt, 2*math.Pi)
+
+		// Waves much shorter than the hull average out along it
+		x := k * length / 2
+		hull := 1.0
+		if x > 1e-6 {
+			hull = math.Abs(math.Sin(x) / x)
+		}
+
+		r := math.Abs(encounter) / rollOmega
+		resonance := 1 / math.Sqrt(math.Pow(1-r*r, 2)+math.Pow(2*rollDamping*r, 2))
+
+		slope := k * w.amplitude * math.Sin(w.phase)
+		heave += w.amplitude * math.Cos(w.phase) * hull
+		pitch += slope * math.Cos(theta) * hull
+		roll += slope * math.Sin(theta) * resonance
+		yaw += yawResponseRatio * slope * math.Sin(2*theta) * hull
+	}
+
+	a := &m.attitude
+	a.Pitch = pitch * 180 / math.Pi
+	a.Roll = roll * 180 / math.Pi
+	a.Yaw = yaw * 180 / math.Pi
+	a.Heave = heave
+	m.updateRoll(prevRoll, dt, t)
+
+	return *a
+}
+
+// updateRoll tracks the roll period and amplitudes from the rolls from port
+// to starboard, interpolating the crossing time between samples
+func (m *AttitudeModel) updateRoll(prev, dt float64, t time.Time) {
+	a := &m.attitude
+	if dt > 0 && prev <= 0 && a.Roll > 0 {
+		crossing := t.Add(-time.Duration(dt * a.Roll / (a.Roll - prev) * float64(time.Second)))
+		if !m.upCross.IsZero() {
+			a.RollPeriod = crossing.Sub(m.upCross).Seconds()
+			a.RollAmplitudePort, a.RollAmplitudeStbd = m.cyclePort, m.cycleStbd
+		}
+		m.upCross = crossing
+		m.cyclePort, m.cycleStbd = 0, 0
+	}
+
+	m.cyclePort = math.Max(m.cyclePort, -a.Roll)
+	m.cycleStbd = math.Max(m.cycleStbd, a.Roll)
+	a.RollPeakPort = math.Max(a.RollPeakPort, -a.Roll)
+	a.RollPeakStbd = math.Max(a.RollPeakStbd, a.Roll)
+}
@@ -0,0 +1,67 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// sampleAttitude samples the model every half second for the given duration
+// and returns the standard deviations of heave, pitch and roll
+func sampleAttitude(m *AttitudeModel, v Vessel, duration time.Duration) (heave, pitch, roll float64, last Attitude) {
+	start := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+	n := int(duration / (500 * time.Millisecond))
+	for i := 0; i < n; i++ {
+		last = m.Sample(v, start.Add(time.Duration(i)*500*time.Millisecond))
+		heave += last.Heave * last.Heave
+		pitch += last.Pitch * last.Pitch
+		roll += last.Roll * last.Roll
+	}
+	return math.Sqrt(heave / float64(n)), math.Sqrt(pitch / float64(n)), math.Sqrt(roll / float64(n)), last
+}
+
+func TestAttitudeModelWaveHeight(t *testing.T) {
+	cfg := SeaStateConfig{WaveHeight: 2, WaveDirection: 0}
+	model := NewAttitudeModel(cfg)
+	if period := model.Config().WavePeriod; math.Abs(period-5*math.Sqrt2) > 1e-9 {
+		t.Errorf("Expected a derived peak period of %.1f s, got %.1f", 5*math.Sqrt2, period)
+	}
+
+	// A small, slow vessel follows the long waves, so its heave has the
+	// standard deviation of the sea surface, Hs/4
+	heave, _, _, _ := sampleAttitude(model, Vessel{ToBow: 1, ToStern: 1}, 2*time.Hour)
+	if math.Abs(heave-cfg.WaveHeight/4) > 0.15 {
+		t.Errorf("Expected heave of %.2f m RMS, got %.2f", cfg.WaveHeight/4, heave)
+	}
+}
+
+func TestAttitudeModelEncounterAngle(t *testing.T) {
+	own := DefaultOwnShip()
+
+	head := NewAttitudeModel(SeaStateConfig{WaveHeight: 2, WaveDirection: own.Heading})
+	if angle := head.EncounterAngle(own); angle != 0 {
+		t.Errorf("Expected head seas, got %.1f", angle)
+	}
+	_, pitch, roll, _ := sampleAttitude(head, own, 30*time.Minute)
+	if roll > 1e-9 || pitch < 1 {
+		t.Errorf("Expected pitching without roll in head seas, got %.2f° pitch and %.2f° roll RMS", pitch, roll)
+	}
+
+	beam := 90.0
+	abeam := NewAttitudeModel(SeaStateConfig{WaveHeight: 2, EncounterAngle: &beam})
+	if angle := abeam.EncounterAngle(own); angle != 90 {
+		t.Errorf("Expected the fixed encounter angle, got %.1f", angle)
+	}
+	_, pitch, roll, last := sampleAttitude(abeam, own, 30*time.Minute)
+	if pitch > 1e-9 || roll < 2 {
+		t.Errorf("Expected rolling without pitch in beam seas, got %.2f° pitch and %.2f° roll RMS", pitch, roll)
+	}
+
+	// Beam seas excite the roll near its natural period
+	if last.RollPeriod < 3 || last.RollPeriod > 15 {
+		t.Errorf("Expected a roll period near 6 s, got %.1f", last.RollPeriod)
+	}
+	if last.RollPeakPort < last.RollAmplitudePort || last.RollPeakStbd < last.RollAmplitudeStbd || last.RollPeakStbd == 0 {
+		t.Errorf("Expected the peak hold to cover the last roll, got %+v", last)
+	}
+}
@@ -0,0 +1,165 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// AutopilotMode is the steering mode of the autopilot
+type AutopilotMode string
+
+// Autopilot modes
+const (
+	AutopilotStandby AutopilotMode = "standby" // Hand steering, or a rudder order from a follow-up lever
+	AutopilotHeading AutopilotMode = "auto"    // Holds a heading to steer
+	AutopilotTrack   AutopilotMode = "track"   // Follows the course to a waypoint, correcting the cross-track error
+)
+
+// ParseAutopilotMode parses an autopilot mode name
+func ParseAutopilotMode(s string) (AutopilotMode, error) {
+	switch m := AutopilotMode(s); m {
+	case AutopilotStandby, AutopilotHeading, AutopilotTrack:
+		return m, nil
+	}
+	return AutopilotStandby, fmt.Errorf("unknown autopilot mode %q: expected standby, auto or track", s)
+}
+
+// AutopilotConfig describes the autopilot's steering gear and controller
+type AutopilotConfig struct {
+	SteeringConfig
+	HeadingGain   float64 // Rudder per degree of heading error
+	RateGain      float64 // Counter rudder per degree per second of rate of turn
+	OffTrackGain  float64 // Course correction per nautical mile of cross-track error, degrees
+	OffTrackLimit float64 // Largest course correction towards the track, degrees
+}
+
+// DefaultAutopilotConfig returns the autopilot of the default steering
+func DefaultAutopilotConfig() AutopilotConfig {
+	return AutopilotConfig{
+		SteeringConfig: DefaultSteeringConfig(),
+		HeadingGain:    1,
+		RateGain:       2,
+		OffTrackGain:   100,
+		OffTrackLimit:  30,
+	}
+}
+
+// AutopilotState is the state of the autopilot and its rudder. Rudder angles
+// are positive to starboard.
+type AutopilotState struct {
+	Mode           AutopilotMode
+	HeadingToSteer float64 // Degrees true, NaN in standby
+	Track          float64 // Course to the waypoint in track mode, degrees true, NaN otherwise
+	RudderOrder    float64 // Degrees, NaN without an order
+	Rudder         float64 // Degrees
+}
+
+// Autopilot steers own ship to a heading or along a track by moving the
+// rudder of its steering, which turns the vessel. It is a Helm for the fleet.
+type Autopilot struct {
+	config   AutopilotConfig
+	steering *Steering
+
+	mu       sync.Mutex
+	mode     AutopilotMode
+	heading  float64 // Heading to steer, degrees true
+	track    float64 // Course to the waypoint, degrees true
+	offTrack float64 // Cross-track error, nautical miles, positive right of the track
+	order    float64 // Rudder order in standby, NaN for hand steering
+}
+
+// NewAutopilot creates an autopilot in standby
+func NewAutopilot(cfg AutopilotConfig) *Autopilot {
+	return &Autopilot{config: cfg, steering: NewSteering(cfg.SteeringConfig), mode: AutopilotStandby, order: math.NaN()}
+}
+
+// Config returns the autopilot configuration
+func (a *Autopilot) Config() AutopilotConfig {
+	return a.config
+}
+
+// Standby disengages the autopilot, leaving own ship to its own rate of turn
+func (a *Autopilot) Standby() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.mode, a.order = AutopilotStandby, math.NaN()
+}
+
+// SetRudder puts the autopilot in standby with a rudder order in degrees,
+// positive to starboard, as a follow-up lever does
+func (a *Autopilot) SetRudder(angle float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.mode = AutopilotStandby
+	a.order = a.steering.Limit(angle)
+}
+
+// SetHeading engages the autopilot to hold a true heading
+func (a *Autopilot) SetHeading(heading float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.mode, a.heading = AutopilotHeading, NormalizeDegrees(heading)
+}
+
+// SetTrack engages the autopilot to follow a true course to a waypoint with
+// the cross-track error in nautical miles, positive right of the track
+func (a *Autopilot) SetTrack(course, offTrack float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.mode, a.track, a.offTrack = AutopilotTrack, NormalizeDegrees(course), offTrack
+}
+
+// State returns the autopilot mode, heading to steer and rudder
+func (a *Autopilot) State() AutopilotState {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	s := AutopilotState{
+		Mode:           a.mode,
+		HeadingToSteer: math.NaN(),
+		Track:          math.NaN(),
+		RudderOrder:    a.order,
+		Rudder:         a.steering.Rudder(),
+	}
+	switch a.mode {
+	case AutopilotHeading:
+		s.HeadingToSteer = a.heading
+	case AutopilotTrack:
+		s.HeadingToSteer = a.trackHeading()
+		s.Track = a.track
+	}
+	return s
+}
+
+// trackHeading returns the heading to steer back onto the track
+func (a *Autopilot) trackHeading() float64 {
+	correction := math.Max(-a.config.OffTrackLimit, math.Min(a.config.OffTrackLimit, a.offTrack*a.config.OffTrackGain))
+	return NormalizeDegrees(a.track - correction)
+}
+
+// Steer moves the rudder towards its order over dt and returns own ship's
+// rate of turn in degrees per minute. In standby without a rudder order own
+// ship keeps its rate of turn and the rudder follows it.
+func (a *Autopilot) Steer(own Vessel, dt time.Duration) float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cfg := a.config
+	order := a.order
+	switch a.mode {
+	case AutopilotStandby:
+		if math.IsNaN(order) {
+			a.steering.Follow(own.ROT, own.STW)
+			return own.ROT
+		}
+	case AutopilotHeading, AutopilotTrack:
+		target := a.heading
+		if a.mode == AutopilotTrack {
+			target = a.trackHeading()
+		}
+		order = cfg.HeadingGain*SignedDegrees(target-own.Heading) - cfg.RateGain*own.ROT/60
+	}
+	return a.steering.Step(order, own.STW, dt)
+}
@@ -0,0 +1,92 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// steerFor runs own ship under the autopilot for the given time in one second steps
+func steerFor(fleet *Fleet, d time.Duration) {
+	for t := time.Duration(0); t < d; t += time.Second {
+		fleet.Step(time.Second)
+	}
+}
+
+func TestAutopilotHeading(t *testing.T) {
+	pilot := NewAutopilot(DefaultAutopilotConfig())
+	fleet := NewFleet(DefaultOwnShip())
+	fleet.SetHelm(pilot)
+
+	// In standby own ship keeps its heading
+	steerFor(fleet, 10*time.Second)
+	if h := fleet.OwnShip().Heading; h != 45 {
+		t.Errorf("Expected the heading to stay at 45 in standby, got %.1f", h)
+	}
+
+	pilot.SetHeading(90)
+	steerFor(fleet, 5*time.Second)
+	if s := pilot.State(); s.Rudder <= 0 || s.Rudder > 25 {
+		t.Errorf("Expected starboard rudder limited by the rudder rate, got %.1f", s.Rudder)
+	}
+
+	steerFor(fleet, 2*time.Minute)
+	own := fleet.OwnShip()
+	if math.Abs(SignedDegrees(own.Heading-90)) > 1 {
+		t.Errorf("Expected own ship to settle on 090, got %.1f", own.Heading)
+	}
+	if s := pilot.State(); math.Abs(s.Rudder) > 1 || s.HeadingToSteer != 90 {
+		t.Errorf("Expected the rudder amidships on 090, got %.1f steering %.1f", s.Rudder, s.HeadingToSteer)
+	}
+
+	// Across north to port
+	pilot.SetHeading(350)
+	steerFor(fleet, 3*time.Minute)
+	if h := fleet.OwnShip().Heading; math.Abs(SignedDegrees(h-350)) > 1 {
+		t.Errorf("Expected own ship to settle on 350, got %.1f", h)
+	}
+}
+
+func TestAutopilotTrack(t *testing.T) {
+	pilot := NewAutopilot(DefaultAutopilotConfig())
+
+	// Right of the track the pilot steers to port of the course
+	pilot.SetTrack(10, 0.2)
+	if s := pilot.State(); s.Mode != AutopilotTrack || s.HeadingToSteer != 350 || s.Track != 10 {
+		t.Errorf("Expected to steer 350 for a course of 010 0.2 NM right of track, got %+v", s)
+	}
+	pilot.SetTrack(10, -5)
+	if s := pilot.State(); s.HeadingToSteer != 40 {
+		t.Errorf("Expected the correction limited to 30 degrees, got %.1f", s.HeadingToSteer)
+	}
+}
+
+func TestAutopilotRudderOrder(t *testing.T) {
+	// Turn at the steady rate at once
+	cfg := DefaultAutopilotConfig()
+	cfg.TurnLength = 0
+	pilot := NewAutopilot(cfg)
+	own := DefaultOwnShip()
+
+	pilot.SetRudder(-50)
+	if s := pilot.State(); s.Mode != AutopilotStandby || s.RudderOrder != -35 {
+		t.Errorf("Expected a standby rudder order limited to port 35, got %+v", s)
+	}
+	var rot float64
+	for i := 0; i < 10; i++ {
+		rot = pilot.Steer(own, time.Second)
+	}
+	if want := -35 * own.STW; rot != want {
+		t.Errorf("Expected a rate of turn of %.0f deg/min with hard over port rudder, got %.1f", want, rot)
+	}
+
+	pilot.Standby()
+	own.ROT = 30
+	if rot := pilot.Steer(own, time.Second); rot != 30 || pilot.State().Rudder != 5 {
+		t.Errorf("Expected own ship's rate of turn kept and the rudder following it, got %.1f and %.1f", rot, pilot.State().Rudder)
+	}
+
+	if _, err := ParseAutopilotMode("wind"); err == nil {
+		t.Error("Expected an error for an unknown mode")
+	}
+}
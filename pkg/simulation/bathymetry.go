@@ -0,0 +1,284 @@
+package simulation
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+)
+
+// Bathymetry gives the charted depth below chart datum at a position,
+// reporting false where no depth is known
+type Bathymetry interface {
+	Depth(lat, lon float64) (float64, bool)
+}
+
+// FlatBathymetry is the same charted depth everywhere, in meters
+type FlatBathymetry float64
+
+// Depth returns the flat depth
+func (b FlatBathymetry) Depth(lat, lon float64) (float64, bool) {
+	return float64(b), true
+}
+
+// DepthGrid is a bathymetry grid on regular latitude/longitude nodes,
+// interpolated bilinearly. Positions outside the grid or next to a node
+// without data have no depth.
+type DepthGrid struct {
+	lats, lons []float64   // Ascending node coordinates, degrees
+	depths     [][]float64 // Depth at [lat][lon], meters below chart datum, NaN for no data
+}
+
+// Depth interpolates the charted depth at a position
+func (g *DepthGrid) Depth(lat, lon float64) (float64, bool) {
+	if lat < g.lats[0] || lat > g.lats[len(g.lats)-1] || lon < g.lons[0] || lon > g.lons[len(g.lons)-1] {
+		return 0, false
+	}
+
+	i, fy := gridCell(g.lats, lat)
+	j, fx := gridCell(g.lons, lon)
+	depth := bilinear(g.depths, i, j, fy, fx)
+	return depth, !math.IsNaN(depth)
+}
+
+// LoadBathymetry reads a bathymetry grid, choosing the format by extension:
+// .asc for an ESRI ASCII grid, anything else for XYZ. Elevation grids such as
+// GEBCO, negative below the datum, are converted to depths when elevation is set.
+func LoadBathymetry(path string, elevation bool) (*DepthGrid, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bathymetry: %w", err)
+	}
+	defer f.Close()
+
+	parse := ParseXYZ
+	if strings.EqualFold(filepath.Ext(path), ".asc") {
+		parse = ParseESRIGrid
+	}
+	grid, err := parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bathymetry %s: %w", path, err)
+	}
+
+	if elevation {
+		for _, row := range grid.depths {
+			for j := range row {
+				row[j] = -row[j]
+			}
+		}
+	}
+	return grid, nil
+}
+
+// ParseESRIGrid parses an ESRI ASCII grid with longitude as x and latitude
+// as y. Rows run from north to south.
+func ParseESRIGrid(r io.Reader) (*DepthGrid, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	header := map[string]float64{}
+	var values []float64
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		// Header lines start with a keyword, data lines with a number
+		if len(values) == 0 && len(fields) == 2 {
+			if _, err := strconv.ParseFloat(fields[0], 64); err != nil {
+				v, err := strconv.ParseFloat(fields[1], 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid header value %q", fields[1])
+				}
+				header[strings.ToLower(fields[0])] = v
+				continue
+			}
+		}
+
+		for _, field := range fields {
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", field)
+			}
+			values = append(values, v)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, key := range []string{"ncols", "nrows", "cellsize"} {
+		if _, ok := header[key]; !ok {
+			return nil, fmt.Errorf("missing %s", key)
+		}
+	}
+	cols, rows, cell := int(header["ncols"]), int(header["nrows"]), header["cellsize"]
+	if cols < 1 || rows < 1 || cell <= 0 {
+		return nil, fmt.Errorf("invalid grid size %dx%d with cell size %g", cols, rows, cell)
+	}
+	if len(values) != cols*rows {
+		return nil, fmt.Errorf("expected %d values, got %d", cols*rows, len(values))
+	}
+
+	// Corner registration places the nodes half a cell in from the corner
+	x0, xok := header["xllcenter"]
+	y0, yok := header["yllcenter"]
+	if !xok || !yok {
+		x0, y0 = header["xllcorner"]+cell/2, header["yllcorner"]+cell/2
+	}
+	noData, hasNoData := header["nodata_value"]
+
+	g := &DepthGrid{lats: make([]float64, rows), lons: make([]float64, cols), depths: make([][]float64, rows)}
+	for j := range g.lons {
+		g.lons[j] = x0 + float64(j)*cell
+	}
+	for i := range g.lats {
+		g.lats[i] = y0 + float64(i)*cell
+		g.depths[i] = make([]float64, cols)
+		row := values[(rows-1-i)*cols : (rows-i)*cols]
+		for j, v := range row {
+			if hasNoData && v == noData {
+				v = math.NaN()
+			}
+			g.depths[i][j] = v
+		}
+	}
+
+	return g, nil
+}
+
+// ParseXYZ parses lines of longitude, latitude and depth separated by spaces
+// or commas. The points must lie on a regular grid; missing nodes have no
+// data. Lines starting with # are ignored.
+func ParseXYZ(r io.Reader) (*DepthGrid, error) {
+	type node struct{ lat, lon float64 }
+	nodes := map[node]float64{}
+	latSet, lonSet := map[float64]bool{}, map[float64]bool{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(strings.ReplaceAll(line, ",", " "))
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid line %q: expected x y z", line)
+		}
+		var v [3]float64
+		for i, field := range fields {
+			var err error
+			if v[i], err = strconv.ParseFloat(field, 64); err != nil {
+				return nil, fmt.Errorf("invalid value %q", field)
+			}
+		}
+
+		n := node{lat: v[1], lon: v[0]}
+		if _, ok := nodes[n]; ok {
+			return nil, fmt.Errorf("duplicate node %g,%g", n.lon, n.lat)
+		}
+		nodes[n] = v[2]
+		latSet[n.lat], lonSet[n.lon] = true, true
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no grid nodes")
+	}
+
+	g := &DepthGrid{lats: sortedKeys(latSet), lons: sortedKeys(lonSet)}
+	g.depths = make([][]float64, len(g.lats))
+	for i, lat := range g.lats {
+		g.depths[i] = make([]float64, len(g.lons))
+		for j, lon := range g.lons {
+			depth, ok := nodes[node{lat, lon}]
+			if !ok {
+				depth = math.NaN()
+			}
+			g.depths[i][j] = depth
+		}
+	}
+
+	return g, nil
+}
+
+// TideHeight is a semi-diurnal tide, the height of the water above chart datum
+type TideHeight struct {
+	MeanLevel float64       // Mean height above chart datum, meters
+	Range     float64       // Difference between high and low water, meters
+	Period    time.Duration // Tidal period, M2Period when zero
+	Epoch     time.Time     // Time of high water
+}
+
+// Height returns the height of tide at time t
+func (h TideHeight) Height(t time.Time) float64 {
+	period := h.Period
+	if period == 0 {
+		period = M2Period
+	}
+	phase := 2 * math.Pi * t.Sub(h.Epoch).Seconds() / period.Seconds()
+	return h.MeanLevel + h.Range/2*math.Cos(phase)
+}
+
+// DepthConfig describes an echo sounder installation
+type DepthConfig struct {
+	TransducerDepth float64 // Depth of the transducer below the waterline, meters
+	Offset          float64 // DPT offset, positive to the waterline or negative to the keel, meters
+	MaxRange        float64 // Deepest depth the sounder reports, meters
+	Noise           float64 // Standard deviation of the measurement noise, meters
+}
+
+// DefaultDepthConfig returns a transducer 0.5 m below the waterline of a yacht drawing 1.8 m
+func DefaultDepthConfig() DepthConfig {
+	return DepthConfig{
+		TransducerDepth: 0.5,
+		Offset:          -1.3,
+		MaxRange:        200,
+		Noise:           0.05,
+	}
+}
+
+// Sounding is a depth measurement
+type Sounding struct {
+	Valid    bool    // False over land, outside the chart or beyond range
+	Depth    float64 // Below the transducer, meters
+	Offset   float64 // Meters
+	MaxRange float64 // Meters
+}
+
+// DepthSounder measures the depth of water under own ship from the charted
+// depth, the height of tide and the transducer depth
+type DepthSounder struct {
+	config     DepthConfig
+	bathymetry Bathymetry
+	tide       TideHeight
+}
+
+// NewDepthSounder creates a depth sounder over the given bathymetry
+func NewDepthSounder(cfg DepthConfig, bathymetry Bathymetry, tide TideHeight) *DepthSounder {
+	return &DepthSounder{config: cfg, bathymetry: bathymetry, tide: tide}
+}
+
+// Sample measures the depth under the vessel at time t
+func (d *DepthSounder) Sample(v Vessel, t time.Time) Sounding {
+	s := Sounding{Offset: d.config.Offset, MaxRange: d.config.MaxRange}
+
+	charted, ok := d.bathymetry.Depth(v.Latitude, v.Longitude)
+	if !ok {
+		return s
+	}
+
+	s.Depth = charted + d.tide.Height(t) - d.config.TransducerDepth + util.RandomNormal(0, d.config.Noise)
+	s.Valid = s.Depth > 0 && (d.config.MaxRange <= 0 || s.Depth <= d.config.MaxRange)
+	return s
+}
@@ -0,0 +1,99 @@
+package simulation
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testESRIGrid = `ncols 3
+nrows 2
+xllcorner 16.0
+yllcorner 48.0
+cellsize 0.1
+NODATA_value -9999
+20 30 -9999
+10 20 30
+`
+
+func TestParseESRIGrid(t *testing.T) {
+	grid, err := ParseESRIGrid(strings.NewReader(testESRIGrid))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		lat, lon float64
+		depth    float64
+		ok       bool
+	}{
+		{48.05, 16.05, 10, true}, // South-west node
+		{48.15, 16.05, 20, true}, // North-west node
+		{48.10, 16.10, 20, true}, // Centre of the western cells
+		{48.05, 16.25, 30, true}, // South-east node
+		{48.15, 16.25, 0, false}, // No data
+		{48.10, 16.20, 0, false}, // Next to a node without data
+		{47.00, 16.05, 0, false}, // Outside the grid
+	}
+	for _, tt := range tests {
+		depth, ok := grid.Depth(tt.lat, tt.lon)
+		if ok != tt.ok || (ok && math.Abs(depth-tt.depth) > 1e-9) {
+			t.Errorf("Depth(%.2f, %.2f) = %.2f, %v; expected %.2f, %v", tt.lat, tt.lon, depth, ok, tt.depth, tt.ok)
+		}
+	}
+
+	if _, err := ParseESRIGrid(strings.NewReader("ncols 2\nnrows 2\ncellsize 1\n1 2 3\n")); err == nil {
+		t.Error("Expected an error for a short grid")
+	}
+}
+
+func TestLoadBathymetryXYZElevation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chart.xyz")
+	xyz := "# lon lat elevation\n16.0,48.0,-12\n16.1,48.0,-8\n16.0,48.1,-4\n"
+	if err := os.WriteFile(path, []byte(xyz), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	grid, err := LoadBathymetry(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if depth, ok := grid.Depth(48.0, 16.05); !ok || math.Abs(depth-10) > 1e-9 {
+		t.Errorf("Expected 10 m between the southern nodes, got %.2f, %v", depth, ok)
+	}
+	if _, ok := grid.Depth(48.05, 16.05); ok {
+		t.Error("Expected no depth next to the missing north-east node")
+	}
+}
+
+func TestParseXYZDuplicate(t *testing.T) {
+	xyz := "16.0 48.0 10\n16.1 48.0 12\n16.0 48.0 11\n"
+	if _, err := ParseXYZ(strings.NewReader(xyz)); err == nil {
+		t.Error("Expected an error for a duplicate node")
+	}
+}
+
+func TestDepthSounder(t *testing.T) {
+	epoch := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+	tide := TideHeight{MeanLevel: 1.5, Range: 3, Epoch: epoch}
+	cfg := DepthConfig{TransducerDepth: 0.5, Offset: -1.3, MaxRange: 100}
+	sounder := NewDepthSounder(cfg, FlatBathymetry(4), tide)
+	own := DefaultOwnShip()
+
+	if s := sounder.Sample(own, epoch); !s.Valid || math.Abs(s.Depth-6.5) > 1e-9 || s.Offset != -1.3 {
+		t.Errorf("Expected 6.5 m below the transducer at high water, got %+v", s)
+	}
+	if s := sounder.Sample(own, epoch.Add(M2Period/2)); !s.Valid || math.Abs(s.Depth-3.5) > 1e-9 {
+		t.Errorf("Expected 3.5 m below the transducer at low water, got %+v", s)
+	}
+
+	if s := NewDepthSounder(cfg, FlatBathymetry(0.2), TideHeight{}).Sample(own, epoch); s.Valid {
+		t.Errorf("Expected no valid sounding with the transducer aground, got %+v", s)
+	}
+	if s := NewDepthSounder(cfg, FlatBathymetry(150), TideHeight{}).Sample(own, epoch); s.Valid {
+		t.Errorf("Expected no valid sounding beyond the maximum range, got %+v", s)
+	}
+}
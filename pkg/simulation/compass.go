@@ -0,0 +1,98 @@
+package simulation
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DeviationPoint is a single entry of a deviation card
+type DeviationPoint struct {
+	Heading   float64 `json:"heading"`   // Compass heading, degrees
+	Deviation float64 `json:"deviation"` // Degrees, east positive
+}
+
+// DeviationCard lists a compass's deviation at a number of headings. Between
+// entries the deviation is interpolated linearly, wrapping around north.
+type DeviationCard []DeviationPoint
+
+// ParseDeviationCard parses a card such as "0:1.5,90:-2,180:0.5,270:2"
+func ParseDeviationCard(spec string) (DeviationCard, error) {
+	var card DeviationCard
+	if strings.TrimSpace(spec) == "" {
+		return card, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		heading, deviation, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid deviation entry %q: expected heading:deviation", entry)
+		}
+		h, err := strconv.ParseFloat(heading, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deviation heading %q", heading)
+		}
+		d, err := strconv.ParseFloat(deviation, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deviation %q", deviation)
+		}
+		card = append(card, DeviationPoint{Heading: NormalizeDegrees(h), Deviation: d})
+	}
+
+	return card, nil
+}
+
+// Deviation returns the deviation at the given compass heading
+func (c DeviationCard) Deviation(heading float64) float64 {
+	switch len(c) {
+	case 0:
+		return 0
+	case 1:
+		return c[0].Deviation
+	}
+
+	points := make(DeviationCard, len(c))
+	copy(points, c)
+	sort.Slice(points, func(i, j int) bool { return points[i].Heading < points[j].Heading })
+
+	heading = NormalizeDegrees(heading)
+	for i := range points {
+		next := points[(i+1)%len(points)]
+		span := NormalizeDegrees(next.Heading - points[i].Heading)
+		offset := NormalizeDegrees(heading - points[i].Heading)
+		if span == 0 || offset > span {
+			continue
+		}
+		return points[i].Deviation + (next.Deviation-points[i].Deviation)*offset/span
+	}
+	return points[0].Deviation
+}
+
+// CompassHeading is a heading resolved into its true, magnetic and compass components
+type CompassHeading struct {
+	True      float64 // Degrees
+	Magnetic  float64 // Degrees
+	Compass   float64 // Degrees, as read from the compass
+	Deviation float64 // Degrees, east positive
+	Variation float64 // Degrees, east positive
+}
+
+// ResolveHeading applies variation and the deviation card to a true heading.
+// Deviation is tabulated against compass heading, so it is found by iterating
+// from the magnetic heading.
+func ResolveHeading(trueHeading, variation float64, card DeviationCard) CompassHeading {
+	h := CompassHeading{
+		True:      NormalizeDegrees(trueHeading),
+		Magnetic:  NormalizeDegrees(trueHeading - variation),
+		Variation: variation,
+	}
+
+	h.Compass = h.Magnetic
+	for i := 0; i < 3; i++ {
+		h.Deviation = card.Deviation(h.Compass)
+		h.Compass = NormalizeDegrees(h.Magnetic - h.Deviation)
+	}
+
+	return h
+}
@@ -0,0 +1,75 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDeviationCard(t *testing.T) {
+	card, err := ParseDeviationCard("0:2, 90:-2, 180:0, 270:4")
+	if err != nil {
+		t.Fatalf("ParseDeviationCard failed: %v", err)
+	}
+
+	tests := []struct {
+		heading, deviation float64
+	}{
+		{0, 2},
+		{45, 0},
+		{90, -2},
+		{135, -1},
+		{315, 3},
+		{360, 2},
+	}
+	for _, tt := range tests {
+		if got := card.Deviation(tt.heading); math.Abs(got-tt.deviation) > 1e-9 {
+			t.Errorf("Deviation(%.0f) = %.2f, expected %.2f", tt.heading, got, tt.deviation)
+		}
+	}
+
+	for _, spec := range []string{"0", "x:1", "0:y"} {
+		if _, err := ParseDeviationCard(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestResolveHeading(t *testing.T) {
+	card := DeviationCard{{Heading: 0, Deviation: 3}, {Heading: 180, Deviation: -3}}
+	h := ResolveHeading(95, 5, card)
+
+	if h.Magnetic != 90 {
+		t.Errorf("Expected magnetic heading 90, got %.2f", h.Magnetic)
+	}
+
+	// Compass plus deviation gives magnetic, with deviation read at the compass heading
+	if math.Abs(h.Compass+h.Deviation-h.Magnetic) > 1e-9 || math.Abs(card.Deviation(h.Compass)-h.Deviation) > 0.01 {
+		t.Errorf("Inconsistent compass heading %.2f and deviation %.2f", h.Compass, h.Deviation)
+	}
+}
+
+func TestHeadingSensorRateOfTurn(t *testing.T) {
+	sensor, err := NewSensor(SensorConfig{Name: "gyro", Kind: SensorHeading})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+	own := DefaultOwnShip()
+	own.Heading = 358
+	sensor.Sample(own, start)
+
+	// Turning through north to port at 3 degrees in 2 seconds
+	own.Heading = 355
+	reading := sensor.Sample(own, start.Add(2*time.Second))
+	if math.Abs(reading.Vessel.ROT+90) > 1e-9 {
+		t.Errorf("Expected -90 deg/min, got %.2f", reading.Vessel.ROT)
+	}
+
+	own.Heading = 1
+	reading = sensor.Sample(own, start.Add(4*time.Second))
+	if math.Abs(reading.Vessel.ROT-180) > 1e-9 {
+		t.Errorf("Expected 180 deg/min through north, got %.2f", reading.Vessel.ROT)
+	}
+}
@@ -0,0 +1,249 @@
+package simulation
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Current is a surface current
+type Current struct {
+	Set   float64 // Direction the current flows towards, degrees true
+	Drift float64 // Knots
+}
+
+// velocity returns the north and east components of the current in knots
+func (c Current) velocity() (float64, float64) {
+	set := c.Set * math.Pi / 180
+	return c.Drift * math.Cos(set), c.Drift * math.Sin(set)
+}
+
+// currentFromVelocity builds a current from its north and east components in knots
+func currentFromVelocity(north, east float64) Current {
+	c := Current{Drift: math.Hypot(north, east)}
+	if c.Drift > 1e-9 {
+		c.Set = NormalizeDegrees(math.Atan2(east, north) * 180 / math.Pi)
+	}
+	return c
+}
+
+// CurrentModel gives the surface current at a position and time
+type CurrentModel interface {
+	Current(lat, lon float64, t time.Time) Current
+}
+
+// ConstantCurrent is the same current everywhere and at all times
+type ConstantCurrent Current
+
+// Current returns the constant current
+func (c ConstantCurrent) Current(lat, lon float64, t time.Time) Current {
+	return Current(c)
+}
+
+// M2Period is the period of the principal lunar semi-diurnal tide
+const M2Period = 12*time.Hour + 25*time.Minute + 14*time.Second
+
+// TidalCurrent is a rotary tidal stream tracing an ellipse. The flood runs
+// along Axis at Major knots at Epoch, the ebb runs the opposite way half a
+// period later and the stream turns through the minor axis in between,
+// clockwise for a positive Minor.
+type TidalCurrent struct {
+	Axis   float64       // Direction of the flood, degrees true
+	Major  float64       // Maximum flood and ebb rate, knots
+	Minor  float64       // Rate at slack water across the major axis, knots
+	Period time.Duration // Tidal period, M2Period when zero
+	Epoch  time.Time     // Time of maximum flood
+}
+
+// Current returns the tidal stream at time t
+func (c TidalCurrent) Current(lat, lon float64, t time.Time) Current {
+	period := c.Period
+	if period == 0 {
+		period = M2Period
+	}
+
+	phase := 2 * math.Pi * t.Sub(c.Epoch).Seconds() / period.Seconds()
+	along, across := c.Major*math.Cos(phase), c.Minor*math.Sin(phase)
+
+	axis := c.Axis * math.Pi / 180
+	north := along*math.Cos(axis) - across*math.Sin(axis)
+	east := along*math.Sin(axis) + across*math.Cos(axis)
+	return currentFromVelocity(north, east)
+}
+
+// CurrentGrid is a current field sampled on a regular latitude/longitude
+// grid, interpolated bilinearly between nodes and clamped at its edges
+type CurrentGrid struct {
+	lats, lons  []float64   // Ascending node coordinates, degrees
+	north, east [][]float64 // Components at [lat][lon], knots
+}
+
+// LoadCurrentGrid reads a current grid from a CSV file
+func LoadCurrentGrid(path string) (*CurrentGrid, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read current grid: %w", err)
+	}
+	defer f.Close()
+
+	grid, err := ParseCurrentGrid(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse current grid %s: %w", path, err)
+	}
+	return grid, nil
+}
+
+// ParseCurrentGrid parses CSV rows of latitude, longitude, set and drift
+// (degrees and knots). Every combination of the latitudes and longitudes
+// present must be listed once; lines starting with # are ignored.
+func ParseCurrentGrid(r io.Reader) (*CurrentGrid, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	type node struct{ lat, lon float64 }
+	nodes := map[node]Current{}
+	latSet, lonSet := map[float64]bool{}, map[float64]bool{}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var v [4]float64
+		for i, field := range record {
+			if v[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64); err != nil {
+				return nil, fmt.Errorf("invalid value %q", field)
+			}
+		}
+
+		n := node{v[0], v[1]}
+		if _, ok := nodes[n]; ok {
+			return nil, fmt.Errorf("duplicate node %g,%g", n.lat, n.lon)
+		}
+		nodes[n] = Current{Set: v[2], Drift: v[3]}
+		latSet[n.lat], lonSet[n.lon] = true, true
+	}
+
+	g := &CurrentGrid{lats: sortedKeys(latSet), lons: sortedKeys(lonSet)}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no grid nodes")
+	}
+	if len(nodes) != len(g.lats)*len(g.lons) {
+		return nil, fmt.Errorf("expected %d nodes for a %dx%d grid, got %d",
+			len(g.lats)*len(g.lons), len(g.lats), len(g.lons), len(nodes))
+	}
+
+	g.north, g.east = make([][]float64, len(g.lats)), make([][]float64, len(g.lats))
+	for i, lat := range g.lats {
+		g.north[i], g.east[i] = make([]float64, len(g.lons)), make([]float64, len(g.lons))
+		for j, lon := range g.lons {
+			c, ok := nodes[node{lat, lon}]
+			if !ok {
+				return nil, fmt.Errorf("missing node %g,%g", lat, lon)
+			}
+			g.north[i][j], g.east[i][j] = c.velocity()
+		}
+	}
+
+	return g, nil
+}
+
+// Current interpolates the current at a position
+func (g *CurrentGrid) Current(lat, lon float64, t time.Time) Current {
+	i, fy := gridCell(g.lats, lat)
+	j, fx := gridCell(g.lons, lon)
+	return currentFromVelocity(bilinear(g.north, i, j, fy, fx), bilinear(g.east, i, j, fy, fx))
+}
+
+// bilinear interpolates between the node [i][j] and its neighbours to the
+// north and east, with fractional offsets fy and fx towards them. A node with
+// a zero weight does not contribute, so an edge node needs no neighbour.
+func bilinear(v [][]float64, i, j int, fy, fx float64) float64 {
+	at := func(i, j int, w float64) float64 {
+		if w == 0 {
+			return 0
+		}
+		return w * v[i][j]
+	}
+	return at(i, j, (1-fy)*(1-fx)) + at(i, min(j+1, len(v[i])-1), (1-fy)*fx) +
+		at(min(i+1, len(v)-1), j, fy*(1-fx)) + at(min(i+1, len(v)-1), min(j+1, len(v[i])-1), fy*fx)
+}
+
+// gridCell returns the index of the node at or below v and the fractional
+// position of v towards the next node, clamped to the grid
+func gridCell(nodes []float64, v float64) (int, float64) {
+	if v <= nodes[0] || len(nodes) == 1 {
+		return 0, 0
+	}
+	if v >= nodes[len(nodes)-1] {
+		return len(nodes) - 1, 0
+	}
+	i := sort.SearchFloat64s(nodes, v) - 1
+	return i, (v - nodes[i]) / (nodes[i+1] - nodes[i])
+}
+
+// sortedKeys returns the keys of a set in ascending order
+func sortedKeys(set map[float64]bool) []float64 {
+	keys := make([]float64, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Float64s(keys)
+	return keys
+}
+
+// ParseCurrent parses a current specification:
+//
+//	constant:SET:DRIFT           e.g. constant:090:1.5
+//	tidal:AXIS:MAJOR[:MINOR]     e.g. tidal:045:2.5:0.3, with maximum flood now
+//	grid:PATH                    a CSV file read by LoadCurrentGrid
+//
+// An empty specification returns a nil model, meaning no current.
+func ParseCurrent(spec string, now time.Time) (CurrentModel, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, nil
+	}
+
+	kind, args, _ := strings.Cut(spec, ":")
+	if kind == "grid" {
+		grid, err := LoadCurrentGrid(args)
+		if err != nil {
+			return nil, err
+		}
+		return grid, nil
+	}
+
+	var values []float64
+	for _, field := range strings.Split(args, ":") {
+		v, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid current %q: %q is not a number", spec, field)
+		}
+		values = append(values, v)
+	}
+
+	switch {
+	case kind == "constant" && len(values) == 2:
+		return ConstantCurrent{Set: NormalizeDegrees(values[0]), Drift: values[1]}, nil
+	case kind == "tidal" && (len(values) == 2 || len(values) == 3):
+		c := TidalCurrent{Axis: NormalizeDegrees(values[0]), Major: values[1], Epoch: now}
+		if len(values) == 3 {
+			c.Minor = values[2]
+		}
+		return c, nil
+	}
+	return nil, fmt.Errorf("invalid current %q: expected constant:SET:DRIFT, tidal:AXIS:MAJOR[:MINOR] or grid:PATH", spec)
+}
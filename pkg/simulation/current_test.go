@@ -0,0 +1,118 @@
+package simulation
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTidalCurrent(t *testing.T) {
+	epoch := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+	tide := TidalCurrent{Axis: 45, Major: 2.5, Minor: 0.5, Epoch: epoch}
+
+	tests := []struct {
+		offset time.Duration
+		set    float64
+		drift  float64
+	}{
+		{0, 45, 2.5},
+		{M2Period / 4, 135, 0.5},
+		{M2Period / 2, 225, 2.5},
+		{3 * M2Period / 4, 315, 0.5},
+	}
+	for _, tt := range tests {
+		c := tide.Current(0, 0, epoch.Add(tt.offset))
+		if math.Abs(c.Set-tt.set) > 1e-6 || math.Abs(c.Drift-tt.drift) > 1e-6 {
+			t.Errorf("At %v: expected %.0f° at %.1f kn, got %.3f° at %.3f kn", tt.offset, tt.set, tt.drift, c.Set, c.Drift)
+		}
+	}
+}
+
+func TestCurrentGrid(t *testing.T) {
+	grid, err := ParseCurrentGrid(strings.NewReader(`# lat,lon,set,drift
+48.0, 16.0, 90, 1
+48.0, 16.5, 90, 3
+48.5, 16.0, 0, 1
+48.5, 16.5, 0, 3
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c := grid.Current(48.0, 16.25, time.Time{}); math.Abs(c.Set-90) > 1e-6 || math.Abs(c.Drift-2) > 1e-6 {
+		t.Errorf("Expected 090° at 2 kn between the southern nodes, got %+v", c)
+	}
+	if c := grid.Current(48.25, 16.0, time.Time{}); math.Abs(c.Set-45) > 1e-6 || math.Abs(c.Drift-math.Sqrt2/2) > 1e-6 {
+		t.Errorf("Expected 045° at 0.71 kn between the western nodes, got %+v", c)
+	}
+	if c := grid.Current(50, 20, time.Time{}); math.Abs(c.Set) > 1e-6 || math.Abs(c.Drift-3) > 1e-6 {
+		t.Errorf("Expected the north-eastern node outside the grid, got %+v", c)
+	}
+
+	if _, err := ParseCurrentGrid(strings.NewReader("48,16,90,1\n48,17,90,1\n49,16,90,1\n")); err == nil {
+		t.Error("Expected an error for an incomplete grid")
+	}
+}
+
+func TestParseCurrent(t *testing.T) {
+	now := time.Now()
+
+	m, err := ParseCurrent("constant:090:1.5", now)
+	if err != nil || m.Current(0, 0, now) != (Current{Set: 90, Drift: 1.5}) {
+		t.Errorf("Unexpected constant current %+v, %v", m, err)
+	}
+
+	m, err = ParseCurrent("tidal:045:2.5:0.3", now)
+	if tide, ok := m.(TidalCurrent); err != nil || !ok || tide.Minor != 0.3 || !tide.Epoch.Equal(now) {
+		t.Errorf("Unexpected tidal current %+v, %v", m, err)
+	}
+
+	if m, err := ParseCurrent("", now); m != nil || err != nil {
+		t.Errorf("Expected no current, got %+v, %v", m, err)
+	}
+	for _, spec := range []string{"constant:090", "tidal:x:1", "eddy:1:2"} {
+		if _, err := ParseCurrent(spec, now); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestFleetCurrent(t *testing.T) {
+	own := DefaultOwnShip()
+	own.Heading, own.STW = 0, 6
+	fleet := NewFleet(own)
+	fleet.SetCurrent(ConstantCurrent{Set: 90, Drift: 2})
+
+	v := fleet.OwnShip()
+	if math.Abs(v.SOG-math.Hypot(6, 2)) > 1e-9 || math.Abs(v.COG-math.Atan2(2, 6)*180/math.Pi) > 1e-9 {
+		t.Errorf("Expected SOG %.2f on COG %.1f, got %.2f on %.1f", math.Hypot(6, 2), math.Atan2(2, 6)*180/math.Pi, v.SOG, v.COG)
+	}
+	if v.Heading != 0 || v.STW != 6 {
+		t.Errorf("Current should not change heading or speed through water, got %+v", v)
+	}
+
+	fleet.Step(time.Hour)
+	moved := fleet.OwnShip()
+	if _, d := BearingDistance(own.Latitude, own.Longitude, moved.Latitude, moved.Longitude); math.Abs(d-math.Hypot(6, 2)) > 0.01 {
+		t.Errorf("Expected to cover %.2f NM over ground in an hour, got %.2f", math.Hypot(6, 2), d)
+	}
+}
+
+func TestWaterSpeedComponents(t *testing.T) {
+	own := DefaultOwnShip()
+	own.Heading, own.STW, own.Leeway = 0, 6, 10
+	fleet := NewFleet(own)
+	fleet.SetCurrent(ConstantCurrent{Set: 90, Drift: 2})
+
+	// The current does not add to the motion through the water, the leeway does
+	v := fleet.OwnShip()
+	along, across := v.WaterSpeedComponents(fleet.Current())
+	leeway := 10 * math.Pi / 180
+	if math.Abs(along-6*math.Cos(leeway)) > 1e-9 || math.Abs(across-6*math.Sin(leeway)) > 1e-9 {
+		t.Errorf("Expected %.3f along and %.3f across, got %.3f and %.3f", 6*math.Cos(leeway), 6*math.Sin(leeway), along, across)
+	}
+	if _, groundAcross := v.GroundSpeedComponents(); math.Abs(groundAcross-(2+6*math.Sin(leeway))) > 1e-9 {
+		t.Errorf("Expected %.3f across over ground, got %.3f", 2+6*math.Sin(leeway), groundAcross)
+	}
+}
@@ -0,0 +1,302 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// BatteryType is the construction of a battery, numbered as in PGN 127513
+type BatteryType uint8
+
+// Battery types
+const (
+	BatteryFlooded BatteryType = 0
+	BatteryGel     BatteryType = 1
+	BatteryAGM     BatteryType = 2
+	BatteryLithium BatteryType = 15 // LiFePO4, with lithium chemistry and no lead-acid type
+)
+
+// ParseBatteryType parses a battery type name: flooded, gel, agm or lithium
+func ParseBatteryType(s string) (BatteryType, error) {
+	switch s {
+	case "flooded":
+		return BatteryFlooded, nil
+	case "gel":
+		return BatteryGel, nil
+	case "agm":
+		return BatteryAGM, nil
+	case "lithium":
+		return BatteryLithium, nil
+	}
+	return BatteryAGM, fmt.Errorf("unknown battery type %q: expected flooded, gel, agm or lithium", s)
+}
+
+// BatteryConfig describes a 12 V battery bank
+type BatteryConfig struct {
+	Name     string
+	Type     BatteryType
+	Capacity float64 // Amp hours
+	SoC      float64 // State of charge at start-up, percent
+	Health   float64 // State of health, percent
+}
+
+// ElectricalConfig describes own ship's DC system: a house bank charged by
+// solar panels, the engine alternator and a shore power charger, and a start
+// battery kept topped up by the alternator
+type ElectricalConfig struct {
+	House             BatteryConfig
+	Start             BatteryConfig
+	SolarPeak         float64 // Solar panel output at noon, watts
+	AlternatorCurrent float64 // Alternator output while motoring, amps
+	ChargerCurrent    float64 // Shore power charger output, amps
+	ShorePower        bool    // Shore power is connected
+	BaseLoad          float64 // Fridge, instruments and standby loads, amps
+	NightLoad         float64 // Navigation, anchor and cabin lights after dark, amps
+	UnderwayLoad      float64 // Autopilot, radar and plotter under way, amps
+}
+
+// DefaultElectricalConfig returns the DC system of a cruising yacht
+func DefaultElectricalConfig() ElectricalConfig {
+	return ElectricalConfig{
+		House:             BatteryConfig{Name: "House", Type: BatteryAGM, Capacity: 400, SoC: 85, Health: 96},
+		Start:             BatteryConfig{Name: "Start", Type: BatteryFlooded, Capacity: 100, SoC: 95, Health: 90},
+		SolarPeak:         400,
+		AlternatorCurrent: 60,
+		ChargerCurrent:    30,
+		BaseLoad:          3,
+		NightLoad:         4,
+		UnderwayLoad:      6,
+	}
+}
+
+// DC instances of the electrical system
+const (
+	DCHouse      uint8 = 0
+	DCStart      uint8 = 1
+	DCSolar      uint8 = 2
+	DCAlternator uint8 = 3
+)
+
+// ChargerState is the operating state of a charger, numbered as in PGN 127507
+type ChargerState uint8
+
+// Charger operating states
+const (
+	ChargerNotCharging ChargerState = 0
+	ChargerBulk        ChargerState = 1
+	ChargerAbsorption  ChargerState = 2
+	ChargerFloat       ChargerState = 5
+	ChargerDisabled    ChargerState = 8
+)
+
+// Charging voltages of a 12 V lead-acid bank
+const (
+	absorptionVoltage = 14.4
+	floatVoltage      = 13.6
+)
+
+// Battery is the state of a battery bank
+type Battery struct {
+	Instance      uint8
+	Config        BatteryConfig
+	Voltage       float64 // Volts
+	Current       float64 // Amps, positive charging
+	Temperature   float64 // °C
+	SoC           float64 // State of charge, percent
+	TimeRemaining float64 // Minutes until flat at the present discharge, NaN while charging
+	Ripple        float64 // Ripple voltage, volts
+}
+
+// DCSource is the output of a charging source
+type DCSource struct {
+	Instance uint8
+	Voltage  float64 // Volts
+	Current  float64 // Amps
+}
+
+// Charger is the state of the shore power charger
+type Charger struct {
+	Instance        uint8
+	BatteryInstance uint8
+	Enabled         bool
+	State           ChargerState
+	Current         float64 // Amps
+}
+
+// Electrical is the state of the DC system
+type Electrical struct {
+	Batteries  []Battery // House and start banks
+	Solar      DCSource
+	Alternator DCSource
+	Charger    Charger
+	Load       float64 // House load, amps
+}
+
+// ElectricalModel simulates own ship's batteries through the day: solar
+// charging follows the sun at own ship's longitude, lights come on after dark,
+// the alternator charges while motoring and the shore power charger
+// runs through bulk, absorption and float
+type ElectricalModel struct {
+	config ElectricalConfig
+
+	mu   sync.Mutex
+	last time.Time
+	soc  [2]float64 // House and start state of charge, percent
+}
+
+// NewElectricalModel creates an electrical system with the given configuration
+func NewElectricalModel(cfg ElectricalConfig) *ElectricalModel {
+	return &ElectricalModel{config: cfg, soc: [2]float64{cfg.House.SoC, cfg.Start.SoC}}
+}
+
+// Config returns the electrical configuration
+func (m *ElectricalModel) Config() ElectricalConfig {
+	return m.config
+}
+
+// SetShorePower connects or disconnects shore power
+func (m *ElectricalModel) SetShorePower(connected bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config.ShorePower = connected
+}
+
+// Sample advances the batteries to time t and returns the state of the DC
+// system for own ship and its engines
+func (m *ElectricalModel) Sample(v Vessel, engines []Engine, t time.Time) Electrical {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg := m.config
+	var dt float64
+	if !m.last.IsZero() && t.After(m.last) {
+		dt = t.Sub(m.last).Seconds()
+	}
+	if m.last.IsZero() || t.After(m.last) {
+		m.last = t
+	}
+
+	// House loads: the fridge compressor cycles, the lights come on after dark
+	load := cfg.BaseLoad
+	if math.Mod(float64(t.Unix()), 1200) < 480 {
+		load += 4
+	}
+	hour := localSolarHour(v.Longitude, t)
+	if hour < 6 || hour >= 20 {
+		load += cfg.NightLoad
+	}
+	if v.STW > 0.1 {
+		load += cfg.UnderwayLoad
+	}
+
+	// Charge sources: the alternator is counted only while motoring, as
+	// engines idling in neutral stand in for engines that are stopped
+	running := false
+	for _, e := range engines {
+		running = running || e.Gear != GearNeutral
+	}
+	solar := cfg.SolarPeak * math.Max(0, math.Sin(math.Pi*(hour-6)/12)) / floatVoltage
+	var alternator, charger float64
+	if running {
+		alternator = cfg.AlternatorCurrent
+	}
+	if cfg.ShorePower {
+		charger = cfg.ChargerCurrent
+	}
+
+	// The house bank takes what the sources give less the load, up to its
+	// acceptance; the start battery is topped up through a combiner
+	house := m.chargeBattery(0, cfg.House, solar+alternator+charger-load, dt)
+	start := m.chargeBattery(1, cfg.Start, math.Min(alternator, 5), dt)
+	house.Instance, start.Instance = DCHouse, DCStart
+
+	// Sources are curtailed once the bank stops accepting their full output
+	supplied := house.Current + load
+	scale := 1.0
+	if total := solar + alternator + charger; total > 0 && supplied < total {
+		scale = math.Max(supplied, 0) / total
+	}
+
+	state := ChargerDisabled
+	switch {
+	case !cfg.ShorePower:
+	case house.SoC >= 99.5:
+		state = ChargerFloat
+	case house.SoC >= 80:
+		state = ChargerAbsorption
+	default:
+		state = ChargerBulk
+	}
+	if cfg.ShorePower {
+		house.Ripple = 0.05
+	}
+
+	return Electrical{
+		Batteries:  []Battery{house, start},
+		Solar:      DCSource{Instance: DCSolar, Voltage: house.Voltage + 0.3, Current: solar * scale},
+		Alternator: DCSource{Instance: DCAlternator, Voltage: house.Voltage + 0.2, Current: alternator * scale},
+		Charger:    Charger{Instance: 0, BatteryInstance: DCHouse, Enabled: cfg.ShorePower, State: state, Current: charger * scale},
+		Load:       load,
+	}
+}
+
+// chargeBattery applies a net current to battery i over dt seconds, limited
+// by its charge acceptance, and returns its state
+func (m *ElectricalModel) chargeBattery(i int, cfg BatteryConfig, current, dt float64) Battery {
+	soc := m.soc[i]
+	lithium := cfg.Type == BatteryLithium
+
+	// Lead-acid banks accept less as they fill; lithium banks take their
+	// full rate almost to the top
+	acceptance := 0.25 * cfg.Capacity
+	taper := 80.0
+	if lithium {
+		acceptance, taper = 0.5*cfg.Capacity, 98
+	}
+	if soc > taper {
+		acceptance *= (100 - soc) / (100 - taper)
+	}
+	charging := current > 0
+	current = math.Min(current, acceptance)
+
+	efficiency := 1.0
+	if current > 0 && !lithium {
+		efficiency = 0.9
+	}
+	soc += current * efficiency * dt / 3600 / cfg.Capacity * 100
+	soc = math.Min(math.Max(soc, 0), 100)
+	m.soc[i] = soc
+
+	// Open circuit voltage with the drop or rise across the internal resistance
+	ocv := 11.6 + 0.011*soc
+	if lithium {
+		ocv = 12.8 + 0.005*(soc-50)
+	}
+	resistance := 0.8 / cfg.Capacity
+	voltage := ocv + current*resistance
+
+	// Charge sources hold a filling bank at absorption, then float
+	if charging && soc > taper {
+		voltage = absorptionVoltage
+		if soc >= 99.5 {
+			voltage = floatVoltage
+		}
+	}
+
+	remaining := math.NaN()
+	if current < 0 {
+		remaining = soc / 100 * cfg.Capacity / -current * 60
+	}
+
+	return Battery{
+		Config:        cfg,
+		Voltage:       voltage,
+		Current:       current,
+		Temperature:   25 + 0.02*math.Abs(current),
+		SoC:           soc,
+		TimeRemaining: remaining,
+		Ripple:        0.005,
+	}
+}
@@ -0,0 +1,80 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestElectricalModelSolarDay(t *testing.T) {
+	cfg := DefaultElectricalConfig()
+	model := NewElectricalModel(cfg)
+	own := Vessel{Longitude: 0}
+	midnight := time.Date(2025, 6, 21, 0, 0, 0, 0, time.UTC)
+
+	night := model.Sample(own, nil, midnight)
+	if night.Solar.Current != 0 {
+		t.Errorf("Expected no solar current at midnight, got %.1f A", night.Solar.Current)
+	}
+	house := night.Batteries[0]
+	if house.Current >= 0 || math.IsNaN(house.TimeRemaining) {
+		t.Errorf("Expected the house bank discharging with a time remaining at night, got %.1f A and %.0f min", house.Current, house.TimeRemaining)
+	}
+	if night.Load < cfg.BaseLoad+cfg.NightLoad {
+		t.Errorf("Expected the lights on at night, got a load of %.1f A", night.Load)
+	}
+
+	// Through the night the house bank runs down
+	dawn := model.Sample(own, nil, midnight.Add(6*time.Hour))
+	if dawn.Batteries[0].SoC >= house.SoC {
+		t.Errorf("Expected the state of charge to fall overnight, got %.1f%% then %.1f%%", house.SoC, dawn.Batteries[0].SoC)
+	}
+
+	noon := model.Sample(own, nil, midnight.Add(12*time.Hour))
+	if want := cfg.SolarPeak / floatVoltage; math.Abs(noon.Solar.Current-want) > 0.1 {
+		t.Errorf("Expected %.1f A of solar current at noon, got %.1f", want, noon.Solar.Current)
+	}
+	if noon.Batteries[0].Current <= 0 || !math.IsNaN(noon.Batteries[0].TimeRemaining) {
+		t.Errorf("Expected the house bank charging at noon, got %.1f A", noon.Batteries[0].Current)
+	}
+}
+
+func TestElectricalModelCharging(t *testing.T) {
+	cfg := DefaultElectricalConfig()
+	cfg.SolarPeak = 0
+	cfg.House.SoC = 50
+	cfg.Start.SoC = 90
+	model := NewElectricalModel(cfg)
+	own := Vessel{STW: 6}
+	start := time.Date(2025, 6, 21, 12, 0, 0, 0, time.UTC)
+	running := []Engine{{RPM: 2400}}
+
+	e := model.Sample(own, running, start)
+	if e.Alternator.Current != cfg.AlternatorCurrent || e.Charger.State != ChargerDisabled {
+		t.Errorf("Expected the alternator at %.0f A and the charger disabled, got %.1f A and state %d", cfg.AlternatorCurrent, e.Alternator.Current, e.Charger.State)
+	}
+	e = model.Sample(own, running, start.Add(time.Hour))
+	if e.Batteries[1].SoC <= cfg.Start.SoC {
+		t.Errorf("Expected the alternator to charge the start battery, got %.1f%%", e.Batteries[1].SoC)
+	}
+
+	// On shore power the charger runs through bulk and absorption to float
+	model.SetShorePower(true)
+	moored := Vessel{}
+	states := map[ChargerState]bool{}
+	for h := 2; h <= 24; h++ {
+		e = model.Sample(moored, nil, start.Add(time.Duration(h)*time.Hour))
+		states[e.Charger.State] = true
+	}
+	for _, s := range []ChargerState{ChargerBulk, ChargerAbsorption, ChargerFloat} {
+		if !states[s] {
+			t.Errorf("Expected the charger to pass through state %d, got %v", s, states)
+		}
+	}
+	if house := e.Batteries[0]; house.SoC < 99.5 || house.Voltage != floatVoltage {
+		t.Errorf("Expected a full house bank at float voltage, got %.1f%% at %.2f V", house.SoC, house.Voltage)
+	}
+	if e.Charger.Current >= cfg.ChargerCurrent {
+		t.Errorf("Expected the charger to be curtailed at float, got %.1f A", e.Charger.Current)
+	}
+}
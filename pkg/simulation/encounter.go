@@ -0,0 +1,186 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EncounterType describes the geometry of a collision-course scenario
+type EncounterType string
+
+// Encounter types
+const (
+	HeadOn            EncounterType = "head-on"
+	CrossingStarboard EncounterType = "crossing" // Target crosses from own ship's starboard side
+	CrossingPort      EncounterType = "crossing-port"
+	Overtaking        EncounterType = "overtaking" // Target overtakes own ship from astern
+	Overtaken         EncounterType = "overtaken"  // Own ship overtakes a slower target ahead
+)
+
+// Encounter describes a target to be placed relative to own ship so that it
+// reaches the requested closest point of approach after the requested time
+type Encounter struct {
+	Type       EncounterType
+	CPA        float64       // Nautical miles
+	TCPA       time.Duration // Time until the closest point of approach
+	Speed      float64       // Target speed in knots, defaults depend on the encounter type
+	PassToPort bool          // Target passes on own ship's port side instead of starboard at CPA
+}
+
+// ParseEncounter parses an encounter in the form type:cpa:tcpa[:speed], for
+// example "crossing:0.5:6m" or "head-on:0:10m:12"
+func ParseEncounter(s string) (Encounter, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 3 || len(parts) > 4 {
+		return Encounter{}, fmt.Errorf("invalid encounter %q: expected type:cpa:tcpa[:speed]", s)
+	}
+
+	e := Encounter{Type: EncounterType(parts[0])}
+	switch e.Type {
+	case HeadOn, CrossingStarboard, CrossingPort, Overtaking, Overtaken:
+	default:
+		return Encounter{}, fmt.Errorf("invalid encounter type %q", parts[0])
+	}
+
+	cpa, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || cpa < 0 {
+		return Encounter{}, fmt.Errorf("invalid encounter CPA %q", parts[1])
+	}
+	e.CPA = cpa
+
+	tcpa, err := time.ParseDuration(parts[2])
+	if err != nil || tcpa <= 0 {
+		return Encounter{}, fmt.Errorf("invalid encounter TCPA %q", parts[2])
+	}
+	e.TCPA = tcpa
+
+	if len(parts) == 4 {
+		speed, err := strconv.ParseFloat(parts[3], 64)
+		if err != nil || speed < 0 {
+			return Encounter{}, fmt.Errorf("invalid encounter speed %q", parts[3])
+		}
+		e.Speed = speed
+	}
+
+	return e, nil
+}
+
+// minRelativeSpeed is the slowest approach in knots an encounter can be built
+// with; slower targets would start on top of own ship
+const minRelativeSpeed = 0.1
+
+// PlaceTarget returns a target vessel whose initial position and velocity give
+// the requested CPA and TCPA relative to own ship, assuming both hold course and speed.
+// The target passes own ship's starboard side at CPA (astern for crossing targets
+// from starboard) unless PassToPort is set. It returns an error when the target
+// would not move relative to own ship or its speed contradicts the encounter
+// type, e.g. an overtaking target no faster than own ship.
+func PlaceTarget(own Vessel, e Encounter) (Vessel, error) {
+	course, speed := e.targetMotion(own)
+	switch {
+	case e.Type == Overtaking && speed <= own.SOG:
+		return Vessel{}, fmt.Errorf("overtaking target speed %.1f kn must exceed own ship's %.1f kn", speed, own.SOG)
+	case e.Type == Overtaken && speed >= own.SOG:
+		return Vessel{}, fmt.Errorf("overtaken target speed %.1f kn must be below own ship's %.1f kn", speed, own.SOG)
+	}
+
+	// Work in a local frame with x east and y north, in nautical miles and hours
+	vox, voy := velocity(own.COG, own.SOG)
+	vtx, vty := velocity(course, speed)
+	vrx, vry := vtx-vox, vty-voy
+	vr := math.Hypot(vrx, vry)
+	if vr < minRelativeSpeed {
+		return Vessel{}, fmt.Errorf("%s target at %.1f kn does not move relative to own ship", e.Type, speed)
+	}
+
+	// The CPA lies perpendicular to the relative motion line
+	nx, ny := vry/vr, -vrx/vr
+	sx, sy := velocity(own.Heading+90, 1)
+	if (nx*sx+ny*sy < 0) != e.PassToPort {
+		nx, ny = -nx, -ny
+	}
+	px, py := nx*e.CPA, ny*e.CPA
+
+	hours := e.TCPA.Hours()
+	x0, y0 := px-vrx*hours, py-vry*hours
+	bearing := NormalizeDegrees(math.Atan2(x0, y0) * 180 / math.Pi)
+	lat, lon := Destination(own.Latitude, own.Longitude, bearing, math.Hypot(x0, y0))
+
+	return Vessel{
+		Name:        fmt.Sprintf("%s TARGET", strings.ToUpper(string(e.Type))),
+		ShipType:    70,
+		Class:       ClassA,
+		ToBow:       80,
+		ToStern:     20,
+		ToPort:      8,
+		ToStarboard: 8,
+		Draught:     6,
+		Latitude:    lat,
+		Longitude:   lon,
+		Heading:     course,
+		COG:         course,
+		SOG:         speed,
+	}, nil
+}
+
+// targetMotion returns the target course and speed for the encounter type
+func (e Encounter) targetMotion(own Vessel) (float64, float64) {
+	speed := e.Speed
+	switch e.Type {
+	case HeadOn:
+		if speed == 0 {
+			speed = own.SOG
+		}
+		return NormalizeDegrees(own.COG + 180), speed
+	case CrossingPort:
+		if speed == 0 {
+			speed = own.SOG
+		}
+		return NormalizeDegrees(own.COG + 90), speed
+	case Overtaking:
+		if speed == 0 {
+			speed = own.SOG + 5
+		}
+		return own.COG, speed
+	case Overtaken:
+		if speed == 0 {
+			speed = own.SOG / 2
+		}
+		return own.COG, speed
+	default:
+		if speed == 0 {
+			speed = own.SOG
+		}
+		return NormalizeDegrees(own.COG - 90), speed
+	}
+}
+
+// CPA returns the closest point of approach in nautical miles between own ship
+// and a target, and the time until it is reached. TCPA is negative when the
+// vessels are already moving apart.
+func CPA(own, target Vessel) (float64, time.Duration) {
+	bearing, distance := BearingDistance(own.Latitude, own.Longitude, target.Latitude, target.Longitude)
+	px, py := velocity(bearing, distance)
+
+	vox, voy := velocity(own.COG, own.SOG)
+	vtx, vty := velocity(target.COG, target.SOG)
+	vrx, vry := vtx-vox, vty-voy
+
+	vr2 := vrx*vrx + vry*vry
+	if vr2 < 1e-12 {
+		return distance, 0
+	}
+
+	hours := -(px*vrx + py*vry) / vr2
+	cpa := math.Hypot(px+vrx*hours, py+vry*hours)
+	return cpa, time.Duration(hours * float64(time.Hour))
+}
+
+// velocity returns the east and north components of a course and speed
+func velocity(course, speed float64) (float64, float64) {
+	rad := course * math.Pi / 180
+	return speed * math.Sin(rad), speed * math.Cos(rad)
+}
@@ -0,0 +1,109 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPlaceTargetCPA(t *testing.T) {
+	own := DefaultOwnShip()
+
+	testCases := []Encounter{
+		{Type: HeadOn, CPA: 0.5, TCPA: 10 * time.Minute},
+		{Type: CrossingStarboard, CPA: 0.2, TCPA: 6 * time.Minute},
+		{Type: CrossingPort, CPA: 1, TCPA: 12 * time.Minute, Speed: 15},
+		{Type: Overtaking, CPA: 0.1, TCPA: 20 * time.Minute},
+		{Type: Overtaken, CPA: 0.3, TCPA: 15 * time.Minute, PassToPort: true},
+		{Type: HeadOn, CPA: 0, TCPA: 5 * time.Minute},
+	}
+
+	for _, e := range testCases {
+		t.Run(string(e.Type), func(t *testing.T) {
+			target, err := PlaceTarget(own, e)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			cpa, tcpa := CPA(own, target)
+			if math.Abs(cpa-e.CPA) > 0.01 {
+				t.Errorf("Expected CPA %.2f NM, got %.4f", e.CPA, cpa)
+			}
+			if math.Abs((tcpa - e.TCPA).Seconds()) > 5 {
+				t.Errorf("Expected TCPA %s, got %s", e.TCPA, tcpa)
+			}
+
+			// Advance both vessels to the CPA and check the separation
+			fleet := NewFleet(own, target)
+			fleet.Step(e.TCPA)
+			moved, _ := fleet.Target(target.MMSI)
+			_, distance := BearingDistance(fleet.OwnShip().Latitude, fleet.OwnShip().Longitude, moved.Latitude, moved.Longitude)
+			if math.Abs(distance-e.CPA) > 0.01 {
+				t.Errorf("Expected separation %.2f NM at TCPA, got %.4f", e.CPA, distance)
+			}
+
+			// At CPA the target should be on the requested side of own ship
+			if e.CPA > 0 && e.Type == HeadOn {
+				bearing, _ := BearingDistance(fleet.OwnShip().Latitude, fleet.OwnShip().Longitude, moved.Latitude, moved.Longitude)
+				relative := NormalizeDegrees(bearing - own.Heading)
+				if (relative > 180) != e.PassToPort {
+					t.Errorf("Target passed on the wrong side, relative bearing %.1f", relative)
+				}
+			}
+		})
+	}
+}
+
+func TestPlaceTargetInvalid(t *testing.T) {
+	own := DefaultOwnShip()
+	stopped := own
+	stopped.SOG = 0
+
+	testCases := []struct {
+		name string
+		own  Vessel
+		e    Encounter
+	}{
+		{"stopped own ship", stopped, Encounter{Type: CrossingStarboard, CPA: 0.5, TCPA: 6 * time.Minute}},
+		{"stopped head-on", stopped, Encounter{Type: HeadOn, CPA: 0.5, TCPA: 6 * time.Minute}},
+		{"overtaking at own speed", own, Encounter{Type: Overtaking, CPA: 0.1, TCPA: 6 * time.Minute, Speed: own.SOG}},
+		{"overtaken at own speed", own, Encounter{Type: Overtaken, CPA: 0.1, TCPA: 6 * time.Minute, Speed: own.SOG}},
+		{"slower overtaking", own, Encounter{Type: Overtaking, CPA: 0.1, TCPA: 6 * time.Minute, Speed: own.SOG / 2}},
+		{"faster overtaken", own, Encounter{Type: Overtaken, CPA: 0.1, TCPA: 6 * time.Minute, Speed: own.SOG + 5}},
+	}
+
+	for _, tc := range testCases {
+		if _, err := PlaceTarget(tc.own, tc.e); err == nil {
+			t.Errorf("%s: expected an error", tc.name)
+		}
+	}
+}
+
+func TestCPADiverging(t *testing.T) {
+	own := Vessel{Latitude: 0, Longitude: 0, COG: 0, SOG: 10}
+	target := Vessel{Latitude: -0.1, Longitude: 0, COG: 180, SOG: 10}
+
+	cpa, tcpa := CPA(own, target)
+	if tcpa >= 0 {
+		t.Errorf("Expected negative TCPA for diverging vessels, got %s", tcpa)
+	}
+	if cpa > 0.01 {
+		t.Errorf("Expected CPA of 0 for vessels on reciprocal courses, got %f", cpa)
+	}
+}
+
+func TestParseEncounter(t *testing.T) {
+	e, err := ParseEncounter("crossing:0.5:6m:12")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if e.Type != CrossingStarboard || e.CPA != 0.5 || e.TCPA != 6*time.Minute || e.Speed != 12 {
+		t.Errorf("Unexpected encounter: %+v", e)
+	}
+
+	for _, invalid := range []string{"crossing:0.5", "sideways:0.5:6m", "head-on:x:6m", "head-on:0.5:-1m", "head-on:1:1m:fast"} {
+		if _, err := ParseEncounter(invalid); err == nil {
+			t.Errorf("Expected error parsing %q", invalid)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+)
+
+// EngineConfig describes own ship's propulsion
+type EngineConfig struct {
+	Count       int     // Number of engines, 1 (single) or 2 (twin, port and starboard)
+	IdleRPM     float64 // Idle speed, RPM
+	MaxRPM      float64 // Rated speed, RPM
+	MaxSpeed    float64 // Speed through water at rated speed, knots, from which the engine speed is derived
+	MaxFuelRate float64 // Fuel consumption of each engine at rated speed, liters per hour
+	Hours       float64 // Engine hours at start-up
+}
+
+// DefaultEngineConfig returns a single diesel sized for a 12 m yacht
+func DefaultEngineConfig() EngineConfig {
+	return EngineConfig{
+		Count:       1,
+		IdleRPM:     750,
+		MaxRPM:      3000,
+		MaxSpeed:    7.5,
+		MaxFuelRate: 18,
+		Hours:       1250,
+	}
+}
+
+// EngineFault is a fault injected into an engine
+type EngineFault string
+
+// Engine faults
+const (
+	FaultNone           EngineFault = ""
+	FaultOverheat       EngineFault = "overheat"         // Coolant temperature climbs past the alarm limit
+	FaultLowOilPressure EngineFault = "low-oil-pressure" // Oil pressure falls below the alarm limit
+)
+
+// ParseEngineFault parses an engine fault name
+func ParseEngineFault(s string) (EngineFault, error) {
+	switch f := EngineFault(s); f {
+	case FaultNone, FaultOverheat, FaultLowOilPressure:
+		return f, nil
+	}
+	return FaultNone, fmt.Errorf("unknown engine fault %q: expected overheat or low-oil-pressure", s)
+}
+
+// EngineAlarm is a set of engine alarms, numbered as the bits of the PGN
+// 127489 discrete status fields: status 1 in the low 16 bits and status 2 above
+type EngineAlarm uint32
+
+// Engine alarms
+const (
+	AlarmCheckEngine     EngineAlarm = 1 << 0
+	AlarmOverTemperature EngineAlarm = 1 << 1
+	AlarmLowOilPressure  EngineAlarm = 1 << 2
+	AlarmWarningLevel1   EngineAlarm = 1 << 16
+	AlarmWarningLevel2   EngineAlarm = 1 << 17
+)
+
+// Gear is the position of a transmission
+type Gear uint8
+
+// Gear positions, numbered as in PGN 127493
+const (
+	GearForward Gear = 0
+	GearNeutral Gear = 1
+	GearReverse Gear = 2
+)
+
+// Engine alarm limits
+const (
+	overheatLimit       = 100.0 // Coolant temperature, °C
+	lowOilPressureLimit = 100.0 // Oil pressure, kPa
+)
+
+// Engine time constants, seconds
+const (
+	engineSpeedTime   = 2.0
+	engineThermalTime = 180.0
+	engineOilTime     = 5.0
+)
+
+// Engine is the state of one engine and its transmission
+type Engine struct {
+	Instance           uint8   // 0 for a single or port engine, 1 for starboard
+	RPM                float64 // Revolutions per minute
+	Throttle           float64 // Fraction from idle to rated speed, derived from the RPM
+	Load               float64 // Percent of rated power
+	Torque             float64 // Percent of rated torque
+	BoostPressure      float64 // kPa
+	OilPressure        float64 // kPa
+	OilTemperature     float64 // °C
+	CoolantTemperature float64 // °C
+	AlternatorVoltage  float64 // Volts
+	FuelRate           float64 // Liters per hour
+	Hours              float64 // Total running hours
+	Alarms             EngineAlarm
+
+	Gear                    Gear
+	TransmissionPressure    float64 // Transmission oil pressure, kPa
+	TransmissionTemperature float64 // Transmission oil temperature, °C
+}
+
+// EngineModel simulates own ship's engines. The engines do not propel the
+// vessel: their speed is derived from its speed through water by the
+// propeller law, so a change of speed, e.g. through the control API, changes
+// the RPM and not the other way round.
+type EngineModel struct {
+	config EngineConfig
+
+	mu      sync.Mutex
+	last    time.Time
+	engines []Engine
+	rpm     []float64 // Shaft speed without measurement noise
+	faults  []EngineFault
+}
+
+// NewEngineModel creates the engines of the given configuration, idling at
+// start-up with the coolant still warming up
+func NewEngineModel(cfg EngineConfig) *EngineModel {
+	m := &EngineModel{config: cfg}
+	for i := 0; i < cfg.Count; i++ {
+		m.engines = append(m.engines, Engine{
+			Instance:           uint8(i),
+			RPM:                cfg.IdleRPM,
+			OilPressure:        150,
+			OilTemperature:     50,
+			CoolantTemperature: 45,
+			Hours:              cfg.Hours + float64(i)*7.5, // Twins are rarely run for exactly the same hours
+			Gear:               GearNeutral,
+		})
+	}
+	m.rpm = make([]float64, cfg.Count)
+	for i := range m.rpm {
+		m.rpm[i] = cfg.IdleRPM
+	}
+	m.faults = make([]EngineFault, cfg.Count)
+	return m
+}
+
+// Config returns the engine configuration
+func (m *EngineModel) Config() EngineConfig {
+	return m.config
+}
+
+// InjectFault sets the fault of an engine instance, FaultNone clearing it
+func (m *EngineModel) InjectFault(instance int, fault EngineFault) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if instance < 0 || instance >= len(m.faults) {
+		return fmt.Errorf("no engine instance %d", instance)
+	}
+	m.faults[instance] = fault
+	return nil
+}
+
+// Sample advances the engines to time t and returns their state, turning at
+// the RPM derived from the vessel's speed through water
+func (m *EngineModel) Sample(v Vessel, t time.Time) []Engine {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cfg := m.config
+	var dt float64
+	if !m.last.IsZero() && t.After(m.last) {
+		dt = t.Sub(m.last).Seconds()
+	}
+	if m.last.IsZero() || t.After(m.last) {
+		m.last = t
+	}
+
+	// The propeller law: speed through water is proportional to shaft speed
+	gear := GearNeutral
+	target := cfg.IdleRPM
+	if v.STW > 0.1 && cfg.MaxSpeed > 0 {
+		gear = GearForward
+		target = math.Min(math.Max(cfg.MaxRPM*v.STW/cfg.MaxSpeed, cfg.IdleRPM), cfg.MaxRPM)
+	}
+
+	for i := range m.engines {
+		e := &m.engines[i]
+		fault := m.faults[i]
+
+		// Twin engines are never perfectly synchronized
+		m.rpm[i] = lag(m.rpm[i], target*(1+0.01*float64(e.Instance)), dt, engineSpeedTime)
+		e.RPM = m.rpm[i] + util.RandomNormal(0, 3)
+		e.Gear = gear
+		e.Hours += dt / 3600
+
+		speed := m.rpm[i] / cfg.MaxRPM
+		e.Throttle = math.Max(0, (m.rpm[i]-cfg.IdleRPM)/(cfg.MaxRPM-cfg.IdleRPM))
+		e.Load = 100 * math.Pow(speed, 3)
+		e.Torque = 100 * math.Pow(speed, 2)
+		e.BoostPressure = 1.5 * e.Load
+		e.FuelRate = cfg.MaxFuelRate * (0.08 + 0.92*e.Load/100)
+		e.AlternatorVoltage = 14.2
+
+		coolant, oil := 82.0, 150+300*speed
+		switch fault {
+		case FaultOverheat:
+			coolant = 108
+		case FaultLowOilPressure:
+			oil = 60
+		}
+		e.CoolantTemperature = lag(e.CoolantTemperature, coolant, dt, engineThermalTime)
+		e.OilTemperature = lag(e.OilTemperature, e.CoolantTemperature+10*speed, dt, engineThermalTime)
+		e.OilPressure = lag(e.OilPressure, oil, dt, engineOilTime)
+
+		e.TransmissionPressure = 400.0
+		if gear != GearNeutral {
+			e.TransmissionPressure = 1800
+		}
+		e.TransmissionTemperature = 0.6*e.OilTemperature + 20*speed
+
+		e.Alarms = 0
+		if e.CoolantTemperature > overheatLimit {
+			e.Alarms |= AlarmOverTemperature | AlarmCheckEngine | AlarmWarningLevel1
+		}
+		if e.OilPressure < lowOilPressureLimit {
+			e.Alarms |= AlarmLowOilPressure | AlarmCheckEngine | AlarmWarningLevel2
+		}
+	}
+
+	engines := make([]Engine, len(m.engines))
+	copy(engines, m.engines)
+	return engines
+}
+
+// lag moves value towards target as a first-order response with time
+// constant tau, exact for any step size dt
+func lag(value, target, dt, tau float64) float64 {
+	return target + (value-target)*math.Exp(-dt/tau)
+}
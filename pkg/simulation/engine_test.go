@@ -0,0 +1,79 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// runEngines samples the engines every second for the given duration
+func runEngines(m *EngineModel, v Vessel, start time.Time, duration time.Duration) []Engine {
+	var engines []Engine
+	for t := time.Duration(0); t <= duration; t += time.Second {
+		engines = m.Sample(v, start.Add(t))
+	}
+	return engines
+}
+
+func TestEngineModelSpeed(t *testing.T) {
+	cfg := DefaultEngineConfig()
+	cfg.Count = 2
+	model := NewEngineModel(cfg)
+	start := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+
+	engines := runEngines(model, Vessel{STW: 6}, start, time.Minute)
+	if len(engines) != 2 || engines[0].Instance != 0 || engines[1].Instance != 1 {
+		t.Fatalf("Expected port and starboard engines, got %+v", engines)
+	}
+
+	// 6 knots is 80% of the speed at rated RPM
+	for _, e := range engines {
+		if math.Abs(e.RPM-2400) > 50 || e.Gear != GearForward {
+			t.Errorf("Engine %d: expected about 2400 RPM in forward gear, got %.0f in gear %d", e.Instance, e.RPM, e.Gear)
+		}
+		if math.Abs(e.Load-51.2) > 3 {
+			t.Errorf("Engine %d: expected a load of about 51%%, got %.1f", e.Instance, e.Load)
+		}
+	}
+	if engines[1].Hours-engines[0].Hours != 7.5 {
+		t.Errorf("Expected the starboard engine to have 7.5 more hours, got %.3f and %.3f", engines[0].Hours, engines[1].Hours)
+	}
+	if hours := engines[0].Hours - cfg.Hours; math.Abs(hours-1.0/60) > 1e-9 {
+		t.Errorf("Expected a minute of running time, got %.4f hours", hours)
+	}
+
+	// Stopped in the water, the engines idle in neutral
+	engines = runEngines(model, Vessel{}, start.Add(2*time.Minute), time.Minute)
+	if e := engines[0]; math.Abs(e.RPM-cfg.IdleRPM) > 20 || e.Gear != GearNeutral {
+		t.Errorf("Expected idle in neutral, got %.0f RPM in gear %d", e.RPM, e.Gear)
+	}
+}
+
+func TestEngineModelFaults(t *testing.T) {
+	model := NewEngineModel(DefaultEngineConfig())
+	start := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+
+	engines := runEngines(model, Vessel{STW: 6}, start, 20*time.Minute)
+	if e := engines[0]; e.Alarms != 0 || math.Abs(e.CoolantTemperature-82) > 1 {
+		t.Fatalf("Expected a healthy engine at 82°C, got %.1f°C with alarms %b", e.CoolantTemperature, e.Alarms)
+	}
+
+	if err := model.InjectFault(1, FaultOverheat); err == nil {
+		t.Error("Expected an error for a missing engine instance")
+	}
+	if err := model.InjectFault(0, FaultOverheat); err != nil {
+		t.Fatal(err)
+	}
+	engines = runEngines(model, Vessel{STW: 6}, start.Add(21*time.Minute), 10*time.Minute)
+	if want := AlarmOverTemperature | AlarmCheckEngine | AlarmWarningLevel1; engines[0].Alarms != want {
+		t.Errorf("Expected over temperature alarms %b, got %b at %.1f°C", want, engines[0].Alarms, engines[0].CoolantTemperature)
+	}
+
+	fault, err := ParseEngineFault("low-oil-pressure")
+	if err != nil || fault != FaultLowOilPressure {
+		t.Errorf("Expected the low oil pressure fault, got %q, %v", fault, err)
+	}
+	if _, err := ParseEngineFault("fire"); err == nil {
+		t.Error("Expected an error for an unknown fault")
+	}
+}
@@ -0,0 +1,326 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+)
+
+// Fleet holds own ship and the simulated target vessels around it
+type Fleet struct {
+	mu      sync.RWMutex
+	own     Vessel
+	targets []Vessel
+	current CurrentModel
+	helm    Helm
+	clock   time.Time
+	pause   pauseClock
+}
+
+// pauseClock keeps the time of the wall-clock models, which stops while the
+// fleet is paused
+type pauseClock struct {
+	paused bool
+	since  time.Time     // Wall-clock time the fleet was paused at
+	total  time.Duration // Time spent paused before since
+}
+
+// Helm steers own ship, returning its rate of turn in degrees per minute
+// after dt at the helm
+type Helm interface {
+	Steer(own Vessel, dt time.Duration) float64
+}
+
+// NewFleet creates a fleet from own ship and an optional set of targets
+func NewFleet(own Vessel, targets ...Vessel) *Fleet {
+	return &Fleet{
+		own:     own,
+		targets: append([]Vessel(nil), targets...),
+		clock:   time.Now(),
+	}
+}
+
+// DefaultOwnShip returns the own ship used when no scenario is configured
+func DefaultOwnShip() Vessel {
+	return Vessel{
+		MMSI:        244000001,
+		Name:        "NMEA SIMULATOR",
+		CallSign:    "PD0001",
+		ShipType:    37, // Pleasure craft
+		Class:       ClassA,
+		ToBow:       8,
+		ToStern:     4,
+		ToPort:      2,
+		ToStarboard: 2,
+		Draught:     1.8,
+		Destination: "VIENNA",
+		Latitude:    48.196077,
+		Longitude:   16.358193,
+		Heading:     45,
+		COG:         45,
+		SOG:         6,
+		STW:         6,
+	}
+}
+
+// RandomTargets returns n target vessels placed within radius nautical miles of own ship.
+// Most targets are Class A vessels under way; the second reports on a schedule
+// assigned by a base station, the third of every four lies at anchor, the fourth
+// of every four is Class B and the last is an aid to navigation.
+func RandomTargets(own Vessel, n int, radius float64) []Vessel {
+	targets := make([]Vessel, 0, n)
+	for i := 0; i < n; i++ {
+		lat, lon := Destination(own.Latitude, own.Longitude,
+			util.RandomFloat(0, 360), util.RandomFloat(radius/4, radius))
+		course := util.RandomFloat(0, 360)
+
+		v := Vessel{
+			MMSI:        uint32(244100000 + i + 1),
+			IMO:         uint32(9100000 + i + 1),
+			Name:        fmt.Sprintf("TARGET %d", i+1),
+			CallSign:    fmt.Sprintf("PT%04d", i+1),
+			ShipType:    70, // Cargo
+			Class:       ClassA,
+			ToBow:       util.RandomFloat(40, 150),
+			ToStern:     util.RandomFloat(10, 40),
+			ToPort:      util.RandomFloat(5, 15),
+			ToStarboard: util.RandomFloat(5, 15),
+			Draught:     util.RandomFloat(3, 12),
+			Destination: "ROTTERDAM",
+			Latitude:    lat,
+			Longitude:   lon,
+			Heading:     course,
+			COG:         course,
+			SOG:         util.RandomFloat(2, 18),
+		}
+
+		switch {
+		case n > 1 && i == n-1:
+			v.MMSI = uint32(992440000 + i + 1)
+			v.Name = fmt.Sprintf("BUOY %d", i+1)
+			v.Class = AidToNavigation
+			v.AidType = 1 // Reference point
+			v.IMO, v.CallSign, v.ShipType, v.Destination = 0, "", 0, ""
+			v.ToBow, v.ToStern, v.ToPort, v.ToStarboard, v.Draught = 1, 1, 1, 1, 0
+			v.Heading, v.COG, v.SOG = 0, 0, 0
+		case i%4 == 2:
+			v.NavStatus = 1 // At anchor
+			v.SOG = 0
+		case i == 1:
+			v.Assigned = true
+		case i%4 == 3:
+			v.Class = ClassB
+			v.ShipType = 36 // Sailing
+			v.IMO = 0
+			v.ToBow, v.ToStern = util.RandomFloat(6, 12), util.RandomFloat(2, 4)
+			v.ToPort, v.ToStarboard = 2, 2
+			v.Draught = util.RandomFloat(1, 2.5)
+			v.SOG = util.RandomFloat(2, 8)
+		}
+
+		targets = append(targets, v)
+	}
+	return targets
+}
+
+// OwnShip returns a copy of own ship state
+func (f *Fleet) OwnShip() Vessel {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.own
+}
+
+// SetOwnShip replaces own ship state. When a current is set, own ship's
+// course and speed over ground are derived from its heading and speed through water.
+func (f *Fleet) SetOwnShip(v Vessel) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.own = v
+	f.applyCurrent()
+}
+
+// UpdateOwnShip changes own ship state in place and returns the result. As
+// with SetOwnShip, a current overrides the course and speed over ground.
+func (f *Fleet) UpdateOwnShip(update func(v *Vessel)) Vessel {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	update(&f.own)
+	f.applyCurrent()
+	return f.own
+}
+
+// SetCurrent sets the current acting on own ship; nil removes it
+func (f *Fleet) SetCurrent(m CurrentModel) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.current = m
+	f.applyCurrent()
+}
+
+// SetHelm sets the helm steering own ship; nil leaves own ship's rate of turn as set
+func (f *Fleet) SetHelm(h Helm) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.helm = h
+}
+
+// Current returns the current at own ship's position, zero when none is set
+func (f *Fleet) Current() Current {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.current == nil {
+		return Current{}
+	}
+	return f.current.Current(f.own.Latitude, f.own.Longitude, f.clock)
+}
+
+// applyCurrent resolves own ship's motion over ground under the current and
+// leeway
+func (f *Fleet) applyCurrent() {
+	switch {
+	case f.current != nil:
+		f.own.ApplyCurrent(f.current.Current(f.own.Latitude, f.own.Longitude, f.clock))
+	case f.own.Leeway != 0:
+		f.own.ApplyCurrent(Current{})
+	}
+}
+
+// Targets returns a copy of the current target vessels
+func (f *Fleet) Targets() []Vessel {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return append([]Vessel(nil), f.targets...)
+}
+
+// AddTarget adds a target vessel to the fleet, assigning an unused MMSI when
+// none is set, and returns the target's MMSI
+func (f *Fleet) AddTarget(v Vessel) uint32 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if v.MMSI == 0 {
+		v.MMSI = 244200000
+		for f.hasMMSI(v.MMSI) {
+			v.MMSI++
+		}
+	}
+	f.targets = append(f.targets, v)
+	return v.MMSI
+}
+
+// Target returns the target with the given MMSI
+func (f *Fleet) Target(mmsi uint32) (Vessel, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, v := range f.targets {
+		if v.MMSI == mmsi {
+			return v, true
+		}
+	}
+	return Vessel{}, false
+}
+
+// RemoveTarget removes the target with the given MMSI, reporting whether it existed
+func (f *Fleet) RemoveTarget(mmsi uint32) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, v := range f.targets {
+		if v.MMSI == mmsi {
+			f.targets = append(f.targets[:i], f.targets[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Fleet) hasMMSI(mmsi uint32) bool {
+	if f.own.MMSI == mmsi {
+		return true
+	}
+	for _, v := range f.targets {
+		if v.MMSI == mmsi {
+			return true
+		}
+	}
+	return false
+}
+
+// Step advances own ship and every target by the given duration. Targets
+// move at their course and speed over ground; own ship turns at the rate of
+// turn given by the helm and is set by the current.
+func (f *Fleet) Step(dt time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.clock = f.clock.Add(dt)
+	if f.helm != nil {
+		f.own.ROT = f.helm.Steer(f.own, dt)
+	}
+	f.own.Step(dt)
+	f.applyCurrent()
+	for i := range f.targets {
+		f.targets[i].Step(dt)
+	}
+}
+
+// SetPaused pauses or resumes Run, freezing own ship, the targets and the
+// time of the models while paused
+func (f *Fleet) SetPaused(paused bool) {
+	f.setPaused(paused, time.Now())
+}
+
+func (f *Fleet) setPaused(paused bool, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case paused && !f.pause.paused:
+		f.pause.since = now
+	case !paused && f.pause.paused:
+		f.pause.total += now.Sub(f.pause.since)
+	}
+	f.pause.paused = paused
+}
+
+// Paused reports whether the fleet is paused
+func (f *Fleet) Paused() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.pause.paused
+}
+
+// ModelTime returns the time at which to sample the engine, tank, electrical,
+// weather and other wall-clock models at wall-clock time t: t less the time
+// spent paused, held at the time of the pause while paused
+func (f *Fleet) ModelTime(t time.Time) time.Time {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.pause.paused {
+		t = f.pause.since
+	}
+	return t.Add(-f.pause.total)
+}
+
+// Run steps the fleet every interval until the context is cancelled, except
+// while paused
+func (f *Fleet) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if !f.Paused() {
+				f.Step(now.Sub(last))
+			}
+			last = now
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package simulation
+
+import (
+	"math"
+)
+
+// EarthRadiusNM is the mean radius of the earth in nautical miles
+const EarthRadiusNM = 3440.065
+
+// NormalizeDegrees wraps an angle into the range [0, 360)
+func NormalizeDegrees(deg float64) float64 {
+	deg = math.Mod(deg, 360)
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
+
+// SignedDegrees wraps an angle into the range [-180, 180), negative to port
+func SignedDegrees(deg float64) float64 {
+	return NormalizeDegrees(deg+180) - 180
+}
+
+// Destination returns the position reached by travelling distance nautical miles
+// from lat/lon along the given true bearing on a great circle
+func Destination(lat, lon, bearing, distance float64) (float64, float64) {
+	if distance == 0 {
+		return lat, lon
+	}
+
+	phi1 := lat * math.Pi / 180
+	lambda1 := lon * math.Pi / 180
+	theta := bearing * math.Pi / 180
+	delta := distance / EarthRadiusNM
+
+	phi2 := math.Asin(math.Sin(phi1)*math.Cos(delta) + math.Cos(phi1)*math.Sin(delta)*math.Cos(theta))
+	lambda2 := lambda1 + math.Atan2(
+		math.Sin(theta)*math.Sin(delta)*math.Cos(phi1),
+		math.Cos(delta)-math.Sin(phi1)*math.Sin(phi2),
+	)
+
+	lon2 := math.Mod(lambda2*180/math.Pi+540, 360) - 180
+	return phi2 * 180 / math.Pi, lon2
+}
+
+// BearingDistance returns the initial true bearing in degrees and the distance in
+// nautical miles from the first position to the second
+func BearingDistance(lat1, lon1, lat2, lon2 float64) (float64, float64) {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dLambda := (lon2 - lon1) * math.Pi / 180
+
+	y := math.Sin(dLambda) * math.Cos(phi2)
+	x := math.Cos(phi1)*math.Sin(phi2) - math.Sin(phi1)*math.Cos(phi2)*math.Cos(dLambda)
+	bearing := NormalizeDegrees(math.Atan2(y, x) * 180 / math.Pi)
+
+	dPhi := phi2 - phi1
+	a := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	distance := 2 * EarthRadiusNM * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+	return bearing, distance
+}
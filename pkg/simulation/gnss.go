@@ -0,0 +1,292 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GNSSSystem identifies a satellite navigation system
+type GNSSSystem uint8
+
+// Supported satellite systems, numbered as the NMEA 4.10 GNSS system ID
+const (
+	GPS     GNSSSystem = 1
+	GLONASS GNSSSystem = 2
+	Galileo GNSSSystem = 3
+	BeiDou  GNSSSystem = 4
+)
+
+// Talker returns the NMEA talker ID used for sentences specific to the system
+func (s GNSSSystem) Talker() string {
+	switch s {
+	case GLONASS:
+		return "GL"
+	case Galileo:
+		return "GA"
+	case BeiDou:
+		return "GB"
+	default:
+		return "GP"
+	}
+}
+
+// String returns the name of the system
+func (s GNSSSystem) String() string {
+	switch s {
+	case GLONASS:
+		return "GLONASS"
+	case Galileo:
+		return "Galileo"
+	case BeiDou:
+		return "BeiDou"
+	default:
+		return "GPS"
+	}
+}
+
+// ParseGNSSSystem parses a system name such as "gps" or "galileo"
+func ParseGNSSSystem(name string) (GNSSSystem, error) {
+	for _, s := range []GNSSSystem{GPS, GLONASS, Galileo, BeiDou} {
+		if strings.EqualFold(name, s.String()) {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown GNSS system %q", name)
+}
+
+// Satellite is the observed state of a satellite in view
+type Satellite struct {
+	System    GNSSSystem
+	PRN       int     // NMEA satellite ID
+	Elevation float64 // Degrees
+	Azimuth   float64 // Degrees true
+	SNR       float64 // dB-Hz
+	Used      bool    // Used in the navigation solution
+}
+
+// orbit describes a satellite on a circular orbit
+type orbit struct {
+	system      GNSSSystem
+	prn         int
+	radius      float64 // Kilometers
+	period      time.Duration
+	inclination float64 // Radians
+	raan        float64 // Right ascension of the ascending node, radians
+	phase       float64 // Argument of latitude at the epoch, radians
+}
+
+// Walker constellation parameters for each system
+var constellationParams = map[GNSSSystem]struct {
+	planes, perPlane int
+	radius           float64
+	period           time.Duration
+	inclination      float64
+	firstPRN         int
+}{
+	GPS:     {6, 4, 26560, 11*time.Hour + 58*time.Minute, 55, 1},
+	GLONASS: {3, 8, 25510, 11*time.Hour + 16*time.Minute, 64.8, 65},
+	Galileo: {3, 8, 29600, 14*time.Hour + 5*time.Minute, 56, 1},
+	BeiDou:  {3, 8, 27900, 12*time.Hour + 53*time.Minute, 55, 1},
+}
+
+const (
+	earthRadiusKm   = 6371.0
+	earthRotation   = 7.2921159e-5 // Radians per second
+	maxSatsInSolver = 12
+)
+
+// constellationEpoch is the reference time for the simulated orbits
+var constellationEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Constellation simulates the satellites of one or more GNSS systems on idealised circular orbits
+type Constellation struct {
+	orbits []orbit
+}
+
+// NewConstellation creates a constellation containing the given systems
+func NewConstellation(systems ...GNSSSystem) *Constellation {
+	c := &Constellation{}
+	for _, system := range systems {
+		p, ok := constellationParams[system]
+		if !ok {
+			continue
+		}
+		prn := p.firstPRN
+		for plane := 0; plane < p.planes; plane++ {
+			for slot := 0; slot < p.perPlane; slot++ {
+				c.orbits = append(c.orbits, orbit{
+					system:      system,
+					prn:         prn,
+					radius:      p.radius,
+					period:      p.period,
+					inclination: p.inclination * math.Pi / 180,
+					raan:        2 * math.Pi * float64(plane) / float64(p.planes),
+					phase: 2*math.Pi*float64(slot)/float64(p.perPlane) +
+						math.Pi*float64(plane)/float64(p.planes*p.perPlane),
+				})
+				prn++
+			}
+		}
+	}
+	return c
+}
+
+// Systems returns the systems present in the constellation
+func (c *Constellation) Systems() []GNSSSystem {
+	var systems []GNSSSystem
+	seen := make(map[GNSSSystem]bool)
+	for _, o := range c.orbits {
+		if !seen[o.system] {
+			seen[o.system] = true
+			systems = append(systems, o.system)
+		}
+	}
+	return systems
+}
+
+// Visible returns the satellites above the elevation mask in degrees as seen
+// from the given position at time t, ordered by system and PRN
+func (c *Constellation) Visible(lat, lon float64, t time.Time, mask float64) []Satellite {
+	phi := lat * math.Pi / 180
+	lambda := lon * math.Pi / 180
+
+	// Observer position in earth-centred earth-fixed coordinates
+	ox := earthRadiusKm * math.Cos(phi) * math.Cos(lambda)
+	oy := earthRadiusKm * math.Cos(phi) * math.Sin(lambda)
+	oz := earthRadiusKm * math.Sin(phi)
+
+	elapsed := t.Sub(constellationEpoch).Seconds()
+	theta := earthRotation * elapsed
+
+	var sats []Satellite
+	for _, o := range c.orbits {
+		u := o.phase + 2*math.Pi*elapsed/o.period.Seconds()
+
+		// Inertial position of the satellite
+		x := o.radius * (math.Cos(u)*math.Cos(o.raan) - math.Sin(u)*math.Cos(o.inclination)*math.Sin(o.raan))
+		y := o.radius * (math.Cos(u)*math.Sin(o.raan) + math.Sin(u)*math.Cos(o.inclination)*math.Cos(o.raan))
+		z := o.radius * math.Sin(u) * math.Sin(o.inclination)
+
+		// Rotate into the earth-fixed frame
+		xe := x*math.Cos(theta) + y*math.Sin(theta)
+		ye := -x*math.Sin(theta) + y*math.Cos(theta)
+
+		// Line of sight in local east, north, up coordinates
+		dx, dy, dz := xe-ox, ye-oy, z-oz
+		east := -math.Sin(lambda)*dx + math.Cos(lambda)*dy
+		north := -math.Sin(phi)*math.Cos(lambda)*dx - math.Sin(phi)*math.Sin(lambda)*dy + math.Cos(phi)*dz
+		up := math.Cos(phi)*math.Cos(lambda)*dx + math.Cos(phi)*math.Sin(lambda)*dy + math.Sin(phi)*dz
+
+		elevation := math.Atan2(up, math.Hypot(east, north)) * 180 / math.Pi
+		if elevation < mask {
+			continue
+		}
+
+		sats = append(sats, Satellite{
+			System:    o.system,
+			PRN:       o.prn,
+			Elevation: elevation,
+			Azimuth:   NormalizeDegrees(math.Atan2(east, north) * 180 / math.Pi),
+		})
+	}
+
+	sort.Slice(sats, func(i, j int) bool {
+		if sats[i].System != sats[j].System {
+			return sats[i].System < sats[j].System
+		}
+		return sats[i].PRN < sats[j].PRN
+	})
+	return sats
+}
+
+// DOP holds dilution of precision values together with the position error
+// covariance factors used to derive error statistics
+type DOP struct {
+	PDOP, HDOP, VDOP, TDOP float64
+	EE, NN, UU, EN         float64 // Cofactor matrix terms in east/north/up
+}
+
+// ComputeDOP computes dilution of precision from the geometry of the given satellites.
+// It returns false when fewer than four satellites are available or the geometry is degenerate.
+func ComputeDOP(sats []Satellite) (DOP, bool) {
+	if len(sats) < 4 {
+		return DOP{}, false
+	}
+
+	// Normal matrix G^T G of the linearised pseudorange equations
+	var n [4][4]float64
+	for _, s := range sats {
+		el := s.Elevation * math.Pi / 180
+		az := s.Azimuth * math.Pi / 180
+		row := [4]float64{
+			-math.Cos(el) * math.Sin(az),
+			-math.Cos(el) * math.Cos(az),
+			-math.Sin(el),
+			1,
+		}
+		for i := 0; i < 4; i++ {
+			for j := 0; j < 4; j++ {
+				n[i][j] += row[i] * row[j]
+			}
+		}
+	}
+
+	q, ok := invert4(n)
+	if !ok {
+		return DOP{}, false
+	}
+
+	return DOP{
+		PDOP: math.Sqrt(q[0][0] + q[1][1] + q[2][2]),
+		HDOP: math.Sqrt(q[0][0] + q[1][1]),
+		VDOP: math.Sqrt(q[2][2]),
+		TDOP: math.Sqrt(q[3][3]),
+		EE:   q[0][0],
+		NN:   q[1][1],
+		UU:   q[2][2],
+		EN:   q[0][1],
+	}, true
+}
+
+// invert4 inverts a 4x4 matrix using Gauss-Jordan elimination
+func invert4(m [4][4]float64) ([4][4]float64, bool) {
+	var inv [4][4]float64
+	for i := range inv {
+		inv[i][i] = 1
+	}
+
+	for col := 0; col < 4; col++ {
+		pivot := col
+		for row := col + 1; row < 4; row++ {
+			if math.Abs(m[row][col]) > math.Abs(m[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(m[pivot][col]) < 1e-12 {
+			return inv, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		p := m[col][col]
+		for j := 0; j < 4; j++ {
+			m[col][j] /= p
+			inv[col][j] /= p
+		}
+		for row := 0; row < 4; row++ {
+			if row == col {
+				continue
+			}
+			f := m[row][col]
+			for j := 0; j < 4; j++ {
+				m[row][j] -= f * m[col][j]
+				inv[row][j] -= f * inv[col][j]
+			}
+		}
+	}
+
+	return inv, true
+}
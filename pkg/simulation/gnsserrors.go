@@ -0,0 +1,106 @@
+package simulation
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+)
+
+// GNSSErrorModel describes the position error injected into a receiver's
+// solution. The zero value reports the true position.
+type GNSSErrorModel struct {
+	HorizontalSigma   float64 `json:"horizontal_sigma"`   // White noise per horizontal axis, meters (1 sigma)
+	VerticalSigma     float64 `json:"vertical_sigma"`     // White noise in altitude, meters (1 sigma)
+	DriftSigma        float64 `json:"drift_sigma"`        // Steady-state Gauss-Markov drift per axis, meters (1 sigma)
+	DriftTime         float64 `json:"drift_time"`         // Correlation time of the drift, seconds
+	MultipathRate     float64 `json:"multipath_rate"`     // Mean number of multipath jumps per hour
+	MultipathJump     float64 `json:"multipath_jump"`     // Typical horizontal offset during a jump, meters
+	MultipathDuration float64 `json:"multipath_duration"` // Duration of a jump, seconds
+}
+
+// DefaultGNSSErrorModel returns the error model of a typical marine receiver
+func DefaultGNSSErrorModel() GNSSErrorModel {
+	return GNSSErrorModel{
+		HorizontalSigma:   1.5,
+		VerticalSigma:     2.5,
+		DriftSigma:        2,
+		DriftTime:         300,
+		MultipathRate:     2,
+		MultipathJump:     15,
+		MultipathDuration: 20,
+	}
+}
+
+// gnssErrors holds the time-correlated state of an error model
+type gnssErrors struct {
+	model GNSSErrorModel
+
+	mu             sync.Mutex
+	last           time.Time
+	drift          [3]float64 // North, east and up, meters
+	multipathUntil time.Time
+	multipath      float64 // Meters
+	multipathDir   float64 // Degrees true
+}
+
+// positionError is a sampled position error with the statistics a receiver would report for it
+type positionError struct {
+	north, east, up  float64 // Meters
+	sigmaH, sigmaV   float64 // Expected error per axis without multipath, meters
+	multipath        float64 // Magnitude of an active multipath offset, meters
+	multipathBearing float64 // Degrees true
+}
+
+// sample advances the drift and multipath state to time t and draws an error
+func (g *gnssErrors) sample(t time.Time) positionError {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	m := g.model
+	if g.last.IsZero() {
+		// Start from the stationary distribution of the drift
+		for i := range g.drift {
+			g.drift[i] = util.RandomNormal(0, m.DriftSigma)
+		}
+	} else if dt := t.Sub(g.last).Seconds(); dt > 0 {
+		// First-order Gauss-Markov process, exact for any step size
+		if m.DriftTime > 0 {
+			phi := math.Exp(-dt / m.DriftTime)
+			for i := range g.drift {
+				g.drift[i] = phi*g.drift[i] + util.RandomNormal(0, m.DriftSigma*math.Sqrt(1-phi*phi))
+			}
+		}
+
+		if !t.Before(g.multipathUntil) {
+			g.multipath = 0
+			if m.MultipathRate > 0 && rand.Float64() < 1-math.Exp(-m.MultipathRate*dt/3600) {
+				g.multipath = m.MultipathJump * (0.5 + rand.Float64())
+				g.multipathDir = rand.Float64() * 360
+				g.multipathUntil = t.Add(time.Duration(m.MultipathDuration * float64(time.Second)))
+			}
+		}
+	}
+	if t.After(g.last) {
+		g.last = t
+	}
+
+	e := positionError{
+		north:  g.drift[0] + util.RandomNormal(0, m.HorizontalSigma),
+		east:   g.drift[1] + util.RandomNormal(0, m.HorizontalSigma),
+		up:     g.drift[2] + util.RandomNormal(0, m.VerticalSigma),
+		sigmaH: math.Hypot(m.HorizontalSigma, m.DriftSigma),
+		sigmaV: math.Hypot(m.VerticalSigma, m.DriftSigma),
+	}
+	if g.multipath > 0 {
+		dir := g.multipathDir * math.Pi / 180
+		e.north += g.multipath * math.Cos(dir)
+		e.east += g.multipath * math.Sin(dir)
+		e.multipath = g.multipath
+		e.multipathBearing = g.multipathDir
+	}
+
+	return e
+}
@@ -0,0 +1,85 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGNSSErrorsWhiteNoise(t *testing.T) {
+	g := &gnssErrors{model: GNSSErrorModel{HorizontalSigma: 2, VerticalSigma: 4}}
+	start := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+
+	var sumN, sumU float64
+	const n = 5000
+	for i := 0; i < n; i++ {
+		e := g.sample(start.Add(time.Duration(i) * time.Second))
+		sumN += e.north * e.north
+		sumU += e.up * e.up
+	}
+
+	if sigma := math.Sqrt(sumN / n); math.Abs(sigma-2) > 0.15 {
+		t.Errorf("Expected horizontal sigma 2, got %.2f", sigma)
+	}
+	if sigma := math.Sqrt(sumU / n); math.Abs(sigma-4) > 0.3 {
+		t.Errorf("Expected vertical sigma 4, got %.2f", sigma)
+	}
+}
+
+func TestGNSSErrorsDriftIsCorrelated(t *testing.T) {
+	g := &gnssErrors{model: GNSSErrorModel{DriftSigma: 5, DriftTime: 300}}
+	start := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+
+	prev := g.sample(start)
+	for i := 1; i < 100; i++ {
+		e := g.sample(start.Add(time.Duration(i) * time.Second))
+
+		// Over one second the drift moves by about 5*sqrt(2/300) = 0.4 m
+		if math.Abs(e.north-prev.north) > 2.5 || math.Abs(e.east-prev.east) > 2.5 {
+			t.Fatalf("Drift jumped from %.2f,%.2f to %.2f,%.2f in one second", prev.north, prev.east, e.north, e.east)
+		}
+		prev = e
+	}
+}
+
+func TestGNSSErrorsMultipath(t *testing.T) {
+	g := &gnssErrors{model: GNSSErrorModel{MultipathRate: 1e9, MultipathJump: 20, MultipathDuration: 10}}
+	start := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+
+	g.sample(start)
+	e := g.sample(start.Add(time.Second))
+	if e.multipath < 10 || e.multipath > 30 {
+		t.Fatalf("Expected a multipath jump of 10-30 m, got %.1f", e.multipath)
+	}
+	if offset := math.Hypot(e.north, e.east); math.Abs(offset-e.multipath) > 1e-9 {
+		t.Errorf("Expected offset %.1f m, got %.1f m", e.multipath, offset)
+	}
+}
+
+func TestGNSSReceiverReportsInjectedError(t *testing.T) {
+	cfg := DefaultGNSSConfig()
+	cfg.Errors = GNSSErrorModel{HorizontalSigma: 3, VerticalSigma: 5, MultipathRate: 1e9, MultipathJump: 20, MultipathDuration: 60}
+	receiver := NewGNSSReceiver(cfg)
+
+	start := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+	receiver.Fix(DefaultOwnShip(), start)
+	fix := receiver.Fix(DefaultOwnShip(), start.Add(time.Second))
+
+	if fix.SigmaMinor != 3 || fix.SigmaMajor <= fix.SigmaMinor {
+		t.Errorf("Expected an ellipse stretched by multipath, got major %.1f minor %.1f", fix.SigmaMajor, fix.SigmaMinor)
+	}
+	if fix.RMS <= cfg.UERE {
+		t.Errorf("Expected residuals above UERE during multipath, got %.1f", fix.RMS)
+	}
+	if hdop := math.Hypot(fix.SigmaLat, fix.SigmaLon) / cfg.UERE; math.Abs(fix.DOP.HDOP-hdop) > 1e-9 {
+		t.Errorf("Expected HDOP %.2f derived from the error, got %.2f", hdop, fix.DOP.HDOP)
+	}
+	if fix.SigmaAlt != 5 || math.Abs(fix.DOP.VDOP-5/cfg.UERE) > 1e-9 {
+		t.Errorf("Unexpected vertical statistics: sigma %.1f VDOP %.2f", fix.SigmaAlt, fix.DOP.VDOP)
+	}
+
+	own := DefaultOwnShip()
+	if _, dist := BearingDistance(own.Latitude, own.Longitude, fix.Latitude, fix.Longitude); dist*1852 < 1 {
+		t.Errorf("Expected the position to be offset by the injected error, got %.2f m", dist*1852)
+	}
+}
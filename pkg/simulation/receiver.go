@@ -0,0 +1,206 @@
+package simulation
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+)
+
+// GNSSConfig holds the parameters of a simulated GNSS receiver
+type GNSSConfig struct {
+	Systems         []GNSSSystem
+	ElevationMask   float64 // Degrees
+	MinSNR          float64 // dB-Hz required to use a satellite in the solution
+	UERE            float64 // User equivalent range error in meters (1 sigma)
+	AntennaAltitude float64 // Meters above mean sea level
+	GeoidSeparation float64 // Meters between the WGS84 ellipsoid and mean sea level
+	Errors          GNSSErrorModel
+}
+
+// DefaultGNSSConfig returns a multi-constellation receiver configuration
+func DefaultGNSSConfig() GNSSConfig {
+	return GNSSConfig{
+		Systems:         []GNSSSystem{GPS, GLONASS, Galileo, BeiDou},
+		ElevationMask:   5,
+		MinSNR:          25,
+		UERE:            3,
+		AntennaAltitude: 3,
+		GeoidSeparation: 45,
+		Errors:          DefaultGNSSErrorModel(),
+	}
+}
+
+// GNSSFix is a position solution together with the satellites and error statistics behind it
+type GNSSFix struct {
+	Time            time.Time
+	Valid           bool
+	Latitude        float64 // Degrees
+	Longitude       float64 // Degrees
+	Altitude        float64 // Meters above mean sea level
+	GeoidSeparation float64 // Meters
+	COG             float64 // Course over ground, degrees true
+	SOG             float64 // Speed over ground, knots
+	Satellites      []Satellite
+	DOP             DOP
+
+	// Error statistics in meters (1 sigma)
+	RMS         float64 // Pseudorange residuals
+	SigmaLat    float64
+	SigmaLon    float64
+	SigmaAlt    float64
+	SigmaMajor  float64 // Semi-major axis of the error ellipse
+	SigmaMinor  float64 // Semi-minor axis of the error ellipse
+	Orientation float64 // Orientation of the semi-major axis, degrees true
+}
+
+// Used returns the satellites used in the solution
+func (f GNSSFix) Used() []Satellite {
+	var used []Satellite
+	for _, s := range f.Satellites {
+		if s.Used {
+			used = append(used, s)
+		}
+	}
+	return used
+}
+
+// Systems returns the systems with satellites in view, in ascending order
+func (f GNSSFix) Systems() []GNSSSystem {
+	var systems []GNSSSystem
+	for _, s := range f.Satellites {
+		if len(systems) == 0 || systems[len(systems)-1] != s.System {
+			systems = append(systems, s.System)
+		}
+	}
+	return systems
+}
+
+// GNSSReceiver simulates a GNSS receiver tracking a constellation
+type GNSSReceiver struct {
+	config        GNSSConfig
+	constellation *Constellation
+	errors        *gnssErrors
+}
+
+// NewGNSSReceiver creates a receiver with the given configuration
+func NewGNSSReceiver(cfg GNSSConfig) *GNSSReceiver {
+	return &GNSSReceiver{
+		config:        cfg,
+		constellation: NewConstellation(cfg.Systems...),
+		errors:        &gnssErrors{model: cfg.Errors},
+	}
+}
+
+// Config returns the receiver configuration
+func (r *GNSSReceiver) Config() GNSSConfig {
+	return r.config
+}
+
+// Fix computes the receiver's solution for the vessel's position at time t
+func (r *GNSSReceiver) Fix(v Vessel, t time.Time) GNSSFix {
+	sats := r.constellation.Visible(v.Latitude, v.Longitude, t, r.config.ElevationMask)
+
+	// Signal strength rises with elevation, with some scintillation on top
+	for i := range sats {
+		snr := 20 + 25*math.Sin(sats[i].Elevation*math.Pi/180) + util.RandomNormal(0, 1.5)
+		sats[i].SNR = math.Max(0, math.Min(99, snr))
+	}
+	selectSatellites(sats, r.config.MinSNR)
+
+	fix := GNSSFix{
+		Time:            t,
+		Latitude:        v.Latitude,
+		Longitude:       v.Longitude,
+		Altitude:        r.config.AntennaAltitude,
+		GeoidSeparation: r.config.GeoidSeparation,
+		COG:             v.COG,
+		SOG:             v.SOG,
+		Satellites:      sats,
+	}
+
+	dop, ok := ComputeDOP(fix.Used())
+	if !ok {
+		return fix
+	}
+	fix.Valid = true
+	fix.DOP = dop
+
+	if r.config.Errors == (GNSSErrorModel{}) {
+		r.geometryStatistics(&fix)
+	} else {
+		r.injectError(&fix, r.errors.sample(t))
+	}
+
+	return fix
+}
+
+// geometryStatistics derives the error statistics of an error-free fix from
+// the UERE and the satellite geometry
+func (r *GNSSReceiver) geometryStatistics(fix *GNSSFix) {
+	dop := fix.DOP
+	uere2 := r.config.UERE * r.config.UERE
+	see, snn, sen := uere2*dop.EE, uere2*dop.NN, uere2*dop.EN
+	fix.RMS = r.config.UERE
+	fix.SigmaLat = math.Sqrt(snn)
+	fix.SigmaLon = math.Sqrt(see)
+	fix.SigmaAlt = r.config.UERE * dop.VDOP
+
+	// Eigen decomposition of the horizontal covariance gives the error ellipse
+	mean := (see + snn) / 2
+	diff := math.Sqrt((see-snn)*(see-snn)/4 + sen*sen)
+	fix.SigmaMajor = math.Sqrt(mean + diff)
+	fix.SigmaMinor = math.Sqrt(math.Max(0, mean-diff))
+	fix.Orientation = NormalizeDegrees(0.5 * math.Atan2(2*sen, snn-see) * 180 / math.Pi)
+	if fix.Orientation >= 180 {
+		fix.Orientation -= 180
+	}
+}
+
+// injectError offsets the fix by a sampled error and reports statistics that
+// match it. A multipath jump stretches the error ellipse along its direction
+// and raises the residuals, and HDOP and VDOP are rescaled so that UERE times
+// DOP equals the reported error.
+func (r *GNSSReceiver) injectError(fix *GNSSFix, e positionError) {
+	bearing := math.Atan2(e.east, e.north) * 180 / math.Pi
+	fix.Latitude, fix.Longitude = Destination(fix.Latitude, fix.Longitude, bearing, math.Hypot(e.north, e.east)/metersPerNM)
+	fix.Altitude += e.up
+
+	dir := e.multipathBearing * math.Pi / 180
+	mn, me := e.multipath*math.Cos(dir), e.multipath*math.Sin(dir)
+	fix.SigmaLat = math.Hypot(e.sigmaH, mn)
+	fix.SigmaLon = math.Hypot(e.sigmaH, me)
+	fix.SigmaAlt = e.sigmaV
+	fix.SigmaMajor = math.Hypot(e.sigmaH, e.multipath)
+	fix.SigmaMinor = e.sigmaH
+	fix.Orientation = math.Mod(e.multipathBearing, 180)
+	fix.RMS = math.Hypot(r.config.UERE, e.multipath)
+
+	if r.config.UERE > 0 {
+		fix.DOP.HDOP = math.Hypot(fix.SigmaLat, fix.SigmaLon) / r.config.UERE
+		fix.DOP.VDOP = fix.SigmaAlt / r.config.UERE
+		fix.DOP.PDOP = math.Hypot(fix.DOP.HDOP, fix.DOP.VDOP)
+	}
+}
+
+// selectSatellites marks the highest satellites of each system with adequate
+// signal strength as used, up to the twelve that fit in a GSA sentence
+func selectSatellites(sats []Satellite, minSNR float64) {
+	bySystem := make(map[GNSSSystem][]int)
+	for i, s := range sats {
+		if s.SNR >= minSNR {
+			bySystem[s.System] = append(bySystem[s.System], i)
+		}
+	}
+
+	for _, idx := range bySystem {
+		sort.Slice(idx, func(a, b int) bool { return sats[idx[a]].Elevation > sats[idx[b]].Elevation })
+		for n, i := range idx {
+			if n >= maxSatsInSolver {
+				break
+			}
+			sats[i].Used = true
+		}
+	}
+}
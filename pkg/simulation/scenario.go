@@ -0,0 +1,29 @@
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Scenario describes the simulated installation, loaded from a JSON file
+type Scenario struct {
+	Sensors  []SensorConfig  `json:"sensors"`
+	SeaState *SeaStateConfig `json:"sea_state,omitempty"` // Waves driving own ship's attitude, nil keeps the command line sea state
+	Tanks    []TankConfig    `json:"tanks,omitempty"`     // Tanks replacing DefaultTanks
+}
+
+// LoadScenario reads a scenario from a JSON file
+func LoadScenario(path string) (Scenario, error) {
+	var sc Scenario
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return sc, fmt.Errorf("failed to read scenario: %w", err)
+	}
+	if err := json.Unmarshal(data, &sc); err != nil {
+		return sc, fmt.Errorf("failed to parse scenario %s: %w", path, err)
+	}
+
+	return sc, nil
+}
@@ -0,0 +1,335 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+)
+
+// SensorKind identifies the quantity a simulated sensor measures
+type SensorKind string
+
+// Supported sensor kinds
+const (
+	SensorGNSS    SensorKind = "gnss"    // Position fix, output as GGA/GLL/GNSS sentences and PGNs 129025/129539/129540
+	SensorHeading SensorKind = "heading" // Heading and rate of turn, output as HDT/THS or HDG/HDM, ROT and PGNs 127250/127251
+)
+
+// FailureMode describes how a sensor behaves while it has failed
+type FailureMode string
+
+// Supported failure modes
+const (
+	FailureSilent  FailureMode = "silent"  // Output stops
+	FailureInvalid FailureMode = "invalid" // Output continues, flagged invalid or with empty fields
+	FailureFrozen  FailureMode = "frozen"  // Output continues with the last value, still flagged valid
+)
+
+// metersPerNM is the number of meters in a nautical mile
+const metersPerNM = 1852.0
+
+// SensorConfig describes a single simulated sensor instance
+type SensorConfig struct {
+	Name            string          `json:"name"`
+	Kind            SensorKind      `json:"kind"`
+	Talker          string          `json:"talker"`           // NMEA 0183 talker ID, empty keeps the default
+	Source          uint8           `json:"source"`           // NMEA 2000 source address
+	Systems         []string        `json:"systems"`          // Satellite systems tracked by a GNSS sensor, empty tracks all
+	Bias            float64         `json:"bias"`             // Constant error, meters (gnss) or degrees (heading)
+	BiasBearing     float64         `json:"bias_bearing"`     // Direction of a GNSS position bias, degrees true
+	Noise           float64         `json:"noise"`            // Standard deviation of white noise, meters per horizontal axis (gnss) or degrees (heading)
+	GNSSErrors      *GNSSErrorModel `json:"gnss_errors"`      // Error model of a GNSS sensor, nil uses DefaultGNSSErrorModel
+	Magnetic        bool            `json:"magnetic"`         // Heading sensor reads magnetic heading, as a fluxgate compass does
+	Deviation       DeviationCard   `json:"deviation"`        // Deviation card of a magnetic heading sensor
+	Latency         float64         `json:"latency"`          // Delay between the truth state and its output, seconds
+	FailureRate     float64         `json:"failure_rate"`     // Mean number of failures per hour
+	FailureDuration float64         `json:"failure_duration"` // Duration of a failure, seconds
+	FailureMode     FailureMode     `json:"failure_mode"`     // Behaviour while failed, defaults to silent
+}
+
+// ReadingStatus describes whether a sensor reading is available and valid
+type ReadingStatus int
+
+// Reading status values
+const (
+	ReadingNone    ReadingStatus = iota // No output
+	ReadingValid                        // Output flagged valid
+	ReadingInvalid                      // Output flagged invalid
+)
+
+// Reading is the output of a sensor at a point in time. Vessel carries the
+// observed state with the sensor's errors applied; Fix is set for GNSS sensors.
+type Reading struct {
+	Time   time.Time
+	Status ReadingStatus
+	Vessel Vessel
+	Fix    GNSSFix
+}
+
+// truthSample is a snapshot of the truth state used to model latency
+type truthSample struct {
+	time   time.Time
+	vessel Vessel
+}
+
+// Sensor simulates a sensor observing own ship with its own errors and failures
+type Sensor struct {
+	config   SensorConfig
+	receiver *GNSSReceiver
+
+	mu          sync.Mutex
+	history     []truthSample
+	lastSample  time.Time
+	lastHeading truthSample
+	failedUntil time.Time
+	lastValid   Reading
+	reading     Reading
+}
+
+// NewSensor creates a sensor from its configuration
+func NewSensor(cfg SensorConfig) (*Sensor, error) {
+	if cfg.Talker != "" && len(cfg.Talker) != 2 {
+		return nil, fmt.Errorf("sensor %q: invalid talker ID %q", cfg.Name, cfg.Talker)
+	}
+
+	switch cfg.FailureMode {
+	case "":
+		cfg.FailureMode = FailureSilent
+	case FailureSilent, FailureInvalid, FailureFrozen:
+	default:
+		return nil, fmt.Errorf("sensor %q: unknown failure mode %q", cfg.Name, cfg.FailureMode)
+	}
+
+	s := &Sensor{config: cfg}
+
+	switch cfg.Kind {
+	case SensorGNSS:
+		gnssCfg := DefaultGNSSConfig()
+		if len(cfg.Systems) > 0 {
+			gnssCfg.Systems = nil
+			for _, name := range cfg.Systems {
+				system, err := ParseGNSSSystem(name)
+				if err != nil {
+					return nil, fmt.Errorf("sensor %q: %w", cfg.Name, err)
+				}
+				gnssCfg.Systems = append(gnssCfg.Systems, system)
+			}
+		}
+		if cfg.GNSSErrors != nil {
+			gnssCfg.Errors = *cfg.GNSSErrors
+		}
+		if cfg.Noise > 0 {
+			gnssCfg.Errors.HorizontalSigma = cfg.Noise
+		}
+		s.receiver = NewGNSSReceiver(gnssCfg)
+	case SensorHeading:
+	default:
+		return nil, fmt.Errorf("sensor %q: unknown kind %q", cfg.Name, cfg.Kind)
+	}
+
+	return s, nil
+}
+
+// Config returns the sensor configuration
+func (s *Sensor) Config() SensorConfig {
+	return s.config
+}
+
+// Reading returns the most recent reading
+func (s *Sensor) Reading() Reading {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reading
+}
+
+// Sample observes the truth state at time now and returns the new reading
+func (s *Sensor) Sample(truth Vessel, now time.Time) Reading {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Failures arrive as a Poisson process at the configured rate
+	if !s.lastSample.IsZero() && !now.Before(s.failedUntil) && s.config.FailureRate > 0 {
+		dt := now.Sub(s.lastSample).Hours()
+		if rand.Float64() < 1-math.Exp(-s.config.FailureRate*dt) {
+			s.failedUntil = now.Add(time.Duration(s.config.FailureDuration * float64(time.Second)))
+		}
+	}
+	s.lastSample = now
+
+	delayed := s.delayed(truth, now)
+	if s.config.Kind == SensorHeading {
+		delayed.ROT = s.rateOfTurn(delayed, now)
+	}
+
+	observed := s.observe(delayed)
+	reading := Reading{Time: now, Status: ReadingValid, Vessel: observed}
+	if s.receiver != nil {
+		reading.Fix = s.receiver.Fix(observed, now)
+		if !reading.Fix.Valid {
+			reading.Status = ReadingInvalid
+		}
+	}
+
+	if now.Before(s.failedUntil) {
+		switch s.config.FailureMode {
+		case FailureInvalid:
+			reading.Status = ReadingInvalid
+			reading.Fix.Valid = false
+		case FailureFrozen:
+			reading = s.lastValid
+			reading.Time = now
+			reading.Fix.Time = now
+		default:
+			reading = Reading{Time: now}
+		}
+	} else if reading.Status == ReadingValid {
+		s.lastValid = reading
+	}
+
+	s.reading = reading
+	return reading
+}
+
+// Failed reports whether the sensor is failed at time now
+func (s *Sensor) Failed(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return now.Before(s.failedUntil)
+}
+
+// Fail fails the sensor from time now for the given duration, as a random
+// failure does
+func (s *Sensor) Fail(now time.Time, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failedUntil = now.Add(d)
+}
+
+// delayed records the truth state and returns the state latency ago
+func (s *Sensor) delayed(truth Vessel, now time.Time) Vessel {
+	s.history = append(s.history, truthSample{time: now, vessel: truth})
+
+	cutoff := now.Add(-time.Duration(s.config.Latency * float64(time.Second)))
+	i := 0
+	for i+1 < len(s.history) && !s.history[i+1].time.After(cutoff) {
+		i++
+	}
+	s.history = s.history[i:]
+
+	return s.history[0].vessel
+}
+
+// rateOfTurn derives the rate of turn in degrees per minute from the change
+// in heading since the previous sample
+func (s *Sensor) rateOfTurn(v Vessel, now time.Time) float64 {
+	prev := s.lastHeading
+	s.lastHeading = truthSample{time: now, vessel: v}
+
+	dt := now.Sub(prev.time).Minutes()
+	if prev.time.IsZero() || dt <= 0 {
+		return v.ROT
+	}
+
+	change := NormalizeDegrees(v.Heading - prev.vessel.Heading)
+	if change > 180 {
+		change -= 360
+	}
+	return change / dt
+}
+
+// observe applies the sensor's bias and noise to a truth state. The noise of
+// a GNSS sensor is part of its receiver's error model instead.
+func (s *Sensor) observe(v Vessel) Vessel {
+	switch s.config.Kind {
+	case SensorGNSS:
+		// The receiver is unaware of a bias, so it is applied before the fix
+		if s.config.Bias != 0 {
+			v.Latitude, v.Longitude = Destination(v.Latitude, v.Longitude, s.config.BiasBearing, s.config.Bias/metersPerNM)
+		}
+	case SensorHeading:
+		v.Heading = NormalizeDegrees(v.Heading + s.config.Bias + util.RandomNormal(0, s.config.Noise))
+	}
+	return v
+}
+
+// Sensors is the set of sensors installed on own ship
+type Sensors []*Sensor
+
+// NewSensors creates sensors from their configurations. Names must be unique,
+// and so must the NMEA 2000 source addresses of the sensors of a kind.
+func NewSensors(cfgs []SensorConfig) (Sensors, error) {
+	sensors := make(Sensors, 0, len(cfgs))
+	names := make(map[string]bool)
+	sources := make(map[SensorKind]map[uint8]string)
+	for _, cfg := range cfgs {
+		if names[cfg.Name] {
+			return nil, fmt.Errorf("duplicate sensor name %q", cfg.Name)
+		}
+		names[cfg.Name] = true
+
+		if sources[cfg.Kind] == nil {
+			sources[cfg.Kind] = make(map[uint8]string)
+		}
+		if other, ok := sources[cfg.Kind][cfg.Source]; ok {
+			return nil, fmt.Errorf("sensor %q: source %d already used by %s sensor %q", cfg.Name, cfg.Source, cfg.Kind, other)
+		}
+		sources[cfg.Kind][cfg.Source] = cfg.Name
+
+		s, err := NewSensor(cfg)
+		if err != nil {
+			return nil, err
+		}
+		sensors = append(sensors, s)
+	}
+	return sensors, nil
+}
+
+// Kind returns the sensors of the given kind
+func (ss Sensors) Kind(kind SensorKind) Sensors {
+	var out Sensors
+	for _, s := range ss {
+		if s.config.Kind == kind {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Named returns the sensor with the given name
+func (ss Sensors) Named(name string) (*Sensor, bool) {
+	for _, s := range ss {
+		if s.config.Name == name {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// Sample samples every sensor against the fleet's own ship
+func (ss Sensors) Sample(fleet *Fleet, now time.Time) {
+	own := fleet.OwnShip()
+	for _, s := range ss {
+		s.Sample(own, now)
+	}
+}
+
+// Run samples the sensors every interval until the context is cancelled
+func (ss Sensors) Run(ctx context.Context, fleet *Fleet, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ss.Sample(fleet, time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			ss.Sample(fleet, now)
+		}
+	}
+}
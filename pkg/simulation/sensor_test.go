@@ -0,0 +1,162 @@
+package simulation
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSensorBiasAndLatency(t *testing.T) {
+	sensor, err := NewSensor(SensorConfig{Name: "compass", Kind: SensorHeading, Bias: 2, Latency: 2})
+	if err != nil {
+		t.Fatalf("NewSensor failed: %v", err)
+	}
+
+	start := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+	own := DefaultOwnShip()
+	for i := 0; i <= 5; i++ {
+		own.Heading = float64(10 * i)
+		reading := sensor.Sample(own, start.Add(time.Duration(i)*time.Second))
+
+		// The reading lags the truth by two samples, offset by the bias
+		want := math.Max(0, float64(10*(i-2))) + 2
+		if reading.Status != ReadingValid || math.Abs(reading.Vessel.Heading-want) > 1e-9 {
+			t.Errorf("Sample %d: expected heading %.1f, got %.1f", i, want, reading.Vessel.Heading)
+		}
+	}
+}
+
+func TestSensorGNSSBias(t *testing.T) {
+	sensor, err := NewSensor(SensorConfig{Name: "gps2", Kind: SensorGNSS, Systems: []string{"gps"}, Bias: 100, BiasBearing: 90, GNSSErrors: &GNSSErrorModel{}})
+	if err != nil {
+		t.Fatalf("NewSensor failed: %v", err)
+	}
+
+	own := DefaultOwnShip()
+	reading := sensor.Sample(own, time.Now())
+	bearing, dist := BearingDistance(own.Latitude, own.Longitude, reading.Fix.Latitude, reading.Fix.Longitude)
+	if math.Abs(dist*1852-100) > 0.1 || math.Abs(bearing-90) > 0.1 {
+		t.Errorf("Expected a 100 m bias to the east, got %.1f m at %.1f°", dist*1852, bearing)
+	}
+	if systems := reading.Fix.Systems(); len(systems) != 1 || systems[0] != GPS {
+		t.Errorf("Expected a GPS-only fix, got %v", systems)
+	}
+}
+
+func TestSensorFailureModes(t *testing.T) {
+	start := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+	own := DefaultOwnShip()
+
+	tests := []struct {
+		mode   FailureMode
+		status ReadingStatus
+	}{
+		{FailureSilent, ReadingNone},
+		{FailureInvalid, ReadingInvalid},
+		{FailureFrozen, ReadingValid},
+	}
+
+	for _, tt := range tests {
+		// A very high failure rate guarantees a failure on the second sample
+		sensor, err := NewSensor(SensorConfig{Name: "gps", Kind: SensorGNSS, FailureRate: 1e9, FailureDuration: 60, FailureMode: tt.mode})
+		if err != nil {
+			t.Fatalf("NewSensor failed: %v", err)
+		}
+
+		first := sensor.Sample(own, start)
+		moved := own
+		moved.Latitude += 0.01
+		reading := sensor.Sample(moved, start.Add(time.Second))
+
+		if !sensor.Failed(start.Add(time.Second)) {
+			t.Fatalf("%s: expected sensor to have failed", tt.mode)
+		}
+		if reading.Status != tt.status {
+			t.Errorf("%s: expected status %d, got %d", tt.mode, tt.status, reading.Status)
+		}
+		if tt.mode == FailureFrozen && reading.Fix.Latitude != first.Fix.Latitude {
+			t.Errorf("Frozen sensor should repeat its last position")
+		}
+	}
+}
+
+func TestSensorFail(t *testing.T) {
+	start := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+	sensors, err := NewSensors([]SensorConfig{{Name: "gps", Kind: SensorGNSS}, {Name: "compass", Kind: SensorHeading}})
+	if err != nil {
+		t.Fatalf("NewSensors failed: %v", err)
+	}
+	if _, ok := sensors.Named("log"); ok {
+		t.Error("Expected no sensor named log")
+	}
+	compass, ok := sensors.Named("compass")
+	if !ok || compass.Config().Kind != SensorHeading {
+		t.Fatalf("Expected the compass, got %v", compass)
+	}
+
+	compass.Fail(start, time.Minute)
+	if reading := compass.Sample(DefaultOwnShip(), start.Add(time.Second)); reading.Status != ReadingNone {
+		t.Errorf("Expected a silent failure, got status %d", reading.Status)
+	}
+	if compass.Failed(start.Add(time.Minute)) {
+		t.Error("Expected the failure to end after a minute")
+	}
+}
+
+func TestNewSensorErrors(t *testing.T) {
+	for _, cfg := range []SensorConfig{
+		{Name: "a", Kind: "sonar"},
+		{Name: "b", Kind: SensorHeading, Talker: "HEX"},
+		{Name: "c", Kind: SensorHeading, FailureMode: "explode"},
+		{Name: "d", Kind: SensorGNSS, Systems: []string{"loran"}},
+	} {
+		if _, err := NewSensor(cfg); err == nil {
+			t.Errorf("Expected error for sensor %s", cfg.Name)
+		}
+	}
+}
+
+func TestNewSensorsDuplicates(t *testing.T) {
+	for _, cfgs := range [][]SensorConfig{
+		{{Name: "gps", Kind: SensorGNSS, Source: 1}, {Name: "gps", Kind: SensorHeading, Source: 2}},
+		{{Name: "gps1", Kind: SensorGNSS, Source: 1}, {Name: "gps2", Kind: SensorGNSS, Source: 1}},
+	} {
+		if _, err := NewSensors(cfgs); err == nil {
+			t.Errorf("Expected error for sensors %+v", cfgs)
+		}
+	}
+
+	// A GNSS receiver and a compass of one device may share a source
+	if _, err := NewSensors([]SensorConfig{{Name: "gps", Kind: SensorGNSS, Source: 1}, {Name: "compass", Kind: SensorHeading, Source: 1}}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestLoadScenario(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	data := `{"sensors": [
+		{"name": "gps1", "kind": "gnss", "talker": "GP", "source": 10},
+		{"name": "fluxgate", "kind": "heading", "talker": "HC", "source": 20, "bias": 1.5, "noise": 0.5}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := LoadScenario(path)
+	if err != nil {
+		t.Fatalf("LoadScenario failed: %v", err)
+	}
+
+	sensors, err := NewSensors(sc.Sensors)
+	if err != nil {
+		t.Fatalf("NewSensors failed: %v", err)
+	}
+	if len(sensors.Kind(SensorGNSS)) != 1 || len(sensors.Kind(SensorHeading)) != 1 {
+		t.Errorf("Unexpected sensors: %+v", sc.Sensors)
+	}
+	if cfg := sensors[1].Config(); cfg.Source != 20 || cfg.Bias != 1.5 || cfg.FailureMode != FailureSilent {
+		t.Errorf("Unexpected heading sensor config: %+v", cfg)
+	}
+}
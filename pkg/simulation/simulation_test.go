@@ -0,0 +1,188 @@
+package simulation
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDestinationBearingDistance(t *testing.T) {
+	lat, lon := Destination(48.0, 16.0, 90, 10)
+
+	bearing, distance := BearingDistance(48.0, 16.0, lat, lon)
+	if math.Abs(distance-10) > 0.001 {
+		t.Errorf("Expected distance of 10 NM, got %f", distance)
+	}
+	if math.Abs(bearing-90) > 0.1 {
+		t.Errorf("Expected bearing of 90 degrees, got %f", bearing)
+	}
+}
+
+func TestNormalizeDegrees(t *testing.T) {
+	testCases := map[float64]float64{
+		0:    0,
+		360:  0,
+		-90:  270,
+		725:  5,
+		-725: 355,
+	}
+	for input, expected := range testCases {
+		if got := NormalizeDegrees(input); math.Abs(got-expected) > 1e-9 {
+			t.Errorf("NormalizeDegrees(%f) = %f; want %f", input, got, expected)
+		}
+	}
+}
+
+func TestVesselStep(t *testing.T) {
+	v := Vessel{Latitude: 0, Longitude: 0, COG: 0, Heading: 0, SOG: 60}
+	v.Step(time.Minute)
+
+	if math.Abs(v.Latitude-1.0/60) > 1e-4 {
+		t.Errorf("Expected vessel to move one minute of latitude north, got %f", v.Latitude)
+	}
+
+	v.ROT = 30
+	v.Step(time.Minute)
+	if math.Abs(v.Heading-30) > 1e-9 || math.Abs(v.COG-30) > 1e-9 {
+		t.Errorf("Expected heading and COG of 30 degrees after turning, got %f/%f", v.Heading, v.COG)
+	}
+
+	aton := Vessel{Class: AidToNavigation, Latitude: 1, Longitude: 1, SOG: 10}
+	aton.Step(time.Hour)
+	if aton.Latitude != 1 || aton.Longitude != 1 {
+		t.Error("Aids to navigation should not move")
+	}
+}
+
+func TestFleet(t *testing.T) {
+	own := DefaultOwnShip()
+	fleet := NewFleet(own, RandomTargets(own, 5, 5)...)
+
+	targets := fleet.Targets()
+	if len(targets) != 5 {
+		t.Fatalf("Expected 5 targets, got %d", len(targets))
+	}
+	if targets[4].Class != AidToNavigation {
+		t.Error("Expected last target to be an aid to navigation")
+	}
+	if targets[3].Class != ClassB {
+		t.Error("Expected fourth target to be Class B")
+	}
+
+	for _, target := range targets {
+		_, distance := BearingDistance(own.Latitude, own.Longitude, target.Latitude, target.Longitude)
+		if distance > 5.001 {
+			t.Errorf("Target %d placed %f NM from own ship", target.MMSI, distance)
+		}
+	}
+
+	fleet.Step(time.Hour)
+	moved := fleet.OwnShip()
+	_, distance := BearingDistance(own.Latitude, own.Longitude, moved.Latitude, moved.Longitude)
+	if math.Abs(distance-own.SOG) > 0.01 {
+		t.Errorf("Expected own ship to travel %f NM in an hour, got %f", own.SOG, distance)
+	}
+}
+
+func TestFleetPause(t *testing.T) {
+	fleet := NewFleet(DefaultOwnShip())
+	fleet.SetPaused(true)
+	if !fleet.Paused() {
+		t.Fatal("Expected the fleet to be paused")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go fleet.Run(ctx, 5*time.Millisecond)
+	time.Sleep(30 * time.Millisecond)
+	if got := fleet.OwnShip(); got.Latitude != DefaultOwnShip().Latitude {
+		t.Errorf("Expected own ship to stay put while paused, moved to %f", got.Latitude)
+	}
+
+	fleet.SetPaused(false)
+	time.Sleep(30 * time.Millisecond)
+	if got := fleet.OwnShip(); got.Latitude == DefaultOwnShip().Latitude {
+		t.Error("Expected own ship to move once resumed")
+	}
+}
+
+func TestFleetModelTime(t *testing.T) {
+	fleet := NewFleet(DefaultOwnShip())
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	if got := fleet.ModelTime(start); !got.Equal(start) {
+		t.Errorf("Expected the model time to be the wall-clock time before a pause, got %v", got)
+	}
+
+	// The model time stands still while paused and resumes without the pause
+	fleet.setPaused(true, start)
+	if got := fleet.ModelTime(start.Add(time.Minute)); !got.Equal(start) {
+		t.Errorf("Expected the model time to stop at %v while paused, got %v", start, got)
+	}
+	fleet.setPaused(false, start.Add(time.Minute))
+	if got := fleet.ModelTime(start.Add(2 * time.Minute)); !got.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected the model time to exclude the pause, got %v", got)
+	}
+
+	// Pausing twice keeps the first pause
+	fleet.setPaused(true, start.Add(2*time.Minute))
+	fleet.setPaused(true, start.Add(3*time.Minute))
+	fleet.setPaused(false, start.Add(4*time.Minute))
+	if got := fleet.ModelTime(start.Add(4 * time.Minute)); !got.Equal(start.Add(time.Minute)) {
+		t.Errorf("Expected both pauses excluded from the model time, got %v", got)
+	}
+}
+
+func TestFleetUpdateOwnShip(t *testing.T) {
+	fleet := NewFleet(DefaultOwnShip())
+	fleet.SetCurrent(ConstantCurrent{Set: 90, Drift: 1})
+
+	own := fleet.UpdateOwnShip(func(v *Vessel) {
+		v.Heading, v.STW = 0, 5
+	})
+	if own.Heading != 0 || own.STW != 5 {
+		t.Errorf("Expected heading 0 and 5 knots through water, got %+v", own)
+	}
+	// The current sets own ship east of its heading
+	if math.Abs(own.SOG-math.Hypot(5, 1)) > 1e-9 || own.COG <= 0 || own.COG >= 90 {
+		t.Errorf("Expected the current to apply, got COG %f SOG %f", own.COG, own.SOG)
+	}
+}
+
+func TestGNSSReceiverFix(t *testing.T) {
+	receiver := NewGNSSReceiver(DefaultGNSSConfig())
+	fix := receiver.Fix(DefaultOwnShip(), time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC))
+
+	if !fix.Valid {
+		t.Fatal("Expected a valid fix with four constellations")
+	}
+	if len(fix.Systems()) != 4 {
+		t.Errorf("Expected satellites from 4 systems, got %v", fix.Systems())
+	}
+	if len(fix.Used()) < 4 || len(fix.Used()) > len(fix.Satellites) {
+		t.Errorf("Unexpected number of used satellites: %d of %d", len(fix.Used()), len(fix.Satellites))
+	}
+
+	for _, s := range fix.Satellites {
+		if s.Elevation < 5 || s.Elevation > 90 || s.Azimuth < 0 || s.Azimuth >= 360 {
+			t.Errorf("Satellite %d has invalid geometry: el %.1f az %.1f", s.PRN, s.Elevation, s.Azimuth)
+		}
+	}
+
+	if fix.DOP.HDOP <= 0 || fix.DOP.HDOP > 2 || fix.DOP.PDOP < fix.DOP.HDOP {
+		t.Errorf("Unexpected DOP values: %+v", fix.DOP)
+	}
+	if fix.SigmaMajor < fix.SigmaMinor {
+		t.Errorf("Semi-major axis %.2f smaller than semi-minor %.2f", fix.SigmaMajor, fix.SigmaMinor)
+	}
+	horizontal := math.Hypot(fix.SigmaLat, fix.SigmaLon)
+	if math.Abs(horizontal-receiver.Config().UERE*fix.DOP.HDOP) > 1e-9 {
+		t.Errorf("Horizontal error %.3f does not match UERE x HDOP", horizontal)
+	}
+}
+
+func TestComputeDOPInsufficient(t *testing.T) {
+	if _, ok := ComputeDOP([]Satellite{{Elevation: 45}, {Elevation: 30}, {Elevation: 60}}); ok {
+		t.Error("Expected DOP computation to fail with three satellites")
+	}
+}
@@ -0,0 +1,68 @@
+package simulation
+
+import (
+	"time"
+)
+
+// Snapshot is own ship and its environment sampled once for an update, so
+// that every output reports the same values for the same instant
+type Snapshot struct {
+	Time     time.Time
+	Own      Vessel
+	Current  Current // Current at own ship's position
+	Fix      GNSSFix // Fix of the default receiver, zero without one
+	Wind     Wind    // True wind
+	Depth    Sounding
+	Weather  Weather // Weather at own ship's position
+	Attitude Attitude
+	Engines  []Engine
+	Tanks    []Tank
+}
+
+// Sampler samples the noisy models shared by the outputs into snapshots.
+// Models left nil are not sampled.
+type Sampler struct {
+	Fleet    *Fleet
+	GNSS     *GNSSReceiver
+	Wind     *WindModel
+	Depth    *DepthSounder
+	Weather  *WeatherModel
+	Attitude *AttitudeModel
+	Engines  *EngineModel
+	Tanks    *TankModel
+}
+
+// Sample draws every model once at time t. The wind, depth, weather, engine
+// and tank models are sampled at the fleet's model time, so they stand still
+// while the fleet is paused.
+func (s Sampler) Sample(t time.Time) Snapshot {
+	snap := Snapshot{Time: t}
+	model := t
+	if s.Fleet != nil {
+		snap.Own = s.Fleet.OwnShip()
+		snap.Current = s.Fleet.Current()
+		model = s.Fleet.ModelTime(t)
+	}
+	if s.GNSS != nil {
+		snap.Fix = s.GNSS.Fix(snap.Own, t)
+	}
+	if s.Wind != nil {
+		snap.Wind = s.Wind.Sample(model)
+	}
+	if s.Depth != nil {
+		snap.Depth = s.Depth.Sample(snap.Own, model)
+	}
+	if s.Weather != nil {
+		snap.Weather = s.Weather.Sample(snap.Own.Longitude, model)
+	}
+	if s.Attitude != nil {
+		snap.Attitude = s.Attitude.Sample(snap.Own, t)
+	}
+	if s.Engines != nil {
+		snap.Engines = s.Engines.Sample(snap.Own, model)
+	}
+	if s.Tanks != nil {
+		snap.Tanks = s.Tanks.Sample(snap.Engines, model)
+	}
+	return snap
+}
@@ -0,0 +1,94 @@
+package simulation
+
+import (
+	"math"
+	"time"
+)
+
+// SteeringConfig describes own ship's steering gear and turning response
+type SteeringConfig struct {
+	RudderLimit float64 // Hard over rudder angle, degrees
+	RudderRate  float64 // Rudder speed, degrees per second
+	TurnRate    float64 // Steady rate of turn per degree of rudder and knot of speed, degrees per minute
+	TurnLength  float64 // Distance run through the water while the rate of turn builds up to 63% of its steady value, meters
+}
+
+// DefaultSteeringConfig returns the steering of a 12 m yacht with a hydraulic
+// drive, putting the rudder hard over in about 15 seconds and settling into a
+// turn within about two boat lengths
+func DefaultSteeringConfig() SteeringConfig {
+	return SteeringConfig{
+		RudderLimit: 35,
+		RudderRate:  5,
+		TurnRate:    1,
+		TurnLength:  20,
+	}
+}
+
+// minTurnSpeed is the speed through water in knots below which the turning
+// response is no longer slowed down
+const minTurnSpeed = 0.5
+
+// Steering simulates the rudder moving towards its order at a limited rate
+// and own ship's first-order (Nomoto) turning response to it. The steady rate
+// of turn is proportional to the rudder angle and the speed through water,
+// and the time constant is inversely proportional to the speed, so a boat
+// turns faster and answers the helm sooner the faster it goes. Steering is
+// not safe for concurrent use.
+type Steering struct {
+	config SteeringConfig
+	rudder float64 // Degrees, positive to starboard
+	rot    float64 // Degrees per minute, positive to starboard
+}
+
+// NewSteering creates the steering with the rudder amidships
+func NewSteering(cfg SteeringConfig) *Steering {
+	return &Steering{config: cfg}
+}
+
+// Config returns the steering configuration
+func (s *Steering) Config() SteeringConfig {
+	return s.config
+}
+
+// Rudder returns the rudder angle in degrees, positive to starboard
+func (s *Steering) Rudder() float64 {
+	return s.rudder
+}
+
+// Limit clamps a rudder angle to the hard over angle
+func (s *Steering) Limit(angle float64) float64 {
+	return math.Max(-s.config.RudderLimit, math.Min(s.config.RudderLimit, angle))
+}
+
+// TimeConstant returns the time the rate of turn takes to reach 63% of its
+// steady value at a speed through water in knots
+func (s *Steering) TimeConstant(stw float64) time.Duration {
+	speed := math.Max(stw, minTurnSpeed) * 1852 / 3600
+	return time.Duration(s.config.TurnLength / speed * float64(time.Second))
+}
+
+// Step moves the rudder towards the order over dt and returns the rate of
+// turn in degrees per minute at a speed through water in knots
+func (s *Steering) Step(order, stw float64, dt time.Duration) float64 {
+	cfg := s.config
+	step := cfg.RudderRate * dt.Seconds()
+	s.rudder += math.Max(-step, math.Min(step, s.Limit(order)-s.rudder))
+
+	steady := cfg.TurnRate * s.rudder * math.Max(stw, 0)
+	if tc := s.TimeConstant(stw); tc > 0 {
+		s.rot += (steady - s.rot) * (1 - math.Exp(-dt.Seconds()/tc.Seconds()))
+	} else {
+		s.rot = steady
+	}
+	return s.rot
+}
+
+// Follow sets the rudder to the angle holding a rate of turn in degrees per
+// minute at a speed through water in knots, as a helmsman does
+func (s *Steering) Follow(rot, stw float64) {
+	s.rot, s.rudder = rot, 0
+	if speed := math.Max(stw, 0); speed > 0 && s.config.TurnRate > 0 {
+		s.rudder = s.Limit(rot / (s.config.TurnRate * speed))
+	}
+}
@@ -0,0 +1,61 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSteeringTurnResponse(t *testing.T) {
+	s := NewSteering(DefaultSteeringConfig())
+
+	// The rudder moves at the rudder rate and stops at hard over
+	s.Step(50, 6, 2*time.Second)
+	if r := s.Rudder(); r != 10 {
+		t.Errorf("Expected 10 degrees of rudder after 2 s, got %.1f", r)
+	}
+	for i := 0; i < 10; i++ {
+		s.Step(50, 6, time.Second)
+	}
+	if r := s.Rudder(); r != 35 {
+		t.Errorf("Expected the rudder hard over at 35, got %.1f", r)
+	}
+
+	// The rate of turn lags the rudder and settles on the steady rate
+	s = NewSteering(DefaultSteeringConfig())
+	s.rudder = 10
+	tc := s.TimeConstant(6)
+	rot := s.Step(10, 6, tc)
+	if want := 60 * (1 - math.Exp(-1)); math.Abs(rot-want) > 0.01 {
+		t.Errorf("Expected %.1f deg/min after one time constant, got %.1f", want, rot)
+	}
+	for i := 0; i < 60; i++ {
+		rot = s.Step(10, 6, time.Second)
+	}
+	if math.Abs(rot-60) > 0.01 {
+		t.Errorf("Expected a steady 60 deg/min with 10 degrees of rudder at 6 knots, got %.1f", rot)
+	}
+}
+
+func TestSteeringSpeedDependence(t *testing.T) {
+	s := NewSteering(DefaultSteeringConfig())
+
+	// Twice the speed answers the helm in half the time
+	if slow, fast := s.TimeConstant(6), s.TimeConstant(12); math.Abs(slow.Seconds()-2*fast.Seconds()) > 1e-6 {
+		t.Errorf("Expected the time constant to halve at twice the speed, got %v and %v", slow, fast)
+	}
+	if tc := s.TimeConstant(6).Seconds(); math.Abs(tc-20/(6*1852.0/3600)) > 1e-6 {
+		t.Errorf("Expected a time constant of 20 m at 6 knots, got %.2f s", tc)
+	}
+
+	// Stopped, the rudder has no effect
+	s.rudder = 20
+	if rot := s.Step(20, 0, time.Minute); rot != 0 {
+		t.Errorf("Expected no rate of turn when stopped, got %.1f", rot)
+	}
+
+	s.Follow(30, 6)
+	if s.Rudder() != 5 {
+		t.Errorf("Expected 5 degrees of rudder holding 30 deg/min at 6 knots, got %.1f", s.Rudder())
+	}
+}
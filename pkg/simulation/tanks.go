@@ -0,0 +1,153 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// FluidType identifies the contents of a tank
+type FluidType string
+
+// Supported fluid types
+const (
+	FluidFuel       FluidType = "fuel"
+	FluidFreshWater FluidType = "fresh_water"
+	FluidGrayWater  FluidType = "gray_water"
+	FluidLiveWell   FluidType = "live_well"
+	FluidOil        FluidType = "oil"
+	FluidBlackWater FluidType = "black_water"
+)
+
+// fluidNumbers maps fluid types to their numbers in PGN 127505
+var fluidNumbers = map[FluidType]uint8{
+	FluidFuel:       0,
+	FluidFreshWater: 1,
+	FluidGrayWater:  2,
+	FluidLiveWell:   3,
+	FluidOil:        4,
+	FluidBlackWater: 5,
+}
+
+// Number returns the fluid type number used in PGN 127505
+func (f FluidType) Number() uint8 {
+	return fluidNumbers[f]
+}
+
+// TankConfig describes a tank. Fuel tanks are drawn down by the engines with
+// the same instance, or by every engine when no fuel tank matches its instance.
+type TankConfig struct {
+	Fluid    FluidType `json:"fluid"`
+	Instance uint8     `json:"instance"`
+	Capacity float64   `json:"capacity"` // Liters
+	Level    float64   `json:"level"`    // Level at start-up, percent
+	Rate     float64   `json:"rate"`     // Liters per hour, positive filling and negative draining
+}
+
+// DefaultTanks returns the tanks of a cruising yacht: fuel, fresh water
+// used by the crew and the gray and black water tanks it fills
+func DefaultTanks() []TankConfig {
+	return []TankConfig{
+		{Fluid: FluidFuel, Capacity: 200, Level: 80},
+		{Fluid: FluidFreshWater, Capacity: 300, Level: 70, Rate: -2},
+		{Fluid: FluidGrayWater, Capacity: 80, Level: 20, Rate: 1.5},
+		{Fluid: FluidBlackWater, Capacity: 60, Level: 10, Rate: 0.3},
+	}
+}
+
+// Tank is the state of a tank
+type Tank struct {
+	Config TankConfig
+	Level  float64 // Percent
+	Volume float64 // Liters
+}
+
+// TankModel simulates tank levels, filling and draining at constant rates
+// and drawing fuel at the engines' fuel rate
+type TankModel struct {
+	config []TankConfig
+
+	mu     sync.Mutex
+	last   time.Time
+	volume []float64 // Liters
+}
+
+// NewTankModel creates a tank model from the tank configurations
+func NewTankModel(tanks []TankConfig) (*TankModel, error) {
+	m := &TankModel{config: tanks, volume: make([]float64, len(tanks))}
+	seen := make(map[FluidType]map[uint8]bool)
+	for i, cfg := range tanks {
+		if _, ok := fluidNumbers[cfg.Fluid]; !ok {
+			return nil, fmt.Errorf("tank %d: unknown fluid %q", i, cfg.Fluid)
+		}
+		if cfg.Instance > 15 {
+			return nil, fmt.Errorf("tank %d: instance %d out of range 0-15", i, cfg.Instance)
+		}
+		if cfg.Capacity <= 0 {
+			return nil, fmt.Errorf("tank %d: capacity must be positive", i)
+		}
+		if seen[cfg.Fluid] == nil {
+			seen[cfg.Fluid] = make(map[uint8]bool)
+		}
+		if seen[cfg.Fluid][cfg.Instance] {
+			return nil, fmt.Errorf("tank %d: duplicate %s tank instance %d", i, cfg.Fluid, cfg.Instance)
+		}
+		seen[cfg.Fluid][cfg.Instance] = true
+		m.volume[i] = cfg.Capacity * math.Min(math.Max(cfg.Level, 0), 100) / 100
+	}
+	return m, nil
+}
+
+// Config returns the tank configurations
+func (m *TankModel) Config() []TankConfig {
+	return m.config
+}
+
+// Sample advances the tanks to time t, drawing fuel for the engines, and
+// returns their levels
+func (m *TankModel) Sample(engines []Engine, t time.Time) []Tank {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var dt float64
+	if !m.last.IsZero() && t.After(m.last) {
+		dt = t.Sub(m.last).Hours()
+	}
+	if m.last.IsZero() || t.After(m.last) {
+		m.last = t
+	}
+
+	rates := make([]float64, len(m.config))
+	for i, cfg := range m.config {
+		rates[i] = cfg.Rate
+	}
+	for _, e := range engines {
+		tanks := m.fuelTanks(e.Instance)
+		for _, i := range tanks {
+			rates[i] -= e.FuelRate / float64(len(tanks))
+		}
+	}
+
+	tanks := make([]Tank, len(m.config))
+	for i, cfg := range m.config {
+		m.volume[i] = math.Min(math.Max(m.volume[i]+rates[i]*dt, 0), cfg.Capacity)
+		tanks[i] = Tank{Config: cfg, Level: 100 * m.volume[i] / cfg.Capacity, Volume: m.volume[i]}
+	}
+	return tanks
+}
+
+// fuelTanks returns the indexes of the fuel tanks feeding an engine
+func (m *TankModel) fuelTanks(instance uint8) []int {
+	var all []int
+	for i, cfg := range m.config {
+		if cfg.Fluid != FluidFuel {
+			continue
+		}
+		if cfg.Instance == instance {
+			return []int{i}
+		}
+		all = append(all, i)
+	}
+	return all
+}
@@ -0,0 +1,59 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewTankModelValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		tanks []TankConfig
+	}{
+		{"unknown fluid", []TankConfig{{Fluid: "diesel", Capacity: 100}}},
+		{"zero capacity", []TankConfig{{Fluid: FluidFuel}}},
+		{"instance out of range", []TankConfig{{Fluid: FluidFuel, Instance: 16, Capacity: 100}}},
+		{"duplicate instance", []TankConfig{{Fluid: FluidFuel, Capacity: 100}, {Fluid: FluidFuel, Capacity: 50}}},
+	}
+	for _, tt := range tests {
+		if _, err := NewTankModel(tt.tanks); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+	if _, err := NewTankModel(DefaultTanks()); err != nil {
+		t.Errorf("Expected the default tanks to be valid, got %v", err)
+	}
+}
+
+func TestTankModelRates(t *testing.T) {
+	model, err := NewTankModel([]TankConfig{
+		{Fluid: FluidFuel, Instance: 0, Capacity: 200, Level: 50},
+		{Fluid: FluidFuel, Instance: 1, Capacity: 200, Level: 50},
+		{Fluid: FluidFreshWater, Capacity: 100, Level: 10, Rate: -6},
+		{Fluid: FluidGrayWater, Capacity: 100, Level: 50, Rate: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2025, 6, 21, 12, 0, 0, 0, time.UTC)
+	engines := []Engine{{Instance: 0, FuelRate: 10}, {Instance: 1, FuelRate: 5}}
+
+	model.Sample(engines, start)
+	tanks := model.Sample(engines, start.Add(2*time.Hour))
+
+	if math.Abs(tanks[0].Volume-80) > 1e-9 || math.Abs(tanks[1].Volume-90) > 1e-9 {
+		t.Errorf("Expected each engine to draw on its own fuel tank, got %.1f and %.1f L", tanks[0].Volume, tanks[1].Volume)
+	}
+	if math.Abs(tanks[0].Level-40) > 1e-9 {
+		t.Errorf("Expected the port fuel tank at 40%%, got %.1f", tanks[0].Level)
+	}
+	if tanks[2].Volume != 0 || tanks[3].Level != 70 {
+		t.Errorf("Expected the fresh water empty and the gray water at 70%%, got %.1f L and %.1f%%", tanks[2].Volume, tanks[3].Level)
+	}
+
+	// A repeated sample at the same time leaves the tanks unchanged
+	if again := model.Sample(engines, start.Add(2*time.Hour)); again[3].Level != tanks[3].Level {
+		t.Errorf("Expected the same level, got %.1f%% then %.1f%%", tanks[3].Level, again[3].Level)
+	}
+}
@@ -0,0 +1,23 @@
+package simulation
+
+import (
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/simulation/wmm"
+)
+
+// VariationFunc returns the magnetic variation in degrees, east positive, at a
+// position and time
+type VariationFunc func(lat, lon float64, t time.Time) float64
+
+// WMMVariation computes the variation from the embedded World Magnetic Model
+func WMMVariation(lat, lon float64, t time.Time) float64 {
+	return wmm.Declination(lat, lon, t)
+}
+
+// FixedVariation returns a VariationFunc reporting the same variation everywhere
+func FixedVariation(variation float64) VariationFunc {
+	return func(float64, float64, time.Time) float64 {
+		return variation
+	}
+}
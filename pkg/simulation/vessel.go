@@ -0,0 +1,106 @@
+// Package simulation provides the shared vessel state that drives sentence and PGN generation
+package simulation
+
+import (
+	"math"
+	"time"
+)
+
+// VesselClass identifies the kind of AIS station a vessel represents
+type VesselClass uint8
+
+// Vessel classes
+const (
+	ClassA VesselClass = iota
+	ClassB
+	AidToNavigation
+)
+
+// Vessel holds the static and kinematic state of a simulated vessel
+type Vessel struct {
+	// Static data
+	MMSI        uint32
+	IMO         uint32
+	Name        string
+	CallSign    string
+	ShipType    uint8 // AIS ship and cargo type
+	Class       VesselClass
+	Assigned    bool    // Class A reporting on a schedule assigned by a base station
+	AidType     uint8   // AIS aid-to-navigation type, only used for AidToNavigation
+	ToBow       float64 // Meters from GNSS antenna to bow
+	ToStern     float64 // Meters from GNSS antenna to stern
+	ToPort      float64 // Meters from GNSS antenna to port side
+	ToStarboard float64 // Meters from GNSS antenna to starboard side
+	Draught     float64 // Meters
+	Destination string
+
+	// Dynamic data
+	NavStatus uint8   // AIS navigational status
+	Latitude  float64 // Degrees, positive north
+	Longitude float64 // Degrees, positive east
+	Heading   float64 // Degrees true
+	COG       float64 // Degrees true
+	SOG       float64 // Knots
+	STW       float64 // Speed through water, knots
+	ROT       float64 // Degrees per minute, positive to starboard
+	Leeway    float64 // Degrees the track through the water is set off the heading, positive to starboard
+}
+
+// Length returns the overall length of the vessel in meters
+func (v Vessel) Length() float64 {
+	return v.ToBow + v.ToStern
+}
+
+// Beam returns the overall beam of the vessel in meters
+func (v Vessel) Beam() float64 {
+	return v.ToPort + v.ToStarboard
+}
+
+// ApplyCurrent sets the course and speed over ground to the vessel's motion
+// through the water, along its heading set off by the leeway at its speed
+// through water, plus the current
+func (v *Vessel) ApplyCurrent(c Current) {
+	track := (v.Heading + v.Leeway) * math.Pi / 180
+	north, east := c.velocity()
+	ground := currentFromVelocity(v.STW*math.Cos(track)+north, v.STW*math.Sin(track)+east)
+
+	v.COG, v.SOG = ground.Set, ground.Drift
+	if v.SOG < 1e-9 {
+		v.COG = v.Heading
+	}
+}
+
+// GroundSpeedComponents returns the speed over ground along and across the
+// heading in knots, positive forward and to starboard
+func (v Vessel) GroundSpeedComponents() (float64, float64) {
+	drift := (v.COG - v.Heading) * math.Pi / 180
+	return v.SOG * math.Cos(drift), v.SOG * math.Sin(drift)
+}
+
+// WaterSpeedComponents returns the speed through the water along and across
+// the heading in knots, positive forward and to starboard: the motion over
+// ground less the current
+func (v Vessel) WaterSpeedComponents(c Current) (float64, float64) {
+	heading := v.Heading * math.Pi / 180
+	course := v.COG * math.Pi / 180
+	currentNorth, currentEast := c.velocity()
+	north := v.SOG*math.Cos(course) - currentNorth
+	east := v.SOG*math.Sin(course) - currentEast
+	return north*math.Cos(heading) + east*math.Sin(heading), east*math.Cos(heading) - north*math.Sin(heading)
+}
+
+// Step advances the vessel by dead reckoning over the given duration
+func (v *Vessel) Step(dt time.Duration) {
+	if v.Class == AidToNavigation {
+		return
+	}
+
+	minutes := dt.Minutes()
+	if v.ROT != 0 {
+		v.Heading = NormalizeDegrees(v.Heading + v.ROT*minutes)
+		v.COG = NormalizeDegrees(v.COG + v.ROT*minutes)
+	}
+
+	distance := v.SOG * dt.Hours()
+	v.Latitude, v.Longitude = Destination(v.Latitude, v.Longitude, v.COG, distance)
+}
@@ -0,0 +1,92 @@
+package simulation
+
+import (
+	"sort"
+	"sync"
+)
+
+// Waypoint is a named position
+type Waypoint struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Route is a named list of waypoints in the order they are sailed
+type Route struct {
+	Name      string   `json:"name"`
+	Waypoints []string `json:"waypoints"`
+}
+
+// Waypoints holds the waypoints and routes loaded into the simulator,
+// replacing waypoints and routes with the same name
+type Waypoints struct {
+	mu        sync.RWMutex
+	waypoints map[string]Waypoint
+	routes    map[string]Route
+}
+
+// NewWaypoints creates an empty waypoint store
+func NewWaypoints() *Waypoints {
+	return &Waypoints{
+		waypoints: make(map[string]Waypoint),
+		routes:    make(map[string]Route),
+	}
+}
+
+// SetWaypoint adds or replaces a waypoint
+func (w *Waypoints) SetWaypoint(wp Waypoint) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.waypoints[wp.Name] = wp
+}
+
+// Waypoint returns the waypoint with the given name
+func (w *Waypoints) Waypoint(name string) (Waypoint, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	wp, ok := w.waypoints[name]
+	return wp, ok
+}
+
+// List returns the waypoints sorted by name
+func (w *Waypoints) List() []Waypoint {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	list := make([]Waypoint, 0, len(w.waypoints))
+	for _, wp := range w.waypoints {
+		list = append(list, wp)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// SetRoute adds or replaces a route
+func (w *Waypoints) SetRoute(r Route) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	r.Waypoints = append([]string(nil), r.Waypoints...)
+	w.routes[r.Name] = r
+}
+
+// Route returns the route with the given name
+func (w *Waypoints) Route(name string) (Route, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	r, ok := w.routes[name]
+	return r, ok
+}
+
+// Routes returns the routes sorted by name
+func (w *Waypoints) Routes() []Route {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	list := make([]Route, 0, len(w.routes))
+	for _, r := range w.routes {
+		list = append(list, r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
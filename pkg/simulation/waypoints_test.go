@@ -0,0 +1,30 @@
+package simulation
+
+import "testing"
+
+func TestWaypoints(t *testing.T) {
+	w := NewWaypoints()
+	w.SetWaypoint(Waypoint{Name: "B", Latitude: 1, Longitude: 2})
+	w.SetWaypoint(Waypoint{Name: "A", Latitude: 3, Longitude: 4})
+	w.SetWaypoint(Waypoint{Name: "B", Latitude: 5, Longitude: 6})
+
+	if wp, ok := w.Waypoint("B"); !ok || wp.Latitude != 5 || wp.Longitude != 6 {
+		t.Errorf("Expected waypoint B to be replaced, got %+v", wp)
+	}
+	if list := w.List(); len(list) != 2 || list[0].Name != "A" || list[1].Name != "B" {
+		t.Errorf("Expected waypoints sorted by name, got %+v", list)
+	}
+
+	waypoints := []string{"A", "B"}
+	w.SetRoute(Route{Name: "1", Waypoints: waypoints})
+	waypoints[0] = "C"
+	if r, ok := w.Route("1"); !ok || len(r.Waypoints) != 2 || r.Waypoints[0] != "A" {
+		t.Errorf("Expected the route to keep its own waypoints, got %+v", r)
+	}
+	if _, ok := w.Route("2"); ok {
+		t.Error("Expected an unknown route not to be found")
+	}
+	if routes := w.Routes(); len(routes) != 1 || routes[0].Name != "1" {
+		t.Errorf("Expected one route, got %+v", routes)
+	}
+}
@@ -0,0 +1,179 @@
+package simulation
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+)
+
+// WeatherConfig describes the meteorological conditions of the weather model
+type WeatherConfig struct {
+	AirTemperature   float64 // Daily mean air temperature, °C
+	DiurnalRange     float64 // Difference between the afternoon maximum and the dawn minimum, °C
+	WaterTemperature float64 // Sea surface temperature, °C
+	Pressure         float64 // Mean sea level pressure, hPa
+	Humidity         float64 // Relative humidity at the daily mean temperature, percent
+	FrontRate        float64 // Mean number of passing fronts per day
+	FrontDepth       float64 // Fall of the pressure at the passage of a front, hPa
+	FrontCooling     float64 // Fall of the air temperature behind a front, °C
+	FrontDuration    float64 // Time from the first fall of the pressure to full recovery, hours
+}
+
+// DefaultWeatherConfig returns a mild summer day with a front every other day
+func DefaultWeatherConfig() WeatherConfig {
+	return WeatherConfig{
+		AirTemperature:   18,
+		DiurnalRange:     6,
+		WaterTemperature: 16,
+		Pressure:         1013.25,
+		Humidity:         75,
+		FrontRate:        0.5,
+		FrontDepth:       12,
+		FrontCooling:     4,
+		FrontDuration:    12,
+	}
+}
+
+// Weather fluctuations: amplitudes (1 sigma) and correlation time in seconds
+const (
+	weatherTemperatureNoise = 0.2
+	weatherPressureNoise    = 0.1
+	weatherNoiseTime        = 600.0
+)
+
+// Weather is a set of meteorological observations
+type Weather struct {
+	AirTemperature   float64 // °C
+	WaterTemperature float64 // °C
+	DewPoint         float64 // °C
+	Humidity         float64 // Relative humidity, percent
+	Pressure         float64 // Sea level pressure, hPa
+}
+
+// WeatherModel simulates air temperature, pressure and humidity following
+// the solar day at own ship's longitude, with passing fronts bringing a fall
+// and recovery of the pressure, moist air and cooling behind them
+type WeatherModel struct {
+	config WeatherConfig
+
+	mu          sync.Mutex
+	started     bool
+	last        time.Time
+	temperature float64 // Air temperature fluctuation, °C
+	pressure    float64 // Pressure fluctuation, hPa
+	frontStart  time.Time
+}
+
+// NewWeatherModel creates a weather model with the given configuration
+func NewWeatherModel(cfg WeatherConfig) *WeatherModel {
+	return &WeatherModel{config: cfg}
+}
+
+// Config returns the weather model configuration
+func (w *WeatherModel) Config() WeatherConfig {
+	return w.config
+}
+
+// StartFront makes a front begin to pass at time t, replacing any front in progress
+func (w *WeatherModel) StartFront(t time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.frontStart = t
+}
+
+// frontDuration returns the duration of a front passage
+func (w *WeatherModel) frontDuration() time.Duration {
+	return time.Duration(w.config.FrontDuration * float64(time.Hour))
+}
+
+// Sample advances the model to time t and returns the weather at a longitude
+func (w *WeatherModel) Sample(lon float64, t time.Time) Weather {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cfg := w.config
+	if !w.started {
+		w.started = true
+		w.temperature = util.RandomNormal(0, weatherTemperatureNoise)
+		w.pressure = util.RandomNormal(0, weatherPressureNoise)
+	} else if dt := t.Sub(w.last).Seconds(); dt > 0 {
+		// First-order Gauss-Markov processes, exact for any step size
+		phi := math.Exp(-dt / weatherNoiseTime)
+		scale := math.Sqrt(1 - phi*phi)
+		w.temperature = phi*w.temperature + util.RandomNormal(0, weatherTemperatureNoise*scale)
+		w.pressure = phi*w.pressure + util.RandomNormal(0, weatherPressureNoise*scale)
+
+		// Fronts arrive as a Poisson process once the last one has passed
+		frontEnd := w.frontStart.Add(w.frontDuration())
+		if !t.Before(frontEnd) && cfg.FrontRate > 0 && rand.Float64() < 1-math.Exp(-cfg.FrontRate*dt/86400) {
+			w.frontStart = t
+		}
+	}
+	if t.After(w.last) {
+		w.last = t
+	}
+
+	hour := localSolarHour(lon, t)
+
+	// The air is warmest mid-afternoon, and the atmospheric tide raises the
+	// pressure around 10:00 and 22:00
+	temperature := cfg.AirTemperature + cfg.DiurnalRange/2*math.Cos(2*math.Pi*(hour-15)/24)
+	pressure := cfg.Pressure + 0.6*math.Cos(4*math.Pi*(hour-10)/24)
+
+	// The dew point of the air mass is fixed by the mean conditions, so the
+	// relative humidity rises at night and falls in the afternoon
+	dewPoint := DewPoint(cfg.AirTemperature, cfg.Humidity)
+
+	// A front lowers the pressure to its passage half way through and brings
+	// near-saturated air; the cooling follows in the second half
+	if cfg.FrontDuration > 0 {
+		if s := t.Sub(w.frontStart).Hours() / cfg.FrontDuration; s >= 0 && s < 1 {
+			bump := math.Pow(math.Sin(math.Pi*s), 2)
+			pressure -= cfg.FrontDepth * bump
+			if s > 0.5 {
+				temperature -= cfg.FrontCooling * math.Sin(2*math.Pi*(s-0.5))
+			}
+			dewPoint += bump * 0.9 * math.Max(0, temperature-dewPoint)
+		}
+	}
+
+	temperature += w.temperature
+	dewPoint = math.Min(dewPoint, temperature)
+
+	return Weather{
+		AirTemperature:   temperature,
+		WaterTemperature: cfg.WaterTemperature,
+		DewPoint:         dewPoint,
+		Humidity:         RelativeHumidity(temperature, dewPoint),
+		Pressure:         pressure + w.pressure,
+	}
+}
+
+// localSolarHour returns the local solar time in hours at a longitude
+func localSolarHour(lon float64, t time.Time) float64 {
+	u := t.UTC()
+	return math.Mod(float64(u.Hour())+float64(u.Minute())/60+float64(u.Second())/3600+lon/15+48, 24)
+}
+
+// Magnus formula coefficients for saturation vapour pressure over water
+const (
+	magnusB = 17.62
+	magnusC = 243.12 // °C
+)
+
+// DewPoint returns the dew point in °C of air at a temperature in °C and a
+// relative humidity in percent
+func DewPoint(temperature, humidity float64) float64 {
+	gamma := math.Log(math.Max(humidity, 1)/100) + magnusB*temperature/(magnusC+temperature)
+	return magnusC * gamma / (magnusB - gamma)
+}
+
+// RelativeHumidity returns the relative humidity in percent of air at a
+// temperature and dew point in °C
+func RelativeHumidity(temperature, dewPoint float64) float64 {
+	rh := 100 * math.Exp(magnusB*dewPoint/(magnusC+dewPoint)-magnusB*temperature/(magnusC+temperature))
+	return math.Min(rh, 100)
+}
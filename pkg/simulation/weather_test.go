@@ -0,0 +1,81 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDewPoint(t *testing.T) {
+	if dp := DewPoint(20, 100); math.Abs(dp-20) > 1e-9 {
+		t.Errorf("Expected saturated air at its dew point, got %.2f", dp)
+	}
+	if dp := DewPoint(20, 50); math.Abs(dp-9.3) > 0.1 {
+		t.Errorf("Expected a dew point of 9.3°C at 20°C and 50%%, got %.2f", dp)
+	}
+	if rh := RelativeHumidity(25, DewPoint(25, 62)); math.Abs(rh-62) > 1e-6 {
+		t.Errorf("Expected 62%% humidity back, got %.3f", rh)
+	}
+}
+
+func TestWeatherModelDiurnal(t *testing.T) {
+	cfg := DefaultWeatherConfig()
+	cfg.FrontRate = 0
+	model := NewWeatherModel(cfg)
+	dawn := time.Date(2025, 6, 21, 3, 0, 0, 0, time.UTC)
+
+	night := model.Sample(0, dawn)
+	day := model.Sample(0, dawn.Add(12*time.Hour))
+
+	if diff := day.AirTemperature - night.AirTemperature; math.Abs(diff-cfg.DiurnalRange) > 1.5 {
+		t.Errorf("Expected the afternoon to be %.0f°C warmer than dawn, got %.1f", cfg.DiurnalRange, diff)
+	}
+	if night.Humidity <= day.Humidity {
+		t.Errorf("Expected higher humidity at dawn, got %.1f%% at dawn and %.1f%% in the afternoon", night.Humidity, day.Humidity)
+	}
+	if math.Abs(night.Pressure-cfg.Pressure) > 1.2 {
+		t.Errorf("Expected pressure near %.1f hPa, got %.1f", cfg.Pressure, night.Pressure)
+	}
+	if night.WaterTemperature != cfg.WaterTemperature {
+		t.Errorf("Expected water temperature %.1f, got %.1f", cfg.WaterTemperature, night.WaterTemperature)
+	}
+
+	// Local time follows the longitude: 03:00 UTC is mid-afternoon at 180°E
+	if east := model.Sample(180, dawn.Add(12*time.Hour)); east.AirTemperature > night.AirTemperature+1.5 {
+		t.Errorf("Expected 03:00 local time at 180°E, got %.1f°C", east.AirTemperature)
+	}
+}
+
+func TestWeatherModelFront(t *testing.T) {
+	cfg := DefaultWeatherConfig()
+	cfg.FrontRate = 0
+	model := NewWeatherModel(cfg)
+	start := time.Date(2025, 6, 21, 12, 0, 0, 0, time.UTC)
+	duration := time.Duration(cfg.FrontDuration * float64(time.Hour))
+
+	before := model.Sample(0, start)
+	model.StartFront(start)
+
+	// The pressure falls steadily to the passage, fast enough for a barograph alarm
+	threeHours := model.Sample(0, start.Add(3*time.Hour))
+	passage := model.Sample(0, start.Add(duration/2))
+	if fall := before.Pressure - threeHours.Pressure; fall < 3 {
+		t.Errorf("Expected a fall of at least 3 hPa in 3 hours, got %.1f", fall)
+	}
+	if fall := before.Pressure - passage.Pressure; math.Abs(fall-cfg.FrontDepth) > 2 {
+		t.Errorf("Expected a fall of %.0f hPa at the passage, got %.1f", cfg.FrontDepth, fall)
+	}
+	if passage.Humidity < 90 {
+		t.Errorf("Expected near-saturated air at the passage, got %.1f%%", passage.Humidity)
+	}
+
+	// Behind the front the air cools, then the weather returns to normal
+	behind := model.Sample(0, start.Add(duration*3/4))
+	if behind.AirTemperature > model.Sample(0, start.Add(duration*3/4+24*time.Hour)).AirTemperature-cfg.FrontCooling+1.5 {
+		t.Errorf("Expected cooling of %.0f°C behind the front, got %.1f°C", cfg.FrontCooling, behind.AirTemperature)
+	}
+	after := model.Sample(0, start.Add(24*time.Hour))
+	if math.Abs(after.Pressure-before.Pressure) > 1 {
+		t.Errorf("Expected the pressure to recover to %.1f hPa, got %.1f", before.Pressure, after.Pressure)
+	}
+}
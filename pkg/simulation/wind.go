@@ -0,0 +1,145 @@
+package simulation
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/captv89/nmea-simulator/pkg/nmea0183/util"
+)
+
+// WindConfig describes the true wind of the weather model
+type WindConfig struct {
+	Direction    float64 // Mean direction the wind blows from, degrees true
+	Speed        float64 // Mean speed, knots
+	Trend        float64 // Change of the mean direction, degrees per hour, positive veering (clockwise)
+	Variability  float64 // Gauss-Markov fluctuation of the direction, degrees (1 sigma)
+	Turbulence   float64 // Gauss-Markov fluctuation of the speed, fraction of the mean speed (1 sigma)
+	GustRate     float64 // Mean number of gusts per hour
+	GustFactor   float64 // Typical gust peak as a fraction of the mean speed
+	GustDuration float64 // Duration of a gust, seconds
+}
+
+// DefaultWindConfig returns a moderate south-westerly breeze with gusts
+func DefaultWindConfig() WindConfig {
+	return WindConfig{
+		Direction:    225,
+		Speed:        12,
+		Variability:  5,
+		Turbulence:   0.1,
+		GustRate:     30,
+		GustFactor:   0.4,
+		GustDuration: 15,
+	}
+}
+
+// Correlation times of the speed and direction fluctuations, seconds
+const (
+	windSpeedTime     = 60.0
+	windDirectionTime = 120.0
+)
+
+// Wind is a wind vector
+type Wind struct {
+	Direction float64 // Direction the wind blows from, degrees true
+	Speed     float64 // Knots
+}
+
+// WindModel simulates the true wind with slow fluctuations, gusts and a
+// veering or backing trend
+type WindModel struct {
+	config WindConfig
+
+	mu        sync.Mutex
+	start     time.Time
+	last      time.Time
+	direction float64 // Direction fluctuation, degrees
+	speed     float64 // Speed fluctuation, knots
+	gustStart time.Time
+	gust      float64 // Peak of the current gust, knots
+}
+
+// NewWindModel creates a wind model with the given configuration
+func NewWindModel(cfg WindConfig) *WindModel {
+	return &WindModel{config: cfg}
+}
+
+// Config returns the wind model configuration
+func (w *WindModel) Config() WindConfig {
+	return w.config
+}
+
+// Sample advances the model to time t and returns the true wind
+func (w *WindModel) Sample(t time.Time) Wind {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	cfg := w.config
+	speedSigma := cfg.Speed * cfg.Turbulence
+	if w.start.IsZero() {
+		w.start = t
+		w.direction = util.RandomNormal(0, cfg.Variability)
+		w.speed = util.RandomNormal(0, speedSigma)
+	} else if dt := t.Sub(w.last).Seconds(); dt > 0 {
+		// First-order Gauss-Markov processes, exact for any step size
+		phi := math.Exp(-dt / windDirectionTime)
+		w.direction = phi*w.direction + util.RandomNormal(0, cfg.Variability*math.Sqrt(1-phi*phi))
+		phi = math.Exp(-dt / windSpeedTime)
+		w.speed = phi*w.speed + util.RandomNormal(0, speedSigma*math.Sqrt(1-phi*phi))
+
+		// Gusts arrive as a Poisson process
+		gustEnd := w.gustStart.Add(time.Duration(cfg.GustDuration * float64(time.Second)))
+		if !t.Before(gustEnd) && cfg.GustRate > 0 && rand.Float64() < 1-math.Exp(-cfg.GustRate*dt/3600) {
+			w.gustStart = t
+			w.gust = cfg.GustFactor * cfg.Speed * (0.5 + rand.Float64())
+		}
+	}
+	if t.After(w.last) {
+		w.last = t
+	}
+
+	// A gust rises and falls as half a sine wave
+	var gust float64
+	if elapsed := t.Sub(w.gustStart).Seconds(); cfg.GustDuration > 0 && elapsed >= 0 && elapsed < cfg.GustDuration {
+		gust = w.gust * math.Sin(math.Pi*elapsed/cfg.GustDuration)
+	}
+
+	hours := t.Sub(w.start).Hours()
+	return Wind{
+		Direction: NormalizeDegrees(cfg.Direction + cfg.Trend*hours + w.direction),
+		Speed:     math.Max(0, cfg.Speed+w.speed+gust),
+	}
+}
+
+// RelativeWind is the wind as measured on board a moving vessel
+type RelativeWind struct {
+	True          Wind    // Ground referenced true wind
+	TrueAngle     float64 // True wind angle off the bow, degrees clockwise
+	ApparentAngle float64 // Apparent wind angle off the bow, degrees clockwise
+	ApparentSpeed float64 // Knots
+}
+
+// ResolveWind derives the true and apparent wind angles and the apparent wind
+// speed on board a vessel from the true wind and the vessel's motion over ground
+func ResolveWind(w Wind, v Vessel) RelativeWind {
+	// Velocity of the air and of the vessel, north and east components in knots
+	from := w.Direction * math.Pi / 180
+	airN, airE := -w.Speed*math.Cos(from), -w.Speed*math.Sin(from)
+	cog := v.COG * math.Pi / 180
+	boatN, boatE := v.SOG*math.Cos(cog), v.SOG*math.Sin(cog)
+
+	// The apparent wind is the air velocity relative to the vessel
+	appN, appE := airN-boatN, airE-boatE
+	r := RelativeWind{
+		True:          w,
+		TrueAngle:     NormalizeDegrees(w.Direction - v.Heading),
+		ApparentSpeed: math.Hypot(appN, appE),
+	}
+	r.ApparentAngle = r.TrueAngle
+	if r.ApparentSpeed > 1e-9 {
+		r.ApparentAngle = NormalizeDegrees(math.Atan2(-appE, -appN)*180/math.Pi - v.Heading)
+	}
+
+	return r
+}
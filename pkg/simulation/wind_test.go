@@ -0,0 +1,69 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestResolveWind(t *testing.T) {
+	tests := []struct {
+		name          string
+		wind          Wind
+		vessel        Vessel
+		trueAngle     float64
+		apparentAngle float64
+		apparentSpeed float64
+	}{
+		{"stationary", Wind{Direction: 270, Speed: 10}, Vessel{Heading: 0}, 270, 270, 10},
+		{"head to wind", Wind{Direction: 0, Speed: 10}, Vessel{Heading: 0, COG: 0, SOG: 6}, 0, 0, 16},
+		{"running", Wind{Direction: 180, Speed: 10}, Vessel{Heading: 0, COG: 0, SOG: 6}, 180, 180, 4},
+		{"beam reach", Wind{Direction: 90, Speed: 10}, Vessel{Heading: 0, COG: 0, SOG: 10}, 90, 45, 10 * math.Sqrt2},
+		{"heading offset", Wind{Direction: 90, Speed: 10}, Vessel{Heading: 80}, 10, 10, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := ResolveWind(tt.wind, tt.vessel)
+			if math.Abs(r.TrueAngle-tt.trueAngle) > 1e-6 {
+				t.Errorf("Expected true angle %.1f, got %.3f", tt.trueAngle, r.TrueAngle)
+			}
+			if math.Abs(r.ApparentAngle-tt.apparentAngle) > 1e-6 {
+				t.Errorf("Expected apparent angle %.1f, got %.3f", tt.apparentAngle, r.ApparentAngle)
+			}
+			if math.Abs(r.ApparentSpeed-tt.apparentSpeed) > 1e-6 {
+				t.Errorf("Expected apparent speed %.2f, got %.3f", tt.apparentSpeed, r.ApparentSpeed)
+			}
+		})
+	}
+}
+
+func TestWindModelTrend(t *testing.T) {
+	model := NewWindModel(WindConfig{Direction: 350, Speed: 12, Trend: 60})
+	start := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+
+	if w := model.Sample(start); w.Direction != 350 || w.Speed != 12 {
+		t.Errorf("Expected 350° at 12 kn, got %+v", w)
+	}
+	if w := model.Sample(start.Add(30 * time.Minute)); math.Abs(w.Direction-20) > 1e-9 {
+		t.Errorf("Expected the wind to veer to 020°, got %.1f", w.Direction)
+	}
+}
+
+func TestWindModelGusts(t *testing.T) {
+	cfg := WindConfig{Direction: 225, Speed: 10, GustRate: 3600, GustFactor: 0.5, GustDuration: 10}
+	model := NewWindModel(cfg)
+	start := time.Date(2025, 4, 29, 12, 0, 0, 0, time.UTC)
+
+	var peak float64
+	for i := 0; i < 60; i++ {
+		w := model.Sample(start.Add(time.Duration(i) * time.Second))
+		if w.Speed < cfg.Speed {
+			t.Fatalf("Gusts should only add to the mean speed, got %.1f", w.Speed)
+		}
+		peak = math.Max(peak, w.Speed)
+	}
+	if peak <= cfg.Speed || peak > cfg.Speed*(1+1.5*cfg.GustFactor) {
+		t.Errorf("Expected gusts up to %.1f kn, got a peak of %.1f", cfg.Speed*(1+1.5*cfg.GustFactor), peak)
+	}
+}
@@ -0,0 +1,243 @@
+// Package wmm implements the World Magnetic Model, a degree 12 spherical
+// harmonic model of the Earth's main magnetic field. The WMM2025 coefficients
+// (valid 2025.0 to 2030.0) are embedded in the binary.
+package wmm
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaxDegree is the degree and order of the model
+const MaxDegree = 12
+
+// Reference ellipsoid (WGS84) and geomagnetic reference radius, kilometers
+const (
+	semiMajorAxis   = 6378.137
+	flattening      = 1 / 298.257223563
+	referenceRadius = 6371.2
+)
+
+//go:embed WMM.COF
+var embeddedCoefficients string
+
+// Model is a set of Gauss coefficients and their secular variation
+type Model struct {
+	Name  string
+	Epoch float64 // Decimal year
+
+	g, h       [MaxDegree + 1][MaxDegree + 1]float64 // nT
+	gDot, hDot [MaxDegree + 1][MaxDegree + 1]float64 // nT per year
+}
+
+// Field is the magnetic field vector at a point
+type Field struct {
+	X           float64 // North component, nT
+	Y           float64 // East component, nT
+	Z           float64 // Down component, nT
+	H           float64 // Horizontal intensity, nT
+	F           float64 // Total intensity, nT
+	Inclination float64 // Degrees, positive down
+	Declination float64 // Degrees, east positive
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultModel *Model
+)
+
+// Default returns the embedded model
+func Default() *Model {
+	defaultOnce.Do(func() {
+		m, err := Load(strings.NewReader(embeddedCoefficients))
+		if err != nil {
+			panic(fmt.Sprintf("wmm: invalid embedded coefficients: %v", err))
+		}
+		defaultModel = m
+	})
+	return defaultModel
+}
+
+// Load reads a model in the NOAA WMM.COF format: a header line with the
+// epoch and model name followed by lines of n, m, g, h, g-dot and h-dot
+func Load(r io.Reader) (*Model, error) {
+	scanner := bufio.NewScanner(r)
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("missing header")
+	}
+
+	header := strings.Fields(scanner.Text())
+	if len(header) < 2 {
+		return nil, fmt.Errorf("invalid header %q", scanner.Text())
+	}
+	epoch, err := strconv.ParseFloat(header[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid epoch %q", header[0])
+	}
+	m := &Model{Name: header[1], Epoch: epoch}
+
+	count := 0
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "9999") {
+			break
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("invalid coefficient line %q", line)
+		}
+		var v [6]float64
+		for i, f := range fields {
+			if v[i], err = strconv.ParseFloat(f, 64); err != nil {
+				return nil, fmt.Errorf("invalid coefficient line %q", line)
+			}
+		}
+
+		n, order := int(v[0]), int(v[1])
+		if n < 1 || n > MaxDegree || order < 0 || order > n {
+			return nil, fmt.Errorf("invalid degree and order %d,%d", n, order)
+		}
+		m.g[n][order], m.h[n][order] = v[2], v[3]
+		m.gDot[n][order], m.hDot[n][order] = v[4], v[5]
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if want := (MaxDegree+1)*(MaxDegree+2)/2 - 1; count != want {
+		return nil, fmt.Errorf("expected %d coefficients, got %d", want, count)
+	}
+
+	return m, nil
+}
+
+// Field computes the magnetic field at a geodetic position, altitude above
+// the ellipsoid in kilometers and time
+func (m *Model) Field(lat, lon, alt float64, t time.Time) Field {
+	dt := DecimalYear(t) - m.Epoch
+
+	// Geodetic to geocentric spherical coordinates
+	phi := lat * math.Pi / 180
+	lambda := lon * math.Pi / 180
+	e2 := flattening * (2 - flattening)
+	rc := semiMajorAxis / math.Sqrt(1-e2*math.Sin(phi)*math.Sin(phi))
+	p := (rc + alt) * math.Cos(phi)
+	z := (rc*(1-e2) + alt) * math.Sin(phi)
+	r := math.Hypot(p, z)
+	phiC := math.Asin(z / r)
+
+	// Colatitude, kept away from the poles where the east component is undefined
+	theta := math.Pi/2 - phiC
+	sinT, cosT := math.Sin(theta), math.Cos(theta)
+	if math.Abs(sinT) < 1e-10 {
+		sinT = 1e-10
+	}
+
+	P, dP := legendre(sinT, cosT)
+
+	var xc, yc, zc float64
+	ratio := referenceRadius / r
+	scale := ratio * ratio
+	for n := 1; n <= MaxDegree; n++ {
+		scale *= ratio // (a/r)^(n+2)
+		for k := 0; k <= n; k++ {
+			g := m.g[n][k] + dt*m.gDot[n][k]
+			h := m.h[n][k] + dt*m.hDot[n][k]
+			cosM, sinM := math.Cos(float64(k)*lambda), math.Sin(float64(k)*lambda)
+
+			xc += scale * (g*cosM + h*sinM) * dP[n][k]
+			yc += scale * float64(k) * (g*sinM - h*cosM) * P[n][k] / sinT
+			zc -= scale * float64(n+1) * (g*cosM + h*sinM) * P[n][k]
+		}
+	}
+
+	// Rotate from geocentric to geodetic axes
+	psi := phiC - phi
+	f := Field{
+		X: xc*math.Cos(psi) - zc*math.Sin(psi),
+		Y: yc,
+		Z: xc*math.Sin(psi) + zc*math.Cos(psi),
+	}
+	f.H = math.Hypot(f.X, f.Y)
+	f.F = math.Hypot(f.H, f.Z)
+	f.Inclination = math.Atan2(f.Z, f.H) * 180 / math.Pi
+	f.Declination = math.Atan2(f.Y, f.X) * 180 / math.Pi
+
+	return f
+}
+
+// Declination returns the magnetic declination (variation) at sea level in
+// degrees, east positive
+func (m *Model) Declination(lat, lon float64, t time.Time) float64 {
+	return m.Field(lat, lon, 0, t).Declination
+}
+
+// Declination returns the declination from the embedded model
+func Declination(lat, lon float64, t time.Time) float64 {
+	return Default().Declination(lat, lon, t)
+}
+
+// DecimalYear converts a time to a decimal year
+func DecimalYear(t time.Time) float64 {
+	t = t.UTC()
+	start := time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	return float64(t.Year()) + t.Sub(start).Seconds()/end.Sub(start).Seconds()
+}
+
+// legendre returns the Schmidt semi-normalized associated Legendre functions
+// of cos(theta) and their derivatives with respect to theta
+func legendre(sinT, cosT float64) (P, dP [MaxDegree + 1][MaxDegree + 1]float64) {
+	// Gauss-normalized recursion
+	P[0][0] = 1
+	for n := 1; n <= MaxDegree; n++ {
+		for k := 0; k <= n; k++ {
+			switch {
+			case n == k:
+				P[n][k] = sinT * P[n-1][k-1]
+				dP[n][k] = sinT*dP[n-1][k-1] + cosT*P[n-1][k-1]
+			case n == 1:
+				P[n][k] = cosT * P[n-1][k]
+				dP[n][k] = cosT*dP[n-1][k] - sinT*P[n-1][k]
+			default:
+				K := float64((n-1)*(n-1)-k*k) / float64((2*n-1)*(2*n-3))
+				P[n][k] = cosT*P[n-1][k] - K*P[n-2][k]
+				dP[n][k] = cosT*dP[n-1][k] - sinT*P[n-1][k] - K*dP[n-2][k]
+			}
+		}
+	}
+
+	// Convert to Schmidt semi-normalization
+	var S [MaxDegree + 1][MaxDegree + 1]float64
+	S[0][0] = 1
+	for n := 1; n <= MaxDegree; n++ {
+		S[n][0] = S[n-1][0] * float64(2*n-1) / float64(n)
+		for k := 1; k <= n; k++ {
+			delta := 1.0
+			if k == 1 {
+				delta = 2
+			}
+			S[n][k] = S[n][k-1] * math.Sqrt(float64(n-k+1)*delta/float64(n+k))
+		}
+	}
+	for n := 1; n <= MaxDegree; n++ {
+		for k := 0; k <= n; k++ {
+			P[n][k] *= S[n][k]
+			dP[n][k] *= S[n][k]
+		}
+	}
+
+	return P, dP
+}
@@ -0,0 +1,76 @@
+package wmm
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldMatchesWMM2020TestValues(t *testing.T) {
+	f, err := os.Open("testdata/WMM2020.COF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	m, err := Load(f)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Test values from the WMM2020 technical report
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		lat, lon, alt    float64
+		x, z, incl, decl float64
+	}{
+		{80, 0, 0, 6570.4, 54606.0, 83.14, -1.28},
+		{0, 120, 0, 39624.3, -10932.5, -15.42, 0.16},
+		{-80, 240, 0, 5940.6, -52480.8, -72.20, 69.36},
+		{80, 0, 100, 6261.8, 52429.1, 83.19, -1.70},
+	}
+
+	for _, tt := range tests {
+		field := m.Field(tt.lat, tt.lon, tt.alt, epoch)
+		if math.Abs(field.X-tt.x) > 1 || math.Abs(field.Z-tt.z) > 1 {
+			t.Errorf("(%.0f, %.0f, %.0f km): expected X %.1f Z %.1f, got X %.1f Z %.1f", tt.lat, tt.lon, tt.alt, tt.x, tt.z, field.X, field.Z)
+		}
+		if math.Abs(field.Inclination-tt.incl) > 0.01 || math.Abs(field.Declination-tt.decl) > 0.01 {
+			t.Errorf("(%.0f, %.0f, %.0f km): expected I %.2f D %.2f, got I %.2f D %.2f", tt.lat, tt.lon, tt.alt, tt.incl, tt.decl, field.Inclination, field.Declination)
+		}
+	}
+}
+
+func TestDefaultModel(t *testing.T) {
+	m := Default()
+	if m.Name != "WMM-2025" || m.Epoch != 2025 {
+		t.Errorf("Unexpected embedded model %s %.1f", m.Name, m.Epoch)
+	}
+
+	// Vienna has an easterly variation of about 5 degrees
+	if d := Declination(48.2, 16.36, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)); d < 4.5 || d > 6 {
+		t.Errorf("Unexpected declination at Vienna: %.2f", d)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for _, data := range []string{
+		"",
+		"2025.0",
+		"2025.0 WMM\n  1  0  -29351.8 0.0 12.0\n",
+		"2025.0 WMM\n 13  0  1.0 0.0 0.0 0.0\n",
+		"2025.0 WMM\n  1  0  -29351.8 0.0 12.0 0.0\n999999\n",
+	} {
+		if _, err := Load(strings.NewReader(data)); err == nil {
+			t.Errorf("Expected error loading %q", data)
+		}
+	}
+}
+
+func TestDecimalYear(t *testing.T) {
+	if y := DecimalYear(time.Date(2025, 7, 2, 12, 0, 0, 0, time.UTC)); math.Abs(y-2025.5) > 1e-9 {
+		t.Errorf("Expected 2025.5, got %.6f", y)
+	}
+}